@@ -14,7 +14,9 @@ import (
 
 	"jupitor/internal/cnapi"
 	"jupitor/internal/config"
+	"jupitor/internal/domain"
 	"jupitor/internal/store"
+	"jupitor/internal/util"
 )
 
 func main() {
@@ -40,6 +42,15 @@ func main() {
 	logger := slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: slog.LevelInfo}))
 	slog.SetDefault(logger)
 
+	markets, err := util.LoadMarketRegistry(cfg.MarketsFile)
+	if err != nil {
+		log.Fatalf("loading markets: %v", err)
+	}
+	cnMarket, err := markets.Get(domain.MarketCN)
+	if err != nil {
+		log.Fatalf("loading markets: %v", err)
+	}
+
 	// Create store and server.
 	ps := store.NewParquetStore(cfg.Storage.DataDir)
 	referenceDir := "reference"
@@ -47,6 +58,7 @@ func main() {
 		referenceDir = d
 	}
 	srv := cnapi.NewCNServer(cfg.Storage.DataDir, referenceDir, ps, logger)
+	srv.SetMarket(cnMarket)
 
 	if err := srv.Init(); err != nil {
 		log.Fatalf("initializing CN server: %v", err)
@@ -0,0 +1,448 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"jupitor/internal/domain"
+	"jupitor/internal/ettime"
+	"jupitor/pkg/jupitor"
+)
+
+// ---------------------------------------------------------------------------
+// Market data
+// ---------------------------------------------------------------------------
+
+func runBars(ctx context.Context, args []string) error {
+	o := newOptions("bars")
+	market := o.fs.String("market", "us", "market: us or cn")
+	start := o.fs.String("start", "", "first day (YYYY-MM-DD)")
+	end := o.fs.String("end", "", "last day (YYYY-MM-DD)")
+	pos, err := o.parse(args, 1)
+	if err != nil {
+		return err
+	}
+	// US days are ET trading days; cn bars are keyed by UTC-midnight dates.
+	loc := ettime.Location()
+	if strings.EqualFold(*market, "cn") {
+		loc = time.UTC
+	}
+	from, to, err := parseRange(*start, *end, loc)
+	if err != nil {
+		return err
+	}
+
+	bars, err := o.client().GetBars(ctx, strings.ToUpper(pos[0]), *market, from, to)
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, len(bars))
+	for i, b := range bars {
+		rows[i] = []string{
+			b.Symbol, b.Timestamp.Format(time.DateOnly),
+			ftoa(b.Open), ftoa(b.High), ftoa(b.Low), ftoa(b.Close),
+			strconv.FormatInt(b.Volume, 10), ftoa(b.VWAP),
+		}
+	}
+	return o.print(bars, []string{"SYMBOL", "DATE", "OPEN", "HIGH", "LOW", "CLOSE", "VOLUME", "VWAP"}, rows)
+}
+
+func runTrades(ctx context.Context, args []string) error {
+	o := newOptions("trades")
+	start := o.fs.String("start", "", "start (YYYY-MM-DD or RFC 3339)")
+	end := o.fs.String("end", "", "end (YYYY-MM-DD or RFC 3339)")
+	pos, err := o.parse(args, 1)
+	if err != nil {
+		return err
+	}
+	from, to, err := parseRange(*start, *end, ettime.Location())
+	if err != nil {
+		return err
+	}
+
+	trades, err := o.client().GetTrades(ctx, strings.ToUpper(pos[0]), from, to)
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, len(trades))
+	for i, t := range trades {
+		rows[i] = []string{
+			t.Symbol, t.Timestamp.Format(time.RFC3339Nano),
+			ftoa(t.Price), strconv.FormatInt(t.Size, 10), t.Exchange, t.ID,
+		}
+	}
+	return o.print(trades, []string{"SYMBOL", "TIME", "PRICE", "SIZE", "EXCHANGE", "ID"}, rows)
+}
+
+// ---------------------------------------------------------------------------
+// Trading
+// ---------------------------------------------------------------------------
+
+var orderHeaders = []string{"ID", "SYMBOL", "SIDE", "TYPE", "QTY", "LIMIT", "STOP", "STATUS", "FILLED", "AVG_PRICE", "STRATEGY", "CREATED"}
+
+func orderRow(o domain.Order) []string {
+	return []string{
+		o.ID, o.Symbol, string(o.Side), string(o.Type), ftoa(o.Qty),
+		ftoa(o.LimitPrice), ftoa(o.StopPrice), string(o.Status),
+		ftoa(o.FilledQty), ftoa(o.FilledAvgPrice), o.StrategyID, fmtTime(o.CreatedAt),
+	}
+}
+
+func runOrdersList(ctx context.Context, args []string) error {
+	o := newOptions("orders list")
+	status := o.fs.String("status", "", "only orders with this status")
+	if _, err := o.parse(args, 0); err != nil {
+		return err
+	}
+
+	orders, err := o.client().GetOrders(ctx, domain.OrderStatus(*status))
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, len(orders))
+	for i, ord := range orders {
+		rows[i] = orderRow(ord)
+	}
+	return o.print(orders, orderHeaders, rows)
+}
+
+func runOrdersSubmit(ctx context.Context, args []string) error {
+	o := newOptions("orders submit")
+	side := o.fs.String("side", "", "buy or sell")
+	qty := o.fs.Float64("qty", 0, "quantity")
+	typ := o.fs.String("type", "market", "market, limit, stop or stop_limit")
+	limit := o.fs.Float64("limit", 0, "limit price")
+	stop := o.fs.Float64("stop", 0, "stop price")
+	tif := o.fs.String("tif", "day", "time in force: day, gtc, ioc or fok")
+	strategyID := o.fs.String("strategy", "", "strategy ID to attribute the order to")
+	pos, err := o.parse(args, 1)
+	if err != nil {
+		return err
+	}
+	if *side == "" || *qty <= 0 {
+		return usageError("-side and a positive -qty are required")
+	}
+
+	order, err := o.client().SubmitOrder(ctx, &domain.Order{
+		Symbol:      strings.ToUpper(pos[0]),
+		Side:        domain.OrderSide(*side),
+		Type:        domain.OrderType(*typ),
+		TimeInForce: domain.TimeInForce(*tif),
+		Qty:         *qty,
+		LimitPrice:  *limit,
+		StopPrice:   *stop,
+		StrategyID:  *strategyID,
+	})
+	if err != nil {
+		return err
+	}
+	return o.print(order, orderHeaders, [][]string{orderRow(*order)})
+}
+
+func runOrdersCancel(ctx context.Context, args []string) error {
+	o := newOptions("orders cancel")
+	pos, err := o.parse(args, 1)
+	if err != nil {
+		return err
+	}
+
+	order, err := o.client().CancelOrder(ctx, pos[0])
+	if err != nil {
+		return err
+	}
+	return o.print(order, orderHeaders, [][]string{orderRow(*order)})
+}
+
+func runPositions(ctx context.Context, args []string) error {
+	o := newOptions("positions")
+	if _, err := o.parse(args, 0); err != nil {
+		return err
+	}
+
+	positions, err := o.client().GetPositions(ctx)
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, len(positions))
+	for i, p := range positions {
+		rows[i] = []string{
+			p.Symbol, string(p.Side), ftoa(p.Qty), ftoa(p.AvgEntryPrice),
+			ftoa(p.MarketValue), ftoa(p.UnrealizedPL), ftoa(p.UnrealizedPLPct),
+		}
+	}
+	return o.print(positions, []string{"SYMBOL", "SIDE", "QTY", "AVG_ENTRY", "MARKET_VALUE", "UNREALIZED_PL", "UNREALIZED_PL_PCT"}, rows)
+}
+
+func runAccount(ctx context.Context, args []string) error {
+	o := newOptions("account")
+	if _, err := o.parse(args, 0); err != nil {
+		return err
+	}
+
+	acct, err := o.client().GetAccount(ctx)
+	if err != nil {
+		return err
+	}
+	return o.print(acct,
+		[]string{"EQUITY", "CASH", "BUYING_POWER", "PORTFOLIO_VALUE", "DAILY_PL", "DAILY_PL_PCT"},
+		[][]string{{
+			ftoa(acct.Equity), ftoa(acct.Cash), ftoa(acct.BuyingPower),
+			ftoa(acct.PortfolioValue), ftoa(acct.DailyPL), ftoa(acct.DailyPLPct),
+		}})
+}
+
+// ---------------------------------------------------------------------------
+// Strategies
+// ---------------------------------------------------------------------------
+
+func runStrategiesList(ctx context.Context, args []string) error {
+	o := newOptions("strategies list")
+	if _, err := o.parse(args, 0); err != nil {
+		return err
+	}
+	sc, err := jupitor.DialStrategies(o.grpc)
+	if err != nil {
+		return err
+	}
+	defer sc.Close()
+
+	infos, err := sc.ListStrategies(ctx)
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, len(infos))
+	for i, s := range infos {
+		rows[i] = []string{s.ID, s.Status, strings.Join(s.Symbols, ","), formatParams(s.Params), s.Description}
+	}
+	return o.print(infos, []string{"ID", "STATUS", "SYMBOLS", "PARAMS", "DESCRIPTION"}, rows)
+}
+
+func runStrategiesStart(ctx context.Context, args []string) error {
+	o := newOptions("strategies start")
+	var symbols listFlag
+	params := paramFlag{}
+	o.fs.Var(&symbols, "symbols", "comma-separated symbols (default all)")
+	o.fs.Var(params, "param", "strategy parameter key=value (repeatable)")
+	pos, err := o.parse(args, 1)
+	if err != nil {
+		return err
+	}
+	sc, err := jupitor.DialStrategies(o.grpc)
+	if err != nil {
+		return err
+	}
+	defer sc.Close()
+
+	if err := sc.StartStrategy(ctx, pos[0], symbols, params); err != nil {
+		return err
+	}
+	fmt.Fprintf(o.out, "started %s\n", pos[0])
+	return nil
+}
+
+func runStrategiesStop(ctx context.Context, args []string) error {
+	o := newOptions("strategies stop")
+	pos, err := o.parse(args, 1)
+	if err != nil {
+		return err
+	}
+	sc, err := jupitor.DialStrategies(o.grpc)
+	if err != nil {
+		return err
+	}
+	defer sc.Close()
+
+	if err := sc.StopStrategy(ctx, pos[0]); err != nil {
+		return err
+	}
+	fmt.Fprintf(o.out, "stopped %s\n", pos[0])
+	return nil
+}
+
+func runBacktest(ctx context.Context, args []string) error {
+	o := newOptions("backtest run")
+	var symbols listFlag
+	params := paramFlag{}
+	start := o.fs.String("start", "", "first day (YYYY-MM-DD)")
+	end := o.fs.String("end", "", "last day (YYYY-MM-DD)")
+	capital := o.fs.Float64("capital", 100000, "initial capital")
+	signals := o.fs.Bool("signals", false, "list generated signals instead of the summary")
+	o.fs.Var(&symbols, "symbols", "comma-separated symbols")
+	o.fs.Var(params, "param", "strategy parameter key=value (repeatable)")
+	pos, err := o.parse(args, 1)
+	if err != nil {
+		return err
+	}
+	if *start == "" || *end == "" {
+		return usageError("-start and -end are required")
+	}
+	from, to, err := parseRange(*start, *end, ettime.Location())
+	if err != nil {
+		return err
+	}
+	sc, err := jupitor.DialStrategies(o.grpc)
+	if err != nil {
+		return err
+	}
+	defer sc.Close()
+
+	res, err := sc.RunBacktest(ctx, jupitor.BacktestRequest{
+		StrategyID:     pos[0],
+		Symbols:        symbols,
+		Start:          from,
+		End:            to,
+		InitialCapital: *capital,
+		Params:         params,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *signals {
+		rows := make([][]string, len(res.Signals))
+		for i, s := range res.Signals {
+			rows[i] = []string{fmtTime(s.CreatedAt), s.Symbol, string(s.Type), ftoa(s.Strength)}
+		}
+		return o.print(res.Signals, []string{"TIME", "SYMBOL", "TYPE", "STRENGTH"}, rows)
+	}
+	return o.print(res,
+		[]string{"STRATEGY", "TOTAL_RETURN", "SHARPE", "MAX_DRAWDOWN", "TRADES", "WIN_RATE", "PROFIT_FACTOR", "SIGNALS"},
+		[][]string{{
+			res.StrategyID, ftoa(res.TotalReturn), ftoa(res.SharpeRatio), ftoa(res.MaxDrawdown),
+			strconv.Itoa(res.TotalTrades), ftoa(res.WinRate), ftoa(res.ProfitFactor),
+			strconv.Itoa(len(res.Signals)),
+		}})
+}
+
+// ---------------------------------------------------------------------------
+// Dashboard (us-stream)
+// ---------------------------------------------------------------------------
+
+func runWatchlistList(ctx context.Context, args []string) error {
+	o := newOptions("watchlist list")
+	date := o.fs.String("date", "", "trading day (YYYY-MM-DD, default today)")
+	if _, err := o.parse(args, 0); err != nil {
+		return err
+	}
+
+	symbols, err := o.streamClient().GetWatchlist(ctx, *date)
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, len(symbols))
+	for i, s := range symbols {
+		rows[i] = []string{s}
+	}
+	return o.print(symbols, []string{"SYMBOL"}, rows)
+}
+
+func runWatchlistAdd(ctx context.Context, args []string) error {
+	o := newOptions("watchlist add")
+	date := o.fs.String("date", "", "trading day (YYYY-MM-DD, default today)")
+	pos, err := o.parse(args, 1)
+	if err != nil {
+		return err
+	}
+
+	c := o.streamClient()
+	for _, sym := range pos {
+		if err := c.AddToWatchlist(ctx, *date, strings.ToUpper(sym)); err != nil {
+			return fmt.Errorf("adding %s: %w", sym, err)
+		}
+	}
+	return nil
+}
+
+func runWatchlistRemove(ctx context.Context, args []string) error {
+	o := newOptions("watchlist rm")
+	date := o.fs.String("date", "", "trading day (YYYY-MM-DD, default today)")
+	pos, err := o.parse(args, 1)
+	if err != nil {
+		return err
+	}
+
+	c := o.streamClient()
+	for _, sym := range pos {
+		if err := c.RemoveFromWatchlist(ctx, *date, strings.ToUpper(sym)); err != nil {
+			return fmt.Errorf("removing %s: %w", sym, err)
+		}
+	}
+	return nil
+}
+
+func runTargetsGet(ctx context.Context, args []string) error {
+	o := newOptions("targets get")
+	date := o.fs.String("date", "", "trading day (YYYY-MM-DD, default today)")
+	if _, err := o.parse(args, 0); err != nil {
+		return err
+	}
+
+	targets, err := o.streamClient().GetTargets(ctx, *date)
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(targets))
+	for k := range targets {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	rows := make([][]string, len(keys))
+	for i, k := range keys {
+		rows[i] = []string{k, ftoa(targets[k])}
+	}
+	return o.print(targets, []string{"KEY", "VALUE"}, rows)
+}
+
+func runTargetsSet(ctx context.Context, args []string) error {
+	o := newOptions("targets set")
+	date := o.fs.String("date", "", "trading day (YYYY-MM-DD, default today)")
+	pos, err := o.parse(args, 2)
+	if err != nil {
+		return err
+	}
+	value, err := strconv.ParseFloat(pos[1], 64)
+	if err != nil {
+		return usageError(fmt.Sprintf("invalid value %q", pos[1]))
+	}
+	day := *date
+	if day == "" {
+		// us-stream keys trade parameters by the ET trading day.
+		day = ettime.DateOf(time.Now())
+	}
+
+	return o.streamClient().SetTarget(ctx, day, pos[0], value)
+}
+
+// ---------------------------------------------------------------------------
+// Helpers
+// ---------------------------------------------------------------------------
+
+// parseRange parses start and end with parseTime, reading dates in loc.
+func parseRange(start, end string, loc *time.Location) (time.Time, time.Time, error) {
+	from, err := parseTime(start, false, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to, err := parseTime(end, true, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return from, to, nil
+}
+
+func formatParams(params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + "=" + params[k]
+	}
+	return strings.Join(parts, ",")
+}
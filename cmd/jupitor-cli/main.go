@@ -1,43 +1,123 @@
+// Command jupitor-cli is the operator command line for jupitor-server and
+// us-stream.
+//
+// Usage:
+//
+//	jupitor-cli <command> [subcommand] [flags] [args]
+//
+// Flags may appear before or after positional arguments. Every command
+// accepts -o table|json|csv and the endpoint flags below, which default to
+// $JUPITOR_SERVER, $JUPITOR_GRPC and $US_STREAM_URL.
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 )
 
 const version = "0.1.0"
 
-func main() {
-	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: jupitor-cli <command> [options]\n\n")
-		fmt.Fprintf(os.Stderr, "Commands:\n")
-		fmt.Fprintf(os.Stderr, "  version    Print the CLI version\n")
-		fmt.Fprintf(os.Stderr, "  status     Show jupitor-server status\n")
-		fmt.Fprintf(os.Stderr, "  symbols    List available symbols\n")
-		fmt.Fprintf(os.Stderr, "\n")
+// command is a leaf command; run receives the arguments after its name.
+type command struct {
+	usage string
+	run   func(ctx context.Context, args []string) error
+}
+
+// commands maps "name" or "name sub" to the command to run.
+var commands = map[string]command{
+	"bars":             {"bars [-market us|cn] [-start DATE] [-end DATE] SYMBOL", runBars},
+	"trades":           {"trades [-start TIME] [-end TIME] SYMBOL", runTrades},
+	"orders list":      {"orders list [-status STATUS]", runOrdersList},
+	"orders submit":    {"orders submit -side buy|sell -qty N [-type T] [-limit P] [-stop P] [-tif TIF] [-strategy ID] SYMBOL", runOrdersSubmit},
+	"orders cancel":    {"orders cancel ORDER_ID", runOrdersCancel},
+	"positions":        {"positions", runPositions},
+	"account":          {"account", runAccount},
+	"strategies list":  {"strategies list", runStrategiesList},
+	"strategies start": {"strategies start [-symbols A,B] [-param K=V]... STRATEGY_ID", runStrategiesStart},
+	"strategies stop":  {"strategies stop STRATEGY_ID", runStrategiesStop},
+	"backtest run":     {"backtest run -start DATE -end DATE [-symbols A,B] [-capital N] [-param K=V]... [-signals] STRATEGY_ID", runBacktest},
+	"watchlist list":   {"watchlist list [-date DATE]", runWatchlistList},
+	"watchlist add":    {"watchlist add [-date DATE] SYMBOL...", runWatchlistAdd},
+	"watchlist rm":     {"watchlist rm [-date DATE] SYMBOL...", runWatchlistRemove},
+	"targets get":      {"targets get [-date DATE]", runTargetsGet},
+	"targets set":      {"targets set [-date DATE] KEY VALUE", runTargetsSet},
+}
+
+// commandOrder lists commands in usage order.
+var commandOrder = []string{
+	"bars", "trades",
+	"orders list", "orders submit", "orders cancel",
+	"positions", "account",
+	"strategies list", "strategies start", "strategies stop",
+	"backtest run",
+	"watchlist list", "watchlist add", "watchlist rm",
+	"targets get", "targets set",
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: jupitor-cli <command> [flags] [args]\n\n")
+	fmt.Fprintf(os.Stderr, "Commands:\n")
+	fmt.Fprintf(os.Stderr, "  version\n")
+	for _, name := range commandOrder {
+		fmt.Fprintf(os.Stderr, "  %s\n", commands[name].usage)
 	}
+	fmt.Fprintf(os.Stderr, "\nCommon flags:\n")
+	fmt.Fprintf(os.Stderr, "  -o table|json|csv   output format (default table)\n")
+	fmt.Fprintf(os.Stderr, "  -server URL         jupitor-server REST URL ($JUPITOR_SERVER, default %s)\n", defaultServer)
+	fmt.Fprintf(os.Stderr, "  -grpc ADDR          jupitor-server gRPC address ($JUPITOR_GRPC, default %s)\n", defaultGRPC)
+	fmt.Fprintf(os.Stderr, "  -stream URL         us-stream HTTP URL ($US_STREAM_URL, default %s)\n", defaultStream)
+	fmt.Fprintf(os.Stderr, "\n")
+}
 
+// lookup resolves the command named by the leading one or two words of args
+// and returns its name, the command and the remaining arguments.
+func lookup(args []string) (string, command, []string, bool) {
+	name, rest := args[0], args[1:]
+	if cmd, ok := commands[name]; ok {
+		return name, cmd, rest, true
+	}
+	if len(rest) > 0 {
+		if cmd, ok := commands[name+" "+rest[0]]; ok {
+			return name + " " + rest[0], cmd, rest[1:], true
+		}
+		name += " " + rest[0]
+	}
+	return name, command{}, nil, false
+}
+
+func main() {
+	flag.Usage = usage
 	if len(os.Args) < 2 {
-		flag.Usage()
+		usage()
 		os.Exit(1)
 	}
-
-	switch os.Args[1] {
-	case "version":
+	if os.Args[1] == "version" {
 		fmt.Printf("jupitor-cli %s\n", version)
+		return
+	}
 
-	case "status":
-		// TODO: Connect to jupitor-server API and retrieve status.
-		fmt.Println("status: not implemented")
+	name, cmd, args, ok := lookup(os.Args[1:])
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n\n", name)
+		usage()
+		os.Exit(1)
+	}
 
-	case "symbols":
-		// TODO: Connect to jupitor-server API and list symbols.
-		fmt.Println("symbols: not implemented")
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
 
-	default:
-		fmt.Fprintf(os.Stderr, "unknown command: %s\n\n", os.Args[1])
-		flag.Usage()
+	if err := cmd.run(ctx, args); err != nil {
+		var ue usageError
+		if errors.As(err, &ue) {
+			fmt.Fprintf(os.Stderr, "%v\nusage: jupitor-cli %s\n", err, cmd.usage)
+			os.Exit(2)
+		}
+		fmt.Fprintf(os.Stderr, "jupitor-cli %s: %v\n", name, err)
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"jupitor/internal/ettime"
+)
+
+func TestLookup(t *testing.T) {
+	for _, tc := range []struct {
+		args     []string
+		wantName string
+		wantRest []string
+		wantOK   bool
+	}{
+		{[]string{"positions", "-o", "json"}, "positions", []string{"-o", "json"}, true},
+		{[]string{"orders", "list", "-status", "filled"}, "orders list", []string{"-status", "filled"}, true},
+		{[]string{"orders"}, "orders", nil, false},
+		{[]string{"orders", "bogus"}, "orders bogus", nil, false},
+		{[]string{"nope"}, "nope", nil, false},
+	} {
+		name, _, rest, ok := lookup(tc.args)
+		if name != tc.wantName || ok != tc.wantOK || strings.Join(rest, " ") != strings.Join(tc.wantRest, " ") {
+			t.Errorf("lookup(%q) = %q, %q, %v; want %q, %q, %v",
+				tc.args, name, rest, ok, tc.wantName, tc.wantRest, tc.wantOK)
+		}
+	}
+	for _, name := range commandOrder {
+		if _, ok := commands[name]; !ok {
+			t.Errorf("commandOrder lists unknown command %q", name)
+		}
+	}
+	if len(commandOrder) != len(commands) {
+		t.Errorf("commandOrder has %d commands, commands has %d", len(commandOrder), len(commands))
+	}
+}
+
+func TestOptionsParseInterleaved(t *testing.T) {
+	o := newOptions("test")
+	side := o.fs.String("side", "", "")
+	var symbols listFlag
+	params := paramFlag{}
+	o.fs.Var(&symbols, "symbols", "")
+	o.fs.Var(params, "param", "")
+
+	pos, err := o.parse([]string{"AAPL", "-side", "buy", "MSFT", "-o", "csv", "-symbols", "a, b", "-param", "k=v", "-param", "x=1"}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(pos, ",") != "AAPL,MSFT" || *side != "buy" || o.output != "csv" {
+		t.Errorf("pos = %v, side = %q, output = %q", pos, *side, o.output)
+	}
+	if strings.Join(symbols, ",") != "A,B" || params["k"] != "v" || params["x"] != "1" {
+		t.Errorf("symbols = %v, params = %v", symbols, params)
+	}
+
+	var ue usageError
+	for _, args := range [][]string{
+		{"AAPL"},                   // too few positional arguments
+		{"AAPL", "B", "-o", "xml"}, // unknown output format
+		{"AAPL", "B", "-bogus"},    // unknown flag
+	} {
+		if _, err := newOptions("test").parse(args, 2); !errors.As(err, &ue) {
+			t.Errorf("parse(%q) err = %v, want usageError", args, err)
+		}
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	et := ettime.Location()
+	for _, tc := range []struct {
+		in    string
+		isEnd bool
+		loc   *time.Location
+		want  time.Time
+	}{
+		{"", false, et, time.Time{}},
+		{"2024-03-08", false, et, time.Date(2024, 3, 8, 5, 0, 0, 0, time.UTC)},
+		{"2024-03-08", true, et, time.Date(2024, 3, 9, 5, 0, 0, 0, time.UTC).Add(-time.Nanosecond)},
+		// DST starts on 2024-03-10: the ET day is 23 hours long.
+		{"2024-03-10", true, et, time.Date(2024, 3, 11, 4, 0, 0, 0, time.UTC).Add(-time.Nanosecond)},
+		{"2024-03-08", false, time.UTC, time.Date(2024, 3, 8, 0, 0, 0, 0, time.UTC)},
+		{"2024-03-08T14:30:00Z", true, et, time.Date(2024, 3, 8, 14, 30, 0, 0, time.UTC)},
+	} {
+		got, err := parseTime(tc.in, tc.isEnd, tc.loc)
+		if err != nil || !got.Equal(tc.want) {
+			t.Errorf("parseTime(%q, %v, %s) = %v, %v; want %v", tc.in, tc.isEnd, tc.loc, got, err, tc.want)
+		}
+	}
+	var ue usageError
+	if _, err := parseTime("03/08/2024", false, et); !errors.As(err, &ue) {
+		t.Errorf("bad time err = %v, want usageError", err)
+	}
+}
+
+func TestOptionsPrint(t *testing.T) {
+	headers := []string{"SYMBOL", "QTY"}
+	rows := [][]string{{"AAPL", "10"}, {"MSFT", "2.5"}}
+	v := []map[string]any{{"symbol": "AAPL", "qty": 10}, {"symbol": "MSFT", "qty": 2.5}}
+
+	for _, tc := range []struct {
+		format string
+		want   string
+	}{
+		{"table", "SYMBOL  QTY\nAAPL    10\nMSFT    2.5\n"},
+		{"csv", "SYMBOL,QTY\nAAPL,10\nMSFT,2.5\n"},
+		{"json", "[\n  {\n    \"qty\": 10,\n    \"symbol\": \"AAPL\"\n  },\n  {\n    \"qty\": 2.5,\n    \"symbol\": \"MSFT\"\n  }\n]\n"},
+	} {
+		var buf bytes.Buffer
+		o := &options{output: tc.format, out: &buf}
+		if err := o.print(v, headers, rows); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tc.want {
+			t.Errorf("%s output:\n%s\nwant:\n%s", tc.format, buf.String(), tc.want)
+		}
+	}
+}
+
+func TestRunPositions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/positions" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{
+			{"symbol": "AAPL", "side": "long", "qty": 10, "avg_entry_price": 150, "market_value": 1600,
+				"unrealized_pl": 100, "unrealized_pl_pct": 0.0667},
+		}})
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	old := stdout
+	stdout = &buf
+	defer func() { stdout = old }()
+
+	if err := runPositions(context.Background(), []string{"-server", srv.URL, "-o", "csv"}); err != nil {
+		t.Fatal(err)
+	}
+	want := "SYMBOL,SIDE,QTY,AVG_ENTRY,MARKET_VALUE,UNREALIZED_PL,UNREALIZED_PL_PCT\nAAPL,long,10,150,1600,100,0.0667\n"
+	if buf.String() != want {
+		t.Errorf("output:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	var ue usageError
+	if err := runPositions(context.Background(), []string{"-server", srv.URL, "extra", "-o", "yaml"}); !errors.As(err, &ue) {
+		t.Errorf("bad output format err = %v, want usageError", err)
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"jupitor/pkg/jupitor"
+)
+
+// Default endpoints, matching server.port, server.grpc_port and stream.port
+// in config/jupitor.yaml.
+const (
+	defaultServer = "http://localhost:8090"
+	defaultGRPC   = "localhost:9090"
+	defaultStream = "http://localhost:8080"
+)
+
+// stdout is where commands print their results.
+var stdout io.Writer = os.Stdout
+
+// usageError reports invalid command-line arguments.
+type usageError string
+
+func (e usageError) Error() string { return string(e) }
+
+// options holds the flags shared by every command plus the command's own
+// flag set.
+type options struct {
+	fs     *flag.FlagSet
+	output string
+	server string
+	grpc   string
+	stream string
+	out    io.Writer
+}
+
+func newOptions(name string) *options {
+	o := &options{fs: flag.NewFlagSet(name, flag.ContinueOnError), out: stdout}
+	o.fs.SetOutput(io.Discard)
+	o.fs.StringVar(&o.output, "o", "table", "output format: table, json or csv")
+	o.fs.StringVar(&o.server, "server", envOr("JUPITOR_SERVER", defaultServer), "jupitor-server REST URL")
+	o.fs.StringVar(&o.grpc, "grpc", envOr("JUPITOR_GRPC", defaultGRPC), "jupitor-server gRPC address")
+	o.fs.StringVar(&o.stream, "stream", envOr("US_STREAM_URL", defaultStream), "us-stream HTTP URL")
+	return o
+}
+
+// parse parses flags interspersed with positional arguments, returning the
+// positional arguments. It fails unless at least minArgs were given.
+func (o *options) parse(args []string, minArgs int) ([]string, error) {
+	var pos []string
+	for {
+		if err := o.fs.Parse(args); err != nil {
+			return nil, usageError(err.Error())
+		}
+		rest := o.fs.Args()
+		if len(rest) == 0 {
+			break
+		}
+		pos = append(pos, rest[0])
+		args = rest[1:]
+	}
+	switch o.output {
+	case "table", "json", "csv":
+	default:
+		return nil, usageError(fmt.Sprintf("unknown output format %q", o.output))
+	}
+	if len(pos) < minArgs {
+		return nil, usageError("missing arguments")
+	}
+	return pos, nil
+}
+
+func (o *options) client() *jupitor.Client { return jupitor.NewClient(o.server) }
+
+func (o *options) streamClient() *jupitor.Client { return jupitor.NewClient(o.stream) }
+
+// print writes v as JSON, or rows under headers as a table or CSV.
+func (o *options) print(v any, headers []string, rows [][]string) error {
+	switch o.output {
+	case "json":
+		enc := json.NewEncoder(o.out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case "csv":
+		w := csv.NewWriter(o.out)
+		w.Write(headers)
+		w.WriteAll(rows)
+		return w.Error()
+	default:
+		w := tabwriter.NewWriter(o.out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, strings.Join(headers, "\t"))
+		for _, r := range rows {
+			fmt.Fprintln(w, strings.Join(r, "\t"))
+		}
+		return w.Flush()
+	}
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// parseTime parses a date (2006-01-02) or RFC 3339 timestamp; an empty
+// string yields the zero time. A date starts at midnight in loc, and a
+// date-only end covers that whole day in loc.
+func parseTime(s string, isEnd bool, loc *time.Location) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, s, loc); err == nil {
+		if isEnd {
+			t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, usageError(fmt.Sprintf("invalid time %q (want YYYY-MM-DD or RFC 3339)", s))
+	}
+	return t, nil
+}
+
+// listFlag is a comma-separated list flag.
+type listFlag []string
+
+func (l *listFlag) String() string { return strings.Join(*l, ",") }
+
+func (l *listFlag) Set(s string) error {
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, strings.ToUpper(v))
+		}
+	}
+	return nil
+}
+
+// paramFlag is a repeatable key=value flag.
+type paramFlag map[string]string
+
+func (p paramFlag) String() string { return fmt.Sprint(map[string]string(p)) }
+
+func (p paramFlag) Set(s string) error {
+	k, v, ok := strings.Cut(s, "=")
+	if !ok || k == "" {
+		return fmt.Errorf("want key=value, got %q", s)
+	}
+	p[k] = v
+	return nil
+}
+
+func ftoa(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+
+func fmtTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
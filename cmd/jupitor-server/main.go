@@ -1,11 +1,36 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"jupitor/internal/api"
+	"jupitor/internal/broker"
 	"jupitor/internal/config"
+	"jupitor/internal/dashboard"
+	"jupitor/internal/domain"
+	"jupitor/internal/engine"
+	"jupitor/internal/gather/us"
+	"jupitor/internal/live"
+	"jupitor/internal/store"
+	"jupitor/internal/strategy"
+	"jupitor/internal/strategy/builtins"
+	"jupitor/internal/util"
+	"jupitor/pkg/jupitor"
+)
+
+const (
+	// reconcileInterval is how often broker state is diffed against the stores.
+	reconcileInterval = 30 * time.Second
+
+	// shutdownTimeout bounds how long in-flight requests may take to drain.
+	shutdownTimeout = 10 * time.Second
 )
 
 func main() {
@@ -18,11 +43,147 @@ func main() {
 	if err != nil {
 		log.Fatalf("failed to load config: %v", err)
 	}
+	if cfg.Server.Trading {
+		// The engine places orders through Alpaca.
+		if err := cfg.RequireAlpaca(); err != nil {
+			log.Fatalf("invalid config: %v", err)
+		}
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	slog.SetDefault(logger)
+
+	parquetStore := store.NewParquetStore(cfg.Storage.DataDir)
+	db, err := store.NewSQLiteStore(cfg.Storage.SQLitePath)
+	if err != nil {
+		log.Fatalf("opening sqlite store: %v", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	// The trading calendar splits the live mirror's sessions the way
+	// us-stream does.
+	markets, err := util.LoadMarketRegistry(cfg.MarketsFile)
+	if err != nil {
+		log.Fatalf("loading markets: %v", err)
+	}
+	usMarket, err := markets.Get(domain.MarketUS)
+	if err != nil {
+		log.Fatalf("loading markets: %v", err)
+	}
+	cal := us.NewCalendar(usMarket, cfg.Alpaca.APIKey, cfg.Alpaca.APISecret, cfg.Alpaca.BaseURL, cfg.Storage.DataDir, logger)
+
+	// Live trades mirrored from us-stream feed the streaming RPCs and, when
+	// trading, the strategy runner and the risk checks' market order prices.
+	model := live.NewLiveModel(live.TodayCutoff(time.Now(), cal))
+	streamAddr := "localhost:50051"
+	if a := os.Getenv("STREAM_ADDR"); a != "" {
+		streamAddr = a
+	}
+	go func() {
+		if err := live.NewClient(streamAddr, model, logger).Sync(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("live trade sync stopped", "addr", streamAddr, "error", err)
+		}
+	}()
+	go live.RunDaySwitch(ctx, model, cal)
 
-	fmt.Printf("jupitor-server starting on %s:%d...\n", cfg.Server.Host, cfg.Server.Port)
+	registry := strategy.NewRegistry()
+	registry.RegisterFactory(func() strategy.Strategy { return builtins.NewSMACross(10, 30) })
+
+	mdSrv := api.NewMarketDataService(parquetStore, parquetStore)
+	mdSrv.SetLiveModel(model, logger)
+	if cfg.Alpaca.APIKey != "" {
+		mdSrv.SetQuoteSource(api.NewAlpacaQuoteSource(cfg.Alpaca.APIKey, cfg.Alpaca.APISecret, ""))
+	}
+
+	srv := api.NewServer(cfg)
+	hub := srv.Hub()
+	go hub.FeedLive(ctx, model)
+	// Trade parameters live in us-stream; relay its SSE feed.
+	streamURL := fmt.Sprintf("http://localhost:%d", cfg.Stream.Port)
+	if u := os.Getenv("US_STREAM_URL"); u != "" {
+		streamURL = u
+	}
+	go hub.FeedTargets(ctx, jupitor.NewClient(streamURL).StreamTargets)
+	srv.SetMarketDataService(mdSrv)
+
+	// The engine and strategy runner run in exactly one process per store
+	// and broker account: here with server.trading, otherwise in
+	// jupitor-trader, whose orders and signals are served from the stores.
+	var eng *engine.Engine
+	var runner *strategy.Runner
+	if cfg.Server.Trading {
+		release, err := store.LockEngine(cfg.Storage.SQLitePath)
+		if err != nil {
+			log.Fatalf("server.trading is set but the engine is taken (is jupitor-trader running?): %v", err)
+		}
+		defer release()
+		eng, runner = startTrading(ctx, cfg, db, model, registry, logger)
+		go hub.FeedOrders(ctx, eng)
+		go hub.FeedSignals(ctx, runner)
+		srv.SetTradingService(api.NewTradingService(eng))
+	} else {
+		go hub.PollSignals(ctx, db)
+	}
+
+	srv.SetHandlers(api.NewHandlers(parquetStore, parquetStore, db, eng))
+	srv.SetStrategyService(api.NewStrategyService(registry, runner, strategy.NewBacktester(parquetStore, registry), db))
+
+	slog.Info("jupitor-server starting", "host", cfg.Server.Host, "port", cfg.Server.Port,
+		"grpcPort", cfg.Server.GRPCPort, "trading", cfg.Server.Trading, "paperMode", cfg.Trading.PaperMode)
+	serveErr := srv.ListenAndServe(ctx)
+
+	// Graceful shutdown, also after a serve error so the other listener
+	// stops cleanly before exiting non-zero.
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer shutdownCancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("shutdown", "error", err)
+	}
+	if serveErr != nil {
+		log.Fatalf("server error: %v", serveErr)
+	}
+	slog.Info("shutdown complete")
+}
+
+// startTrading starts the Alpaca-backed engine, its reconciler and order
+// update stream, and a strategy runner fed by model.
+func startTrading(ctx context.Context, cfg *config.Config, db *store.SQLiteStore, model *live.LiveModel,
+	registry *strategy.Registry, logger *slog.Logger) (*engine.Engine, *strategy.Runner) {
+	b := broker.NewAlpacaBroker(cfg.Alpaca.APIKey, cfg.Alpaca.APISecret,
+		broker.AlpacaBaseURL(cfg.Alpaca.BaseURL, cfg.Trading.PaperMode))
+
+	risk := engine.NewRiskManager(cfg.Trading.MaxPositionPct, cfg.Trading.MaxDailyLossPct)
+	risk.SetMaxOpenPositions(cfg.Trading.MaxOpenPositions)
+	if len(cfg.Trading.TierMaxPositionPct) > 0 {
+		tierMap, err := dashboard.LoadTierMap(cfg.Storage.DataDir)
+		if err != nil {
+			slog.Warn("loading tier map for risk limits", "error", err)
+		}
+		risk.SetTierLimits(tierMap, cfg.Trading.TierMaxPositionPct)
+	}
+	for _, sym := range cfg.Trading.HaltedSymbols {
+		risk.HaltSymbol(sym)
+	}
+	risk.SetPriceSource(model.LastPrice)
+	eng := engine.NewEngine(b, db, db, risk)
+	eng.SetLogger(logger)
+	go eng.RunReconciler(ctx, reconcileInterval)
+	go func() {
+		err := b.StreamOrderUpdates(ctx, func(o domain.Order) {
+			if err := eng.HandleOrderUpdate(ctx, o); err != nil {
+				slog.Error("applying order update", "id", o.ID, "error", err)
+			}
+		})
+		if err != nil {
+			slog.Error("order update stream stopped", "error", err)
+		}
+	}()
 
-	// TODO: Initialize ParquetStore and SQLiteStore from cfg.Storage.
-	// TODO: Initialize API server (REST + gRPC) with store dependencies.
-	// TODO: Initialize WebSocket hub for streaming data.
-	// TODO: Start HTTP/gRPC listeners and block until signal.
+	runner := strategy.NewRunner(registry, db, logger)
+	runner.SetOrderSubmitter(eng, 0)
+	go runner.Run(ctx, model)
+	return eng, runner
 }
@@ -1,11 +1,30 @@
 package main
 
 import (
-	"fmt"
+	"context"
 	"log"
+	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"jupitor/internal/broker"
 	"jupitor/internal/config"
+	"jupitor/internal/dashboard"
+	"jupitor/internal/domain"
+	"jupitor/internal/engine"
+	"jupitor/internal/gather/us"
+	"jupitor/internal/live"
+	"jupitor/internal/store"
+	"jupitor/internal/strategy"
+	"jupitor/internal/strategy/builtins"
+	"jupitor/internal/util"
+)
+
+const (
+	// reconcileInterval is how often broker state is diffed against the stores.
+	reconcileInterval = 30 * time.Second
 )
 
 func main() {
@@ -18,11 +37,100 @@ func main() {
 	if err != nil {
 		log.Fatalf("failed to load config: %v", err)
 	}
+	if err := cfg.RequireAlpaca(); err != nil {
+		log.Fatalf("invalid config: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	slog.SetDefault(logger)
+
+	db, err := store.NewSQLiteStore(cfg.Storage.SQLitePath)
+	if err != nil {
+		log.Fatalf("opening sqlite store: %v", err)
+	}
+	defer db.Close()
+
+	release, err := store.LockEngine(cfg.Storage.SQLitePath)
+	if err != nil {
+		log.Fatalf("another process owns the trading engine (jupitor-server with server.trading?): %v", err)
+	}
+	defer release()
+
+	baseURL := broker.AlpacaBaseURL(cfg.Alpaca.BaseURL, cfg.Trading.PaperMode)
+	b := broker.NewAlpacaBroker(cfg.Alpaca.APIKey, cfg.Alpaca.APISecret, baseURL)
+
+	// The trading calendar splits the live mirror's sessions the way
+	// us-stream does.
+	markets, err := util.LoadMarketRegistry(cfg.MarketsFile)
+	if err != nil {
+		log.Fatalf("loading markets: %v", err)
+	}
+	usMarket, err := markets.Get(domain.MarketUS)
+	if err != nil {
+		log.Fatalf("loading markets: %v", err)
+	}
+	cal := us.NewCalendar(usMarket, cfg.Alpaca.APIKey, cfg.Alpaca.APISecret, baseURL, cfg.Storage.DataDir, logger)
+
+	// Mirror of us-stream's live trade model: feeds the strategy runner and
+	// prices market orders for the risk checks.
+	model := live.NewLiveModel(live.TodayCutoff(time.Now(), cal))
+
+	risk := engine.NewRiskManager(cfg.Trading.MaxPositionPct, cfg.Trading.MaxDailyLossPct)
+	risk.SetMaxOpenPositions(cfg.Trading.MaxOpenPositions)
+	if len(cfg.Trading.TierMaxPositionPct) > 0 {
+		tierMap, err := dashboard.LoadTierMap(cfg.Storage.DataDir)
+		if err != nil {
+			slog.Warn("loading tier map for risk limits", "error", err)
+		}
+		risk.SetTierLimits(tierMap, cfg.Trading.TierMaxPositionPct)
+	}
+	for _, sym := range cfg.Trading.HaltedSymbols {
+		risk.HaltSymbol(sym)
+	}
+	risk.SetPriceSource(model.LastPrice)
+	eng := engine.NewEngine(b, db, db, risk)
+	eng.SetLogger(logger)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	slog.Info("jupitor-trader starting", "paperMode", cfg.Trading.PaperMode, "broker", b.Name(), "baseURL", baseURL)
+
+	// Strategy runner fed by the live trade mirror.
+	streamAddr := "localhost:50051"
+	if a := os.Getenv("STREAM_ADDR"); a != "" {
+		streamAddr = a
+	}
+	go func() {
+		if err := live.NewClient(streamAddr, model, logger).Sync(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("live trade sync stopped", "addr", streamAddr, "error", err)
+		}
+	}()
+
+	registry := strategy.NewRegistry()
+	registry.RegisterFactory(func() strategy.Strategy { return builtins.NewSMACross(10, 30) })
+	runner := strategy.NewRunner(registry, db, logger)
+	runner.SetOrderSubmitter(eng, 0)
+	for _, sc := range cfg.Trading.Strategies {
+		if err := runner.Start(ctx, sc.ID, sc.Symbols, sc.Params); err != nil {
+			log.Fatalf("starting strategy %s: %v", sc.ID, err)
+		}
+	}
+	go runner.Run(ctx, model)
+	go live.RunDaySwitch(ctx, model, cal)
 
-	fmt.Printf("jupitor-trader starting (paper_mode=%v)...\n", cfg.Trading.PaperMode)
+	go eng.RunReconciler(ctx, reconcileInterval)
+	go func() {
+		err := b.StreamOrderUpdates(ctx, func(o domain.Order) {
+			if err := eng.HandleOrderUpdate(ctx, o); err != nil {
+				slog.Error("applying order update", "id", o.ID, "error", err)
+			}
+		})
+		if err != nil {
+			slog.Error("order update stream stopped", "error", err)
+		}
+	}()
 
-	// TODO: Initialize ParquetStore and SQLiteStore from cfg.Storage.
-	// TODO: Initialize broker client (Alpaca) from cfg.Alpaca.
-	// TODO: Initialize trading engine with strategy registry and risk limits.
-	// TODO: Start engine event loop and block until signal.
+	<-ctx.Done()
+	slog.Info("shutdown complete")
 }
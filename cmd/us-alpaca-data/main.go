@@ -13,8 +13,10 @@ import (
 	"time"
 
 	"jupitor/internal/config"
+	"jupitor/internal/domain"
 	"jupitor/internal/gather/us"
 	"jupitor/internal/store"
+	"jupitor/internal/util"
 )
 
 func main() {
@@ -30,6 +32,9 @@ func main() {
 	if err != nil {
 		log.Fatalf("failed to load config: %v", err)
 	}
+	if err := cfg.RequireAlpaca(); err != nil {
+		log.Fatalf("invalid config: %v", err)
+	}
 
 	// Dual logger: stdout + /tmp log file.
 	logFileName := fmt.Sprintf("/tmp/us-alpaca-data-%s.log", time.Now().Format("2006-01-02"))
@@ -43,6 +48,15 @@ func main() {
 	logger := slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: slog.LevelInfo}))
 	slog.SetDefault(logger)
 
+	markets, err := util.LoadMarketRegistry(cfg.MarketsFile)
+	if err != nil {
+		log.Fatalf("loading markets: %v", err)
+	}
+	usMarket, err := markets.Get(domain.MarketUS)
+	if err != nil {
+		log.Fatalf("loading markets: %v", err)
+	}
+
 	pstore := store.NewParquetStore(cfg.Storage.DataDir)
 
 	csvPath := "reference/us/symbol_5_chars.csv"
@@ -62,6 +76,7 @@ func main() {
 		"reference/us",
 	)
 
+	gatherer.SetMarket(usMarket)
 	if *exIndexOnly {
 		gatherer.SetExIndexOnly(true)
 	}
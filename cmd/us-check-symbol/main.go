@@ -15,10 +15,12 @@ import (
 	"strings"
 	"time"
 
-	"github.com/parquet-go/parquet-go"
-
+	"jupitor/internal/domain"
+	"jupitor/internal/ettime"
+	"jupitor/internal/gather/us"
 	"jupitor/internal/live"
 	"jupitor/internal/store"
+	"jupitor/internal/util"
 )
 
 func main() {
@@ -28,20 +30,21 @@ func main() {
 	}
 	sym := strings.ToUpper(os.Args[1])
 	dataDir := os.Getenv("DATA_1")
-	loc, _ := time.LoadLocation("America/New_York")
+	loc := ettime.Location()
 	now := time.Now().In(loc)
 	date := now.Format("2006-01-02")
 	if len(os.Args) > 2 {
 		date = os.Args[2]
 	}
 
-	// Determine prev trading date (simple: skip weekends).
-	d, _ := time.ParseInLocation("2006-01-02", date, loc)
-	prev := d.AddDate(0, 0, -1)
-	for prev.Weekday() == time.Saturday || prev.Weekday() == time.Sunday {
-		prev = prev.AddDate(0, 0, -1)
+	// Determine prev trading date from the calendar, seeded from the cached
+	// Alpaca calendar when present.
+	cal := util.NewTradingCalendar(domain.MarketUS)
+	if days, err := util.LoadCalendarCache(us.CalendarCachePath(dataDir)); err == nil {
+		cal.Override(days)
 	}
-	prevDate := prev.Format("2006-01-02")
+	d, _ := time.ParseInLocation("2006-01-02", date, loc)
+	prevDate := cal.PrevTradingDay(d).Format("2006-01-02")
 
 	fmt.Printf("=== %s on %s (prev=%s) ===\n\n", sym, date, prevDate)
 
@@ -49,7 +52,7 @@ func main() {
 	fmt.Println("--- Raw trade files ($DATA_1/us/trades/) ---")
 	for _, dt := range []string{prevDate, date} {
 		path := filepath.Join(dataDir, "us", "trades", sym, dt+".parquet")
-		records, err := parquet.ReadFile[store.TradeRecord](path)
+		records, err := store.ReadTradeRecords(path)
 		if err != nil {
 			fmt.Printf("  %s: %v\n", dt, err)
 			continue
@@ -64,20 +67,18 @@ func main() {
 			}
 		}
 		fmt.Printf("  %s: %d trades  [%s .. %s]\n", dt, len(records),
-			time.UnixMilli(minTS).In(loc).Format("15:04:05"),
-			time.UnixMilli(maxTS).In(loc).Format("15:04:05"))
+			ettime.SessionTime(minTS).Wall().Format("15:04:05"),
+			ettime.SessionTime(maxTS).Wall().Format("15:04:05"))
 	}
 
-	// --- Ex-index file (ET-shifted timestamps) ---
+	// --- Ex-index file (session-time timestamps) ---
 	fmt.Println("\n--- stock-trades-ex-index ---")
 	exPath := filepath.Join(dataDir, "us", "stock-trades-ex-index", date+".parquet")
-	allRecords, err := parquet.ReadFile[store.TradeRecord](exPath)
+	allRecords, err := store.ReadTradeRecords(exPath)
 	if err != nil {
 		fmt.Printf("  error: %v\n", err)
 	} else {
-		open930 := time.Date(d.Year(), d.Month(), d.Day(), 9, 30, 0, 0, loc)
-		_, off := open930.Zone()
-		open930ET := open930.UnixMilli() + int64(off)*1000
+		open930ET := int64(ettime.BoundsOn(d, cal).Open)
 
 		var symRecs []store.TradeRecord
 		for _, r := range allRecords {
@@ -102,8 +103,8 @@ func main() {
 		}
 		fmt.Printf("  %d trades (pre=%d reg=%d)  [%s .. %s]\n",
 			len(symRecs), pre, reg,
-			time.UnixMilli(minTS).UTC().Format("15:04:05"),
-			time.UnixMilli(maxTS).UTC().Format("15:04:05"))
+			ettime.SessionTime(minTS).Wall().Format("15:04:05"),
+			ettime.SessionTime(maxTS).Wall().Format("15:04:05"))
 	}
 
 	// --- Live stream ---
@@ -114,11 +115,7 @@ func main() {
 	}
 	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
 
-	close4pm := time.Date(now.Year(), now.Month(), now.Day(), 16, 0, 0, 0, loc)
-	_, offset := close4pm.Zone()
-	todayCutoff := close4pm.UnixMilli() + int64(offset)*1000
-
-	lm := live.NewLiveModel(todayCutoff)
+	lm := live.NewLiveModel(live.TodayCutoff(now, cal))
 	client := live.NewClient(addr, lm, logger)
 	ctx, cancel := context.WithCancel(context.Background())
 	go func() { _ = client.Sync(ctx) }()
@@ -140,9 +137,7 @@ func main() {
 	fmt.Fprintf(os.Stderr, " done (%d seen)\n", lastCount)
 
 	_, exIdx := lm.TodaySnapshot()
-	open930 := time.Date(d.Year(), d.Month(), d.Day(), 9, 30, 0, 0, loc)
-	_, off := open930.Zone()
-	open930ET := open930.UnixMilli() + int64(off)*1000
+	open930ET := int64(ettime.BoundsOn(d, cal).Open)
 
 	var liveSym []store.TradeRecord
 	for _, r := range exIdx {
@@ -22,8 +22,12 @@ import (
 	"github.com/charmbracelet/lipgloss"
 
 	"jupitor/internal/dashboard"
+	"jupitor/internal/ettime"
+	"jupitor/internal/gather/us"
 	"jupitor/internal/live"
+	"jupitor/internal/news"
 	"jupitor/internal/store"
+	"jupitor/internal/util"
 )
 
 // Styles.
@@ -100,7 +104,7 @@ type newsArticle struct {
 type newsLoadedMsg struct {
 	symbol   string
 	date     string
-	prevDate string // previous trading day (for caching)
+	prevDate string // previous trading day
 	news     []newsArticle
 	err      error
 }
@@ -199,7 +203,7 @@ type model struct {
 	newsSymbol  string                   // symbol of in-flight fetch
 	newsDate    string                   // date of in-flight fetch
 	newsLoading bool
-	prevTDCache map[string]string // date -> previous trading day (from Alpaca Calendar)
+	calendar    *util.TradingCalendar
 
 	// News counts (batch fetch for column display).
 	newsCountCache   map[string]map[string]int // date -> symbol -> count
@@ -222,7 +226,7 @@ type model struct {
 	preloadRunning bool     // true while a preload cmd is in flight
 }
 
-func initialModel(lm *live.LiveModel, tierMap map[string]string, loc *time.Location, cancel context.CancelFunc, dataDir string, histDates []string, logger *slog.Logger, ac *alpacaapi.Client, mdc *marketdata.Client) model {
+func initialModel(lm *live.LiveModel, tierMap map[string]string, loc *time.Location, cancel context.CancelFunc, dataDir string, histDates []string, logger *slog.Logger, cal *util.TradingCalendar, ac *alpacaapi.Client, mdc *marketdata.Client) model {
 	return model{
 		liveModel:        lm,
 		tierMap:          tierMap,
@@ -237,7 +241,7 @@ func initialModel(lm *live.LiveModel, tierMap map[string]string, loc *time.Locat
 		watchlistSymbols: make(map[string]bool),
 		mdClient:         mdc,
 		newsCache:        make(map[string][]newsArticle),
-		prevTDCache:      make(map[string]string),
+		calendar:         cal,
 		newsCountCache:   make(map[string]map[string]int),
 	}
 }
@@ -514,9 +518,6 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.logger.Warn("loading news", "symbol", msg.symbol, "date", msg.date, "error", msg.err)
 		} else {
 			m.newsCache[msg.symbol+":"+msg.date] = msg.news
-			if msg.prevDate != "" {
-				m.prevTDCache[msg.date] = msg.prevDate
-			}
 			m.logger.Info("news loaded", "symbol", msg.symbol, "date", msg.date,
 				"prevDate", msg.prevDate, "articles", len(msg.news))
 		}
@@ -657,35 +658,8 @@ func (m *model) maybeLoadNews() tea.Cmd {
 	m.newsSymbol = sym
 	m.newsDate = date
 	mdc := m.mdClient
-	ac := m.alpacaClient
-	loc := m.loc
-	cachedPrev := m.prevTDCache[date]
+	start, end, prevDate := news.Window(m.calendar, date)
 	return func() tea.Msg {
-		prevDate := cachedPrev
-		if prevDate == "" && ac != nil {
-			d, _ := time.ParseInLocation("2006-01-02", date, loc)
-			lookback := d.AddDate(0, 0, -10)
-			cal, err := ac.GetCalendar(alpacaapi.GetCalendarRequest{Start: lookback, End: d})
-			if err == nil && len(cal) >= 2 {
-				for i := len(cal) - 1; i >= 0; i-- {
-					if cal[i].Date < date {
-						prevDate = cal[i].Date
-						break
-					}
-				}
-			}
-		}
-
-		// Time range: previous trading day 4PM ET → viewed date 8PM ET.
-		t, _ := time.ParseInLocation("2006-01-02", date, loc)
-		end := time.Date(t.Year(), t.Month(), t.Day(), 20, 0, 0, 0, loc)
-		var start time.Time
-		if prevDate != "" {
-			p, _ := time.ParseInLocation("2006-01-02", prevDate, loc)
-			start = time.Date(p.Year(), p.Month(), p.Day(), 16, 0, 0, 0, loc)
-		} else {
-			start = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
-		}
 
 		var all []newsArticle
 
@@ -768,35 +742,9 @@ func (m *model) loadNewsCounts() tea.Cmd {
 	m.newsCountLoading = true
 	m.newsCountDate = date
 	mdc := m.mdClient
-	ac := m.alpacaClient
-	loc := m.loc
-	cachedPrev := m.prevTDCache[date]
+	start, end, _ := news.Window(m.calendar, date)
 
 	return func() tea.Msg {
-		prevDate := cachedPrev
-		if prevDate == "" && ac != nil {
-			d, _ := time.ParseInLocation("2006-01-02", date, loc)
-			lookback := d.AddDate(0, 0, -10)
-			cal, _ := ac.GetCalendar(alpacaapi.GetCalendarRequest{Start: lookback, End: d})
-			if len(cal) >= 2 {
-				for i := len(cal) - 1; i >= 0; i-- {
-					if cal[i].Date < date {
-						prevDate = cal[i].Date
-						break
-					}
-				}
-			}
-		}
-
-		t, _ := time.ParseInLocation("2006-01-02", date, loc)
-		end := time.Date(t.Year(), t.Month(), t.Day(), 20, 0, 0, 0, loc)
-		var start time.Time
-		if prevDate != "" {
-			p, _ := time.ParseInLocation("2006-01-02", prevDate, loc)
-			start = time.Date(p.Year(), p.Month(), p.Day(), 16, 0, 0, 0, loc)
-		} else {
-			start = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
-		}
 
 		counts := make(map[string]int)
 
@@ -1203,6 +1151,7 @@ func (m *model) nextDateFor(date string) string {
 func (m *model) loadHistoryCmd(date string) tea.Cmd {
 	dataDir := m.dataDir
 	loc := m.loc
+	cal := m.calendar
 	sortMode := m.sortMode
 	nextDate := m.nextDateFor(date)
 
@@ -1220,7 +1169,7 @@ func (m *model) loadHistoryCmd(date string) tea.Cmd {
 	}
 
 	return func() tea.Msg {
-		data, nextData, tierMap, trades, err := loadDateData(dataDir, date, nextDate, loc, sortMode, liveTrades)
+		data, nextData, tierMap, trades, err := loadDateData(dataDir, date, nextDate, loc, cal, sortMode, liveTrades)
 		if err != nil {
 			return historyLoadedMsg{date: date, err: err}
 		}
@@ -1231,7 +1180,11 @@ func (m *model) loadHistoryCmd(date string) tea.Cmd {
 // loadDateData loads history data for a date including the next day's trades.
 // liveTrades, if non-nil, provides today's live trades to use as next-day data
 // when the date is the latest history date (no next-date file on disk).
-func loadDateData(dataDir, date, nextDate string, loc *time.Location, sortMode int, liveTrades []store.TradeRecord) (data, nextData dashboard.DayData, tierMap map[string]string, trades int, err error) {
+func loadDateData(dataDir, date, nextDate string, loc *time.Location, cal *util.TradingCalendar, sortMode int, liveTrades []store.TradeRecord) (data, nextData dashboard.DayData, tierMap map[string]string, trades int, err error) {
+	bounds, err := ettime.BoundsFor(date, cal)
+	if err != nil {
+		return
+	}
 	tierMap, err = dashboard.LoadTierMapForDate(dataDir, date)
 	if err != nil {
 		return
@@ -1242,14 +1195,17 @@ func loadDateData(dataDir, date, nextDate string, loc *time.Location, sortMode i
 		return
 	}
 	trades = len(recs)
-	open930 := open930ETForDate(date, loc)
-	data = dashboard.ComputeDayData(date, recs, tierMap, open930, sortMode)
+	data = dashboard.ComputeDayData(date, recs, tierMap, bounds, sortMode)
 
 	// Try loading next-day from history file, or fall back to live trades.
 	var nextRecs []store.TradeRecord
 	var nextDateLabel string
+	var nextBounds ettime.Bounds
 	if nextDate != "" {
 		if loaded, e := dashboard.LoadHistoryTrades(dataDir, nextDate); e == nil && len(loaded) > 0 {
+			if nextBounds, err = ettime.BoundsFor(nextDate, cal); err != nil {
+				return
+			}
 			nextRecs = loaded
 			nextDateLabel = nextDate
 		}
@@ -1257,12 +1213,13 @@ func loadDateData(dataDir, date, nextDate string, loc *time.Location, sortMode i
 		nextRecs = liveTrades
 		now := time.Now().In(loc)
 		nextDateLabel = now.Format("2006-01-02")
+		nextBounds = ettime.BoundsOn(now, cal)
 	}
 
 	if len(nextRecs) > 0 {
 		// History view: only show post-market of current date (4PM–8PM ET).
 		// At that point in time, the next day's pre-market hasn't happened.
-		postEnd := postMarketEndET(date)
+		postEnd := int64(bounds.PostClose)
 		var filtered []store.TradeRecord
 		for i := range nextRecs {
 			if nextRecs[i].Timestamp <= postEnd {
@@ -1270,8 +1227,7 @@ func loadDateData(dataDir, date, nextDate string, loc *time.Location, sortMode i
 			}
 		}
 		if len(filtered) > 0 {
-			nextOpen930 := open930ETForDate(nextDateLabel, loc)
-			nextData = dashboard.ComputeDayData("NEXT: "+nextDateLabel, filtered, tierMap, nextOpen930, sortMode)
+			nextData = dashboard.ComputeDayData("NEXT: "+nextDateLabel, filtered, tierMap, nextBounds, sortMode)
 		}
 	}
 	return
@@ -1345,6 +1301,7 @@ func (m *model) preloadNext() tea.Cmd {
 
 	dataDir := m.dataDir
 	loc := m.loc
+	cal := m.calendar
 	sortMode := m.sortMode
 	nextDate := m.nextDateFor(date)
 
@@ -1360,7 +1317,7 @@ func (m *model) preloadNext() tea.Cmd {
 	m.logger.Info("preload start", "date", date, "queued", len(m.preloadQueue), "cached", len(m.historyCache))
 
 	return func() tea.Msg {
-		data, nextData, tierMap, trades, err := loadDateData(dataDir, date, nextDate, loc, sortMode, liveTrades)
+		data, nextData, tierMap, trades, err := loadDateData(dataDir, date, nextDate, loc, cal, sortMode, liveTrades)
 		if err != nil {
 			return preloadedMsg{date: date, err: err}
 		}
@@ -1384,19 +1341,6 @@ func (m *model) rebuildHistory() {
 	m.historyNextData = entry.nextData
 }
 
-func open930ETForDate(date string, loc *time.Location) int64 {
-	t, _ := time.ParseInLocation("2006-01-02", date, loc)
-	open930 := time.Date(t.Year(), t.Month(), t.Day(), 9, 30, 0, 0, loc)
-	_, off := open930.Zone()
-	return open930.UnixMilli() + int64(off)*1000
-}
-
-// postMarketEndET returns 8PM ET on the given date as ET-shifted milliseconds.
-func postMarketEndET(date string) int64 {
-	t, _ := time.Parse("2006-01-02", date)
-	return time.Date(t.Year(), t.Month(), t.Day(), 20, 0, 0, 0, time.UTC).UnixMilli()
-}
-
 func (m *model) refreshLive() {
 	_, todayExIdx := m.liveModel.TodaySnapshot()
 	_, nextExIdx := m.liveModel.NextSnapshot()
@@ -1423,14 +1367,12 @@ func (m *model) refreshLive() {
 		m.latestTS = "--:--:--"
 	}
 
-	todayOpen930 := time.Date(m.now.Year(), m.now.Month(), m.now.Day(), 9, 30, 0, 0, m.loc).UnixMilli()
-	_, off := m.now.Zone()
-	todayOpen930ET := todayOpen930 + int64(off)*1000
-	nextOpen930ET := todayOpen930ET + 24*60*60*1000
+	todayBounds := ettime.BoundsOn(m.now, m.calendar)
+	nextBounds := ettime.BoundsOn(m.calendar.NextTradingDay(m.now), m.calendar)
 
-	m.todayData = dashboard.ComputeDayData("TODAY", todayExIdx, m.tierMap, todayOpen930ET, m.sortMode)
+	m.todayData = dashboard.ComputeDayData("TODAY", todayExIdx, m.tierMap, todayBounds, m.sortMode)
 	if len(nextExIdx) > 0 {
-		m.nextData = dashboard.ComputeDayData("NEXT DAY", nextExIdx, m.tierMap, nextOpen930ET, m.sortMode)
+		m.nextData = dashboard.ComputeDayData("NEXT DAY", nextExIdx, m.tierMap, nextBounds, m.sortMode)
 	} else {
 		m.nextData = dashboard.DayData{}
 	}
@@ -1890,17 +1832,13 @@ func main() {
 	}
 	logger.Info("history dates available", "count", len(histDates))
 
-	loc, err := time.LoadLocation("America/New_York")
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "loading timezone: %v\n", err)
-		os.Exit(1)
-	}
-	now := time.Now().In(loc)
-	close4pm := time.Date(now.Year(), now.Month(), now.Day(), 16, 0, 0, 0, loc)
-	_, offset := close4pm.Zone()
-	todayCutoff := close4pm.UnixMilli() + int64(offset)*1000
+	// Trading calendar for session bounds and previous trading days, seeded
+	// from the cached Alpaca calendar.
+	cal := us.NewCalendar(nil, os.Getenv("APCA_API_KEY_ID"), os.Getenv("APCA_API_SECRET_KEY"), "", dataDir, logger)
 
-	lm := live.NewLiveModel(todayCutoff)
+	loc := ettime.Location()
+	now := time.Now().In(loc)
+	lm := live.NewLiveModel(live.TodayCutoff(now, cal))
 	client := live.NewClient(addr, lm, logger)
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -1952,7 +1890,7 @@ func main() {
 	}
 
 	p := tea.NewProgram(
-		initialModel(lm, tierMap, loc, cancel, dataDir, histDates, logger, alpacaClient, mdClient),
+		initialModel(lm, tierMap, loc, cancel, dataDir, histDates, logger, cal, alpacaClient, mdClient),
 		tea.WithAltScreen(),
 		tea.WithMouseCellMotion(),
 	)
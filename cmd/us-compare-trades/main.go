@@ -17,8 +17,11 @@ import (
 	"strconv"
 
 	"jupitor/internal/dashboard"
+	"jupitor/internal/domain"
+	"jupitor/internal/ettime"
 	"jupitor/internal/live"
 	"jupitor/internal/store"
+	"jupitor/internal/util"
 )
 
 func main() {
@@ -33,7 +36,7 @@ func main() {
 		addr = a
 	}
 
-	loc, _ := time.LoadLocation("America/New_York")
+	loc := ettime.Location()
 	now := time.Now().In(loc)
 
 	date := now.Format("2006-01-02")
@@ -45,11 +48,7 @@ func main() {
 
 	// --- Load live data from gRPC ---
 	fmt.Fprintf(os.Stderr, "connecting to %s...\n", addr)
-	close4pm := time.Date(now.Year(), now.Month(), now.Day(), 16, 0, 0, 0, loc)
-	_, offset := close4pm.Zone()
-	todayCutoff := close4pm.UnixMilli() + int64(offset)*1000
-
-	lm := live.NewLiveModel(todayCutoff)
+	lm := live.NewLiveModel(live.TodayCutoff(now, util.NewTradingCalendar(domain.MarketUS)))
 	client := live.NewClient(addr, lm, logger)
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -259,9 +258,8 @@ func fmtTS(ms int64, loc *time.Location) string {
 	if ms == 0 {
 		return "--:--:--"
 	}
-	// Timestamps in live model are ET-shifted UTC millis, parquet also ET-shifted.
-	// Just treat as UTC to show the ET time.
-	return time.UnixMilli(ms).UTC().Format("15:04:05")
+	// Timestamps in the live model and parquet files are session time.
+	return ettime.SessionTime(ms).Wall().Format("15:04:05")
 }
 
 func abs(n int) int {
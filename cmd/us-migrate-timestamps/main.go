@@ -0,0 +1,111 @@
+// One-shot tool: rewrite US trade parquet files from session time (ET wall
+// clock stored as UTC) to real UTC timestamps, tagging each file with the
+// schema v2 timestamp marker. Files already migrated are skipped, so the tool
+// can be re-run after an interruption.
+//
+// Usage:
+//
+//	go run cmd/us-migrate-timestamps/main.go [-dry-run] [-workers 8]
+package main
+
+import (
+	"flag"
+	"io/fs"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"jupitor/internal/config"
+	"jupitor/internal/store"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report files that would be migrated without rewriting them")
+	workers := flag.Int("workers", 8, "number of files migrated concurrently")
+	flag.Parse()
+
+	cfgPath := "config/jupitor.yaml"
+	if p := os.Getenv("JUPITOR_CONFIG"); p != "" {
+		cfgPath = p
+	}
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	slog.SetDefault(logger)
+
+	var paths []string
+	for _, dir := range store.TradeDirs {
+		root := filepath.Join(cfg.Storage.DataDir, "us", dir)
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return filepath.SkipDir
+				}
+				return err
+			}
+			if !d.IsDir() && strings.HasSuffix(path, ".parquet") {
+				paths = append(paths, path)
+			}
+			return nil
+		})
+		if err != nil {
+			log.Fatalf("walking %s: %v", root, err)
+		}
+	}
+	slog.Info("scanning trade files", "files", len(paths), "dryRun", *dryRun)
+
+	var migrated, current, failed atomic.Int64
+	work := make(chan string)
+	var wg sync.WaitGroup
+	for range max(*workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range work {
+				if *dryRun {
+					conv, err := store.TradeFileTimestamps(path)
+					switch {
+					case err != nil:
+						failed.Add(1)
+						slog.Error("reading marker", "path", path, "error", err)
+					case conv == store.TimestampsUTC:
+						current.Add(1)
+					default:
+						migrated.Add(1)
+						slog.Info("would migrate", "path", path)
+					}
+					continue
+				}
+				ok, err := store.MigrateTradeFile(path)
+				switch {
+				case err != nil:
+					failed.Add(1)
+					slog.Error("migrating", "path", path, "error", err)
+				case ok:
+					migrated.Add(1)
+				default:
+					current.Add(1)
+				}
+			}
+		}()
+	}
+	for _, p := range paths {
+		work <- p
+	}
+	close(work)
+	wg.Wait()
+
+	slog.Info("timestamp migration complete",
+		"migrated", migrated.Load(), "alreadyUTC", current.Load(), "failed", failed.Load(), "dryRun", *dryRun)
+	if failed.Load() > 0 {
+		os.Exit(1)
+	}
+}
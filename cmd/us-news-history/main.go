@@ -26,13 +26,14 @@ import (
 	"sync"
 	"time"
 
-	alpacaapi "github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
 	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
 	"github.com/parquet-go/parquet-go"
 
 	"jupitor/internal/config"
 	"jupitor/internal/dashboard"
+	"jupitor/internal/gather/us"
 	"jupitor/internal/news"
+	"jupitor/internal/util"
 )
 
 // NewsRecord is one article row in the output parquet file.
@@ -64,27 +65,18 @@ func main() {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
 	slog.SetDefault(logger)
 
-	// Alpaca clients.
+	// Alpaca client.
 	apiKey := os.Getenv("APCA_API_KEY_ID")
 	apiSecret := os.Getenv("APCA_API_SECRET_KEY")
 	if apiKey == "" {
 		log.Fatal("APCA_API_KEY_ID not set")
 	}
 
-	ac := alpacaapi.NewClient(alpacaapi.ClientOpts{
-		APIKey:    apiKey,
-		APISecret: apiSecret,
-	})
 	mdc := marketdata.NewClient(marketdata.ClientOpts{
 		APIKey:    apiKey,
 		APISecret: apiSecret,
 	})
 
-	loc, err := time.LoadLocation("America/New_York")
-	if err != nil {
-		log.Fatalf("loading ET timezone: %v", err)
-	}
-
 	// List all available dates.
 	dates, err := dashboard.ListHistoryDates(dataDir)
 	if err != nil {
@@ -128,16 +120,9 @@ func main() {
 		return
 	}
 
-	// Build calendar cache for previous trading day lookups.
-	// Fetch the full calendar range once.
-	calStart, _ := time.ParseInLocation("2006-01-02", dates[0], loc)
-	calEnd, _ := time.ParseInLocation("2006-01-02", dates[len(dates)-1], loc)
-	calStart = calStart.AddDate(0, 0, -10) // buffer for first date
-	cal, err := ac.GetCalendar(alpacaapi.GetCalendarRequest{Start: calStart, End: calEnd})
-	if err != nil {
-		log.Fatalf("fetching Alpaca calendar: %v", err)
-	}
-	prevTD := buildPrevTradingDayMap(cal)
+	// Trading calendar for the news windows, seeded from the cached Alpaca
+	// calendar.
+	cal := us.NewCalendar(nil, apiKey, apiSecret, "", dataDir, logger)
 
 	// Shared StockTwits rate limiter: 1 request per 500ms across all goroutines.
 	stLimiter := time.NewTicker(500 * time.Millisecond)
@@ -145,7 +130,7 @@ func main() {
 
 	for i, date := range todo {
 		slog.Info("processing date", "date", date, "progress", fmt.Sprintf("%d/%d", i+1, len(todo)))
-		records, err := processDate(dataDir, date, prevTD[date], loc, mdc, stLimiter)
+		records, err := processDate(dataDir, date, cal, mdc, stLimiter)
 		if err != nil {
 			slog.Error("failed to process date", "date", date, "error", err)
 			continue
@@ -160,18 +145,8 @@ func main() {
 	}
 }
 
-// buildPrevTradingDayMap builds a map from each calendar date to its previous
-// trading day.
-func buildPrevTradingDayMap(cal []alpacaapi.CalendarDay) map[string]string {
-	m := make(map[string]string, len(cal))
-	for i := 1; i < len(cal); i++ {
-		m[cal[i].Date] = cal[i-1].Date
-	}
-	return m
-}
-
 // processDate loads trades for a date, picks top symbols, and fetches news.
-func processDate(dataDir, date, prevDate string, loc *time.Location, mdc *marketdata.Client, stLimiter *time.Ticker) ([]NewsRecord, error) {
+func processDate(dataDir, date string, cal *util.TradingCalendar, mdc *marketdata.Client, stLimiter *time.Ticker) ([]NewsRecord, error) {
 	// Load trades and tier map.
 	trades, err := dashboard.LoadHistoryTrades(dataDir, date)
 	if err != nil {
@@ -236,16 +211,8 @@ func processDate(dataDir, date, prevDate string, loc *time.Location, mdc *market
 	}
 	sort.Strings(symbols)
 
-	// Compute time window: prevDate 4PM ET → date 8PM ET.
-	t, _ := time.ParseInLocation("2006-01-02", date, loc)
-	end := time.Date(t.Year(), t.Month(), t.Day(), 20, 0, 0, 0, loc)
-	var start time.Time
-	if prevDate != "" {
-		p, _ := time.ParseInLocation("2006-01-02", prevDate, loc)
-		start = time.Date(p.Year(), p.Month(), p.Day(), 16, 0, 0, 0, loc)
-	} else {
-		start = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
-	}
+	// Compute time window: previous trading day's close → date's post-market end.
+	start, end, _ := news.Window(cal, date)
 
 	slog.Info("fetching news", "date", date, "symbols", len(symbols), "deep_st", len(deepSet),
 		"window", fmt.Sprintf("%s → %s", start.Format("2006-01-02 15:04"), end.Format("2006-01-02 15:04")))
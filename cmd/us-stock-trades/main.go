@@ -36,7 +36,9 @@ func main() {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
 	slog.SetDefault(logger)
 
-	wrote, err := us.GenerateStockTrades(context.Background(), cfg.Storage.DataDir, *n, !*index, logger)
+	_, filter, _ := cfg.TradeFilters() // validated by Load
+	cal := us.NewCalendar(nil, cfg.Alpaca.APIKey, cfg.Alpaca.APISecret, cfg.Alpaca.BaseURL, cfg.Storage.DataDir, logger)
+	wrote, err := us.GenerateStockTrades(context.Background(), cfg.Storage.DataDir, *n, !*index, filter, cal, logger)
 	if err != nil {
 		log.Fatalf("error: %v", err)
 	}
@@ -48,7 +50,7 @@ func main() {
 	}
 
 	if *rolling {
-		rollingWrote, err := us.GenerateRollingBars(context.Background(), cfg.Storage.DataDir, *n, logger)
+		rollingWrote, err := us.GenerateRollingBars(context.Background(), cfg.Storage.DataDir, *n, cal, logger)
 		if err != nil {
 			log.Fatalf("rolling bars error: %v", err)
 		}
@@ -12,7 +12,10 @@ import (
 	"unsafe"
 
 	"jupitor/internal/dashboard"
+	"jupitor/internal/domain"
+	"jupitor/internal/ettime"
 	"jupitor/internal/live"
+	"jupitor/internal/util"
 )
 
 // sortMode cycles: 0=PRE:TRD, 1=PRE:GAIN, 2=REG:TRD, 3=REG:GAIN.
@@ -40,19 +43,10 @@ func main() {
 	}
 	logger.Info("loaded tier map", "symbols", len(tierMap))
 
-	// Compute today's cutoff = 4PM ET in ET-shifted millisecond frame
-	// (must match how the stream server stores timestamps via utcToETMilli).
-	loc, err := time.LoadLocation("America/New_York")
-	if err != nil {
-		logger.Error("loading timezone", "error", err)
-		os.Exit(1)
-	}
-	now := time.Now().In(loc)
-	close4pm := time.Date(now.Year(), now.Month(), now.Day(), 16, 0, 0, 0, loc)
-	_, offset := close4pm.Zone()
-	todayCutoff := close4pm.UnixMilli() + int64(offset)*1000
-
-	model := live.NewLiveModel(todayCutoff)
+	// Today's cutoff = 4PM ET in session time, the model's frame.
+	loc := ettime.Location()
+	cal := util.NewTradingCalendar(domain.MarketUS)
+	model := live.NewLiveModel(live.TodayCutoff(time.Now(), cal))
 	client := live.NewClient(addr, model, logger)
 
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
@@ -103,7 +97,7 @@ func main() {
 	case <-ctx.Done():
 		return
 	}
-	printDashboard(model, tierMap, loc)
+	printDashboard(model, tierMap, loc, cal)
 
 	ticker := time.NewTicker(5 * time.Second)
 	defer ticker.Stop()
@@ -111,9 +105,9 @@ func main() {
 	for {
 		select {
 		case <-ticker.C:
-			printDashboard(model, tierMap, loc)
+			printDashboard(model, tierMap, loc, cal)
 		case <-refreshCh:
-			printDashboard(model, tierMap, loc)
+			printDashboard(model, tierMap, loc, cal)
 		case <-ctx.Done():
 			fmt.Println("\nshutdown")
 			return
@@ -121,16 +115,14 @@ func main() {
 	}
 }
 
-func printDashboard(model *live.LiveModel, tierMap map[string]string, loc *time.Location) {
+func printDashboard(model *live.LiveModel, tierMap map[string]string, loc *time.Location, cal *util.TradingCalendar) {
 	_, todayExIdx := model.TodaySnapshot()
 	_, nextExIdx := model.NextSnapshot()
 	seen := model.SeenCount()
 
 	now := time.Now().In(loc)
-	todayOpen930 := time.Date(now.Year(), now.Month(), now.Day(), 9, 30, 0, 0, loc).UnixMilli()
-	_, off := now.Zone()
-	todayOpen930ET := todayOpen930 + int64(off)*1000
-	nextOpen930ET := todayOpen930ET + 24*60*60*1000
+	todayBounds := ettime.BoundsOn(now, cal)
+	nextBounds := ettime.BoundsOn(cal.NextTradingDay(now), cal)
 
 	sm := int(sortMode.Load())
 	sortLabel := dashboard.SortModeLabel(sm)
@@ -141,11 +133,11 @@ func printDashboard(model *live.LiveModel, tierMap map[string]string, loc *time.
 		now.Format("2006-01-02 15:04:05 MST"),
 		dashboard.FormatInt(seen), dashboard.FormatInt(len(todayExIdx)), dashboard.FormatInt(len(nextExIdx)), sortLabel)
 
-	todayData := dashboard.ComputeDayData("TODAY", todayExIdx, tierMap, todayOpen930ET, sm)
+	todayData := dashboard.ComputeDayData("TODAY", todayExIdx, tierMap, todayBounds, sm)
 	printDay(todayData)
 
 	if len(nextExIdx) > 0 {
-		nextData := dashboard.ComputeDayData("NEXT DAY", nextExIdx, tierMap, nextOpen930ET, sm)
+		nextData := dashboard.ComputeDayData("NEXT DAY", nextExIdx, tierMap, nextBounds, sm)
 		printDay(nextData)
 	}
 }
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"io"
 	"log"
@@ -17,16 +18,23 @@ import (
 	alpacaapi "github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
 	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
 	"google.golang.org/grpc"
+	"gopkg.in/yaml.v3"
 
+	"jupitor/internal/api"
 	"jupitor/internal/config"
 	"jupitor/internal/dashboard"
+	"jupitor/internal/domain"
 	"jupitor/internal/gather/us"
 	"jupitor/internal/httpapi"
-	"jupitor/internal/live"
+	"jupitor/internal/store"
 	"jupitor/internal/tradeparams"
+	"jupitor/internal/util"
 )
 
 func main() {
+	printConfig := flag.Bool("print-config", false, "print the effective configuration and exit")
+	flag.Parse()
+
 	cfgPath := "config/jupitor.yaml"
 	if p := os.Getenv("JUPITOR_CONFIG"); p != "" {
 		cfgPath = p
@@ -36,9 +44,32 @@ func main() {
 	if err != nil {
 		log.Fatalf("failed to load config: %v", err)
 	}
+	if *printConfig {
+		out, err := yaml.Marshal(cfg.Redacted())
+		if err != nil {
+			log.Fatalf("marshalling config: %v", err)
+		}
+		fmt.Printf("# effective configuration (%s + environment)\n%s", cfgPath, out)
+		return
+	}
+	if err := cfg.RequireAlpaca(); err != nil {
+		log.Fatalf("invalid config: %v", err)
+	}
+	sc := cfg.Stream
+	daySwitch, _ := sc.DaySwitchOffset()               // validated by Load
+	tradeFilters, tradeFilter, _ := cfg.TradeFilters() // validated by Load
+
+	markets, err := util.LoadMarketRegistry(cfg.MarketsFile)
+	if err != nil {
+		log.Fatalf("loading markets: %v", err)
+	}
+	usMarket, err := markets.Get(domain.MarketUS)
+	if err != nil {
+		log.Fatalf("loading markets: %v", err)
+	}
 
-	// Dual logger: stdout + /tmp log file.
-	logFileName := fmt.Sprintf("/tmp/us-stream-%s.log", time.Now().Format("2006-01-02"))
+	// Dual logger: stdout + log file.
+	logFileName := filepath.Join(sc.LogDir, fmt.Sprintf("us-stream-%s.log", time.Now().Format("2006-01-02")))
 	logFile, err := os.Create(logFileName)
 	if err != nil {
 		log.Fatalf("failed to create log file: %v", err)
@@ -54,9 +85,20 @@ func main() {
 		cfg.Alpaca.APISecret,
 		cfg.Alpaca.BaseURL,
 		cfg.Storage.DataDir,
-		"reference/us/symbol_5_chars.csv",
-		"reference/us",
+		filepath.Join(sc.RefDir, "symbol_5_chars.csv"),
+		sc.RefDir,
 	)
+	gatherer.SetCacheDir(sc.CacheDir)
+	gatherer.SetDaySwitch(daySwitch)
+	gatherer.SetBackfill(sc.BackfillWorkers, sc.BackfillInterval)
+	gatherer.SetMarket(usMarket)
+	// The market's calendar (session hours, holidays, half days) is shared
+	// by the gatherer and the dashboard.
+	cal := us.NewCalendar(usMarket, cfg.Alpaca.APIKey, cfg.Alpaca.APISecret, cfg.Alpaca.BaseURL, cfg.Storage.DataDir, logger)
+	gatherer.SetCalendar(cal)
+	gatherer.SetJournalDir(sc.JournalDir)
+	gatherer.SetQuotes(sc.QuoteSymbols)
+	gatherer.SetTradeFilter(tradeFilter)
 
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
@@ -88,10 +130,7 @@ func main() {
 		slog.Warn("listing history dates for HTTP API", "error", err)
 	}
 
-	loc, err := time.LoadLocation("America/New_York")
-	if err != nil {
-		log.Fatalf("loading timezone: %v", err)
-	}
+	loc := usMarket.Location
 
 	// Optional Alpaca clients for watchlist and live news support.
 	var alpacaClient *alpacaapi.Client
@@ -112,8 +151,17 @@ func main() {
 	tpStore := tradeparams.NewStore(targetFile, logger)
 
 	// Start HTTP API server.
-	httpAddr := ":8080"
-	dashSrv := httpapi.NewDashboardServer(model, cfg.Storage.DataDir, loc, logger, tierMap, histDates, alpacaClient, mdClient, tpStore, "reference/us")
+	httpAddr := sc.HTTPAddr()
+	dashSrv := httpapi.NewDashboardServer(model, cfg.Storage.DataDir, loc, logger, tierMap, histDates, alpacaClient, mdClient, tpStore, sc.RefDir)
+	dashSrv.SetNewsCache(sc.CacheDir, sc.NewsRefresh)
+	dashSrv.SetStreamStatus(gatherer.Status)
+	dashSrv.SetTradeFilters(tradeFilters, tradeFilter)
+	dashSrv.SetCalendar(cal)
+	quoteBook := gatherer.Quotes()
+	if quoteBook != nil {
+		dashSrv.SetQuotes(quoteBook)
+		gatherer.SetQuoteSymbols(dashSrv.QuoteSymbols)
+	}
 	dashSrv.Start(ctx)
 	httpServer := &http.Server{
 		Addr:    httpAddr,
@@ -127,15 +175,27 @@ func main() {
 	}()
 
 	// Start gRPC server.
-	grpcAddr := ":50051"
+	grpcAddr := sc.GRPCAddr()
 	lis, err := net.Listen("tcp", grpcAddr)
 	if err != nil {
 		log.Fatalf("failed to listen on %s: %v", grpcAddr, err)
 	}
 
 	gs := grpc.NewServer()
-	srv := live.NewServer(model, logger)
-	srv.RegisterGRPC(gs)
+	parquetStore := store.NewParquetStore(cfg.Storage.DataDir)
+	mdSrv := api.NewMarketDataService(parquetStore, parquetStore)
+	mdSrv.SetLiveModel(model, logger)
+	var quoteSources api.QuoteSources
+	if quoteBook != nil {
+		quoteSources = append(quoteSources, quoteBook)
+	}
+	if cfg.Alpaca.APIKey != "" {
+		quoteSources = append(quoteSources, api.NewAlpacaQuoteSource(cfg.Alpaca.APIKey, cfg.Alpaca.APISecret, ""))
+	}
+	if len(quoteSources) > 0 {
+		mdSrv.SetQuoteSource(quoteSources)
+	}
+	mdSrv.RegisterGRPC(gs)
 
 	go func() {
 		slog.Info("gRPC server listening", "addr", grpcAddr)
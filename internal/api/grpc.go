@@ -2,15 +2,40 @@ package api
 
 import (
 	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
 
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "jupitor/internal/api/pb"
+	"jupitor/internal/domain"
+	"jupitor/internal/live"
 	"jupitor/internal/store"
 )
 
+// QuoteSource returns the latest NBBO quote for a symbol. It returns an error
+// wrapping store.ErrNotFound if the symbol has no quote.
+type QuoteSource interface {
+	LatestQuote(ctx context.Context, symbol string) (*domain.Quote, error)
+}
+
 // MarketDataService provides gRPC endpoints for querying historical and
-// real-time market data.
+// real-time market data. Historical bars come from the bar store (China
+// A-share bars from the same store when it implements store.CNBaoBarStore);
+// live RPCs require a LiveModel and GetLatestQuote requires a QuoteSource,
+// otherwise they return Unavailable.
 type MarketDataService struct {
+	pb.UnimplementedMarketDataServer
 	barStore   store.BarStore
 	tradeStore store.TradeStore
+	model      *live.LiveModel
+	liveServer *live.Server
+	quotes     QuoteSource
 }
 
 // NewMarketDataService creates a MarketDataService backed by the given stores.
@@ -21,33 +46,257 @@ func NewMarketDataService(barStore store.BarStore, tradeStore store.TradeStore)
 	}
 }
 
-// TradingService provides gRPC endpoints for order submission and position
-// management.
-type TradingService struct {
-	orderStore    store.OrderStore
-	positionStore store.PositionStore
+// SetLiveModel enables the live RPCs (StreamTrades, StreamBars and
+// StreamLiveTrades) on the given model.
+func (s *MarketDataService) SetLiveModel(model *live.LiveModel, log *slog.Logger) {
+	s.model = model
+	s.liveServer = live.NewServer(model, log)
+}
+
+// SetQuoteSource sets the source used by GetLatestQuote.
+func (s *MarketDataService) SetQuoteSource(q QuoteSource) {
+	s.quotes = q
+}
+
+// RegisterGRPC registers the service on the given gRPC server instance.
+func (s *MarketDataService) RegisterGRPC(gs *grpc.Server) {
+	pb.RegisterMarketDataServer(gs, s)
+}
+
+// GetDailyBars returns daily bars for a symbol within [start, end]. market is
+// "us" (default) or "cn"; end defaults to now and start to one year before
+// end.
+func (s *MarketDataService) GetDailyBars(ctx context.Context, req *pb.GetDailyBarsRequest) (*pb.GetDailyBarsResponse, error) {
+	symbol := req.GetSymbol()
+	if symbol == "" {
+		return nil, status.Error(codes.InvalidArgument, "symbol is required")
+	}
+	end := time.Now()
+	if req.GetEnd() != nil {
+		end = req.GetEnd().AsTime()
+	}
+	start := end.AddDate(-1, 0, 0)
+	if req.GetStart() != nil {
+		start = req.GetStart().AsTime()
+	}
+	if end.Before(start) {
+		return nil, status.Error(codes.InvalidArgument, "end is before start")
+	}
+
+	resp := &pb.GetDailyBarsResponse{}
+	switch market := strings.ToLower(req.GetMarket()); market {
+	case "", "us":
+		if s.barStore == nil {
+			return nil, status.Error(codes.Unavailable, "bar store not configured")
+		}
+		bars, err := s.barStore.ReadBars(ctx, strings.ToUpper(symbol), "us", start, end)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		for i := range bars {
+			resp.Bars = append(resp.Bars, barToProto(&bars[i]))
+		}
+	case "cn":
+		cn, ok := s.barStore.(store.CNBaoBarStore)
+		if !ok {
+			return nil, status.Error(codes.Unavailable, "cn bar store not configured")
+		}
+		bars, err := cn.ReadCNBaoBars(ctx, symbol, start, end)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		for i := range bars {
+			b := cnBarToBar(&bars[i])
+			resp.Bars = append(resp.Bars, barToProto(&b))
+		}
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown market %q", market)
+	}
+	return resp, nil
+}
+
+// GetLatestQuote returns the latest quote for a symbol.
+func (s *MarketDataService) GetLatestQuote(ctx context.Context, req *pb.GetLatestQuoteRequest) (*pb.Quote, error) {
+	if s.quotes == nil {
+		return nil, status.Error(codes.Unavailable, "quote source not configured")
+	}
+	symbol := strings.ToUpper(req.GetSymbol())
+	if symbol == "" {
+		return nil, status.Error(codes.InvalidArgument, "symbol is required")
+	}
+	q, err := s.quotes.LatestQuote(ctx, symbol)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "no quote for %s", symbol)
+	}
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	return &pb.Quote{
+		Symbol:    q.Symbol,
+		Timestamp: timestamppb.New(q.Timestamp),
+		BidPrice:  q.BidPrice,
+		AskPrice:  q.AskPrice,
+		BidSize:   q.BidSize,
+		AskSize:   q.AskSize,
+	}, nil
+}
+
+// StreamTrades streams live trades for the requested symbols (all symbols if
+// none are given) until the client disconnects.
+func (s *MarketDataService) StreamTrades(req *pb.StreamTradesRequest, stream grpc.ServerStreamingServer[pb.Trade]) error {
+	if s.model == nil {
+		return status.Error(codes.Unavailable, "live model not configured")
+	}
+	symbols := symbolSet(req.GetSymbols())
+
+	subID, ch := s.model.Subscribe(4096)
+	defer s.model.Unsubscribe(subID)
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case evt, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if symbols != nil && !symbols[evt.Record.Symbol] {
+				continue
+			}
+			t := live.TradeFromRecord(&evt.Record)
+			if err := stream.Send(tradeToProto(&t)); err != nil {
+				return err
+			}
+		}
+	}
 }
 
-// NewTradingService creates a TradingService backed by the given stores.
-func NewTradingService(orderStore store.OrderStore, positionStore store.PositionStore) *TradingService {
-	return &TradingService{
-		orderStore:    orderStore,
-		positionStore: positionStore,
+// StreamBars aggregates live trades for the requested symbols (all if none
+// are given) into bars of the requested timeframe, "1Min" (default) or
+// "5Min", and streams each bar once its window closes. A window closes when a
+// later trade arrives or live.DefaultBarFlushDelay after its end, whichever is first.
+func (s *MarketDataService) StreamBars(req *pb.StreamBarsRequest, stream grpc.ServerStreamingServer[pb.Bar]) error {
+	if s.model == nil {
+		return status.Error(codes.Unavailable, "live model not configured")
+	}
+	var interval time.Duration
+	switch req.GetTimeframe() {
+	case "", "1Min":
+		interval = time.Minute
+	case "5Min":
+		interval = 5 * time.Minute
+	default:
+		return status.Errorf(codes.InvalidArgument, "unsupported timeframe %q (want 1Min or 5Min)", req.GetTimeframe())
+	}
+	symbols := symbolSet(req.GetSymbols())
+	bars := live.NewBarStream(interval, live.DefaultBarFlushDelay)
+
+	subID, ch := s.model.Subscribe(4096)
+	defer s.model.Unsubscribe(subID)
+
+	send := func(closed []domain.Bar) error {
+		for i := range closed {
+			if err := stream.Send(barToProto(&closed[i])); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			if err := send(bars.Tick(now)); err != nil {
+				return err
+			}
+		case evt, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if symbols != nil && !symbols[evt.Record.Symbol] {
+				continue
+			}
+			if err := send(bars.Add(live.TradeFromRecord(&evt.Record))); err != nil {
+				return err
+			}
+		}
 	}
 }
 
-// StrategyService provides gRPC endpoints for managing and monitoring
-// trading strategies.
-type StrategyService struct {
-	signalStore store.SignalStore
+// StreamLiveTrades sends a snapshot of the live model followed by live
+// updates; see live.Server.
+func (s *MarketDataService) StreamLiveTrades(req *pb.StreamLiveTradesRequest, stream grpc.ServerStreamingServer[pb.LiveTrade]) error {
+	if s.liveServer == nil {
+		return status.Error(codes.Unavailable, "live model not configured")
+	}
+	return s.liveServer.StreamLiveTrades(req, stream)
 }
 
-// NewStrategyService creates a StrategyService backed by the given store.
-func NewStrategyService(signalStore store.SignalStore) *StrategyService {
-	return &StrategyService{
-		signalStore: signalStore,
+// ---------------------------------------------------------------------------
+// Conversion helpers
+// ---------------------------------------------------------------------------
+
+// symbolSet returns the upper-cased symbols as a set, or nil if none are
+// given.
+func symbolSet(symbols []string) map[string]bool {
+	if len(symbols) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(symbols))
+	for _, sym := range symbols {
+		set[strings.ToUpper(sym)] = true
+	}
+	return set
+}
+
+func barToProto(b *domain.Bar) *pb.Bar {
+	return &pb.Bar{
+		Symbol:     b.Symbol,
+		Timestamp:  timestamppb.New(b.Timestamp),
+		Open:       b.Open,
+		High:       b.High,
+		Low:        b.Low,
+		Close:      b.Close,
+		Volume:     b.Volume,
+		TradeCount: b.TradeCount,
+		Vwap:       b.VWAP,
 	}
 }
 
-// Ensure context import is used (referenced by future gRPC handler signatures).
-var _ context.Context
+// cnBarToBar maps a BaoStock bar onto the common bar type. The timestamp is
+// the trading date at midnight UTC and VWAP is derived from turnover
+// (amount / volume).
+func cnBarToBar(b *domain.CNBaoBar) domain.Bar {
+	out := domain.Bar{
+		Symbol: b.Symbol,
+		Open:   b.Open,
+		High:   b.High,
+		Low:    b.Low,
+		Close:  b.Close,
+		Volume: b.Volume,
+	}
+	if d, err := time.Parse("2006-01-02", b.Date); err == nil {
+		out.Timestamp = d
+	}
+	if b.Volume > 0 {
+		out.VWAP = b.Amount / float64(b.Volume)
+	}
+	return out
+}
+
+func tradeToProto(t *domain.Trade) *pb.Trade {
+	return &pb.Trade{
+		Symbol:    t.Symbol,
+		Timestamp: timestamppb.New(t.Timestamp),
+		Price:     t.Price,
+		Size:      t.Size,
+		Exchange:  t.Exchange,
+		Id:        t.ID,
+	}
+}
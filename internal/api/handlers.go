@@ -1,67 +1,415 @@
 package api
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"jupitor/internal/domain"
+	"jupitor/internal/engine"
+	"jupitor/internal/store"
 )
 
-// HandleGetBars returns historical bar data for a symbol.
-func HandleGetBars(w http.ResponseWriter, _ *http.Request) {
-	// TODO: parse symbol, market, start, end from query params
-	// TODO: read bars from BarStore and serialize as JSON
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write([]byte(`{"status":"TODO","data":[]}`))
+const (
+	// defaultTradeLimit and maxTradeLimit bound the page size of
+	// HandleGetTrades.
+	defaultTradeLimit = 10000
+	maxTradeLimit     = 50000
+
+	// defaultTradeRange is the lookback of HandleGetTrades when no start is
+	// given.
+	defaultTradeRange = 24 * time.Hour
+)
+
+// Handlers serves the REST API under /api/v1. Bars and trades are read from
+// the stores, orders from the order store, and order submission, positions
+// and the account go through the engine. Endpoints whose dependency is nil
+// return 503.
+type Handlers struct {
+	bars   store.BarStore
+	trades store.TradeStore
+	orders store.OrderStore
+	engine *engine.Engine
 }
 
-// HandleGetTrades returns historical trade data for a symbol.
-func HandleGetTrades(w http.ResponseWriter, _ *http.Request) {
-	// TODO: parse symbol, start, end from query params
-	// TODO: read trades from TradeStore and serialize as JSON
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write([]byte(`{"status":"TODO","data":[]}`))
+// NewHandlers creates Handlers over the given stores and engine. Any of them
+// may be nil.
+func NewHandlers(bars store.BarStore, trades store.TradeStore, orders store.OrderStore, eng *engine.Engine) *Handlers {
+	return &Handlers{bars: bars, trades: trades, orders: orders, engine: eng}
 }
 
-// HandleGetOrders returns orders matching the given status filter.
-func HandleGetOrders(w http.ResponseWriter, _ *http.Request) {
-	// TODO: parse status filter from query params
-	// TODO: read orders from OrderStore and serialize as JSON
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write([]byte(`{"status":"TODO","data":[]}`))
+// Register mounts the handlers on mux under /api/v1.
+func (h *Handlers) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/bars", h.HandleGetBars)
+	mux.HandleFunc("GET /api/v1/trades", h.HandleGetTrades)
+	mux.HandleFunc("GET /api/v1/orders", h.HandleGetOrders)
+	mux.HandleFunc("GET /api/v1/orders/{id}", h.HandleGetOrder)
+	mux.HandleFunc("POST /api/v1/orders", h.HandleSubmitOrder)
+	mux.HandleFunc("DELETE /api/v1/orders/{id}", h.HandleCancelOrder)
+	mux.HandleFunc("GET /api/v1/positions", h.HandleGetPositions)
+	mux.HandleFunc("GET /api/v1/account", h.HandleGetAccount)
+}
+
+// HandleGetBars returns daily bars for ?symbol= within [start, end]. market
+// is "us" (default) or "cn"; end defaults to now and start to one year
+// before end.
+func (h *Handlers) HandleGetBars(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	symbol := q.Get("symbol")
+	if symbol == "" {
+		writeError(w, http.StatusBadRequest, "symbol is required")
+		return
+	}
+	start, end, err := parseRange(q.Get("start"), q.Get("end"), func(end time.Time) time.Time {
+		return end.AddDate(-1, 0, 0)
+	})
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var bars []domain.Bar
+	switch market := strings.ToLower(q.Get("market")); market {
+	case "", "us":
+		if h.bars == nil {
+			writeError(w, http.StatusServiceUnavailable, "bar store not configured")
+			return
+		}
+		bars, err = h.bars.ReadBars(r.Context(), strings.ToUpper(symbol), "us", start, end)
+	case "cn":
+		cn, ok := h.bars.(store.CNBaoBarStore)
+		if !ok {
+			writeError(w, http.StatusServiceUnavailable, "cn bar store not configured")
+			return
+		}
+		var cnBars []domain.CNBaoBar
+		cnBars, err = cn.ReadCNBaoBars(r.Context(), symbol, start, end)
+		for i := range cnBars {
+			bars = append(bars, cnBarToBar(&cnBars[i]))
+		}
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown market %q", market))
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	out := make([]BarJSON, len(bars))
+	for i := range bars {
+		out[i] = barToJSON(&bars[i])
+	}
+	writeJSON(w, http.StatusOK, ListResponse[BarJSON]{Data: out})
+}
+
+// HandleGetTrades returns trades for ?symbol= within [start, end], oldest
+// first, at most ?limit= per page. end defaults to now and start to 24h
+// before end. When more trades remain, the response carries a
+// next_page_token to pass back as ?page_token=.
+func (h *Handlers) HandleGetTrades(w http.ResponseWriter, r *http.Request) {
+	if h.trades == nil {
+		writeError(w, http.StatusServiceUnavailable, "trade store not configured")
+		return
+	}
+	q := r.URL.Query()
+	symbol := strings.ToUpper(q.Get("symbol"))
+	if symbol == "" {
+		writeError(w, http.StatusBadRequest, "symbol is required")
+		return
+	}
+	start, end, err := parseRange(q.Get("start"), q.Get("end"), func(end time.Time) time.Time {
+		return end.Add(-defaultTradeRange)
+	})
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	limit := defaultTradeLimit
+	if v := q.Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > maxTradeLimit {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxTradeLimit))
+			return
+		}
+	}
+	var skip int
+	if tok := q.Get("page_token"); tok != "" {
+		var from time.Time
+		from, skip, err = parsePageToken(tok)
+		if err != nil || from.Before(start) || from.After(end) {
+			writeError(w, http.StatusBadRequest, "invalid page_token")
+			return
+		}
+		start = from
+	}
+
+	// Read the page plus one trade, to know whether another page follows.
+	var trades []domain.Trade
+	if pr, ok := h.trades.(store.TradePageReader); ok {
+		trades, err = pr.ReadTradesLimit(r.Context(), symbol, start, end, skip+limit+1)
+	} else {
+		trades, err = h.trades.ReadTrades(r.Context(), symbol, start, end)
+		sort.SliceStable(trades, func(i, j int) bool { return trades[i].Timestamp.Before(trades[j].Timestamp) })
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp := ListResponse[TradeJSON]{}
+	if next := skip + limit; next < len(trades) {
+		resp.NextPageToken = pageToken(trades[:next+1])
+		trades = trades[:next]
+	}
+	trades = trades[min(skip, len(trades)):]
+	resp.Data = make([]TradeJSON, len(trades))
+	for i := range trades {
+		resp.Data[i] = tradeToJSON(&trades[i])
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// HandleGetOrders returns orders matching ?status= (all orders if empty).
+func (h *Handlers) HandleGetOrders(w http.ResponseWriter, r *http.Request) {
+	if h.orders == nil {
+		writeError(w, http.StatusServiceUnavailable, "order store not configured")
+		return
+	}
+	st := domain.OrderStatus(strings.ToLower(r.URL.Query().Get("status")))
+	switch st {
+	case "", domain.OrderStatusPending, domain.OrderStatusSubmitted, domain.OrderStatusFilled,
+		domain.OrderStatusPartial, domain.OrderStatusPendingCancel, domain.OrderStatusCancelled, domain.OrderStatusRejected:
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown status %q", st))
+		return
+	}
+
+	orders, err := h.orders.ListOrders(r.Context(), st)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	out := make([]OrderJSON, len(orders))
+	for i := range orders {
+		out[i] = orderToJSON(&orders[i])
+	}
+	writeJSON(w, http.StatusOK, ListResponse[OrderJSON]{Data: out})
+}
+
+// HandleGetOrder returns a single order by ID.
+func (h *Handlers) HandleGetOrder(w http.ResponseWriter, r *http.Request) {
+	if h.orders == nil {
+		writeError(w, http.StatusServiceUnavailable, "order store not configured")
+		return
+	}
+	id := r.PathValue("id")
+	o, err := h.orders.GetOrder(r.Context(), id)
+	if errors.Is(err, store.ErrNotFound) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("order %q not found", id))
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, ItemResponse[OrderJSON]{Data: orderToJSON(o)})
 }
 
 // HandleGetPositions returns all currently open positions.
-func HandleGetPositions(w http.ResponseWriter, _ *http.Request) {
-	// TODO: read positions from Engine or PositionStore
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write([]byte(`{"status":"TODO","data":[]}`))
+func (h *Handlers) HandleGetPositions(w http.ResponseWriter, r *http.Request) {
+	if h.engine == nil {
+		writeError(w, http.StatusServiceUnavailable, "trading engine not configured")
+		return
+	}
+	positions, err := h.engine.GetPositions(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	out := make([]PositionJSON, len(positions))
+	for i := range positions {
+		out[i] = positionToJSON(&positions[i])
+	}
+	writeJSON(w, http.StatusOK, ListResponse[PositionJSON]{Data: out})
 }
 
 // HandleGetAccount returns the current account information.
-func HandleGetAccount(w http.ResponseWriter, _ *http.Request) {
-	// TODO: fetch account info from Broker and serialize as JSON
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write([]byte(`{"status":"TODO","data":{}}`))
+func (h *Handlers) HandleGetAccount(w http.ResponseWriter, r *http.Request) {
+	if h.engine == nil {
+		writeError(w, http.StatusServiceUnavailable, "trading engine not configured")
+		return
+	}
+	acct, err := h.engine.GetAccount(r.Context())
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, ItemResponse[AccountJSON]{Data: AccountJSON{
+		Equity:         acct.Equity,
+		Cash:           acct.Cash,
+		BuyingPower:    acct.BuyingPower,
+		PortfolioValue: acct.PortfolioValue,
+		DailyPL:        acct.DailyPL,
+		DailyPLPct:     acct.DailyPLPct,
+	}})
 }
 
-// HandleSubmitOrder accepts and processes a new order submission.
-func HandleSubmitOrder(w http.ResponseWriter, _ *http.Request) {
-	// TODO: parse order from request body JSON
-	// TODO: submit via Engine.SubmitOrder
-	// TODO: return created order as JSON
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusAccepted)
-	_, _ = w.Write([]byte(`{"status":"TODO","order":{}}`))
+// HandleSubmitOrder submits the SubmitOrderJSON body through the engine and
+// returns the order with 202. Risk and broker rejections return 422; risk
+// rejections carry the rule name.
+func (h *Handlers) HandleSubmitOrder(w http.ResponseWriter, r *http.Request) {
+	if h.engine == nil {
+		writeError(w, http.StatusServiceUnavailable, "trading engine not configured")
+		return
+	}
+	var req SubmitOrderJSON
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid order body: "+err.Error())
+		return
+	}
+	order, err := req.toOrder()
+	if err == nil {
+		err = validateOrder(order)
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	out, err := h.engine.SubmitOrder(r.Context(), order)
+	if err != nil {
+		var re *engine.RiskError
+		switch {
+		case errors.As(err, &re):
+			writeJSON(w, http.StatusUnprocessableEntity, ErrorJSON{Error: re.Error(), Rule: re.Rule})
+		case out != nil && out.Status == domain.OrderStatusRejected:
+			o := orderToJSON(out)
+			writeJSON(w, http.StatusUnprocessableEntity, ErrorJSON{Error: err.Error(), Order: &o})
+		default:
+			writeError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	writeJSON(w, http.StatusAccepted, ItemResponse[OrderJSON]{Data: orderToJSON(out)})
+}
+
+// HandleCancelOrder cancels the open order named in the URL path.
+func (h *Handlers) HandleCancelOrder(w http.ResponseWriter, r *http.Request) {
+	if h.engine == nil {
+		writeError(w, http.StatusServiceUnavailable, "trading engine not configured")
+		return
+	}
+	id := r.PathValue("id")
+	switch err := h.engine.CancelOrder(r.Context(), id); {
+	case errors.Is(err, store.ErrNotFound):
+		writeError(w, http.StatusNotFound, fmt.Sprintf("order %q not found", id))
+		return
+	case errors.Is(err, engine.ErrOrderNotOpen):
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	o, err := h.engine.GetOrder(r.Context(), id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, ItemResponse[OrderJSON]{Data: orderToJSON(o)})
+}
+
+// ---------------------------------------------------------------------------
+// Request parsing
+// ---------------------------------------------------------------------------
+
+// parseRange parses optional start and end query values, each a date
+// (2006-01-02, UTC midnight) or an RFC 3339 timestamp. end defaults to now
+// and start to defStart(end). A date-only end covers that whole day.
+func parseRange(startStr, endStr string, defStart func(end time.Time) time.Time) (start, end time.Time, err error) {
+	end = time.Now().UTC()
+	if endStr != "" {
+		var dateOnly bool
+		if end, dateOnly, err = parseTime(endStr); err != nil {
+			return start, end, fmt.Errorf("invalid end: %w", err)
+		}
+		if dateOnly {
+			end = end.Add(24*time.Hour - time.Nanosecond)
+		}
+	}
+	start = defStart(end)
+	if startStr != "" {
+		if start, _, err = parseTime(startStr); err != nil {
+			return start, end, fmt.Errorf("invalid start: %w", err)
+		}
+	}
+	if end.Before(start) {
+		return start, end, errors.New("end is before start")
+	}
+	return start, end, nil
 }
 
-// HandleCancelOrder requests cancellation of an open order.
-func HandleCancelOrder(w http.ResponseWriter, _ *http.Request) {
-	// TODO: parse order ID from URL path
-	// TODO: cancel via Engine.CancelOrder
+// parseTime parses a date or RFC 3339 timestamp, reporting whether s was a
+// bare date.
+func parseTime(s string) (t time.Time, dateOnly bool, err error) {
+	if t, err = time.Parse(time.DateOnly, s); err == nil {
+		return t, true, nil
+	}
+	t, err = time.Parse(time.RFC3339Nano, s)
+	return t, false, err
+}
+
+// pageToken encodes the position of the last trade in trades, which must
+// hold every trade from the page start onwards, as "<unix nanos>.<n>" where
+// n counts the trades sharing its timestamp that precede it.
+func pageToken(trades []domain.Trade) string {
+	last := trades[len(trades)-1].Timestamp
+	n := 0
+	for i := len(trades) - 2; i >= 0 && trades[i].Timestamp.Equal(last); i-- {
+		n++
+	}
+	return fmt.Sprintf("%d.%d", last.UnixNano(), n)
+}
+
+// parsePageToken decodes a token produced by pageToken into the timestamp
+// to resume from and the number of trades at that timestamp to skip.
+func parsePageToken(tok string) (time.Time, int, error) {
+	nanos, skip, ok := strings.Cut(tok, ".")
+	if !ok {
+		return time.Time{}, 0, errors.New("malformed page token")
+	}
+	ns, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	n, err := strconv.Atoi(skip)
+	if err != nil || n < 0 {
+		return time.Time{}, 0, errors.New("malformed page token")
+	}
+	return time.Unix(0, ns).UTC(), n, nil
+}
+
+// ---------------------------------------------------------------------------
+// Response helpers
+// ---------------------------------------------------------------------------
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write([]byte(`{"status":"TODO"}`))
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("encoding JSON response", "error", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, ErrorJSON{Error: msg})
 }
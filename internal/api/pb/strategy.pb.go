@@ -0,0 +1,935 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.33.4
+// source: strategy.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SignalType int32
+
+const (
+	SignalType_SIGNAL_TYPE_UNSPECIFIED SignalType = 0
+	SignalType_SIGNAL_TYPE_BUY         SignalType = 1
+	SignalType_SIGNAL_TYPE_SELL        SignalType = 2
+	SignalType_SIGNAL_TYPE_HOLD        SignalType = 3
+)
+
+// Enum value maps for SignalType.
+var (
+	SignalType_name = map[int32]string{
+		0: "SIGNAL_TYPE_UNSPECIFIED",
+		1: "SIGNAL_TYPE_BUY",
+		2: "SIGNAL_TYPE_SELL",
+		3: "SIGNAL_TYPE_HOLD",
+	}
+	SignalType_value = map[string]int32{
+		"SIGNAL_TYPE_UNSPECIFIED": 0,
+		"SIGNAL_TYPE_BUY":         1,
+		"SIGNAL_TYPE_SELL":        2,
+		"SIGNAL_TYPE_HOLD":        3,
+	}
+)
+
+func (x SignalType) Enum() *SignalType {
+	p := new(SignalType)
+	*p = x
+	return p
+}
+
+func (x SignalType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SignalType) Descriptor() protoreflect.EnumDescriptor {
+	return file_strategy_proto_enumTypes[0].Descriptor()
+}
+
+func (SignalType) Type() protoreflect.EnumType {
+	return &file_strategy_proto_enumTypes[0]
+}
+
+func (x SignalType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SignalType.Descriptor instead.
+func (SignalType) EnumDescriptor() ([]byte, []int) {
+	return file_strategy_proto_rawDescGZIP(), []int{0}
+}
+
+type ListStrategiesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListStrategiesRequest) Reset() {
+	*x = ListStrategiesRequest{}
+	mi := &file_strategy_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListStrategiesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListStrategiesRequest) ProtoMessage() {}
+
+func (x *ListStrategiesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_strategy_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListStrategiesRequest.ProtoReflect.Descriptor instead.
+func (*ListStrategiesRequest) Descriptor() ([]byte, []int) {
+	return file_strategy_proto_rawDescGZIP(), []int{0}
+}
+
+type ListStrategiesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Strategies    []*StrategyInfo        `protobuf:"bytes,1,rep,name=strategies,proto3" json:"strategies,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListStrategiesResponse) Reset() {
+	*x = ListStrategiesResponse{}
+	mi := &file_strategy_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListStrategiesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListStrategiesResponse) ProtoMessage() {}
+
+func (x *ListStrategiesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_strategy_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListStrategiesResponse.ProtoReflect.Descriptor instead.
+func (*ListStrategiesResponse) Descriptor() ([]byte, []int) {
+	return file_strategy_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListStrategiesResponse) GetStrategies() []*StrategyInfo {
+	if x != nil {
+		return x.Strategies
+	}
+	return nil
+}
+
+type GetStrategyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StrategyId    string                 `protobuf:"bytes,1,opt,name=strategy_id,json=strategyId,proto3" json:"strategy_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStrategyRequest) Reset() {
+	*x = GetStrategyRequest{}
+	mi := &file_strategy_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStrategyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStrategyRequest) ProtoMessage() {}
+
+func (x *GetStrategyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_strategy_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStrategyRequest.ProtoReflect.Descriptor instead.
+func (*GetStrategyRequest) Descriptor() ([]byte, []int) {
+	return file_strategy_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetStrategyRequest) GetStrategyId() string {
+	if x != nil {
+		return x.StrategyId
+	}
+	return ""
+}
+
+type StrategyInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"` // "running", "stopped", "error"
+	Symbols       []string               `protobuf:"bytes,5,rep,name=symbols,proto3" json:"symbols,omitempty"`
+	Params        map[string]string      `protobuf:"bytes,6,rep,name=params,proto3" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StrategyInfo) Reset() {
+	*x = StrategyInfo{}
+	mi := &file_strategy_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StrategyInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StrategyInfo) ProtoMessage() {}
+
+func (x *StrategyInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_strategy_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StrategyInfo.ProtoReflect.Descriptor instead.
+func (*StrategyInfo) Descriptor() ([]byte, []int) {
+	return file_strategy_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *StrategyInfo) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *StrategyInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *StrategyInfo) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *StrategyInfo) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *StrategyInfo) GetSymbols() []string {
+	if x != nil {
+		return x.Symbols
+	}
+	return nil
+}
+
+func (x *StrategyInfo) GetParams() map[string]string {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+type StartStrategyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StrategyId    string                 `protobuf:"bytes,1,opt,name=strategy_id,json=strategyId,proto3" json:"strategy_id,omitempty"`
+	Symbols       []string               `protobuf:"bytes,2,rep,name=symbols,proto3" json:"symbols,omitempty"`
+	Params        map[string]string      `protobuf:"bytes,3,rep,name=params,proto3" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartStrategyRequest) Reset() {
+	*x = StartStrategyRequest{}
+	mi := &file_strategy_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartStrategyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartStrategyRequest) ProtoMessage() {}
+
+func (x *StartStrategyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_strategy_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartStrategyRequest.ProtoReflect.Descriptor instead.
+func (*StartStrategyRequest) Descriptor() ([]byte, []int) {
+	return file_strategy_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *StartStrategyRequest) GetStrategyId() string {
+	if x != nil {
+		return x.StrategyId
+	}
+	return ""
+}
+
+func (x *StartStrategyRequest) GetSymbols() []string {
+	if x != nil {
+		return x.Symbols
+	}
+	return nil
+}
+
+func (x *StartStrategyRequest) GetParams() map[string]string {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+type StopStrategyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StrategyId    string                 `protobuf:"bytes,1,opt,name=strategy_id,json=strategyId,proto3" json:"strategy_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopStrategyRequest) Reset() {
+	*x = StopStrategyRequest{}
+	mi := &file_strategy_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopStrategyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopStrategyRequest) ProtoMessage() {}
+
+func (x *StopStrategyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_strategy_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopStrategyRequest.ProtoReflect.Descriptor instead.
+func (*StopStrategyRequest) Descriptor() ([]byte, []int) {
+	return file_strategy_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *StopStrategyRequest) GetStrategyId() string {
+	if x != nil {
+		return x.StrategyId
+	}
+	return ""
+}
+
+type StrategyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StrategyId    string                 `protobuf:"bytes,1,opt,name=strategy_id,json=strategyId,proto3" json:"strategy_id,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StrategyResponse) Reset() {
+	*x = StrategyResponse{}
+	mi := &file_strategy_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StrategyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StrategyResponse) ProtoMessage() {}
+
+func (x *StrategyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_strategy_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StrategyResponse.ProtoReflect.Descriptor instead.
+func (*StrategyResponse) Descriptor() ([]byte, []int) {
+	return file_strategy_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *StrategyResponse) GetStrategyId() string {
+	if x != nil {
+		return x.StrategyId
+	}
+	return ""
+}
+
+func (x *StrategyResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *StrategyResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type RunBacktestRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	StrategyId     string                 `protobuf:"bytes,1,opt,name=strategy_id,json=strategyId,proto3" json:"strategy_id,omitempty"`
+	Symbols        []string               `protobuf:"bytes,2,rep,name=symbols,proto3" json:"symbols,omitempty"`
+	Start          *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=start,proto3" json:"start,omitempty"`
+	End            *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=end,proto3" json:"end,omitempty"`
+	InitialCapital float64                `protobuf:"fixed64,5,opt,name=initial_capital,json=initialCapital,proto3" json:"initial_capital,omitempty"`
+	Params         map[string]string      `protobuf:"bytes,6,rep,name=params,proto3" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *RunBacktestRequest) Reset() {
+	*x = RunBacktestRequest{}
+	mi := &file_strategy_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunBacktestRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunBacktestRequest) ProtoMessage() {}
+
+func (x *RunBacktestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_strategy_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunBacktestRequest.ProtoReflect.Descriptor instead.
+func (*RunBacktestRequest) Descriptor() ([]byte, []int) {
+	return file_strategy_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RunBacktestRequest) GetStrategyId() string {
+	if x != nil {
+		return x.StrategyId
+	}
+	return ""
+}
+
+func (x *RunBacktestRequest) GetSymbols() []string {
+	if x != nil {
+		return x.Symbols
+	}
+	return nil
+}
+
+func (x *RunBacktestRequest) GetStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Start
+	}
+	return nil
+}
+
+func (x *RunBacktestRequest) GetEnd() *timestamppb.Timestamp {
+	if x != nil {
+		return x.End
+	}
+	return nil
+}
+
+func (x *RunBacktestRequest) GetInitialCapital() float64 {
+	if x != nil {
+		return x.InitialCapital
+	}
+	return 0
+}
+
+func (x *RunBacktestRequest) GetParams() map[string]string {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+type BacktestResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StrategyId    string                 `protobuf:"bytes,1,opt,name=strategy_id,json=strategyId,proto3" json:"strategy_id,omitempty"`
+	TotalReturn   float64                `protobuf:"fixed64,2,opt,name=total_return,json=totalReturn,proto3" json:"total_return,omitempty"`
+	SharpeRatio   float64                `protobuf:"fixed64,3,opt,name=sharpe_ratio,json=sharpeRatio,proto3" json:"sharpe_ratio,omitempty"`
+	MaxDrawdown   float64                `protobuf:"fixed64,4,opt,name=max_drawdown,json=maxDrawdown,proto3" json:"max_drawdown,omitempty"`
+	TotalTrades   int32                  `protobuf:"varint,5,opt,name=total_trades,json=totalTrades,proto3" json:"total_trades,omitempty"`
+	WinRate       float64                `protobuf:"fixed64,6,opt,name=win_rate,json=winRate,proto3" json:"win_rate,omitempty"`
+	ProfitFactor  float64                `protobuf:"fixed64,7,opt,name=profit_factor,json=profitFactor,proto3" json:"profit_factor,omitempty"`
+	Signals       []*Signal              `protobuf:"bytes,8,rep,name=signals,proto3" json:"signals,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BacktestResult) Reset() {
+	*x = BacktestResult{}
+	mi := &file_strategy_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BacktestResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BacktestResult) ProtoMessage() {}
+
+func (x *BacktestResult) ProtoReflect() protoreflect.Message {
+	mi := &file_strategy_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BacktestResult.ProtoReflect.Descriptor instead.
+func (*BacktestResult) Descriptor() ([]byte, []int) {
+	return file_strategy_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *BacktestResult) GetStrategyId() string {
+	if x != nil {
+		return x.StrategyId
+	}
+	return ""
+}
+
+func (x *BacktestResult) GetTotalReturn() float64 {
+	if x != nil {
+		return x.TotalReturn
+	}
+	return 0
+}
+
+func (x *BacktestResult) GetSharpeRatio() float64 {
+	if x != nil {
+		return x.SharpeRatio
+	}
+	return 0
+}
+
+func (x *BacktestResult) GetMaxDrawdown() float64 {
+	if x != nil {
+		return x.MaxDrawdown
+	}
+	return 0
+}
+
+func (x *BacktestResult) GetTotalTrades() int32 {
+	if x != nil {
+		return x.TotalTrades
+	}
+	return 0
+}
+
+func (x *BacktestResult) GetWinRate() float64 {
+	if x != nil {
+		return x.WinRate
+	}
+	return 0
+}
+
+func (x *BacktestResult) GetProfitFactor() float64 {
+	if x != nil {
+		return x.ProfitFactor
+	}
+	return 0
+}
+
+func (x *BacktestResult) GetSignals() []*Signal {
+	if x != nil {
+		return x.Signals
+	}
+	return nil
+}
+
+type Signal struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StrategyId    string                 `protobuf:"bytes,1,opt,name=strategy_id,json=strategyId,proto3" json:"strategy_id,omitempty"`
+	Symbol        string                 `protobuf:"bytes,2,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Type          SignalType             `protobuf:"varint,3,opt,name=type,proto3,enum=jupitor.strategy.SignalType" json:"type,omitempty"`
+	Strength      float64                `protobuf:"fixed64,4,opt,name=strength,proto3" json:"strength,omitempty"` // -1.0 to 1.0
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Metadata      map[string]string      `protobuf:"bytes,6,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Signal) Reset() {
+	*x = Signal{}
+	mi := &file_strategy_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Signal) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Signal) ProtoMessage() {}
+
+func (x *Signal) ProtoReflect() protoreflect.Message {
+	mi := &file_strategy_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Signal.ProtoReflect.Descriptor instead.
+func (*Signal) Descriptor() ([]byte, []int) {
+	return file_strategy_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Signal) GetStrategyId() string {
+	if x != nil {
+		return x.StrategyId
+	}
+	return ""
+}
+
+func (x *Signal) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *Signal) GetType() SignalType {
+	if x != nil {
+		return x.Type
+	}
+	return SignalType_SIGNAL_TYPE_UNSPECIFIED
+}
+
+func (x *Signal) GetStrength() float64 {
+	if x != nil {
+		return x.Strength
+	}
+	return 0
+}
+
+func (x *Signal) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *Signal) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type StreamSignalsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StrategyId    string                 `protobuf:"bytes,1,opt,name=strategy_id,json=strategyId,proto3" json:"strategy_id,omitempty"` // Empty for all strategies
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamSignalsRequest) Reset() {
+	*x = StreamSignalsRequest{}
+	mi := &file_strategy_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamSignalsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamSignalsRequest) ProtoMessage() {}
+
+func (x *StreamSignalsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_strategy_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamSignalsRequest.ProtoReflect.Descriptor instead.
+func (*StreamSignalsRequest) Descriptor() ([]byte, []int) {
+	return file_strategy_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *StreamSignalsRequest) GetStrategyId() string {
+	if x != nil {
+		return x.StrategyId
+	}
+	return ""
+}
+
+var File_strategy_proto protoreflect.FileDescriptor
+
+const file_strategy_proto_rawDesc = "" +
+	"\n" +
+	"\x0estrategy.proto\x12\x10jupitor.strategy\x1a\x1fgoogle/protobuf/timestamp.proto\"\x17\n" +
+	"\x15ListStrategiesRequest\"X\n" +
+	"\x16ListStrategiesResponse\x12>\n" +
+	"\n" +
+	"strategies\x18\x01 \x03(\v2\x1e.jupitor.strategy.StrategyInfoR\n" +
+	"strategies\"5\n" +
+	"\x12GetStrategyRequest\x12\x1f\n" +
+	"\vstrategy_id\x18\x01 \x01(\tR\n" +
+	"strategyId\"\x85\x02\n" +
+	"\fStrategyInfo\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12\x18\n" +
+	"\asymbols\x18\x05 \x03(\tR\asymbols\x12B\n" +
+	"\x06params\x18\x06 \x03(\v2*.jupitor.strategy.StrategyInfo.ParamsEntryR\x06params\x1a9\n" +
+	"\vParamsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xd8\x01\n" +
+	"\x14StartStrategyRequest\x12\x1f\n" +
+	"\vstrategy_id\x18\x01 \x01(\tR\n" +
+	"strategyId\x12\x18\n" +
+	"\asymbols\x18\x02 \x03(\tR\asymbols\x12J\n" +
+	"\x06params\x18\x03 \x03(\v22.jupitor.strategy.StartStrategyRequest.ParamsEntryR\x06params\x1a9\n" +
+	"\vParamsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"6\n" +
+	"\x13StopStrategyRequest\x12\x1f\n" +
+	"\vstrategy_id\x18\x01 \x01(\tR\n" +
+	"strategyId\"e\n" +
+	"\x10StrategyResponse\x12\x1f\n" +
+	"\vstrategy_id\x18\x01 \x01(\tR\n" +
+	"strategyId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\"\xdd\x02\n" +
+	"\x12RunBacktestRequest\x12\x1f\n" +
+	"\vstrategy_id\x18\x01 \x01(\tR\n" +
+	"strategyId\x12\x18\n" +
+	"\asymbols\x18\x02 \x03(\tR\asymbols\x120\n" +
+	"\x05start\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x05start\x12,\n" +
+	"\x03end\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x03end\x12'\n" +
+	"\x0finitial_capital\x18\x05 \x01(\x01R\x0einitialCapital\x12H\n" +
+	"\x06params\x18\x06 \x03(\v20.jupitor.strategy.RunBacktestRequest.ParamsEntryR\x06params\x1a9\n" +
+	"\vParamsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xb1\x02\n" +
+	"\x0eBacktestResult\x12\x1f\n" +
+	"\vstrategy_id\x18\x01 \x01(\tR\n" +
+	"strategyId\x12!\n" +
+	"\ftotal_return\x18\x02 \x01(\x01R\vtotalReturn\x12!\n" +
+	"\fsharpe_ratio\x18\x03 \x01(\x01R\vsharpeRatio\x12!\n" +
+	"\fmax_drawdown\x18\x04 \x01(\x01R\vmaxDrawdown\x12!\n" +
+	"\ftotal_trades\x18\x05 \x01(\x05R\vtotalTrades\x12\x19\n" +
+	"\bwin_rate\x18\x06 \x01(\x01R\awinRate\x12#\n" +
+	"\rprofit_factor\x18\a \x01(\x01R\fprofitFactor\x122\n" +
+	"\asignals\x18\b \x03(\v2\x18.jupitor.strategy.SignalR\asignals\"\xca\x02\n" +
+	"\x06Signal\x12\x1f\n" +
+	"\vstrategy_id\x18\x01 \x01(\tR\n" +
+	"strategyId\x12\x16\n" +
+	"\x06symbol\x18\x02 \x01(\tR\x06symbol\x120\n" +
+	"\x04type\x18\x03 \x01(\x0e2\x1c.jupitor.strategy.SignalTypeR\x04type\x12\x1a\n" +
+	"\bstrength\x18\x04 \x01(\x01R\bstrength\x128\n" +
+	"\ttimestamp\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12B\n" +
+	"\bmetadata\x18\x06 \x03(\v2&.jupitor.strategy.Signal.MetadataEntryR\bmetadata\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"7\n" +
+	"\x14StreamSignalsRequest\x12\x1f\n" +
+	"\vstrategy_id\x18\x01 \x01(\tR\n" +
+	"strategyId*j\n" +
+	"\n" +
+	"SignalType\x12\x1b\n" +
+	"\x17SIGNAL_TYPE_UNSPECIFIED\x10\x00\x12\x13\n" +
+	"\x0fSIGNAL_TYPE_BUY\x10\x01\x12\x14\n" +
+	"\x10SIGNAL_TYPE_SELL\x10\x02\x12\x14\n" +
+	"\x10SIGNAL_TYPE_HOLD\x10\x032\xa8\x04\n" +
+	"\bStrategy\x12c\n" +
+	"\x0eListStrategies\x12'.jupitor.strategy.ListStrategiesRequest\x1a(.jupitor.strategy.ListStrategiesResponse\x12S\n" +
+	"\vGetStrategy\x12$.jupitor.strategy.GetStrategyRequest\x1a\x1e.jupitor.strategy.StrategyInfo\x12[\n" +
+	"\rStartStrategy\x12&.jupitor.strategy.StartStrategyRequest\x1a\".jupitor.strategy.StrategyResponse\x12Y\n" +
+	"\fStopStrategy\x12%.jupitor.strategy.StopStrategyRequest\x1a\".jupitor.strategy.StrategyResponse\x12U\n" +
+	"\vRunBacktest\x12$.jupitor.strategy.RunBacktestRequest\x1a .jupitor.strategy.BacktestResult\x12S\n" +
+	"\rStreamSignals\x12&.jupitor.strategy.StreamSignalsRequest\x1a\x18.jupitor.strategy.Signal0\x01B\x19Z\x17jupitor/internal/api/pbb\x06proto3"
+
+var (
+	file_strategy_proto_rawDescOnce sync.Once
+	file_strategy_proto_rawDescData []byte
+)
+
+func file_strategy_proto_rawDescGZIP() []byte {
+	file_strategy_proto_rawDescOnce.Do(func() {
+		file_strategy_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_strategy_proto_rawDesc), len(file_strategy_proto_rawDesc)))
+	})
+	return file_strategy_proto_rawDescData
+}
+
+var file_strategy_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_strategy_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_strategy_proto_goTypes = []any{
+	(SignalType)(0),                // 0: jupitor.strategy.SignalType
+	(*ListStrategiesRequest)(nil),  // 1: jupitor.strategy.ListStrategiesRequest
+	(*ListStrategiesResponse)(nil), // 2: jupitor.strategy.ListStrategiesResponse
+	(*GetStrategyRequest)(nil),     // 3: jupitor.strategy.GetStrategyRequest
+	(*StrategyInfo)(nil),           // 4: jupitor.strategy.StrategyInfo
+	(*StartStrategyRequest)(nil),   // 5: jupitor.strategy.StartStrategyRequest
+	(*StopStrategyRequest)(nil),    // 6: jupitor.strategy.StopStrategyRequest
+	(*StrategyResponse)(nil),       // 7: jupitor.strategy.StrategyResponse
+	(*RunBacktestRequest)(nil),     // 8: jupitor.strategy.RunBacktestRequest
+	(*BacktestResult)(nil),         // 9: jupitor.strategy.BacktestResult
+	(*Signal)(nil),                 // 10: jupitor.strategy.Signal
+	(*StreamSignalsRequest)(nil),   // 11: jupitor.strategy.StreamSignalsRequest
+	nil,                            // 12: jupitor.strategy.StrategyInfo.ParamsEntry
+	nil,                            // 13: jupitor.strategy.StartStrategyRequest.ParamsEntry
+	nil,                            // 14: jupitor.strategy.RunBacktestRequest.ParamsEntry
+	nil,                            // 15: jupitor.strategy.Signal.MetadataEntry
+	(*timestamppb.Timestamp)(nil),  // 16: google.protobuf.Timestamp
+}
+var file_strategy_proto_depIdxs = []int32{
+	4,  // 0: jupitor.strategy.ListStrategiesResponse.strategies:type_name -> jupitor.strategy.StrategyInfo
+	12, // 1: jupitor.strategy.StrategyInfo.params:type_name -> jupitor.strategy.StrategyInfo.ParamsEntry
+	13, // 2: jupitor.strategy.StartStrategyRequest.params:type_name -> jupitor.strategy.StartStrategyRequest.ParamsEntry
+	16, // 3: jupitor.strategy.RunBacktestRequest.start:type_name -> google.protobuf.Timestamp
+	16, // 4: jupitor.strategy.RunBacktestRequest.end:type_name -> google.protobuf.Timestamp
+	14, // 5: jupitor.strategy.RunBacktestRequest.params:type_name -> jupitor.strategy.RunBacktestRequest.ParamsEntry
+	10, // 6: jupitor.strategy.BacktestResult.signals:type_name -> jupitor.strategy.Signal
+	0,  // 7: jupitor.strategy.Signal.type:type_name -> jupitor.strategy.SignalType
+	16, // 8: jupitor.strategy.Signal.timestamp:type_name -> google.protobuf.Timestamp
+	15, // 9: jupitor.strategy.Signal.metadata:type_name -> jupitor.strategy.Signal.MetadataEntry
+	1,  // 10: jupitor.strategy.Strategy.ListStrategies:input_type -> jupitor.strategy.ListStrategiesRequest
+	3,  // 11: jupitor.strategy.Strategy.GetStrategy:input_type -> jupitor.strategy.GetStrategyRequest
+	5,  // 12: jupitor.strategy.Strategy.StartStrategy:input_type -> jupitor.strategy.StartStrategyRequest
+	6,  // 13: jupitor.strategy.Strategy.StopStrategy:input_type -> jupitor.strategy.StopStrategyRequest
+	8,  // 14: jupitor.strategy.Strategy.RunBacktest:input_type -> jupitor.strategy.RunBacktestRequest
+	11, // 15: jupitor.strategy.Strategy.StreamSignals:input_type -> jupitor.strategy.StreamSignalsRequest
+	2,  // 16: jupitor.strategy.Strategy.ListStrategies:output_type -> jupitor.strategy.ListStrategiesResponse
+	4,  // 17: jupitor.strategy.Strategy.GetStrategy:output_type -> jupitor.strategy.StrategyInfo
+	7,  // 18: jupitor.strategy.Strategy.StartStrategy:output_type -> jupitor.strategy.StrategyResponse
+	7,  // 19: jupitor.strategy.Strategy.StopStrategy:output_type -> jupitor.strategy.StrategyResponse
+	9,  // 20: jupitor.strategy.Strategy.RunBacktest:output_type -> jupitor.strategy.BacktestResult
+	10, // 21: jupitor.strategy.Strategy.StreamSignals:output_type -> jupitor.strategy.Signal
+	16, // [16:22] is the sub-list for method output_type
+	10, // [10:16] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_strategy_proto_init() }
+func file_strategy_proto_init() {
+	if File_strategy_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_strategy_proto_rawDesc), len(file_strategy_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   15,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_strategy_proto_goTypes,
+		DependencyIndexes: file_strategy_proto_depIdxs,
+		EnumInfos:         file_strategy_proto_enumTypes,
+		MessageInfos:      file_strategy_proto_msgTypes,
+	}.Build()
+	File_strategy_proto = out.File
+	file_strategy_proto_goTypes = nil
+	file_strategy_proto_depIdxs = nil
+}
@@ -0,0 +1,331 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.1
+// - protoc             v6.33.4
+// source: strategy.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Strategy_ListStrategies_FullMethodName = "/jupitor.strategy.Strategy/ListStrategies"
+	Strategy_GetStrategy_FullMethodName    = "/jupitor.strategy.Strategy/GetStrategy"
+	Strategy_StartStrategy_FullMethodName  = "/jupitor.strategy.Strategy/StartStrategy"
+	Strategy_StopStrategy_FullMethodName   = "/jupitor.strategy.Strategy/StopStrategy"
+	Strategy_RunBacktest_FullMethodName    = "/jupitor.strategy.Strategy/RunBacktest"
+	Strategy_StreamSignals_FullMethodName  = "/jupitor.strategy.Strategy/StreamSignals"
+)
+
+// StrategyClient is the client API for Strategy service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Strategy service manages trading strategies and backtesting.
+type StrategyClient interface {
+	// List registered strategies.
+	ListStrategies(ctx context.Context, in *ListStrategiesRequest, opts ...grpc.CallOption) (*ListStrategiesResponse, error)
+	// Get strategy status and performance.
+	GetStrategy(ctx context.Context, in *GetStrategyRequest, opts ...grpc.CallOption) (*StrategyInfo, error)
+	// Start a strategy.
+	StartStrategy(ctx context.Context, in *StartStrategyRequest, opts ...grpc.CallOption) (*StrategyResponse, error)
+	// Stop a strategy.
+	StopStrategy(ctx context.Context, in *StopStrategyRequest, opts ...grpc.CallOption) (*StrategyResponse, error)
+	// Run a backtest.
+	RunBacktest(ctx context.Context, in *RunBacktestRequest, opts ...grpc.CallOption) (*BacktestResult, error)
+	// Stream strategy signals.
+	StreamSignals(ctx context.Context, in *StreamSignalsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Signal], error)
+}
+
+type strategyClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewStrategyClient(cc grpc.ClientConnInterface) StrategyClient {
+	return &strategyClient{cc}
+}
+
+func (c *strategyClient) ListStrategies(ctx context.Context, in *ListStrategiesRequest, opts ...grpc.CallOption) (*ListStrategiesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListStrategiesResponse)
+	err := c.cc.Invoke(ctx, Strategy_ListStrategies_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *strategyClient) GetStrategy(ctx context.Context, in *GetStrategyRequest, opts ...grpc.CallOption) (*StrategyInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StrategyInfo)
+	err := c.cc.Invoke(ctx, Strategy_GetStrategy_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *strategyClient) StartStrategy(ctx context.Context, in *StartStrategyRequest, opts ...grpc.CallOption) (*StrategyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StrategyResponse)
+	err := c.cc.Invoke(ctx, Strategy_StartStrategy_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *strategyClient) StopStrategy(ctx context.Context, in *StopStrategyRequest, opts ...grpc.CallOption) (*StrategyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StrategyResponse)
+	err := c.cc.Invoke(ctx, Strategy_StopStrategy_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *strategyClient) RunBacktest(ctx context.Context, in *RunBacktestRequest, opts ...grpc.CallOption) (*BacktestResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BacktestResult)
+	err := c.cc.Invoke(ctx, Strategy_RunBacktest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *strategyClient) StreamSignals(ctx context.Context, in *StreamSignalsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Signal], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Strategy_ServiceDesc.Streams[0], Strategy_StreamSignals_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamSignalsRequest, Signal]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Strategy_StreamSignalsClient = grpc.ServerStreamingClient[Signal]
+
+// StrategyServer is the server API for Strategy service.
+// All implementations must embed UnimplementedStrategyServer
+// for forward compatibility.
+//
+// Strategy service manages trading strategies and backtesting.
+type StrategyServer interface {
+	// List registered strategies.
+	ListStrategies(context.Context, *ListStrategiesRequest) (*ListStrategiesResponse, error)
+	// Get strategy status and performance.
+	GetStrategy(context.Context, *GetStrategyRequest) (*StrategyInfo, error)
+	// Start a strategy.
+	StartStrategy(context.Context, *StartStrategyRequest) (*StrategyResponse, error)
+	// Stop a strategy.
+	StopStrategy(context.Context, *StopStrategyRequest) (*StrategyResponse, error)
+	// Run a backtest.
+	RunBacktest(context.Context, *RunBacktestRequest) (*BacktestResult, error)
+	// Stream strategy signals.
+	StreamSignals(*StreamSignalsRequest, grpc.ServerStreamingServer[Signal]) error
+	mustEmbedUnimplementedStrategyServer()
+}
+
+// UnimplementedStrategyServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedStrategyServer struct{}
+
+func (UnimplementedStrategyServer) ListStrategies(context.Context, *ListStrategiesRequest) (*ListStrategiesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListStrategies not implemented")
+}
+func (UnimplementedStrategyServer) GetStrategy(context.Context, *GetStrategyRequest) (*StrategyInfo, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetStrategy not implemented")
+}
+func (UnimplementedStrategyServer) StartStrategy(context.Context, *StartStrategyRequest) (*StrategyResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method StartStrategy not implemented")
+}
+func (UnimplementedStrategyServer) StopStrategy(context.Context, *StopStrategyRequest) (*StrategyResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method StopStrategy not implemented")
+}
+func (UnimplementedStrategyServer) RunBacktest(context.Context, *RunBacktestRequest) (*BacktestResult, error) {
+	return nil, status.Error(codes.Unimplemented, "method RunBacktest not implemented")
+}
+func (UnimplementedStrategyServer) StreamSignals(*StreamSignalsRequest, grpc.ServerStreamingServer[Signal]) error {
+	return status.Error(codes.Unimplemented, "method StreamSignals not implemented")
+}
+func (UnimplementedStrategyServer) mustEmbedUnimplementedStrategyServer() {}
+func (UnimplementedStrategyServer) testEmbeddedByValue()                  {}
+
+// UnsafeStrategyServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to StrategyServer will
+// result in compilation errors.
+type UnsafeStrategyServer interface {
+	mustEmbedUnimplementedStrategyServer()
+}
+
+func RegisterStrategyServer(s grpc.ServiceRegistrar, srv StrategyServer) {
+	// If the following call panics, it indicates UnimplementedStrategyServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Strategy_ServiceDesc, srv)
+}
+
+func _Strategy_ListStrategies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListStrategiesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StrategyServer).ListStrategies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Strategy_ListStrategies_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StrategyServer).ListStrategies(ctx, req.(*ListStrategiesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Strategy_GetStrategy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStrategyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StrategyServer).GetStrategy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Strategy_GetStrategy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StrategyServer).GetStrategy(ctx, req.(*GetStrategyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Strategy_StartStrategy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartStrategyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StrategyServer).StartStrategy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Strategy_StartStrategy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StrategyServer).StartStrategy(ctx, req.(*StartStrategyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Strategy_StopStrategy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StopStrategyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StrategyServer).StopStrategy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Strategy_StopStrategy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StrategyServer).StopStrategy(ctx, req.(*StopStrategyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Strategy_RunBacktest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunBacktestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StrategyServer).RunBacktest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Strategy_RunBacktest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StrategyServer).RunBacktest(ctx, req.(*RunBacktestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Strategy_StreamSignals_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamSignalsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StrategyServer).StreamSignals(m, &grpc.GenericServerStream[StreamSignalsRequest, Signal]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Strategy_StreamSignalsServer = grpc.ServerStreamingServer[Signal]
+
+// Strategy_ServiceDesc is the grpc.ServiceDesc for Strategy service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Strategy_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "jupitor.strategy.Strategy",
+	HandlerType: (*StrategyServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListStrategies",
+			Handler:    _Strategy_ListStrategies_Handler,
+		},
+		{
+			MethodName: "GetStrategy",
+			Handler:    _Strategy_GetStrategy_Handler,
+		},
+		{
+			MethodName: "StartStrategy",
+			Handler:    _Strategy_StartStrategy_Handler,
+		},
+		{
+			MethodName: "StopStrategy",
+			Handler:    _Strategy_StopStrategy_Handler,
+		},
+		{
+			MethodName: "RunBacktest",
+			Handler:    _Strategy_RunBacktest_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamSignals",
+			Handler:       _Strategy_StreamSignals_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "strategy.proto",
+}
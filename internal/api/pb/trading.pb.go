@@ -0,0 +1,973 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.33.4
+// source: trading.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type OrderSide int32
+
+const (
+	OrderSide_ORDER_SIDE_UNSPECIFIED OrderSide = 0
+	OrderSide_ORDER_SIDE_BUY         OrderSide = 1
+	OrderSide_ORDER_SIDE_SELL        OrderSide = 2
+)
+
+// Enum value maps for OrderSide.
+var (
+	OrderSide_name = map[int32]string{
+		0: "ORDER_SIDE_UNSPECIFIED",
+		1: "ORDER_SIDE_BUY",
+		2: "ORDER_SIDE_SELL",
+	}
+	OrderSide_value = map[string]int32{
+		"ORDER_SIDE_UNSPECIFIED": 0,
+		"ORDER_SIDE_BUY":         1,
+		"ORDER_SIDE_SELL":        2,
+	}
+)
+
+func (x OrderSide) Enum() *OrderSide {
+	p := new(OrderSide)
+	*p = x
+	return p
+}
+
+func (x OrderSide) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (OrderSide) Descriptor() protoreflect.EnumDescriptor {
+	return file_trading_proto_enumTypes[0].Descriptor()
+}
+
+func (OrderSide) Type() protoreflect.EnumType {
+	return &file_trading_proto_enumTypes[0]
+}
+
+func (x OrderSide) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use OrderSide.Descriptor instead.
+func (OrderSide) EnumDescriptor() ([]byte, []int) {
+	return file_trading_proto_rawDescGZIP(), []int{0}
+}
+
+type OrderType int32
+
+const (
+	OrderType_ORDER_TYPE_UNSPECIFIED OrderType = 0
+	OrderType_ORDER_TYPE_MARKET      OrderType = 1
+	OrderType_ORDER_TYPE_LIMIT       OrderType = 2
+	OrderType_ORDER_TYPE_STOP        OrderType = 3
+	OrderType_ORDER_TYPE_STOP_LIMIT  OrderType = 4
+)
+
+// Enum value maps for OrderType.
+var (
+	OrderType_name = map[int32]string{
+		0: "ORDER_TYPE_UNSPECIFIED",
+		1: "ORDER_TYPE_MARKET",
+		2: "ORDER_TYPE_LIMIT",
+		3: "ORDER_TYPE_STOP",
+		4: "ORDER_TYPE_STOP_LIMIT",
+	}
+	OrderType_value = map[string]int32{
+		"ORDER_TYPE_UNSPECIFIED": 0,
+		"ORDER_TYPE_MARKET":      1,
+		"ORDER_TYPE_LIMIT":       2,
+		"ORDER_TYPE_STOP":        3,
+		"ORDER_TYPE_STOP_LIMIT":  4,
+	}
+)
+
+func (x OrderType) Enum() *OrderType {
+	p := new(OrderType)
+	*p = x
+	return p
+}
+
+func (x OrderType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (OrderType) Descriptor() protoreflect.EnumDescriptor {
+	return file_trading_proto_enumTypes[1].Descriptor()
+}
+
+func (OrderType) Type() protoreflect.EnumType {
+	return &file_trading_proto_enumTypes[1]
+}
+
+func (x OrderType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use OrderType.Descriptor instead.
+func (OrderType) EnumDescriptor() ([]byte, []int) {
+	return file_trading_proto_rawDescGZIP(), []int{1}
+}
+
+type TimeInForce int32
+
+const (
+	TimeInForce_TIME_IN_FORCE_UNSPECIFIED TimeInForce = 0
+	TimeInForce_TIME_IN_FORCE_DAY         TimeInForce = 1
+	TimeInForce_TIME_IN_FORCE_GTC         TimeInForce = 2
+	TimeInForce_TIME_IN_FORCE_IOC         TimeInForce = 3
+	TimeInForce_TIME_IN_FORCE_FOK         TimeInForce = 4
+)
+
+// Enum value maps for TimeInForce.
+var (
+	TimeInForce_name = map[int32]string{
+		0: "TIME_IN_FORCE_UNSPECIFIED",
+		1: "TIME_IN_FORCE_DAY",
+		2: "TIME_IN_FORCE_GTC",
+		3: "TIME_IN_FORCE_IOC",
+		4: "TIME_IN_FORCE_FOK",
+	}
+	TimeInForce_value = map[string]int32{
+		"TIME_IN_FORCE_UNSPECIFIED": 0,
+		"TIME_IN_FORCE_DAY":         1,
+		"TIME_IN_FORCE_GTC":         2,
+		"TIME_IN_FORCE_IOC":         3,
+		"TIME_IN_FORCE_FOK":         4,
+	}
+)
+
+func (x TimeInForce) Enum() *TimeInForce {
+	p := new(TimeInForce)
+	*p = x
+	return p
+}
+
+func (x TimeInForce) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TimeInForce) Descriptor() protoreflect.EnumDescriptor {
+	return file_trading_proto_enumTypes[2].Descriptor()
+}
+
+func (TimeInForce) Type() protoreflect.EnumType {
+	return &file_trading_proto_enumTypes[2]
+}
+
+func (x TimeInForce) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TimeInForce.Descriptor instead.
+func (TimeInForce) EnumDescriptor() ([]byte, []int) {
+	return file_trading_proto_rawDescGZIP(), []int{2}
+}
+
+type SubmitOrderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Symbol        string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Side          OrderSide              `protobuf:"varint,2,opt,name=side,proto3,enum=jupitor.trading.OrderSide" json:"side,omitempty"`
+	Type          OrderType              `protobuf:"varint,3,opt,name=type,proto3,enum=jupitor.trading.OrderType" json:"type,omitempty"`
+	TimeInForce   TimeInForce            `protobuf:"varint,4,opt,name=time_in_force,json=timeInForce,proto3,enum=jupitor.trading.TimeInForce" json:"time_in_force,omitempty"`
+	Qty           float64                `protobuf:"fixed64,5,opt,name=qty,proto3" json:"qty,omitempty"`
+	LimitPrice    float64                `protobuf:"fixed64,6,opt,name=limit_price,json=limitPrice,proto3" json:"limit_price,omitempty"` // Required for limit orders
+	StopPrice     float64                `protobuf:"fixed64,7,opt,name=stop_price,json=stopPrice,proto3" json:"stop_price,omitempty"`    // Required for stop orders
+	StrategyId    string                 `protobuf:"bytes,8,opt,name=strategy_id,json=strategyId,proto3" json:"strategy_id,omitempty"`   // Originating strategy
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitOrderRequest) Reset() {
+	*x = SubmitOrderRequest{}
+	mi := &file_trading_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitOrderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitOrderRequest) ProtoMessage() {}
+
+func (x *SubmitOrderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trading_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitOrderRequest.ProtoReflect.Descriptor instead.
+func (*SubmitOrderRequest) Descriptor() ([]byte, []int) {
+	return file_trading_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SubmitOrderRequest) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *SubmitOrderRequest) GetSide() OrderSide {
+	if x != nil {
+		return x.Side
+	}
+	return OrderSide_ORDER_SIDE_UNSPECIFIED
+}
+
+func (x *SubmitOrderRequest) GetType() OrderType {
+	if x != nil {
+		return x.Type
+	}
+	return OrderType_ORDER_TYPE_UNSPECIFIED
+}
+
+func (x *SubmitOrderRequest) GetTimeInForce() TimeInForce {
+	if x != nil {
+		return x.TimeInForce
+	}
+	return TimeInForce_TIME_IN_FORCE_UNSPECIFIED
+}
+
+func (x *SubmitOrderRequest) GetQty() float64 {
+	if x != nil {
+		return x.Qty
+	}
+	return 0
+}
+
+func (x *SubmitOrderRequest) GetLimitPrice() float64 {
+	if x != nil {
+		return x.LimitPrice
+	}
+	return 0
+}
+
+func (x *SubmitOrderRequest) GetStopPrice() float64 {
+	if x != nil {
+		return x.StopPrice
+	}
+	return 0
+}
+
+func (x *SubmitOrderRequest) GetStrategyId() string {
+	if x != nil {
+		return x.StrategyId
+	}
+	return ""
+}
+
+type CancelOrderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       string                 `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelOrderRequest) Reset() {
+	*x = CancelOrderRequest{}
+	mi := &file_trading_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelOrderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelOrderRequest) ProtoMessage() {}
+
+func (x *CancelOrderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trading_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelOrderRequest.ProtoReflect.Descriptor instead.
+func (*CancelOrderRequest) Descriptor() ([]byte, []int) {
+	return file_trading_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CancelOrderRequest) GetOrderId() string {
+	if x != nil {
+		return x.OrderId
+	}
+	return ""
+}
+
+type OrderResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       string                 `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderResponse) Reset() {
+	*x = OrderResponse{}
+	mi := &file_trading_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderResponse) ProtoMessage() {}
+
+func (x *OrderResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trading_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderResponse.ProtoReflect.Descriptor instead.
+func (*OrderResponse) Descriptor() ([]byte, []int) {
+	return file_trading_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *OrderResponse) GetOrderId() string {
+	if x != nil {
+		return x.OrderId
+	}
+	return ""
+}
+
+func (x *OrderResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *OrderResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type StreamOrdersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamOrdersRequest) Reset() {
+	*x = StreamOrdersRequest{}
+	mi := &file_trading_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamOrdersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamOrdersRequest) ProtoMessage() {}
+
+func (x *StreamOrdersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trading_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamOrdersRequest.ProtoReflect.Descriptor instead.
+func (*StreamOrdersRequest) Descriptor() ([]byte, []int) {
+	return file_trading_proto_rawDescGZIP(), []int{3}
+}
+
+type OrderUpdate struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	OrderId        string                 `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Symbol         string                 `protobuf:"bytes,2,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Side           OrderSide              `protobuf:"varint,3,opt,name=side,proto3,enum=jupitor.trading.OrderSide" json:"side,omitempty"`
+	Type           OrderType              `protobuf:"varint,4,opt,name=type,proto3,enum=jupitor.trading.OrderType" json:"type,omitempty"`
+	Status         string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	FilledQty      float64                `protobuf:"fixed64,6,opt,name=filled_qty,json=filledQty,proto3" json:"filled_qty,omitempty"`
+	FilledAvgPrice float64                `protobuf:"fixed64,7,opt,name=filled_avg_price,json=filledAvgPrice,proto3" json:"filled_avg_price,omitempty"`
+	Timestamp      *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *OrderUpdate) Reset() {
+	*x = OrderUpdate{}
+	mi := &file_trading_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderUpdate) ProtoMessage() {}
+
+func (x *OrderUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_trading_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderUpdate.ProtoReflect.Descriptor instead.
+func (*OrderUpdate) Descriptor() ([]byte, []int) {
+	return file_trading_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *OrderUpdate) GetOrderId() string {
+	if x != nil {
+		return x.OrderId
+	}
+	return ""
+}
+
+func (x *OrderUpdate) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *OrderUpdate) GetSide() OrderSide {
+	if x != nil {
+		return x.Side
+	}
+	return OrderSide_ORDER_SIDE_UNSPECIFIED
+}
+
+func (x *OrderUpdate) GetType() OrderType {
+	if x != nil {
+		return x.Type
+	}
+	return OrderType_ORDER_TYPE_UNSPECIFIED
+}
+
+func (x *OrderUpdate) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *OrderUpdate) GetFilledQty() float64 {
+	if x != nil {
+		return x.FilledQty
+	}
+	return 0
+}
+
+func (x *OrderUpdate) GetFilledAvgPrice() float64 {
+	if x != nil {
+		return x.FilledAvgPrice
+	}
+	return 0
+}
+
+func (x *OrderUpdate) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+type GetPositionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPositionsRequest) Reset() {
+	*x = GetPositionsRequest{}
+	mi := &file_trading_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPositionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPositionsRequest) ProtoMessage() {}
+
+func (x *GetPositionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trading_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPositionsRequest.ProtoReflect.Descriptor instead.
+func (*GetPositionsRequest) Descriptor() ([]byte, []int) {
+	return file_trading_proto_rawDescGZIP(), []int{5}
+}
+
+type GetPositionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Positions     []*Position            `protobuf:"bytes,1,rep,name=positions,proto3" json:"positions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPositionsResponse) Reset() {
+	*x = GetPositionsResponse{}
+	mi := &file_trading_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPositionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPositionsResponse) ProtoMessage() {}
+
+func (x *GetPositionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trading_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPositionsResponse.ProtoReflect.Descriptor instead.
+func (*GetPositionsResponse) Descriptor() ([]byte, []int) {
+	return file_trading_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetPositionsResponse) GetPositions() []*Position {
+	if x != nil {
+		return x.Positions
+	}
+	return nil
+}
+
+type Position struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Symbol          string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Qty             float64                `protobuf:"fixed64,2,opt,name=qty,proto3" json:"qty,omitempty"`
+	AvgEntryPrice   float64                `protobuf:"fixed64,3,opt,name=avg_entry_price,json=avgEntryPrice,proto3" json:"avg_entry_price,omitempty"`
+	MarketValue     float64                `protobuf:"fixed64,4,opt,name=market_value,json=marketValue,proto3" json:"market_value,omitempty"`
+	UnrealizedPl    float64                `protobuf:"fixed64,5,opt,name=unrealized_pl,json=unrealizedPl,proto3" json:"unrealized_pl,omitempty"`
+	UnrealizedPlPct float64                `protobuf:"fixed64,6,opt,name=unrealized_pl_pct,json=unrealizedPlPct,proto3" json:"unrealized_pl_pct,omitempty"`
+	Side            OrderSide              `protobuf:"varint,7,opt,name=side,proto3,enum=jupitor.trading.OrderSide" json:"side,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Position) Reset() {
+	*x = Position{}
+	mi := &file_trading_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Position) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Position) ProtoMessage() {}
+
+func (x *Position) ProtoReflect() protoreflect.Message {
+	mi := &file_trading_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Position.ProtoReflect.Descriptor instead.
+func (*Position) Descriptor() ([]byte, []int) {
+	return file_trading_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Position) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *Position) GetQty() float64 {
+	if x != nil {
+		return x.Qty
+	}
+	return 0
+}
+
+func (x *Position) GetAvgEntryPrice() float64 {
+	if x != nil {
+		return x.AvgEntryPrice
+	}
+	return 0
+}
+
+func (x *Position) GetMarketValue() float64 {
+	if x != nil {
+		return x.MarketValue
+	}
+	return 0
+}
+
+func (x *Position) GetUnrealizedPl() float64 {
+	if x != nil {
+		return x.UnrealizedPl
+	}
+	return 0
+}
+
+func (x *Position) GetUnrealizedPlPct() float64 {
+	if x != nil {
+		return x.UnrealizedPlPct
+	}
+	return 0
+}
+
+func (x *Position) GetSide() OrderSide {
+	if x != nil {
+		return x.Side
+	}
+	return OrderSide_ORDER_SIDE_UNSPECIFIED
+}
+
+type GetAccountRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAccountRequest) Reset() {
+	*x = GetAccountRequest{}
+	mi := &file_trading_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAccountRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAccountRequest) ProtoMessage() {}
+
+func (x *GetAccountRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trading_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAccountRequest.ProtoReflect.Descriptor instead.
+func (*GetAccountRequest) Descriptor() ([]byte, []int) {
+	return file_trading_proto_rawDescGZIP(), []int{8}
+}
+
+type AccountInfo struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Equity         float64                `protobuf:"fixed64,1,opt,name=equity,proto3" json:"equity,omitempty"`
+	Cash           float64                `protobuf:"fixed64,2,opt,name=cash,proto3" json:"cash,omitempty"`
+	BuyingPower    float64                `protobuf:"fixed64,3,opt,name=buying_power,json=buyingPower,proto3" json:"buying_power,omitempty"`
+	PortfolioValue float64                `protobuf:"fixed64,4,opt,name=portfolio_value,json=portfolioValue,proto3" json:"portfolio_value,omitempty"`
+	DailyPl        float64                `protobuf:"fixed64,5,opt,name=daily_pl,json=dailyPl,proto3" json:"daily_pl,omitempty"`
+	DailyPlPct     float64                `protobuf:"fixed64,6,opt,name=daily_pl_pct,json=dailyPlPct,proto3" json:"daily_pl_pct,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *AccountInfo) Reset() {
+	*x = AccountInfo{}
+	mi := &file_trading_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AccountInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccountInfo) ProtoMessage() {}
+
+func (x *AccountInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_trading_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccountInfo.ProtoReflect.Descriptor instead.
+func (*AccountInfo) Descriptor() ([]byte, []int) {
+	return file_trading_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *AccountInfo) GetEquity() float64 {
+	if x != nil {
+		return x.Equity
+	}
+	return 0
+}
+
+func (x *AccountInfo) GetCash() float64 {
+	if x != nil {
+		return x.Cash
+	}
+	return 0
+}
+
+func (x *AccountInfo) GetBuyingPower() float64 {
+	if x != nil {
+		return x.BuyingPower
+	}
+	return 0
+}
+
+func (x *AccountInfo) GetPortfolioValue() float64 {
+	if x != nil {
+		return x.PortfolioValue
+	}
+	return 0
+}
+
+func (x *AccountInfo) GetDailyPl() float64 {
+	if x != nil {
+		return x.DailyPl
+	}
+	return 0
+}
+
+func (x *AccountInfo) GetDailyPlPct() float64 {
+	if x != nil {
+		return x.DailyPlPct
+	}
+	return 0
+}
+
+var File_trading_proto protoreflect.FileDescriptor
+
+const file_trading_proto_rawDesc = "" +
+	"\n" +
+	"\rtrading.proto\x12\x0fjupitor.trading\x1a\x1fgoogle/protobuf/timestamp.proto\"\xc1\x02\n" +
+	"\x12SubmitOrderRequest\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\x12.\n" +
+	"\x04side\x18\x02 \x01(\x0e2\x1a.jupitor.trading.OrderSideR\x04side\x12.\n" +
+	"\x04type\x18\x03 \x01(\x0e2\x1a.jupitor.trading.OrderTypeR\x04type\x12@\n" +
+	"\rtime_in_force\x18\x04 \x01(\x0e2\x1c.jupitor.trading.TimeInForceR\vtimeInForce\x12\x10\n" +
+	"\x03qty\x18\x05 \x01(\x01R\x03qty\x12\x1f\n" +
+	"\vlimit_price\x18\x06 \x01(\x01R\n" +
+	"limitPrice\x12\x1d\n" +
+	"\n" +
+	"stop_price\x18\a \x01(\x01R\tstopPrice\x12\x1f\n" +
+	"\vstrategy_id\x18\b \x01(\tR\n" +
+	"strategyId\"/\n" +
+	"\x12CancelOrderRequest\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\tR\aorderId\"\\\n" +
+	"\rOrderResponse\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\tR\aorderId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\"\x15\n" +
+	"\x13StreamOrdersRequest\"\xbb\x02\n" +
+	"\vOrderUpdate\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\tR\aorderId\x12\x16\n" +
+	"\x06symbol\x18\x02 \x01(\tR\x06symbol\x12.\n" +
+	"\x04side\x18\x03 \x01(\x0e2\x1a.jupitor.trading.OrderSideR\x04side\x12.\n" +
+	"\x04type\x18\x04 \x01(\x0e2\x1a.jupitor.trading.OrderTypeR\x04type\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12\x1d\n" +
+	"\n" +
+	"filled_qty\x18\x06 \x01(\x01R\tfilledQty\x12(\n" +
+	"\x10filled_avg_price\x18\a \x01(\x01R\x0efilledAvgPrice\x128\n" +
+	"\ttimestamp\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"\x15\n" +
+	"\x13GetPositionsRequest\"O\n" +
+	"\x14GetPositionsResponse\x127\n" +
+	"\tpositions\x18\x01 \x03(\v2\x19.jupitor.trading.PositionR\tpositions\"\x80\x02\n" +
+	"\bPosition\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\x12\x10\n" +
+	"\x03qty\x18\x02 \x01(\x01R\x03qty\x12&\n" +
+	"\x0favg_entry_price\x18\x03 \x01(\x01R\ravgEntryPrice\x12!\n" +
+	"\fmarket_value\x18\x04 \x01(\x01R\vmarketValue\x12#\n" +
+	"\runrealized_pl\x18\x05 \x01(\x01R\funrealizedPl\x12*\n" +
+	"\x11unrealized_pl_pct\x18\x06 \x01(\x01R\x0funrealizedPlPct\x12.\n" +
+	"\x04side\x18\a \x01(\x0e2\x1a.jupitor.trading.OrderSideR\x04side\"\x13\n" +
+	"\x11GetAccountRequest\"\xc2\x01\n" +
+	"\vAccountInfo\x12\x16\n" +
+	"\x06equity\x18\x01 \x01(\x01R\x06equity\x12\x12\n" +
+	"\x04cash\x18\x02 \x01(\x01R\x04cash\x12!\n" +
+	"\fbuying_power\x18\x03 \x01(\x01R\vbuyingPower\x12'\n" +
+	"\x0fportfolio_value\x18\x04 \x01(\x01R\x0eportfolioValue\x12\x19\n" +
+	"\bdaily_pl\x18\x05 \x01(\x01R\adailyPl\x12 \n" +
+	"\fdaily_pl_pct\x18\x06 \x01(\x01R\n" +
+	"dailyPlPct*P\n" +
+	"\tOrderSide\x12\x1a\n" +
+	"\x16ORDER_SIDE_UNSPECIFIED\x10\x00\x12\x12\n" +
+	"\x0eORDER_SIDE_BUY\x10\x01\x12\x13\n" +
+	"\x0fORDER_SIDE_SELL\x10\x02*\x84\x01\n" +
+	"\tOrderType\x12\x1a\n" +
+	"\x16ORDER_TYPE_UNSPECIFIED\x10\x00\x12\x15\n" +
+	"\x11ORDER_TYPE_MARKET\x10\x01\x12\x14\n" +
+	"\x10ORDER_TYPE_LIMIT\x10\x02\x12\x13\n" +
+	"\x0fORDER_TYPE_STOP\x10\x03\x12\x19\n" +
+	"\x15ORDER_TYPE_STOP_LIMIT\x10\x04*\x88\x01\n" +
+	"\vTimeInForce\x12\x1d\n" +
+	"\x19TIME_IN_FORCE_UNSPECIFIED\x10\x00\x12\x15\n" +
+	"\x11TIME_IN_FORCE_DAY\x10\x01\x12\x15\n" +
+	"\x11TIME_IN_FORCE_GTC\x10\x02\x12\x15\n" +
+	"\x11TIME_IN_FORCE_IOC\x10\x03\x12\x15\n" +
+	"\x11TIME_IN_FORCE_FOK\x10\x042\xb4\x03\n" +
+	"\aTrading\x12R\n" +
+	"\vSubmitOrder\x12#.jupitor.trading.SubmitOrderRequest\x1a\x1e.jupitor.trading.OrderResponse\x12R\n" +
+	"\vCancelOrder\x12#.jupitor.trading.CancelOrderRequest\x1a\x1e.jupitor.trading.OrderResponse\x12[\n" +
+	"\fGetPositions\x12$.jupitor.trading.GetPositionsRequest\x1a%.jupitor.trading.GetPositionsResponse\x12T\n" +
+	"\fStreamOrders\x12$.jupitor.trading.StreamOrdersRequest\x1a\x1c.jupitor.trading.OrderUpdate0\x01\x12N\n" +
+	"\n" +
+	"GetAccount\x12\".jupitor.trading.GetAccountRequest\x1a\x1c.jupitor.trading.AccountInfoB\x19Z\x17jupitor/internal/api/pbb\x06proto3"
+
+var (
+	file_trading_proto_rawDescOnce sync.Once
+	file_trading_proto_rawDescData []byte
+)
+
+func file_trading_proto_rawDescGZIP() []byte {
+	file_trading_proto_rawDescOnce.Do(func() {
+		file_trading_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_trading_proto_rawDesc), len(file_trading_proto_rawDesc)))
+	})
+	return file_trading_proto_rawDescData
+}
+
+var file_trading_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_trading_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_trading_proto_goTypes = []any{
+	(OrderSide)(0),                // 0: jupitor.trading.OrderSide
+	(OrderType)(0),                // 1: jupitor.trading.OrderType
+	(TimeInForce)(0),              // 2: jupitor.trading.TimeInForce
+	(*SubmitOrderRequest)(nil),    // 3: jupitor.trading.SubmitOrderRequest
+	(*CancelOrderRequest)(nil),    // 4: jupitor.trading.CancelOrderRequest
+	(*OrderResponse)(nil),         // 5: jupitor.trading.OrderResponse
+	(*StreamOrdersRequest)(nil),   // 6: jupitor.trading.StreamOrdersRequest
+	(*OrderUpdate)(nil),           // 7: jupitor.trading.OrderUpdate
+	(*GetPositionsRequest)(nil),   // 8: jupitor.trading.GetPositionsRequest
+	(*GetPositionsResponse)(nil),  // 9: jupitor.trading.GetPositionsResponse
+	(*Position)(nil),              // 10: jupitor.trading.Position
+	(*GetAccountRequest)(nil),     // 11: jupitor.trading.GetAccountRequest
+	(*AccountInfo)(nil),           // 12: jupitor.trading.AccountInfo
+	(*timestamppb.Timestamp)(nil), // 13: google.protobuf.Timestamp
+}
+var file_trading_proto_depIdxs = []int32{
+	0,  // 0: jupitor.trading.SubmitOrderRequest.side:type_name -> jupitor.trading.OrderSide
+	1,  // 1: jupitor.trading.SubmitOrderRequest.type:type_name -> jupitor.trading.OrderType
+	2,  // 2: jupitor.trading.SubmitOrderRequest.time_in_force:type_name -> jupitor.trading.TimeInForce
+	0,  // 3: jupitor.trading.OrderUpdate.side:type_name -> jupitor.trading.OrderSide
+	1,  // 4: jupitor.trading.OrderUpdate.type:type_name -> jupitor.trading.OrderType
+	13, // 5: jupitor.trading.OrderUpdate.timestamp:type_name -> google.protobuf.Timestamp
+	10, // 6: jupitor.trading.GetPositionsResponse.positions:type_name -> jupitor.trading.Position
+	0,  // 7: jupitor.trading.Position.side:type_name -> jupitor.trading.OrderSide
+	3,  // 8: jupitor.trading.Trading.SubmitOrder:input_type -> jupitor.trading.SubmitOrderRequest
+	4,  // 9: jupitor.trading.Trading.CancelOrder:input_type -> jupitor.trading.CancelOrderRequest
+	8,  // 10: jupitor.trading.Trading.GetPositions:input_type -> jupitor.trading.GetPositionsRequest
+	6,  // 11: jupitor.trading.Trading.StreamOrders:input_type -> jupitor.trading.StreamOrdersRequest
+	11, // 12: jupitor.trading.Trading.GetAccount:input_type -> jupitor.trading.GetAccountRequest
+	5,  // 13: jupitor.trading.Trading.SubmitOrder:output_type -> jupitor.trading.OrderResponse
+	5,  // 14: jupitor.trading.Trading.CancelOrder:output_type -> jupitor.trading.OrderResponse
+	9,  // 15: jupitor.trading.Trading.GetPositions:output_type -> jupitor.trading.GetPositionsResponse
+	7,  // 16: jupitor.trading.Trading.StreamOrders:output_type -> jupitor.trading.OrderUpdate
+	12, // 17: jupitor.trading.Trading.GetAccount:output_type -> jupitor.trading.AccountInfo
+	13, // [13:18] is the sub-list for method output_type
+	8,  // [8:13] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_trading_proto_init() }
+func file_trading_proto_init() {
+	if File_trading_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_trading_proto_rawDesc), len(file_trading_proto_rawDesc)),
+			NumEnums:      3,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_trading_proto_goTypes,
+		DependencyIndexes: file_trading_proto_depIdxs,
+		EnumInfos:         file_trading_proto_enumTypes,
+		MessageInfos:      file_trading_proto_msgTypes,
+	}.Build()
+	File_trading_proto = out.File
+	file_trading_proto_goTypes = nil
+	file_trading_proto_depIdxs = nil
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+
+	"jupitor/internal/domain"
+	"jupitor/internal/store"
+)
+
+// AlpacaQuoteSource is a QuoteSource backed by Alpaca's latest-quote REST
+// endpoint.
+type AlpacaQuoteSource struct {
+	client *marketdata.Client
+	feed   marketdata.Feed
+}
+
+// NewAlpacaQuoteSource creates an AlpacaQuoteSource with the given
+// credentials. feed selects the data feed ("iex" or "sip"); empty uses the
+// account default.
+func NewAlpacaQuoteSource(apiKey, apiSecret, feed string) *AlpacaQuoteSource {
+	return &AlpacaQuoteSource{
+		client: marketdata.NewClient(marketdata.ClientOpts{
+			APIKey:    apiKey,
+			APISecret: apiSecret,
+		}),
+		feed: marketdata.Feed(feed),
+	}
+}
+
+// LatestQuote fetches the latest quote for symbol.
+func (a *AlpacaQuoteSource) LatestQuote(_ context.Context, symbol string) (*domain.Quote, error) {
+	q, err := a.client.GetLatestQuote(symbol, marketdata.GetLatestQuoteRequest{Feed: a.feed})
+	if err != nil {
+		return nil, fmt.Errorf("fetching quote %s: %w", symbol, err)
+	}
+	if q == nil { // Alpaca omits symbols it has no quote for
+		return nil, fmt.Errorf("quote %s: %w", symbol, store.ErrNotFound)
+	}
+	return &domain.Quote{
+		Symbol:    symbol,
+		Timestamp: q.Timestamp,
+		BidPrice:  q.BidPrice,
+		AskPrice:  q.AskPrice,
+		BidSize:   int64(q.BidSize),
+		AskSize:   int64(q.AskSize),
+	}, nil
+}
+
+// QuoteSources is a QuoteSource that asks each source in turn, moving on to
+// the next only when a source has no quote for the symbol. us-stream puts the
+// streamed quote book ahead of the REST source.
+type QuoteSources []QuoteSource
+
+// LatestQuote returns the first quote found for symbol.
+func (qs QuoteSources) LatestQuote(ctx context.Context, symbol string) (*domain.Quote, error) {
+	err := fmt.Errorf("quote %s: %w", symbol, store.ErrNotFound)
+	for _, src := range qs {
+		var q *domain.Quote
+		if q, err = src.LatestQuote(ctx, symbol); !errors.Is(err, store.ErrNotFound) {
+			return q, err
+		}
+	}
+	return nil, err
+}
@@ -4,36 +4,151 @@ package api
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+
+	"google.golang.org/grpc"
 
 	"jupitor/internal/config"
 )
 
-// Server is the main API server that hosts HTTP and gRPC endpoints.
+// Server is the main API server that hosts HTTP and gRPC endpoints. REST
+// handlers are mounted under /api/v1 and the WebSocket hub under /ws on the
+// HTTP listener; the gRPC services that have been set are registered on the
+// gRPC listener.
 type Server struct {
 	cfg      *config.Config
 	httpAddr string
 	grpcAddr string
+	hub      *Hub
+	handlers *Handlers
+
+	marketData *MarketDataService
+	trading    *TradingService
+	strategies *StrategyService
+
+	mu         sync.Mutex
+	httpServer *http.Server
+	grpcServer *grpc.Server
 }
 
 // NewServer creates a new Server configured from the given Config.
 func NewServer(cfg *config.Config) *Server {
 	return &Server{
-		cfg: cfg,
+		cfg:      cfg,
+		httpAddr: fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port),
+		grpcAddr: fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.GRPCPort),
+		hub:      NewHub(),
+		handlers: NewHandlers(nil, nil, nil, nil),
 	}
 }
 
+// SetHandlers sets the REST handlers mounted under /api/v1.
+func (s *Server) SetHandlers(h *Handlers) { s.handlers = h }
+
+// SetMarketDataService sets the MarketData service registered on the gRPC listener.
+func (s *Server) SetMarketDataService(svc *MarketDataService) { s.marketData = svc }
+
+// SetTradingService sets the Trading service registered on the gRPC listener.
+func (s *Server) SetTradingService(svc *TradingService) { s.trading = svc }
+
+// SetStrategyService sets the Strategy service registered on the gRPC listener.
+func (s *Server) SetStrategyService(svc *StrategyService) { s.strategies = svc }
+
+// Hub returns the WebSocket hub served under /ws, for wiring feeds.
+func (s *Server) Hub() *Hub { return s.hub }
+
+// Handler returns the HTTP handler serving the REST API and WebSocket
+// endpoint, wrapped in logging and CORS middleware.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	s.handlers.Register(mux)
+	mux.Handle("GET /ws", s.hub)
+	return LoggingMiddleware(CORSMiddleware(mux))
+}
+
 // ListenAndServe starts the HTTP and gRPC listeners and blocks until the
-// context is cancelled or a fatal error occurs.
-func (s *Server) ListenAndServe(_ context.Context) error {
-	// TODO: configure HTTP router with handlers and middleware
-	// TODO: start HTTP listener on s.httpAddr
-	// TODO: start gRPC listener on s.grpcAddr
-	return nil
+// context is cancelled or a fatal error occurs. It does not stop the
+// listeners; call Shutdown for that.
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	httpLis, err := net.Listen("tcp", s.httpAddr)
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", s.httpAddr, err)
+	}
+	grpcLis, err := net.Listen("tcp", s.grpcAddr)
+	if err != nil {
+		httpLis.Close()
+		return fmt.Errorf("listening on %s: %w", s.grpcAddr, err)
+	}
+
+	gs := grpc.NewServer()
+	if s.marketData != nil {
+		s.marketData.RegisterGRPC(gs)
+	}
+	if s.trading != nil {
+		s.trading.RegisterGRPC(gs)
+	}
+	if s.strategies != nil {
+		s.strategies.RegisterGRPC(gs)
+	}
+	hs := &http.Server{Handler: s.Handler()}
+
+	s.mu.Lock()
+	s.httpServer, s.grpcServer = hs, gs
+	s.mu.Unlock()
+
+	errCh := make(chan error, 2)
+	go func() {
+		slog.Info("HTTP API server listening", "addr", httpLis.Addr())
+		if err := hs.Serve(httpLis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- fmt.Errorf("http server: %w", err)
+		}
+	}()
+	go func() {
+		slog.Info("gRPC server listening", "addr", grpcLis.Addr())
+		if err := gs.Serve(grpcLis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			errCh <- fmt.Errorf("grpc server: %w", err)
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-errCh:
+		return err
+	}
 }
 
-// Shutdown performs a graceful shutdown of the HTTP and gRPC servers.
-func (s *Server) Shutdown(_ context.Context) error {
-	// TODO: signal HTTP and gRPC servers to stop accepting new connections
-	// TODO: wait for in-flight requests to complete
-	return nil
+// Shutdown performs a graceful shutdown of the HTTP and gRPC servers and
+// disconnects WebSocket clients. gRPC streams still open when ctx expires
+// are closed forcibly.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	hs, gs := s.httpServer, s.grpcServer
+	s.mu.Unlock()
+
+	// Hijacked WebSocket connections are not tracked by http.Server.
+	s.hub.Close()
+
+	var err error
+	if hs != nil {
+		err = hs.Shutdown(ctx)
+	}
+	if gs != nil {
+		stopped := make(chan struct{})
+		go func() {
+			gs.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			gs.Stop()
+		}
+	}
+	return err
 }
@@ -1,9 +1,34 @@
 package api
 
 import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "jupitor/internal/api/pb"
+	"jupitor/internal/broker"
 	"jupitor/internal/config"
+	"jupitor/internal/domain"
+	"jupitor/internal/engine"
+	"jupitor/internal/live"
+	"jupitor/internal/store"
+	"jupitor/internal/strategy"
+	"jupitor/internal/strategy/builtins"
 )
 
 func TestNewServer(t *testing.T) {
@@ -13,3 +38,653 @@ func TestNewServer(t *testing.T) {
 		t.Fatal("NewServer returned nil")
 	}
 }
+
+func TestServerHandlerRoutes(t *testing.T) {
+	h := NewServer(&config.Config{}).Handler()
+
+	tests := []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodGet, "/api/v1/bars?symbol=AAPL", http.StatusServiceUnavailable},
+		{http.MethodPost, "/api/v1/orders", http.StatusServiceUnavailable},
+		{http.MethodDelete, "/api/v1/orders/abc", http.StatusServiceUnavailable},
+		{http.MethodOptions, "/api/v1/orders", http.StatusNoContent},
+		{http.MethodGet, "/api/v1/nope", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+		if rec.Code != tt.want {
+			t.Errorf("%s %s = %d, want %d", tt.method, tt.path, rec.Code, tt.want)
+		}
+		if rec.Header().Get("Access-Control-Allow-Origin") != "*" {
+			t.Errorf("%s %s missing CORS header", tt.method, tt.path)
+		}
+	}
+}
+
+func TestServerListenAndShutdown(t *testing.T) {
+	cfg := &config.Config{Server: config.Server{Host: "127.0.0.1"}}
+	srv := NewServer(cfg)
+	srv.SetMarketDataService(NewMarketDataService(nil, nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.ListenAndServe(ctx) }()
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), time.Second)
+	defer shutdownCancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestStrategyService(t *testing.T, withRunner bool) (*StrategyService, *store.ParquetStore) {
+	t.Helper()
+	ps := store.NewParquetStore(t.TempDir())
+	reg := strategy.NewRegistry()
+	reg.RegisterFactory(func() strategy.Strategy { return builtins.NewSMACross(2, 3) })
+	var runner *strategy.Runner
+	if withRunner {
+		runner = strategy.NewRunner(reg, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	}
+	return NewStrategyService(reg, runner, strategy.NewBacktester(ps, reg), nil), ps
+}
+
+func TestStrategyServiceLifecycle(t *testing.T) {
+	svc, _ := newTestStrategyService(t, true)
+	ctx := context.Background()
+
+	list, err := svc.ListStrategies(ctx, &pb.ListStrategiesRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Strategies) != 1 || list.Strategies[0].Id != "sma-cross" ||
+		list.Strategies[0].Status != "stopped" || list.Strategies[0].Description == "" {
+		t.Fatalf("ListStrategies = %+v", list.Strategies)
+	}
+
+	if _, err := svc.GetStrategy(ctx, &pb.GetStrategyRequest{StrategyId: "nope"}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetStrategy(nope) code = %v, want NotFound", status.Code(err))
+	}
+
+	_, err = svc.StartStrategy(ctx, &pb.StartStrategyRequest{
+		StrategyId: "sma-cross", Symbols: []string{"MSFT", "AAPL"}, Params: map[string]string{"short": "3", "long": "5"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, _ := svc.GetStrategy(ctx, &pb.GetStrategyRequest{StrategyId: "sma-cross"})
+	if info.Status != "running" || len(info.Symbols) != 2 || info.Symbols[0] != "AAPL" || info.Params["long"] != "5" {
+		t.Errorf("GetStrategy = %+v", info)
+	}
+	if _, err := svc.StartStrategy(ctx, &pb.StartStrategyRequest{StrategyId: "sma-cross"}); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("second start code = %v, want AlreadyExists", status.Code(err))
+	}
+	if _, err := svc.RunBacktest(ctx, &pb.RunBacktestRequest{StrategyId: "sma-cross"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("backtest without range code = %v, want InvalidArgument", status.Code(err))
+	}
+
+	if _, err := svc.StopStrategy(ctx, &pb.StopStrategyRequest{StrategyId: "sma-cross"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.StopStrategy(ctx, &pb.StopStrategyRequest{StrategyId: "sma-cross"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("second stop code = %v, want FailedPrecondition", status.Code(err))
+	}
+}
+
+func TestStrategyServiceRunBacktest(t *testing.T) {
+	svc, ps := newTestStrategyService(t, false)
+	ctx := context.Background()
+
+	closes := []float64{10, 9, 8, 9, 11, 13, 12, 10, 8, 7}
+	bars := make([]domain.Bar, len(closes))
+	for i, c := range closes {
+		bars[i] = domain.Bar{Symbol: "AAA", Timestamp: time.Date(2024, 1, 2+i, 0, 0, 0, 0, time.UTC),
+			Open: c, High: c, Low: c, Close: c, Volume: 1000}
+	}
+	if err := ps.WriteBars(ctx, bars); err != nil {
+		t.Fatal(err)
+	}
+
+	req := &pb.RunBacktestRequest{
+		StrategyId:     "sma-cross",
+		Symbols:        []string{"AAA"},
+		Start:          timestamppb.New(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+		End:            timestamppb.New(time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)),
+		InitialCapital: 10000,
+	}
+	res, err := svc.RunBacktest(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Signals) != 2 || res.Signals[0].Type != pb.SignalType_SIGNAL_TYPE_BUY ||
+		res.Signals[1].Type != pb.SignalType_SIGNAL_TYPE_SELL || res.TotalTrades != 1 {
+		t.Errorf("RunBacktest = %+v", res)
+	}
+
+	req.Params = map[string]string{"short": "5", "long": "3"}
+	if _, err := svc.RunBacktest(ctx, req); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("invalid params code = %v, want InvalidArgument", status.Code(err))
+	}
+	// Params apply to the backtest's own instance only.
+	req.Params = nil
+	if res, err := svc.RunBacktest(ctx, req); err != nil || len(res.Signals) != 2 {
+		t.Errorf("RunBacktest after bad params = %+v, %v", res, err)
+	}
+	req.Params = map[string]string{"short": "4", "long": "6"}
+	if _, err := svc.RunBacktest(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	if shared, _ := svc.registry.Get("sma-cross"); shared.(strategy.Describer).Description() != builtins.NewSMACross(2, 3).Description() {
+		t.Errorf("backtest params leaked into the registered instance: %s", shared.(strategy.Describer).Description())
+	}
+	if _, err := svc.StartStrategy(ctx, &pb.StartStrategyRequest{StrategyId: "sma-cross"}); status.Code(err) != codes.Unavailable {
+		t.Errorf("start without runner code = %v, want Unavailable", status.Code(err))
+	}
+}
+
+func newTestTradingService(t *testing.T) (*TradingService, *engine.Engine, *broker.SimulatorBroker) {
+	t.Helper()
+	db, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "trading.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sim := broker.NewSimulatorBroker()
+	sim.SetCash(100000)
+	sim.UpdatePrice("AAPL", 100, time.Date(2025, 3, 10, 14, 30, 0, 0, time.UTC))
+	eng := engine.NewEngine(sim, db, db, engine.NewRiskManager(0.10, 0.02))
+	return NewTradingService(eng), eng, sim
+}
+
+// orderStream is a minimal server stream that forwards sent updates to a
+// channel.
+type orderStream struct {
+	grpc.ServerStream
+	ctx context.Context
+	out chan *pb.OrderUpdate
+}
+
+func (s *orderStream) Context() context.Context { return s.ctx }
+
+func (s *orderStream) Send(u *pb.OrderUpdate) error {
+	s.out <- u
+	return nil
+}
+
+func TestTradingServiceOrders(t *testing.T) {
+	svc, eng, sim := newTestTradingService(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := &orderStream{ctx: ctx, out: make(chan *pb.OrderUpdate, 16)}
+	done := make(chan error, 1)
+	go func() { done <- svc.StreamOrders(&pb.StreamOrdersRequest{}, stream) }()
+	for deadline := time.Now().Add(2 * time.Second); eng.OrderSubscribers() == 0; time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for StreamOrders to subscribe")
+		}
+	}
+
+	if _, err := svc.SubmitOrder(ctx, &pb.SubmitOrderRequest{Symbol: "AAPL", Qty: 10}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("SubmitOrder without side code = %v, want InvalidArgument", status.Code(err))
+	}
+	if _, err := svc.SubmitOrder(ctx, &pb.SubmitOrderRequest{
+		Symbol: "AAPL", Side: pb.OrderSide_ORDER_SIDE_BUY, Type: pb.OrderType_ORDER_TYPE_LIMIT, Qty: 10,
+	}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("limit without price code = %v, want InvalidArgument", status.Code(err))
+	}
+
+	resp, err := svc.SubmitOrder(ctx, &pb.SubmitOrderRequest{Symbol: "AAPL", Side: pb.OrderSide_ORDER_SIDE_BUY, Qty: 10})
+	if err != nil {
+		t.Fatalf("SubmitOrder: %v", err)
+	}
+	if resp.OrderId == "" || resp.Status != "submitted" {
+		t.Fatalf("SubmitOrder = %+v", resp)
+	}
+
+	// Fill at the broker; the reconciler publishes the fill to the stream.
+	sim.ProcessBar(domain.Bar{Symbol: "AAPL", Timestamp: time.Date(2025, 3, 10, 14, 31, 0, 0, time.UTC),
+		Open: 101, High: 102, Low: 100, Close: 101.5, Volume: 1000})
+	if _, err := eng.Reconcile(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	var statuses []string
+	timeout := time.After(2 * time.Second)
+	for len(statuses) < 3 {
+		select {
+		case u := <-stream.out:
+			if u.OrderId != resp.OrderId || u.Side != pb.OrderSide_ORDER_SIDE_BUY || u.Type != pb.OrderType_ORDER_TYPE_MARKET {
+				t.Errorf("update = %+v", u)
+			}
+			statuses = append(statuses, u.Status)
+			if u.Status == "filled" && (u.FilledQty != 10 || u.FilledAvgPrice != 101) {
+				t.Errorf("fill update = %+v", u)
+			}
+		case <-timeout:
+			t.Fatalf("timed out; got statuses %v", statuses)
+		}
+	}
+	if statuses[0] != "pending" || statuses[1] != "submitted" || statuses[2] != "filled" {
+		t.Errorf("statuses = %v, want [pending submitted filled]", statuses)
+	}
+
+	positions, err := svc.GetPositions(ctx, &pb.GetPositionsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(positions.Positions) != 1 || positions.Positions[0].Qty != 10 ||
+		positions.Positions[0].Side != pb.OrderSide_ORDER_SIDE_BUY {
+		t.Errorf("positions = %+v", positions.Positions)
+	}
+
+	if _, err := svc.CancelOrder(ctx, &pb.CancelOrderRequest{OrderId: resp.OrderId}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("cancel filled code = %v, want FailedPrecondition", status.Code(err))
+	}
+	if _, err := svc.CancelOrder(ctx, &pb.CancelOrderRequest{OrderId: "nope"}); status.Code(err) != codes.NotFound {
+		t.Errorf("cancel unknown code = %v, want NotFound", status.Code(err))
+	}
+
+	acct, err := svc.GetAccount(ctx, &pb.GetAccountRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acct.Equity <= 0 {
+		t.Errorf("account = %+v", acct)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("StreamOrders: %v", err)
+	}
+}
+
+func TestTradingServiceRiskRejection(t *testing.T) {
+	svc, _, _ := newTestTradingService(t)
+	ctx := context.Background()
+
+	// 200 shares at $100 is 20% of equity, over the 10% position limit.
+	_, err := svc.SubmitOrder(ctx, &pb.SubmitOrderRequest{Symbol: "AAPL", Side: pb.OrderSide_ORDER_SIDE_BUY, Qty: 200,
+		Type: pb.OrderType_ORDER_TYPE_LIMIT, LimitPrice: 100})
+	st := status.Convert(err)
+	if st.Code() != codes.FailedPrecondition {
+		t.Fatalf("code = %v, want FailedPrecondition (err %v)", st.Code(), err)
+	}
+	var info *errdetails.ErrorInfo
+	for _, d := range st.Details() {
+		if ei, ok := d.(*errdetails.ErrorInfo); ok {
+			info = ei
+		}
+	}
+	if info == nil || info.Reason != "position_limit" || info.Metadata["symbol"] != "AAPL" {
+		t.Errorf("error info = %+v", info)
+	}
+}
+
+// fakeQuotes is a QuoteSource serving fixed quotes.
+type fakeQuotes map[string]domain.Quote
+
+func (f fakeQuotes) LatestQuote(_ context.Context, symbol string) (*domain.Quote, error) {
+	q, ok := f[symbol]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &q, nil
+}
+
+// chanStream is a minimal server stream that forwards sent messages to a
+// channel.
+type chanStream[T any] struct {
+	grpc.ServerStream
+	ctx context.Context
+	out chan *T
+}
+
+func (s *chanStream[T]) Context() context.Context { return s.ctx }
+
+func (s *chanStream[T]) Send(m *T) error {
+	s.out <- m
+	return nil
+}
+
+func TestMarketDataServiceGetDailyBars(t *testing.T) {
+	ps := store.NewParquetStore(t.TempDir())
+	svc := NewMarketDataService(ps, ps)
+	ctx := context.Background()
+
+	bars := []domain.Bar{
+		{Symbol: "AAPL", Timestamp: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Open: 1, High: 2, Low: 1, Close: 2, Volume: 100},
+		{Symbol: "AAPL", Timestamp: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), Open: 2, High: 3, Low: 2, Close: 3, Volume: 200},
+	}
+	if err := ps.WriteBars(ctx, bars); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := svc.GetDailyBars(ctx, &pb.GetDailyBarsRequest{
+		Symbol: "aapl",
+		Start:  timestamppb.New(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+		End:    timestamppb.New(time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Bars) != 2 || resp.Bars[1].Close != 3 || resp.Bars[1].Volume != 200 {
+		t.Errorf("GetDailyBars = %+v", resp.Bars)
+	}
+
+	if _, err := svc.GetDailyBars(ctx, &pb.GetDailyBarsRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("missing symbol code = %v, want InvalidArgument", status.Code(err))
+	}
+	if _, err := svc.GetDailyBars(ctx, &pb.GetDailyBarsRequest{Symbol: "AAPL", Market: "jp"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("unknown market code = %v, want InvalidArgument", status.Code(err))
+	}
+}
+
+func TestMarketDataServiceGetLatestQuote(t *testing.T) {
+	svc := NewMarketDataService(nil, nil)
+	ctx := context.Background()
+
+	if _, err := svc.GetLatestQuote(ctx, &pb.GetLatestQuoteRequest{Symbol: "AAPL"}); status.Code(err) != codes.Unavailable {
+		t.Errorf("no source code = %v, want Unavailable", status.Code(err))
+	}
+
+	svc.SetQuoteSource(fakeQuotes{"AAPL": {Symbol: "AAPL", BidPrice: 189.9, AskPrice: 190.1, BidSize: 3, AskSize: 5}})
+	q, err := svc.GetLatestQuote(ctx, &pb.GetLatestQuoteRequest{Symbol: "aapl"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.BidPrice != 189.9 || q.AskPrice != 190.1 || q.AskSize != 5 {
+		t.Errorf("GetLatestQuote = %+v", q)
+	}
+	if _, err := svc.GetLatestQuote(ctx, &pb.GetLatestQuoteRequest{Symbol: "MSFT"}); status.Code(err) != codes.NotFound {
+		t.Errorf("unknown symbol code = %v, want NotFound", status.Code(err))
+	}
+
+	// The streamed book answers for subscribed symbols; others fall through.
+	book := live.NewQuoteBook()
+	book.Update(domain.Quote{Symbol: "AAPL", BidPrice: 190, AskPrice: 190.02})
+	svc.SetQuoteSource(QuoteSources{book, fakeQuotes{"AAPL": {Symbol: "AAPL", BidPrice: 1}, "MSFT": {Symbol: "MSFT", BidPrice: 410}}})
+	if q, err := svc.GetLatestQuote(ctx, &pb.GetLatestQuoteRequest{Symbol: "AAPL"}); err != nil || q.BidPrice != 190 {
+		t.Errorf("book quote = %+v, %v", q, err)
+	}
+	if q, err := svc.GetLatestQuote(ctx, &pb.GetLatestQuoteRequest{Symbol: "MSFT"}); err != nil || q.BidPrice != 410 {
+		t.Errorf("fallback quote = %+v, %v", q, err)
+	}
+	if _, err := svc.GetLatestQuote(ctx, &pb.GetLatestQuoteRequest{Symbol: "TSLA"}); status.Code(err) != codes.NotFound {
+		t.Errorf("missing everywhere code = %v, want NotFound", status.Code(err))
+	}
+}
+
+func TestMarketDataServiceStreamTrades(t *testing.T) {
+	model := live.NewLiveModel(math.MaxInt64)
+	svc := NewMarketDataService(nil, nil)
+	svc.SetLiveModel(model, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream := &chanStream[pb.Trade]{ctx: ctx, out: make(chan *pb.Trade, 16)}
+	done := make(chan error, 1)
+	go func() { done <- svc.StreamTrades(&pb.StreamTradesRequest{Symbols: []string{"aapl"}}, stream) }()
+
+	// Keep publishing until the stream has subscribed and forwards a trade.
+	var got *pb.Trade
+	for id := int64(1); got == nil; id += 2 {
+		ts := time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC).UnixMilli()
+		model.Add(store.TradeRecord{Symbol: "MSFT", Timestamp: ts, Price: 400, Size: 1, ID: "m"}, id, false)
+		model.Add(store.TradeRecord{Symbol: "AAPL", Timestamp: ts, Price: 190, Size: 7, ID: "a"}, id+1, false)
+		select {
+		case got = <-stream.out:
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	if got.Symbol != "AAPL" || got.Price != 190 || got.Size != 7 {
+		t.Errorf("streamed trade = %+v", got)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	for len(stream.out) > 0 {
+		if tr := <-stream.out; tr.Symbol != "AAPL" {
+			t.Errorf("streamed unrequested symbol %s", tr.Symbol)
+		}
+	}
+
+	if err := NewMarketDataService(nil, nil).StreamTrades(&pb.StreamTradesRequest{}, stream); status.Code(err) != codes.Unavailable {
+		t.Errorf("no live model code = %v, want Unavailable", status.Code(err))
+	}
+}
+
+func TestMarketDataServiceStreamBars(t *testing.T) {
+	model := live.NewLiveModel(math.MaxInt64)
+	svc := NewMarketDataService(nil, nil)
+	svc.SetLiveModel(model, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream := &chanStream[pb.Bar]{ctx: ctx, out: make(chan *pb.Bar, 16)}
+
+	if err := svc.StreamBars(&pb.StreamBarsRequest{Timeframe: "1Day"}, stream); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("unsupported timeframe code = %v, want InvalidArgument", status.Code(err))
+	}
+	go svc.StreamBars(&pb.StreamBarsRequest{Symbols: []string{"AAPL"}, Timeframe: "5Min"}, stream)
+
+	// Windows are an hour ahead so the wall-clock flush never fires. Probe
+	// trades in the two windows before base are republished until the stream
+	// has subscribed and emits a bar.
+	base := time.Now().Truncate(5 * time.Minute).Add(time.Hour)
+	add := func(id int64, at time.Time, price float64, size int64) {
+		model.Add(store.TradeRecord{Symbol: "AAPL", Timestamp: at.UnixMilli(), Price: price, Size: size}, id, false)
+	}
+	id := int64(0)
+	for subscribed := false; !subscribed; {
+		add(id+1, base.Add(-10*time.Minute), 1, 1)
+		add(id+2, base.Add(-5*time.Minute), 1, 1)
+		id += 2
+		select {
+		case <-stream.out:
+			subscribed = true
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	for i, p := range []float64{10, 12, 9, 11} {
+		add(id+int64(i)+1, base.Add(time.Duration(i)*time.Minute), p, 10)
+	}
+	add(id+5, base.Add(5*time.Minute), 11, 1)
+
+	var got *pb.Bar
+	for got == nil || got.Open == 1 { // skip probe bars
+		select {
+		case got = <-stream.out:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for bar")
+		}
+	}
+	if got.Symbol != "AAPL" || got.Open != 10 || got.High != 12 || got.Low != 9 ||
+		got.Close != 11 || got.Volume != 40 || got.TradeCount != 4 {
+		t.Errorf("streamed bar = %+v", got)
+	}
+}
+
+func newTestHandlers(t *testing.T) (*Handlers, *store.ParquetStore, *engine.Engine) {
+	t.Helper()
+	ps := store.NewParquetStore(t.TempDir())
+	db, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "trading.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sim := broker.NewSimulatorBroker()
+	sim.SetCash(100000)
+	sim.UpdatePrice("AAPL", 100, time.Date(2025, 3, 10, 14, 30, 0, 0, time.UTC))
+	eng := engine.NewEngine(sim, db, db, engine.NewRiskManager(0.10, 0.02))
+	return NewHandlers(ps, ps, db, eng), ps, eng
+}
+
+// doJSON serves a request through a mux with h registered and decodes the
+// JSON response into out.
+func doJSON(t *testing.T, h *Handlers, method, target, body string, out any) int {
+	t.Helper()
+	mux := http.NewServeMux()
+	h.Register(mux)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("%s %s content type = %q", method, target, ct)
+	}
+	if out != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: decoding %q: %v", method, target, rec.Body, err)
+		}
+	}
+	return rec.Code
+}
+
+func TestHandlersGetBars(t *testing.T) {
+	h, ps, _ := newTestHandlers(t)
+	bars := []domain.Bar{
+		{Symbol: "AAPL", Timestamp: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Close: 2, Volume: 100},
+		{Symbol: "AAPL", Timestamp: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), Close: 3, Volume: 200},
+		{Symbol: "AAPL", Timestamp: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), Close: 4, Volume: 300},
+	}
+	if err := ps.WriteBars(context.Background(), bars); err != nil {
+		t.Fatal(err)
+	}
+
+	var resp ListResponse[BarJSON]
+	if code := doJSON(t, h, "GET", "/api/v1/bars?symbol=aapl&start=2024-01-01&end=2024-01-31", "", &resp); code != http.StatusOK {
+		t.Fatalf("code = %d", code)
+	}
+	if len(resp.Data) != 2 || resp.Data[1].Close != 3 || resp.Data[1].Volume != 200 {
+		t.Errorf("bars = %+v", resp.Data)
+	}
+
+	for _, target := range []string{
+		"/api/v1/bars",
+		"/api/v1/bars?symbol=AAPL&start=yesterday",
+		"/api/v1/bars?symbol=AAPL&start=2024-02-01&end=2024-01-01",
+		"/api/v1/bars?symbol=AAPL&market=jp",
+	} {
+		var e ErrorJSON
+		if code := doJSON(t, h, "GET", target, "", &e); code != http.StatusBadRequest || e.Error == "" {
+			t.Errorf("GET %s = %d %+v, want 400 with error", target, code, e)
+		}
+	}
+}
+
+func TestHandlersGetTradesPagination(t *testing.T) {
+	h, ps, _ := newTestHandlers(t)
+	base := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	var trades []domain.Trade
+	for i := range 7 {
+		// Pairs of trades share a timestamp so pages split mid-timestamp.
+		trades = append(trades, domain.Trade{Symbol: "AAPL", Timestamp: base.Add(time.Duration(i/2) * time.Second),
+			Price: float64(100 + i), Size: 1, ID: strconv.Itoa(i)})
+	}
+	if err := ps.WriteTrades(context.Background(), trades); err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	target := "/api/v1/trades?symbol=AAPL&start=2024-01-02&end=2024-01-02&limit=3"
+	for pages := 0; ; pages++ {
+		if pages > 5 {
+			t.Fatal("pagination did not terminate")
+		}
+		var resp ListResponse[TradeJSON]
+		if code := doJSON(t, h, "GET", target, "", &resp); code != http.StatusOK {
+			t.Fatalf("code = %d", code)
+		}
+		for _, tr := range resp.Data {
+			ids = append(ids, tr.ID)
+		}
+		if resp.NextPageToken == "" {
+			break
+		}
+		target = "/api/v1/trades?symbol=AAPL&start=2024-01-02&end=2024-01-02&limit=3&page_token=" + resp.NextPageToken
+	}
+	if got := strings.Join(ids, ","); got != "0,1,2,3,4,5,6" {
+		t.Errorf("paged trade ids = %s", got)
+	}
+
+	if code := doJSON(t, h, "GET", "/api/v1/trades?symbol=AAPL&limit=0", "", nil); code != http.StatusBadRequest {
+		t.Errorf("limit=0 code = %d, want 400", code)
+	}
+	if code := doJSON(t, h, "GET", "/api/v1/trades?symbol=AAPL&page_token=x", "", nil); code != http.StatusBadRequest {
+		t.Errorf("bad page_token code = %d, want 400", code)
+	}
+}
+
+func TestHandlersOrders(t *testing.T) {
+	h, _, _ := newTestHandlers(t)
+
+	var created ItemResponse[OrderJSON]
+	code := doJSON(t, h, "POST", "/api/v1/orders",
+		`{"symbol":"aapl","side":"buy","type":"limit","qty":10,"limit_price":99}`, &created)
+	if code != http.StatusAccepted || created.Data.ID == "" || created.Data.Symbol != "AAPL" ||
+		created.Data.TimeInForce != "day" {
+		t.Fatalf("submit = %d %+v", code, created.Data)
+	}
+
+	var list ListResponse[OrderJSON]
+	if code := doJSON(t, h, "GET", "/api/v1/orders?status=submitted", "", &list); code != http.StatusOK || len(list.Data) != 1 {
+		t.Errorf("list submitted = %d %+v", code, list.Data)
+	}
+	if code := doJSON(t, h, "GET", "/api/v1/orders?status=bogus", "", nil); code != http.StatusBadRequest {
+		t.Errorf("bogus status code = %d, want 400", code)
+	}
+
+	var cancelled ItemResponse[OrderJSON]
+	if code := doJSON(t, h, "DELETE", "/api/v1/orders/"+created.Data.ID, "", &cancelled); code != http.StatusOK ||
+		cancelled.Data.Status != "cancelled" {
+		t.Errorf("cancel = %d %+v", code, cancelled.Data)
+	}
+	if code := doJSON(t, h, "DELETE", "/api/v1/orders/"+created.Data.ID, "", nil); code != http.StatusConflict {
+		t.Errorf("second cancel code = %d, want 409", code)
+	}
+	if code := doJSON(t, h, "GET", "/api/v1/orders/nope", "", nil); code != http.StatusNotFound {
+		t.Errorf("unknown order code = %d, want 404", code)
+	}
+
+	for _, body := range []string{
+		`{"symbol":"AAPL","side":"buy"}`,
+		`{"symbol":"AAPL","side":"hold","qty":1}`,
+		`{"symbol":"AAPL","side":"buy","qty":1,"type":"limit"}`,
+		`{"symbol":"AAPL","side":"buy","qty":1,"colour":"red"}`,
+	} {
+		if code := doJSON(t, h, "POST", "/api/v1/orders", body, nil); code != http.StatusBadRequest {
+			t.Errorf("submit %s code = %d, want 400", body, code)
+		}
+	}
+
+	// 200 shares at $100 is 20% of equity, over the 10% position limit.
+	var rejected ErrorJSON
+	code = doJSON(t, h, "POST", "/api/v1/orders", `{"symbol":"AAPL","side":"buy","type":"limit","qty":200,"limit_price":100}`, &rejected)
+	if code != http.StatusUnprocessableEntity || rejected.Rule != "position_limit" {
+		t.Errorf("risk rejection = %d %+v", code, rejected)
+	}
+}
+
+func TestHandlersAccountAndPositions(t *testing.T) {
+	h, _, _ := newTestHandlers(t)
+
+	var acct ItemResponse[AccountJSON]
+	if code := doJSON(t, h, "GET", "/api/v1/account", "", &acct); code != http.StatusOK || acct.Data.Cash != 100000 {
+		t.Errorf("account = %d %+v", code, acct.Data)
+	}
+	var positions ListResponse[PositionJSON]
+	if code := doJSON(t, h, "GET", "/api/v1/positions", "", &positions); code != http.StatusOK || positions.Data == nil {
+		t.Errorf("positions = %d %+v", code, positions)
+	}
+}
@@ -0,0 +1,300 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "jupitor/internal/api/pb"
+	"jupitor/internal/domain"
+	"jupitor/internal/store"
+	"jupitor/internal/strategy"
+)
+
+// signalPollInterval is how often StreamSignals polls the signal store when
+// no in-process runner is available.
+const signalPollInterval = time.Second
+
+// StrategyService provides gRPC endpoints for managing and monitoring
+// trading strategies. Live start/stop and signal streaming are delegated to
+// a strategy.Runner; backtests run on a strategy.Backtester. Either may be
+// nil, in which case the corresponding RPCs return Unavailable.
+type StrategyService struct {
+	pb.UnimplementedStrategyServer
+	registry    *strategy.Registry
+	runner      *strategy.Runner
+	backtester  *strategy.Backtester
+	signalStore store.SignalStore
+}
+
+// NewStrategyService creates a StrategyService over the given registry,
+// runner, backtester and signal store.
+func NewStrategyService(
+	registry *strategy.Registry,
+	runner *strategy.Runner,
+	backtester *strategy.Backtester,
+	signalStore store.SignalStore,
+) *StrategyService {
+	return &StrategyService{
+		registry:    registry,
+		runner:      runner,
+		backtester:  backtester,
+		signalStore: signalStore,
+	}
+}
+
+// RegisterGRPC registers the service on the given gRPC server instance.
+func (s *StrategyService) RegisterGRPC(gs *grpc.Server) {
+	pb.RegisterStrategyServer(gs, s)
+}
+
+// ListStrategies returns every registered strategy with its runner status.
+func (s *StrategyService) ListStrategies(_ context.Context, _ *pb.ListStrategiesRequest) (*pb.ListStrategiesResponse, error) {
+	resp := &pb.ListStrategiesResponse{}
+	for _, name := range s.registry.List() {
+		resp.Strategies = append(resp.Strategies, s.info(name))
+	}
+	return resp, nil
+}
+
+// GetStrategy returns the status of a single strategy.
+func (s *StrategyService) GetStrategy(_ context.Context, req *pb.GetStrategyRequest) (*pb.StrategyInfo, error) {
+	if _, ok := s.registry.Get(req.GetStrategyId()); !ok {
+		return nil, status.Errorf(codes.NotFound, "strategy %q not found", req.GetStrategyId())
+	}
+	return s.info(req.GetStrategyId()), nil
+}
+
+// StartStrategy starts a strategy on the live runner.
+func (s *StrategyService) StartStrategy(ctx context.Context, req *pb.StartStrategyRequest) (*pb.StrategyResponse, error) {
+	if s.runner == nil {
+		return nil, status.Error(codes.Unavailable, "live strategy runner not configured")
+	}
+	id := req.GetStrategyId()
+	if err := s.runner.Start(ctx, id, req.GetSymbols(), req.GetParams()); err != nil {
+		return nil, runnerStatusError(err)
+	}
+	return &pb.StrategyResponse{StrategyId: id, Status: strategy.StatusRunning, Message: "started"}, nil
+}
+
+// StopStrategy stops a running strategy.
+func (s *StrategyService) StopStrategy(_ context.Context, req *pb.StopStrategyRequest) (*pb.StrategyResponse, error) {
+	if s.runner == nil {
+		return nil, status.Error(codes.Unavailable, "live strategy runner not configured")
+	}
+	id := req.GetStrategyId()
+	if err := s.runner.Stop(id); err != nil {
+		return nil, runnerStatusError(err)
+	}
+	return &pb.StrategyResponse{StrategyId: id, Status: strategy.StatusStopped, Message: "stopped"}, nil
+}
+
+// RunBacktest runs a backtest synchronously and returns its metrics and
+// emitted signals. Each backtest runs on a fresh instance from the
+// strategy's registry factory, so request params never reach the live
+// instance and concurrent backtests do not share state.
+func (s *StrategyService) RunBacktest(ctx context.Context, req *pb.RunBacktestRequest) (*pb.BacktestResult, error) {
+	if s.backtester == nil {
+		return nil, status.Error(codes.Unavailable, "backtester not configured")
+	}
+	id := req.GetStrategyId()
+	strat, err := s.registry.New(id)
+	switch {
+	case errors.Is(err, strategy.ErrUnknownStrategy):
+		return nil, status.Errorf(codes.NotFound, "strategy %q not found", id)
+	case err != nil:
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if req.GetStart() == nil || req.GetEnd() == nil {
+		return nil, status.Error(codes.InvalidArgument, "start and end are required")
+	}
+	if len(req.GetSymbols()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "symbols are required")
+	}
+	if req.GetInitialCapital() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "initial_capital must be positive")
+	}
+	if len(req.GetParams()) > 0 {
+		c, ok := strat.(strategy.Configurable)
+		if !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "strategy %q does not accept params", id)
+		}
+		if err := c.Configure(req.GetParams()); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	res, err := s.backtester.RunStrategy(ctx, strat, req.GetSymbols(),
+		req.GetStart().AsTime(), req.GetEnd().AsTime(), req.GetInitialCapital())
+	if err != nil {
+		var initErr *strategy.InitError
+		switch {
+		case errors.As(err, &initErr):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case ctx.Err() != nil:
+			return nil, status.FromContextError(ctx.Err()).Err()
+		default:
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	out := &pb.BacktestResult{
+		StrategyId:   id,
+		TotalReturn:  res.TotalReturn,
+		SharpeRatio:  res.SharpeRatio,
+		MaxDrawdown:  res.MaxDrawdown,
+		TotalTrades:  int32(res.TotalTrades),
+		WinRate:      res.WinRate,
+		ProfitFactor: res.ProfitFactor,
+	}
+	for i := range res.SignalLog {
+		out.Signals = append(out.Signals, signalToProto(&res.SignalLog[i]))
+	}
+	return out, nil
+}
+
+// StreamSignals streams signals emitted by running strategies, optionally
+// filtered to one strategy, until the client disconnects. With an in-process
+// runner signals are pushed as they are emitted; otherwise the signal store
+// is polled for signals persisted by another process (e.g. jupitor-trader).
+func (s *StrategyService) StreamSignals(req *pb.StreamSignalsRequest, stream grpc.ServerStreamingServer[pb.Signal]) error {
+	if s.runner == nil {
+		if s.signalStore == nil {
+			return status.Error(codes.Unavailable, "no signal source configured")
+		}
+		err := pollSignals(stream.Context(), s.signalStore, req.GetStrategyId(), func(sig *domain.Signal) error {
+			return stream.Send(signalToProto(sig))
+		})
+		if errors.Is(err, errListSignals) {
+			return status.Error(codes.Internal, err.Error())
+		}
+		return err
+	}
+	subID, ch := s.runner.SubscribeSignals(256)
+	defer s.runner.UnsubscribeSignals(subID)
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case sig, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if id := req.GetStrategyId(); id != "" && sig.StrategyID != id {
+				continue
+			}
+			if err := stream.Send(signalToProto(&sig)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// errListSignals wraps signal store failures in pollSignals.
+var errListSignals = errors.New("listing signals")
+
+// pollSignals polls signals for strategyID (all if empty) and passes those
+// newer than the ones stored when the call began to send, oldest first,
+// until ctx is cancelled or send fails.
+func pollSignals(ctx context.Context, signals store.SignalStore, strategyID string, send func(*domain.Signal) error) error {
+	var lastID int64
+	recent, err := signals.ListSignals(ctx, strategyID, 1)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errListSignals, err)
+	}
+	if len(recent) > 0 {
+		lastID = recent[0].ID
+	}
+
+	ticker := time.NewTicker(signalPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		sigs, err := signals.ListSignals(ctx, strategyID, 500)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("%w: %w", errListSignals, err)
+		}
+		// ListSignals is newest first; send oldest first.
+		for i := len(sigs) - 1; i >= 0; i-- {
+			if sigs[i].ID <= lastID {
+				continue
+			}
+			if err := send(&sigs[i]); err != nil {
+				return err
+			}
+			lastID = sigs[i].ID
+		}
+	}
+}
+
+// info builds the StrategyInfo for a registered strategy.
+func (s *StrategyService) info(name string) *pb.StrategyInfo {
+	info := &pb.StrategyInfo{Id: name, Name: name, Status: strategy.StatusStopped}
+	strat, _ := s.registry.Get(name)
+	if d, ok := strat.(strategy.Describer); ok {
+		info.Description = d.Description()
+	}
+	if s.runner != nil {
+		if st, ok := s.runner.Status(name); ok {
+			info.Status = st.Status
+			info.Symbols = st.Symbols
+			info.Params = st.Params
+			sort.Strings(info.Symbols)
+		}
+	}
+	return info
+}
+
+// runnerStatusError maps runner errors onto gRPC status codes.
+func runnerStatusError(err error) error {
+	switch {
+	case errors.Is(err, strategy.ErrUnknownStrategy):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, strategy.ErrAlreadyRunning):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, strategy.ErrNotRunning):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+}
+
+func signalToProto(sig *domain.Signal) *pb.Signal {
+	return &pb.Signal{
+		StrategyId: sig.StrategyID,
+		Symbol:     sig.Symbol,
+		Type:       signalTypeToProto(sig.Type),
+		Strength:   sig.Strength,
+		Timestamp:  timestamppb.New(sig.CreatedAt),
+		Metadata:   sig.Metadata,
+	}
+}
+
+func signalTypeToProto(t domain.SignalType) pb.SignalType {
+	switch t {
+	case domain.SignalTypeBuy:
+		return pb.SignalType_SIGNAL_TYPE_BUY
+	case domain.SignalTypeSell:
+		return pb.SignalType_SIGNAL_TYPE_SELL
+	case domain.SignalTypeHold:
+		return pb.SignalType_SIGNAL_TYPE_HOLD
+	default:
+		return pb.SignalType_SIGNAL_TYPE_UNSPECIFIED
+	}
+}
@@ -0,0 +1,278 @@
+package api
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "jupitor/internal/api/pb"
+	"jupitor/internal/domain"
+	"jupitor/internal/engine"
+	"jupitor/internal/store"
+)
+
+// riskErrorDomain is the ErrorInfo domain attached to risk rejections.
+const riskErrorDomain = "risk.jupitor"
+
+// TradingService provides gRPC endpoints for order submission and position
+// management on top of engine.Engine.
+type TradingService struct {
+	pb.UnimplementedTradingServer
+	engine *engine.Engine
+}
+
+// NewTradingService creates a TradingService backed by the given engine.
+func NewTradingService(eng *engine.Engine) *TradingService {
+	return &TradingService{engine: eng}
+}
+
+// RegisterGRPC registers the service on the given gRPC server instance.
+func (s *TradingService) RegisterGRPC(gs *grpc.Server) {
+	pb.RegisterTradingServer(gs, s)
+}
+
+// SubmitOrder validates and submits an order through the engine. Risk
+// rejections return FailedPrecondition with an ErrorInfo detail whose reason
+// is the rule name; broker rejections are reported in the response with
+// status "rejected".
+func (s *TradingService) SubmitOrder(ctx context.Context, req *pb.SubmitOrderRequest) (*pb.OrderResponse, error) {
+	order := &domain.Order{
+		Symbol:      req.GetSymbol(),
+		Side:        orderSideFromProto(req.GetSide()),
+		Type:        orderTypeFromProto(req.GetType()),
+		TimeInForce: timeInForceFromProto(req.GetTimeInForce()),
+		Qty:         req.GetQty(),
+		LimitPrice:  req.GetLimitPrice(),
+		StopPrice:   req.GetStopPrice(),
+		StrategyID:  req.GetStrategyId(),
+	}
+	if err := validateOrder(order); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	out, err := s.engine.SubmitOrder(ctx, order)
+	if err != nil {
+		var re *engine.RiskError
+		if errors.As(err, &re) {
+			return nil, riskStatus(re)
+		}
+		if out != nil && out.Status == domain.OrderStatusRejected {
+			return &pb.OrderResponse{OrderId: out.ID, Status: string(out.Status), Message: err.Error()}, nil
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &pb.OrderResponse{OrderId: out.ID, Status: string(out.Status), Message: "submitted"}, nil
+}
+
+// CancelOrder cancels an open order.
+func (s *TradingService) CancelOrder(ctx context.Context, req *pb.CancelOrderRequest) (*pb.OrderResponse, error) {
+	id := req.GetOrderId()
+	if id == "" {
+		return nil, status.Error(codes.InvalidArgument, "order_id is required")
+	}
+	switch err := s.engine.CancelOrder(ctx, id); {
+	case errors.Is(err, store.ErrNotFound):
+		return nil, status.Errorf(codes.NotFound, "order %q not found", id)
+	case errors.Is(err, engine.ErrOrderNotOpen):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	o, err := s.engine.GetOrder(ctx, id)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	msg := "cancelled"
+	if o.Status == domain.OrderStatusPendingCancel {
+		msg = "cancel requested"
+	}
+	return &pb.OrderResponse{OrderId: id, Status: string(o.Status), Message: msg}, nil
+}
+
+// GetPositions returns all open positions.
+func (s *TradingService) GetPositions(ctx context.Context, _ *pb.GetPositionsRequest) (*pb.GetPositionsResponse, error) {
+	positions, err := s.engine.GetPositions(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	resp := &pb.GetPositionsResponse{Positions: make([]*pb.Position, 0, len(positions))}
+	for i := range positions {
+		resp.Positions = append(resp.Positions, positionToProto(&positions[i]))
+	}
+	return resp, nil
+}
+
+// StreamOrders streams every order state change (submissions, fills,
+// cancellations) until the client disconnects.
+func (s *TradingService) StreamOrders(_ *pb.StreamOrdersRequest, stream grpc.ServerStreamingServer[pb.OrderUpdate]) error {
+	subID, ch := s.engine.SubscribeOrders(256)
+	defer s.engine.UnsubscribeOrders(subID)
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case o, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(orderUpdateToProto(&o)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// GetAccount returns the broker account snapshot.
+func (s *TradingService) GetAccount(ctx context.Context, _ *pb.GetAccountRequest) (*pb.AccountInfo, error) {
+	acct, err := s.engine.GetAccount(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	return &pb.AccountInfo{
+		Equity:         acct.Equity,
+		Cash:           acct.Cash,
+		BuyingPower:    acct.BuyingPower,
+		PortfolioValue: acct.PortfolioValue,
+		DailyPl:        acct.DailyPL,
+		DailyPlPct:     acct.DailyPLPct,
+	}, nil
+}
+
+// ---------------------------------------------------------------------------
+// Conversion helpers
+// ---------------------------------------------------------------------------
+
+// validateOrder checks the fields an order request must carry.
+func validateOrder(o *domain.Order) error {
+	switch {
+	case o.Symbol == "":
+		return errors.New("symbol is required")
+	case o.Side == "":
+		return errors.New("side is required")
+	case o.Qty <= 0:
+		return errors.New("qty must be positive")
+	}
+	switch o.Type {
+	case domain.OrderTypeLimit, domain.OrderTypeStopLimit:
+		if o.LimitPrice <= 0 {
+			return errors.New("limit_price is required for limit orders")
+		}
+	}
+	switch o.Type {
+	case domain.OrderTypeStop, domain.OrderTypeStopLimit:
+		if o.StopPrice <= 0 {
+			return errors.New("stop_price is required for stop orders")
+		}
+	}
+	return nil
+}
+
+// riskStatus converts a risk rejection into a FailedPrecondition status
+// carrying the rule as ErrorInfo.reason.
+func riskStatus(re *engine.RiskError) error {
+	st := status.New(codes.FailedPrecondition, re.Error())
+	if d, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   re.Rule,
+		Domain:   riskErrorDomain,
+		Metadata: map[string]string{"symbol": re.Symbol},
+	}); err == nil {
+		st = d
+	}
+	return st.Err()
+}
+
+func orderUpdateToProto(o *domain.Order) *pb.OrderUpdate {
+	return &pb.OrderUpdate{
+		OrderId:        o.ID,
+		Symbol:         o.Symbol,
+		Side:           orderSideToProto(o.Side),
+		Type:           orderTypeToProto(o.Type),
+		Status:         string(o.Status),
+		FilledQty:      o.FilledQty,
+		FilledAvgPrice: o.FilledAvgPrice,
+		Timestamp:      timestamppb.New(o.UpdatedAt),
+	}
+}
+
+func positionToProto(p *domain.Position) *pb.Position {
+	side := pb.OrderSide_ORDER_SIDE_BUY
+	if p.Side == domain.PositionSideShort {
+		side = pb.OrderSide_ORDER_SIDE_SELL
+	}
+	return &pb.Position{
+		Symbol:          p.Symbol,
+		Qty:             p.Qty,
+		AvgEntryPrice:   p.AvgEntryPrice,
+		MarketValue:     p.MarketValue,
+		UnrealizedPl:    p.UnrealizedPL,
+		UnrealizedPlPct: p.UnrealizedPLPct,
+		Side:            side,
+	}
+}
+
+func orderSideFromProto(s pb.OrderSide) domain.OrderSide {
+	switch s {
+	case pb.OrderSide_ORDER_SIDE_BUY:
+		return domain.OrderSideBuy
+	case pb.OrderSide_ORDER_SIDE_SELL:
+		return domain.OrderSideSell
+	}
+	return ""
+}
+
+func orderSideToProto(s domain.OrderSide) pb.OrderSide {
+	switch s {
+	case domain.OrderSideBuy:
+		return pb.OrderSide_ORDER_SIDE_BUY
+	case domain.OrderSideSell:
+		return pb.OrderSide_ORDER_SIDE_SELL
+	}
+	return pb.OrderSide_ORDER_SIDE_UNSPECIFIED
+}
+
+// orderTypeFromProto maps an order type; unspecified means market.
+func orderTypeFromProto(t pb.OrderType) domain.OrderType {
+	switch t {
+	case pb.OrderType_ORDER_TYPE_LIMIT:
+		return domain.OrderTypeLimit
+	case pb.OrderType_ORDER_TYPE_STOP:
+		return domain.OrderTypeStop
+	case pb.OrderType_ORDER_TYPE_STOP_LIMIT:
+		return domain.OrderTypeStopLimit
+	}
+	return domain.OrderTypeMarket
+}
+
+func orderTypeToProto(t domain.OrderType) pb.OrderType {
+	switch t {
+	case domain.OrderTypeMarket:
+		return pb.OrderType_ORDER_TYPE_MARKET
+	case domain.OrderTypeLimit:
+		return pb.OrderType_ORDER_TYPE_LIMIT
+	case domain.OrderTypeStop:
+		return pb.OrderType_ORDER_TYPE_STOP
+	case domain.OrderTypeStopLimit:
+		return pb.OrderType_ORDER_TYPE_STOP_LIMIT
+	}
+	return pb.OrderType_ORDER_TYPE_UNSPECIFIED
+}
+
+// timeInForceFromProto maps a time-in-force; unspecified means day.
+func timeInForceFromProto(t pb.TimeInForce) domain.TimeInForce {
+	switch t {
+	case pb.TimeInForce_TIME_IN_FORCE_GTC:
+		return domain.TimeInForceGTC
+	case pb.TimeInForce_TIME_IN_FORCE_IOC:
+		return domain.TimeInForceIOC
+	case pb.TimeInForce_TIME_IN_FORCE_FOK:
+		return domain.TimeInForceFOK
+	}
+	return domain.TimeInForceDay
+}
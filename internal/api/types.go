@@ -0,0 +1,223 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"jupitor/internal/domain"
+)
+
+// ListResponse is the envelope of REST endpoints returning a list.
+// NextPageToken is set when more results remain.
+type ListResponse[T any] struct {
+	Data          []T    `json:"data"`
+	NextPageToken string `json:"next_page_token,omitempty"`
+}
+
+// ItemResponse is the envelope of REST endpoints returning a single object.
+type ItemResponse[T any] struct {
+	Data T `json:"data"`
+}
+
+// ErrorJSON is the body of every REST error response. Rule names the risk
+// rule behind a rejected order; Order is the rejected order when the broker
+// refused it.
+type ErrorJSON struct {
+	Error string     `json:"error"`
+	Rule  string     `json:"rule,omitempty"`
+	Order *OrderJSON `json:"order,omitempty"`
+}
+
+// BarJSON is the JSON representation of an OHLCV bar.
+type BarJSON struct {
+	Symbol     string    `json:"symbol"`
+	Timestamp  time.Time `json:"timestamp"`
+	Open       float64   `json:"open"`
+	High       float64   `json:"high"`
+	Low        float64   `json:"low"`
+	Close      float64   `json:"close"`
+	Volume     int64     `json:"volume"`
+	TradeCount int64     `json:"trade_count"`
+	VWAP       float64   `json:"vwap"`
+}
+
+// TradeJSON is the JSON representation of a single trade.
+type TradeJSON struct {
+	Symbol     string    `json:"symbol"`
+	Timestamp  time.Time `json:"timestamp"`
+	Price      float64   `json:"price"`
+	Size       int64     `json:"size"`
+	Exchange   string    `json:"exchange"`
+	ID         string    `json:"id"`
+	Conditions string    `json:"conditions,omitempty"`
+}
+
+// OrderJSON is the JSON representation of an order.
+type OrderJSON struct {
+	ID             string    `json:"id"`
+	Symbol         string    `json:"symbol"`
+	Side           string    `json:"side"`
+	Type           string    `json:"type"`
+	TimeInForce    string    `json:"time_in_force"`
+	Qty            float64   `json:"qty"`
+	LimitPrice     float64   `json:"limit_price,omitempty"`
+	StopPrice      float64   `json:"stop_price,omitempty"`
+	Status         string    `json:"status"`
+	FilledQty      float64   `json:"filled_qty"`
+	FilledAvgPrice float64   `json:"filled_avg_price"`
+	StrategyID     string    `json:"strategy_id,omitempty"`
+	BrokerOrderID  string    `json:"broker_order_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// SubmitOrderJSON is the request body of POST /api/v1/orders. Type defaults
+// to market and TimeInForce to day.
+type SubmitOrderJSON struct {
+	Symbol      string  `json:"symbol"`
+	Side        string  `json:"side"`
+	Type        string  `json:"type,omitempty"`
+	TimeInForce string  `json:"time_in_force,omitempty"`
+	Qty         float64 `json:"qty"`
+	LimitPrice  float64 `json:"limit_price,omitempty"`
+	StopPrice   float64 `json:"stop_price,omitempty"`
+	StrategyID  string  `json:"strategy_id,omitempty"`
+}
+
+// PositionJSON is the JSON representation of an open position.
+type PositionJSON struct {
+	Symbol          string  `json:"symbol"`
+	Qty             float64 `json:"qty"`
+	AvgEntryPrice   float64 `json:"avg_entry_price"`
+	MarketValue     float64 `json:"market_value"`
+	UnrealizedPL    float64 `json:"unrealized_pl"`
+	UnrealizedPLPct float64 `json:"unrealized_pl_pct"`
+	Side            string  `json:"side"`
+}
+
+// AccountJSON is the JSON representation of the account snapshot.
+type AccountJSON struct {
+	Equity         float64 `json:"equity"`
+	Cash           float64 `json:"cash"`
+	BuyingPower    float64 `json:"buying_power"`
+	PortfolioValue float64 `json:"portfolio_value"`
+	DailyPL        float64 `json:"daily_pl"`
+	DailyPLPct     float64 `json:"daily_pl_pct"`
+}
+
+// SignalJSON is the JSON representation of a strategy signal.
+type SignalJSON struct {
+	ID         int64             `json:"id"`
+	StrategyID string            `json:"strategy_id"`
+	Symbol     string            `json:"symbol"`
+	Type       string            `json:"type"`
+	Strength   float64           `json:"strength"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
+
+// toOrder converts the request into a domain order, rejecting unknown enum
+// values.
+func (r *SubmitOrderJSON) toOrder() (*domain.Order, error) {
+	o := &domain.Order{
+		Symbol:      strings.ToUpper(r.Symbol),
+		Side:        domain.OrderSide(strings.ToLower(r.Side)),
+		Type:        domain.OrderType(strings.ToLower(r.Type)),
+		TimeInForce: domain.TimeInForce(strings.ToLower(r.TimeInForce)),
+		Qty:         r.Qty,
+		LimitPrice:  r.LimitPrice,
+		StopPrice:   r.StopPrice,
+		StrategyID:  r.StrategyID,
+	}
+	switch o.Side {
+	case "", domain.OrderSideBuy, domain.OrderSideSell:
+	default:
+		return nil, fmt.Errorf("unknown side %q", r.Side)
+	}
+	switch o.Type {
+	case "":
+		o.Type = domain.OrderTypeMarket
+	case domain.OrderTypeMarket, domain.OrderTypeLimit, domain.OrderTypeStop, domain.OrderTypeStopLimit:
+	default:
+		return nil, fmt.Errorf("unknown type %q", r.Type)
+	}
+	switch o.TimeInForce {
+	case "":
+		o.TimeInForce = domain.TimeInForceDay
+	case domain.TimeInForceDay, domain.TimeInForceGTC, domain.TimeInForceIOC, domain.TimeInForceFOK:
+	default:
+		return nil, fmt.Errorf("unknown time_in_force %q", r.TimeInForce)
+	}
+	return o, nil
+}
+
+func barToJSON(b *domain.Bar) BarJSON {
+	return BarJSON{
+		Symbol:     b.Symbol,
+		Timestamp:  b.Timestamp,
+		Open:       b.Open,
+		High:       b.High,
+		Low:        b.Low,
+		Close:      b.Close,
+		Volume:     b.Volume,
+		TradeCount: b.TradeCount,
+		VWAP:       b.VWAP,
+	}
+}
+
+func tradeToJSON(t *domain.Trade) TradeJSON {
+	return TradeJSON{
+		Symbol:     t.Symbol,
+		Timestamp:  t.Timestamp,
+		Price:      t.Price,
+		Size:       t.Size,
+		Exchange:   t.Exchange,
+		ID:         t.ID,
+		Conditions: t.Conditions,
+	}
+}
+
+func orderToJSON(o *domain.Order) OrderJSON {
+	return OrderJSON{
+		ID:             o.ID,
+		Symbol:         o.Symbol,
+		Side:           string(o.Side),
+		Type:           string(o.Type),
+		TimeInForce:    string(o.TimeInForce),
+		Qty:            o.Qty,
+		LimitPrice:     o.LimitPrice,
+		StopPrice:      o.StopPrice,
+		Status:         string(o.Status),
+		FilledQty:      o.FilledQty,
+		FilledAvgPrice: o.FilledAvgPrice,
+		StrategyID:     o.StrategyID,
+		BrokerOrderID:  o.BrokerOrderID,
+		CreatedAt:      o.CreatedAt,
+		UpdatedAt:      o.UpdatedAt,
+	}
+}
+
+func positionToJSON(p *domain.Position) PositionJSON {
+	return PositionJSON{
+		Symbol:          p.Symbol,
+		Qty:             p.Qty,
+		AvgEntryPrice:   p.AvgEntryPrice,
+		MarketValue:     p.MarketValue,
+		UnrealizedPL:    p.UnrealizedPL,
+		UnrealizedPLPct: p.UnrealizedPLPct,
+		Side:            string(p.Side),
+	}
+}
+
+func signalToJSON(s *domain.Signal) SignalJSON {
+	return SignalJSON{
+		ID:         s.ID,
+		StrategyID: s.StrategyID,
+		Symbol:     s.Symbol,
+		Type:       string(s.Type),
+		Strength:   s.Strength,
+		Metadata:   s.Metadata,
+		CreatedAt:  s.CreatedAt,
+	}
+}
@@ -1,62 +1,535 @@
 package api
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coder/websocket"
+
+	"jupitor/internal/domain"
+	"jupitor/internal/engine"
+	"jupitor/internal/live"
+	"jupitor/internal/store"
+	"jupitor/internal/strategy"
+	"jupitor/internal/tradeparams"
+)
+
+// Channels a WebSocket client can subscribe to.
+const (
+	ChannelTrades  = "trades"
+	ChannelBars    = "bars"
+	ChannelSignals = "signals"
+	ChannelOrders  = "orders"
+	ChannelTargets = "targets"
+
+	// channelError carries {"error": msg} replies to bad client requests.
+	channelError = "error"
 )
 
-// Client represents a single WebSocket connection managed by a Hub.
+var feedChannels = map[string]bool{
+	ChannelTrades:  true,
+	ChannelBars:    true,
+	ChannelSignals: true,
+	ChannelOrders:  true,
+	ChannelTargets: true,
+}
+
+const (
+	wsReadLimit    = 64 << 10
+	wsWriteTimeout = 10 * time.Second
+	wsPingInterval = 30 * time.Second
+
+	// targetRetryMin and targetRetryMax bound the FeedTargets reconnect
+	// backoff.
+	targetRetryMin = time.Second
+	targetRetryMax = time.Minute
+
+	// clientQueueSize is how many messages may wait for a client before
+	// further messages are coalesced, keeping only the latest per key (e.g.
+	// the last trade per symbol). Slow clients see fewer updates instead of
+	// being disconnected.
+	clientQueueSize = 256
+)
+
+// wsMessage is the envelope of every message pushed to clients.
+type wsMessage struct {
+	Channel string `json:"channel"`
+	Symbol  string `json:"symbol,omitempty"`
+	Data    any    `json:"data"`
+}
+
+// wsRequest is a subscribe/unsubscribe request sent by a client.
+type wsRequest struct {
+	Action   string   `json:"action"`
+	Channels []string `json:"channels"`
+	Symbols  []string `json:"symbols"`
+}
+
+// Client is a single WebSocket connection managed by a Hub. It receives only
+// the channels and symbols it has subscribed to.
 type Client struct {
 	hub  *Hub
-	send chan []byte
+	conn *websocket.Conn
+
+	mu sync.Mutex
+	// subs maps channel to subscribed symbols; a nil set means all symbols.
+	subs         map[string]map[string]bool
+	queue        [][]byte
+	overflow     map[string][]byte // coalesced messages by key
+	overflowKeys []string          // overflow keys in arrival order
+	coalesced    int
+	notify       chan struct{}
 }
 
-// Hub manages a set of WebSocket clients and broadcasts messages to all
-// connected clients.
+// Hub tracks the connected WebSocket clients and fans published messages
+// out to those subscribed to them.
 type Hub struct {
-	clients    map[*Client]bool
-	broadcast  chan []byte
-	register   chan *Client
-	unregister chan *Client
+	mu      sync.RWMutex
+	clients map[*Client]struct{}
+	closed  bool
 }
 
-// NewHub creates a new Hub with initialised channels and client map.
+// NewHub creates an empty Hub.
 func NewHub() *Hub {
-	return &Hub{
-		clients:    make(map[*Client]bool),
-		broadcast:  make(chan []byte),
-		register:   make(chan *Client),
-		unregister: make(chan *Client),
+	return &Hub{clients: make(map[*Client]struct{})}
+}
+
+// ServeHTTP upgrades the request to a WebSocket and serves the client until
+// it disconnects or the hub is closed.
+func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.RLock()
+	closed := h.closed
+	h.mu.RUnlock()
+	if closed {
+		writeError(w, http.StatusServiceUnavailable, "server shutting down")
+		return
+	}
+
+	// Origins are not restricted, matching CORSMiddleware.
+	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{OriginPatterns: []string{"*"}})
+	if err != nil {
+		slog.Warn("websocket upgrade failed", "remote", r.RemoteAddr, "error", err)
+		return
+	}
+	conn.SetReadLimit(wsReadLimit)
+
+	c := &Client{
+		hub:      h,
+		conn:     conn,
+		subs:     make(map[string]map[string]bool),
+		overflow: make(map[string][]byte),
+		notify:   make(chan struct{}, 1),
 	}
+	if !h.register(c) {
+		conn.Close(websocket.StatusGoingAway, "server shutting down")
+		return
+	}
+	defer h.unregister(c)
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	writeDone := make(chan struct{})
+	go func() {
+		defer close(writeDone)
+		defer cancel()
+		if err := c.writePump(ctx); err != nil && ctx.Err() == nil {
+			slog.Debug("websocket write failed", "remote", r.RemoteAddr, "error", err)
+		}
+	}()
+
+	err = c.readPump(ctx)
+	cancel()
+	<-writeDone
+	switch websocket.CloseStatus(err) {
+	case websocket.StatusNormalClosure, websocket.StatusGoingAway:
+		conn.CloseNow()
+	default:
+		if err != nil && !errors.Is(err, context.Canceled) {
+			slog.Debug("websocket read failed", "remote", r.RemoteAddr, "error", err)
+		}
+		conn.Close(websocket.StatusInternalError, "")
+	}
+}
+
+// Close disconnects every client and rejects new ones.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	h.closed = true
+	clients := make([]*Client, 0, len(h.clients))
+	for c := range h.clients {
+		clients = append(clients, c)
+	}
+	h.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, c := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.conn.Close(websocket.StatusGoingAway, "server shutting down")
+		}()
+	}
+	wg.Wait()
 }
 
-// Run starts the Hub's main event loop. It should be launched as a goroutine.
-func (h *Hub) Run() {
-	// TODO: implement full WebSocket hub event loop
+// ClientCount returns the number of connected clients.
+func (h *Hub) ClientCount() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients)
+}
+
+func (h *Hub) register(c *Client) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return false
+	}
+	h.clients[c] = struct{}{}
+	return true
+}
+
+func (h *Hub) unregister(c *Client) {
+	h.mu.Lock()
+	delete(h.clients, c)
+	h.mu.Unlock()
+}
+
+// ---------------------------------------------------------------------------
+// Publishing
+// ---------------------------------------------------------------------------
+
+// Publish sends data on channel to every client subscribed to symbol (or to
+// the whole channel when symbol is empty). Under backpressure messages with
+// the same key replace each other.
+func (h *Hub) Publish(channel, symbol, key string, data any) {
+	msg, err := json.Marshal(wsMessage{Channel: channel, Symbol: symbol, Data: data})
+	if err != nil {
+		slog.Error("encoding websocket message", "channel", channel, "error", err)
+		return
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for c := range h.clients {
+		if c.subscribed(channel, symbol) {
+			c.enqueue(key, msg)
+		}
+	}
+}
+
+// PublishTrade publishes a trade on the trades channel.
+func (h *Hub) PublishTrade(t *domain.Trade) {
+	h.Publish(ChannelTrades, t.Symbol, ChannelTrades+"/"+t.Symbol, tradeToJSON(t))
+}
+
+// PublishBar publishes a bar on the bars channel.
+func (h *Hub) PublishBar(b *domain.Bar) {
+	h.Publish(ChannelBars, b.Symbol, ChannelBars+"/"+b.Symbol, barToJSON(b))
+}
+
+// PublishOrder publishes an order state change on the orders channel.
+func (h *Hub) PublishOrder(o *domain.Order) {
+	h.Publish(ChannelOrders, o.Symbol, ChannelOrders+"/"+o.ID, orderToJSON(o))
+}
+
+// PublishSignal publishes a strategy signal on the signals channel.
+func (h *Hub) PublishSignal(s *domain.Signal) {
+	h.Publish(ChannelSignals, s.Symbol, ChannelSignals+"/"+s.StrategyID+"/"+s.Symbol, signalToJSON(s))
+}
+
+// PublishTargetEvent publishes a trade-parameter event on the targets
+// channel. Target events are not per symbol and reach every subscriber.
+func (h *Hub) PublishTargetEvent(evt tradeparams.Event) {
+	h.Publish(ChannelTargets, "", ChannelTargets+"/"+evt.Date+"/"+evt.Key, evt)
+}
+
+// ---------------------------------------------------------------------------
+// Feeds
+// ---------------------------------------------------------------------------
+
+// FeedLive publishes the model's live trades and the 1-minute bars built
+// from them until ctx is cancelled. Bars are published when their window
+// closes, as in MarketDataService.StreamBars.
+func (h *Hub) FeedLive(ctx context.Context, model *live.LiveModel) {
+	subID, ch := model.Subscribe(4096)
+	defer model.Unsubscribe(subID)
+
+	bars := live.NewBarStream(time.Minute, live.DefaultBarFlushDelay)
+	publishBars := func(closed []domain.Bar) {
+		for i := range closed {
+			h.PublishBar(&closed[i])
+		}
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
 	for {
 		select {
-		case client := <-h.register:
-			h.clients[client] = true
-		case client := <-h.unregister:
-			if _, ok := h.clients[client]; ok {
-				delete(h.clients, client)
-				close(client.send)
-			}
-		case message := <-h.broadcast:
-			for client := range h.clients {
-				select {
-				case client.send <- message:
-				default:
-					close(client.send)
-					delete(h.clients, client)
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			publishBars(bars.Tick(now))
+		case evt, ok := <-ch:
+			if !ok {
+				return
+			}
+			t := live.TradeFromRecord(&evt.Record)
+			h.PublishTrade(&t)
+			publishBars(bars.Add(t))
+		}
+	}
+}
+
+// FeedOrders publishes the engine's order state changes until ctx is
+// cancelled.
+func (h *Hub) FeedOrders(ctx context.Context, eng *engine.Engine) {
+	subID, ch := eng.SubscribeOrders(256)
+	defer eng.UnsubscribeOrders(subID)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case o, ok := <-ch:
+			if !ok {
+				return
+			}
+			h.PublishOrder(&o)
+		}
+	}
+}
+
+// FeedSignals publishes the runner's signals until ctx is cancelled.
+func (h *Hub) FeedSignals(ctx context.Context, runner *strategy.Runner) {
+	subID, ch := runner.SubscribeSignals(256)
+	defer runner.UnsubscribeSignals(subID)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case s, ok := <-ch:
+			if !ok {
+				return
+			}
+			h.PublishSignal(&s)
+		}
+	}
+}
+
+// PollSignals publishes signals persisted to the signal store by another
+// process (e.g. jupitor-trader) until ctx is cancelled. It is the FeedSignals
+// counterpart for a server without an in-process runner.
+func (h *Hub) PollSignals(ctx context.Context, signals store.SignalStore) {
+	err := pollSignals(ctx, signals, "", func(s *domain.Signal) error {
+		h.PublishSignal(s)
+		return nil
+	})
+	if err != nil {
+		slog.Error("signal feed stopped", "error", err)
+	}
+}
+
+// TargetStream delivers trade-parameter events to fn until ctx is cancelled
+// or the stream fails; jupitor.Client.StreamTargets, which follows
+// us-stream's SSE feed, is one.
+type TargetStream func(ctx context.Context, fn func(tradeparams.Event) error) error
+
+// FeedTargets publishes the trade-parameter events of stream until ctx is
+// cancelled, reconnecting with backoff whenever the stream ends.
+func (h *Hub) FeedTargets(ctx context.Context, stream TargetStream) {
+	backoff := targetRetryMin
+	for {
+		err := stream(ctx, func(evt tradeparams.Event) error {
+			backoff = targetRetryMin
+			h.PublishTargetEvent(evt)
+			return nil
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		slog.Warn("targets feed disconnected", "error", err, "retry", backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, targetRetryMax)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Client
+// ---------------------------------------------------------------------------
+
+// readPump applies subscribe/unsubscribe requests until the connection
+// fails. It also processes the pongs that writePump's pings wait for.
+func (c *Client) readPump(ctx context.Context) error {
+	for {
+		typ, data, err := c.conn.Read(ctx)
+		if err != nil {
+			return err
+		}
+		if typ != websocket.MessageText {
+			c.sendError("expected a text message")
+			continue
+		}
+		var req wsRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			c.sendError("invalid JSON request")
+			continue
+		}
+		if err := c.apply(req); err != nil {
+			c.sendError(err.Error())
+		}
+	}
+}
+
+// writePump writes queued messages and pings the client until ctx is
+// cancelled or a write fails.
+func (c *Client) writePump(ctx context.Context) error {
+	ticker := time.NewTicker(wsPingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			pingCtx, cancel := context.WithTimeout(ctx, wsWriteTimeout)
+			err := c.conn.Ping(pingCtx)
+			cancel()
+			if err != nil {
+				return fmt.Errorf("ping: %w", err)
+			}
+		case <-c.notify:
+			for _, msg := range c.drain() {
+				writeCtx, cancel := context.WithTimeout(ctx, wsWriteTimeout)
+				err := c.conn.Write(writeCtx, websocket.MessageText, msg)
+				cancel()
+				if err != nil {
+					return err
 				}
 			}
 		}
 	}
 }
 
-// HandleWebSocket upgrades an HTTP connection to a WebSocket and registers
-// the client with the Hub.
-func HandleWebSocket(_ http.ResponseWriter, _ *http.Request) {
-	// TODO: upgrade HTTP connection to WebSocket using gorilla/websocket or nhooyr.io/websocket
-	// TODO: create Client, register with hub, start read/write pumps
+// apply updates the client's subscriptions. Subscribing without symbols
+// covers every symbol on the channel; unsubscribing without symbols drops
+// the channel. Individual symbols cannot be removed from an all-symbols
+// subscription.
+func (c *Client) apply(req wsRequest) error {
+	if len(req.Channels) == 0 {
+		return errors.New("channels is required")
+	}
+	for _, ch := range req.Channels {
+		if !feedChannels[ch] {
+			return fmt.Errorf("unknown channel %q", ch)
+		}
+	}
+	symbols := make([]string, len(req.Symbols))
+	for i, sym := range req.Symbols {
+		symbols[i] = strings.ToUpper(sym)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch req.Action {
+	case "subscribe":
+		for _, ch := range req.Channels {
+			set, ok := c.subs[ch]
+			switch {
+			case len(symbols) == 0:
+				c.subs[ch] = nil
+			case ok && set == nil:
+				// Already subscribed to every symbol.
+			default:
+				if set == nil {
+					set = make(map[string]bool, len(symbols))
+					c.subs[ch] = set
+				}
+				for _, sym := range symbols {
+					set[sym] = true
+				}
+			}
+		}
+	case "unsubscribe":
+		for _, ch := range req.Channels {
+			set := c.subs[ch]
+			if len(symbols) == 0 {
+				delete(c.subs, ch)
+				continue
+			}
+			if set == nil {
+				continue
+			}
+			for _, sym := range symbols {
+				delete(set, sym)
+			}
+			if len(set) == 0 {
+				delete(c.subs, ch)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown action %q (want subscribe or unsubscribe)", req.Action)
+	}
+	return nil
+}
+
+func (c *Client) subscribed(channel, symbol string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	set, ok := c.subs[channel]
+	if !ok {
+		return false
+	}
+	return set == nil || symbol == "" || set[symbol]
+}
+
+// enqueue queues msg for the write pump. Once the queue is full, messages
+// are coalesced by key until the pump catches up.
+func (c *Client) enqueue(key string, msg []byte) {
+	c.mu.Lock()
+	if len(c.queue) < clientQueueSize && len(c.overflowKeys) == 0 {
+		c.queue = append(c.queue, msg)
+	} else {
+		if _, ok := c.overflow[key]; ok {
+			c.coalesced++
+		} else {
+			c.overflowKeys = append(c.overflowKeys, key)
+		}
+		c.overflow[key] = msg
+	}
+	c.mu.Unlock()
+
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+}
+
+// drain returns every pending message in order and resets the queue.
+func (c *Client) drain() [][]byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := c.queue
+	for _, k := range c.overflowKeys {
+		out = append(out, c.overflow[k])
+	}
+	if c.coalesced > 0 {
+		slog.Debug("websocket client lagging", "coalesced", c.coalesced)
+	}
+	c.queue, c.overflowKeys, c.coalesced = nil, nil, 0
+	clear(c.overflow)
+	return out
+}
+
+func (c *Client) sendError(msg string) {
+	data, _ := json.Marshal(wsMessage{Channel: channelError, Data: ErrorJSON{Error: msg}})
+	c.enqueue(channelError, data)
 }
@@ -0,0 +1,200 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+
+	"jupitor/internal/domain"
+	"jupitor/internal/tradeparams"
+)
+
+// dialHub connects a WebSocket client to a test server serving h.
+func dialHub(t *testing.T, h *Hub) *websocket.Conn {
+	t.Helper()
+	ts := httptest.NewServer(h)
+	t.Cleanup(ts.Close)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(ts.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.CloseNow() })
+	return conn
+}
+
+// waitSubscribed waits until some client of h is subscribed to channel for
+// symbol.
+func waitSubscribed(t *testing.T, h *Hub, channel, symbol string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		h.mu.RLock()
+		for c := range h.clients {
+			if c.subscribed(channel, symbol) {
+				h.mu.RUnlock()
+				return
+			}
+		}
+		h.mu.RUnlock()
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("no client subscribed to %s/%s", channel, symbol)
+}
+
+func readMessage(t *testing.T, conn *websocket.Conn) wsMessage {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var msg struct {
+		wsMessage
+		Data json.RawMessage `json:"data"`
+	}
+	if err := wsjson.Read(ctx, conn, &msg); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return wsMessage{Channel: msg.Channel, Symbol: msg.Symbol, Data: msg.Data}
+}
+
+func TestHubSubscriptions(t *testing.T) {
+	h := NewHub()
+	conn := dialHub(t, h)
+	ctx := context.Background()
+
+	wsjson.Write(ctx, conn, wsRequest{Action: "subscribe", Channels: []string{ChannelTrades}, Symbols: []string{"aapl"}})
+	wsjson.Write(ctx, conn, wsRequest{Action: "subscribe", Channels: []string{ChannelOrders}})
+	waitSubscribed(t, h, ChannelOrders, "MSFT")
+
+	h.PublishTrade(&domain.Trade{Symbol: "MSFT", Price: 1})
+	h.PublishBar(&domain.Bar{Symbol: "AAPL", Close: 2})
+	h.PublishTrade(&domain.Trade{Symbol: "AAPL", Price: 3})
+	h.PublishOrder(&domain.Order{ID: "o1", Symbol: "MSFT", Status: domain.OrderStatusFilled})
+
+	msg := readMessage(t, conn)
+	var trade TradeJSON
+	json.Unmarshal(msg.Data.(json.RawMessage), &trade)
+	if msg.Channel != ChannelTrades || msg.Symbol != "AAPL" || trade.Price != 3 {
+		t.Errorf("first message = %+v %+v, want the AAPL trade", msg, trade)
+	}
+	msg = readMessage(t, conn)
+	var order OrderJSON
+	json.Unmarshal(msg.Data.(json.RawMessage), &order)
+	if msg.Channel != ChannelOrders || order.ID != "o1" || order.Status != "filled" {
+		t.Errorf("second message = %+v %+v, want order o1", msg, order)
+	}
+
+	wsjson.Write(ctx, conn, wsRequest{Action: "subscribe", Channels: []string{"quotes"}})
+	if msg := readMessage(t, conn); msg.Channel != channelError ||
+		!strings.Contains(string(msg.Data.(json.RawMessage)), "unknown channel") {
+		t.Errorf("bad channel reply = %+v", msg)
+	}
+
+	wsjson.Write(ctx, conn, wsRequest{Action: "unsubscribe", Channels: []string{ChannelTrades}, Symbols: []string{"AAPL"}})
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		h.mu.RLock()
+		var subscribed bool
+		for c := range h.clients {
+			subscribed = c.subscribed(ChannelTrades, "AAPL")
+		}
+		h.mu.RUnlock()
+		if !subscribed {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("still subscribed to AAPL trades")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	h.PublishTrade(&domain.Trade{Symbol: "AAPL", Price: 4})
+	h.PublishOrder(&domain.Order{ID: "o2", Symbol: "AAPL"})
+	if msg := readMessage(t, conn); msg.Channel != ChannelOrders {
+		t.Errorf("after unsubscribe got %+v, want the order", msg)
+	}
+
+	// Close waits for the close handshake, so read concurrently.
+	closed := make(chan struct{})
+	go func() {
+		h.Close()
+		close(closed)
+	}()
+	readCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if _, _, err := conn.Read(readCtx); websocket.CloseStatus(err) != websocket.StatusGoingAway {
+		t.Errorf("read after Close = %v, want going away", err)
+	}
+	<-closed
+}
+
+func TestClientCoalescesWhenBacklogged(t *testing.T) {
+	c := &Client{overflow: make(map[string][]byte), notify: make(chan struct{}, 1)}
+	for i := range clientQueueSize {
+		c.enqueue("k"+strconv.Itoa(i), []byte(strconv.Itoa(i)))
+	}
+	// The queue is full: later messages keep only the latest per key.
+	c.enqueue("a", []byte("a1"))
+	c.enqueue("b", []byte("b1"))
+	c.enqueue("a", []byte("a2"))
+
+	out := c.drain()
+	if len(out) != clientQueueSize+2 {
+		t.Fatalf("drained %d messages, want %d", len(out), clientQueueSize+2)
+	}
+	if got := string(out[0]) + "," + string(out[clientQueueSize]) + "," + string(out[clientQueueSize+1]); got != "0,a2,b1" {
+		t.Errorf("drained order = %s, want 0,a2,b1", got)
+	}
+	if rest := c.drain(); len(rest) != 0 {
+		t.Errorf("second drain = %d messages", len(rest))
+	}
+	c.enqueue("a", []byte("a3"))
+	if out := c.drain(); len(out) != 1 || string(out[0]) != "a3" {
+		t.Errorf("after drain = %q, want [a3]", out)
+	}
+}
+
+func TestHubFeedTargets(t *testing.T) {
+	h := NewHub()
+	conn := dialHub(t, h)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	wsjson.Write(ctx, conn, wsRequest{Action: "subscribe", Channels: []string{ChannelTargets}})
+	waitSubscribed(t, h, ChannelTargets, "")
+
+	// The first connection fails after one event; FeedTargets reconnects.
+	var calls int
+	stream := func(ctx context.Context, fn func(tradeparams.Event) error) error {
+		calls++
+		fn(tradeparams.Event{Type: "set", Date: "2024-01-02", Key: "AAPL:target", Value: float64(calls)})
+		if calls == 1 {
+			return errors.New("connection reset")
+		}
+		<-ctx.Done()
+		return nil
+	}
+	done := make(chan struct{})
+	go func() {
+		h.FeedTargets(ctx, stream)
+		close(done)
+	}()
+
+	for want := 1.0; want <= 2; want++ {
+		msg := readMessage(t, conn)
+		var evt tradeparams.Event
+		json.Unmarshal(msg.Data.(json.RawMessage), &evt)
+		if msg.Channel != ChannelTargets || evt.Key != "AAPL:target" || evt.Value != want {
+			t.Errorf("message = %+v %+v, want target event %v", msg, evt, want)
+		}
+	}
+	cancel()
+	<-done
+}
@@ -2,18 +2,54 @@ package broker
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/shopspring/decimal"
 
 	"jupitor/internal/domain"
 )
 
-// Compile-time interface check.
+// Alpaca trading API endpoints.
+const (
+	AlpacaLiveURL  = "https://api.alpaca.markets"
+	AlpacaPaperURL = "https://paper-api.alpaca.markets"
+)
+
+// AlpacaBaseURL returns the trading endpoint for the configured base URL:
+// in paper mode an empty or live URL is replaced by AlpacaPaperURL, so paper
+// mode can never trade the live account by accident.
+func AlpacaBaseURL(configured string, paper bool) string {
+	if paper && (configured == "" || configured == AlpacaLiveURL) {
+		return AlpacaPaperURL
+	}
+	return configured
+}
+
+// Compile-time interface checks.
 var _ Broker = (*AlpacaBroker)(nil)
+var _ OrderQuerier = (*AlpacaBroker)(nil)
+var _ OrderStreamer = (*AlpacaBroker)(nil)
 
 // AlpacaBroker implements the Broker interface using the Alpaca brokerage API.
+//
+// Orders are submitted with the local order ID as Alpaca's client_order_id,
+// so trade updates streamed by StreamOrderUpdates can be matched back to the
+// order the caller submitted.
 type AlpacaBroker struct {
 	apiKey    string
 	apiSecret string
 	baseURL   string
+	client    *alpaca.Client
+	log       *slog.Logger
+
+	mu     sync.Mutex
+	orders map[string]domain.Order // latest known state by local order ID
+	byBID  map[string]string       // broker order ID → local order ID
 }
 
 // NewAlpacaBroker creates a new AlpacaBroker configured with the given
@@ -23,6 +59,14 @@ func NewAlpacaBroker(apiKey, apiSecret, baseURL string) *AlpacaBroker {
 		apiKey:    apiKey,
 		apiSecret: apiSecret,
 		baseURL:   baseURL,
+		client: alpaca.NewClient(alpaca.ClientOpts{
+			APIKey:    apiKey,
+			APISecret: apiSecret,
+			BaseURL:   baseURL,
+		}),
+		log:    slog.Default().With("broker", "alpaca"),
+		orders: make(map[string]domain.Order),
+		byBID:  make(map[string]string),
 	}
 }
 
@@ -31,26 +75,282 @@ func (b *AlpacaBroker) Name() string {
 	return "alpaca"
 }
 
-// SubmitOrder sends an order to the Alpaca API for execution.
+// SubmitOrder sends an order to the Alpaca API for execution. On success the
+// returned order carries the Alpaca order ID in BrokerOrderID and the status
+// reported by Alpaca.
 func (b *AlpacaBroker) SubmitOrder(_ context.Context, order *domain.Order) (*domain.Order, error) {
-	// TODO: implement Alpaca REST API call to POST /v2/orders
-	return order, nil
+	req := alpaca.PlaceOrderRequest{
+		Symbol:        order.Symbol,
+		Qty:           decimalPtr(order.Qty),
+		Side:          alpaca.Side(order.Side),
+		Type:          alpaca.OrderType(order.Type),
+		TimeInForce:   alpaca.TimeInForce(order.TimeInForce),
+		ClientOrderID: order.ID,
+	}
+	if req.TimeInForce == "" {
+		req.TimeInForce = alpaca.Day
+	}
+	if order.LimitPrice > 0 {
+		req.LimitPrice = decimalPtr(order.LimitPrice)
+	}
+	if order.StopPrice > 0 {
+		req.StopPrice = decimalPtr(order.StopPrice)
+	}
+
+	ao, err := b.client.PlaceOrder(req)
+	if err != nil {
+		return nil, fmt.Errorf("alpaca place order %s %s: %w", order.Side, order.Symbol, err)
+	}
+
+	out := *order
+	applyAlpacaOrder(&out, ao)
+	if out.ID == "" {
+		out.ID = ao.ID
+	}
+	b.track(out)
+	return &out, nil
 }
 
 // CancelOrder requests cancellation of an open order via the Alpaca API.
-func (b *AlpacaBroker) CancelOrder(_ context.Context, _ string) error {
-	// TODO: implement Alpaca REST API call to DELETE /v2/orders/{orderID}
+// orderID may be the local order ID of an order submitted through this
+// broker or an Alpaca order ID.
+func (b *AlpacaBroker) CancelOrder(_ context.Context, orderID string) error {
+	b.mu.Lock()
+	bid := orderID
+	if o, ok := b.orders[orderID]; ok && o.BrokerOrderID != "" {
+		bid = o.BrokerOrderID
+	}
+	b.mu.Unlock()
+
+	if err := b.client.CancelOrder(bid); err != nil {
+		return fmt.Errorf("alpaca cancel order %s: %w", orderID, err)
+	}
 	return nil
 }
 
 // GetPositions returns all current positions from the Alpaca account.
 func (b *AlpacaBroker) GetPositions(_ context.Context) ([]domain.Position, error) {
-	// TODO: implement Alpaca REST API call to GET /v2/positions
-	return nil, nil
+	aps, err := b.client.GetPositions()
+	if err != nil {
+		return nil, fmt.Errorf("alpaca get positions: %w", err)
+	}
+	now := time.Now()
+	positions := make([]domain.Position, 0, len(aps))
+	for _, ap := range aps {
+		qty := ap.Qty.InexactFloat64()
+		side := domain.PositionSideLong
+		if ap.Side == "short" || qty < 0 {
+			side = domain.PositionSideShort
+		}
+		positions = append(positions, domain.Position{
+			Symbol:          ap.Symbol,
+			Qty:             abs(qty),
+			AvgEntryPrice:   ap.AvgEntryPrice.InexactFloat64(),
+			MarketValue:     floatOf(ap.MarketValue),
+			UnrealizedPL:    floatOf(ap.UnrealizedPL),
+			UnrealizedPLPct: floatOf(ap.UnrealizedPLPC),
+			Side:            side,
+			UpdatedAt:       now,
+		})
+	}
+	return positions, nil
 }
 
 // GetAccount returns the current account information from the Alpaca API.
+// DailyPL is measured against the previous session's closing equity.
 func (b *AlpacaBroker) GetAccount(_ context.Context) (*domain.AccountInfo, error) {
-	// TODO: implement Alpaca REST API call to GET /v2/account
-	return &domain.AccountInfo{}, nil
+	acct, err := b.client.GetAccount()
+	if err != nil {
+		return nil, fmt.Errorf("alpaca get account: %w", err)
+	}
+	equity := acct.Equity.InexactFloat64()
+	last := acct.LastEquity.InexactFloat64()
+	info := &domain.AccountInfo{
+		Equity:         equity,
+		Cash:           acct.Cash.InexactFloat64(),
+		BuyingPower:    acct.BuyingPower.InexactFloat64(),
+		PortfolioValue: acct.PortfolioValue.InexactFloat64(),
+		DailyPL:        equity - last,
+	}
+	if last != 0 {
+		info.DailyPLPct = info.DailyPL / last
+	}
+	return info, nil
+}
+
+// GetOrder fetches the current state of an order from Alpaca by local or
+// broker order ID.
+func (b *AlpacaBroker) GetOrder(_ context.Context, orderID string) (*domain.Order, error) {
+	b.mu.Lock()
+	o, known := b.orders[orderID]
+	b.mu.Unlock()
+
+	var ao *alpaca.Order
+	var err error
+	if known && o.BrokerOrderID != "" {
+		ao, err = b.client.GetOrder(o.BrokerOrderID)
+	} else {
+		ao, err = b.client.GetOrderByClientOrderID(orderID)
+		var apiErr *alpaca.APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == 404 {
+			ao, err = b.client.GetOrder(orderID)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("alpaca get order %s: %w", orderID, err)
+	}
+
+	out := fromAlpacaOrder(ao)
+	b.track(out)
+	return &out, nil
+}
+
+// ListOpenOrders returns all orders Alpaca still considers open.
+func (b *AlpacaBroker) ListOpenOrders(_ context.Context) ([]domain.Order, error) {
+	aos, err := b.client.GetOrders(alpaca.GetOrdersRequest{Status: "open", Limit: 500})
+	if err != nil {
+		return nil, fmt.Errorf("alpaca list orders: %w", err)
+	}
+	orders := make([]domain.Order, 0, len(aos))
+	for i := range aos {
+		orders = append(orders, fromAlpacaOrder(&aos[i]))
+	}
+	return orders, nil
+}
+
+// StreamOrderUpdates subscribes to Alpaca's trade_updates event stream and
+// calls handler with the updated order (status, FilledQty, FilledAvgPrice)
+// for every event. It reconnects on stream errors, resuming from the last
+// event received, and returns when ctx is cancelled.
+func (b *AlpacaBroker) StreamOrderUpdates(ctx context.Context, handler func(domain.Order)) error {
+	var since time.Time
+	backoff := time.Second
+	for {
+		req := alpaca.StreamTradeUpdatesRequest{}
+		if !since.IsZero() {
+			req.Since = since.Add(time.Nanosecond)
+		}
+		err := b.client.StreamTradeUpdates(ctx, func(tu alpaca.TradeUpdate) {
+			since = tu.At
+			backoff = time.Second
+			handler(b.applyTradeUpdate(tu))
+		}, req)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			b.log.Warn("trade update stream error", "error", err, "retryIn", backoff)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, 30*time.Second)
+	}
+}
+
+// applyTradeUpdate merges a trade update into the tracked order state and
+// returns the updated order.
+func (b *AlpacaBroker) applyTradeUpdate(tu alpaca.TradeUpdate) domain.Order {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	localID := tu.Order.ClientOrderID
+	if id, ok := b.byBID[tu.Order.ID]; ok {
+		localID = id
+	}
+	o, ok := b.orders[localID]
+	if !ok {
+		o = fromAlpacaOrder(&tu.Order)
+		if localID != "" {
+			o.ID = localID
+		}
+	}
+	applyAlpacaOrder(&o, &tu.Order)
+	if !tu.At.IsZero() {
+		o.UpdatedAt = tu.At
+	}
+	b.orders[o.ID] = o
+	b.byBID[o.BrokerOrderID] = o.ID
+	return o
+}
+
+// track records the latest state of an order for update matching.
+func (b *AlpacaBroker) track(o domain.Order) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.orders[o.ID] = o
+	if o.BrokerOrderID != "" {
+		b.byBID[o.BrokerOrderID] = o.ID
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Mapping helpers
+// ---------------------------------------------------------------------------
+
+// mapAlpacaStatus maps an Alpaca order status onto domain.OrderStatus.
+func mapAlpacaStatus(status string) domain.OrderStatus {
+	switch status {
+	case "filled":
+		return domain.OrderStatusFilled
+	case "partially_filled":
+		return domain.OrderStatusPartial
+	case "canceled", "expired", "done_for_day", "replaced":
+		return domain.OrderStatusCancelled
+	case "rejected", "suspended":
+		return domain.OrderStatusRejected
+	case "pending_cancel":
+		return domain.OrderStatusPendingCancel
+	default:
+		// new, accepted, pending_new, pending_replace,
+		// accepted_for_bidding, stopped, calculated, held
+		return domain.OrderStatusSubmitted
+	}
+}
+
+// fromAlpacaOrder converts an Alpaca order into a domain.Order. The local ID
+// is taken from the client order ID when present.
+func fromAlpacaOrder(ao *alpaca.Order) domain.Order {
+	o := domain.Order{
+		ID:          ao.ClientOrderID,
+		Symbol:      ao.Symbol,
+		Side:        domain.OrderSide(ao.Side),
+		Type:        domain.OrderType(ao.Type),
+		TimeInForce: domain.TimeInForce(ao.TimeInForce),
+		Qty:         floatOf(ao.Qty),
+		LimitPrice:  floatOf(ao.LimitPrice),
+		StopPrice:   floatOf(ao.StopPrice),
+		CreatedAt:   ao.CreatedAt,
+	}
+	if o.ID == "" {
+		o.ID = ao.ID
+	}
+	applyAlpacaOrder(&o, ao)
+	return o
+}
+
+// applyAlpacaOrder copies broker-owned fields from an Alpaca order.
+func applyAlpacaOrder(o *domain.Order, ao *alpaca.Order) {
+	o.BrokerOrderID = ao.ID
+	o.Status = mapAlpacaStatus(ao.Status)
+	o.FilledQty = ao.FilledQty.InexactFloat64()
+	o.FilledAvgPrice = floatOf(ao.FilledAvgPrice)
+	if o.CreatedAt.IsZero() {
+		o.CreatedAt = ao.CreatedAt
+	}
+	o.UpdatedAt = ao.UpdatedAt
+}
+
+func decimalPtr(f float64) *decimal.Decimal {
+	d := decimal.NewFromFloat(f)
+	return &d
+}
+
+func floatOf(d *decimal.Decimal) float64 {
+	if d == nil {
+		return 0
+	}
+	return d.InexactFloat64()
 }
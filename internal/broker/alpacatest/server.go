@@ -0,0 +1,476 @@
+// Package alpacatest provides an in-memory fake of the Alpaca trading REST
+// API for testing AlpacaBroker and everything built on it without network
+// access or credentials.
+package alpacatest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/shopspring/decimal"
+)
+
+// Server is a fake Alpaca trading API backed by httptest.Server. It supports
+// order placement, lookup, listing and cancellation, positions, the account
+// endpoint and the /v2/events/trades SSE stream. Orders never fill on their
+// own; tests drive executions with Fill.
+type Server struct {
+	srv *httptest.Server
+
+	mu        sync.Mutex
+	nextID    int
+	orders    map[string]*alpaca.Order // by Alpaca order ID
+	seq       []string                 // order IDs in submission order
+	positions map[string]*alpaca.Position
+	account   alpaca.Account
+	subs      map[int]chan alpaca.TradeUpdate
+	nextSub   int
+	subscribe chan struct{} // closed and replaced when a stream subscribes
+}
+
+// NewServer starts a fake Alpaca server with a 100k cash account.
+func NewServer() *Server {
+	s := &Server{
+		orders:    make(map[string]*alpaca.Order),
+		positions: make(map[string]*alpaca.Position),
+		subs:      make(map[int]chan alpaca.TradeUpdate),
+		subscribe: make(chan struct{}),
+	}
+	s.SetAccount(100000, 100000, 100000)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v2/orders", s.handlePlaceOrder)
+	mux.HandleFunc("GET /v2/orders", s.handleListOrders)
+	mux.HandleFunc("GET /v2/orders/{id}", s.handleGetOrder)
+	mux.HandleFunc("GET /v2/orders:by_client_order_id", s.handleGetOrderByClientID)
+	mux.HandleFunc("DELETE /v2/orders/{id}", s.handleCancelOrder)
+	mux.HandleFunc("GET /v2/positions", s.handleListPositions)
+	mux.HandleFunc("GET /v2/account", s.handleAccount)
+	mux.HandleFunc("GET /v2/events/trades", s.handleTradeEvents)
+	s.srv = httptest.NewServer(requireAuth(mux))
+	return s
+}
+
+// URL returns the base URL to pass to broker.NewAlpacaBroker.
+func (s *Server) URL() string { return s.srv.URL }
+
+// Close shuts the server down and ends all event streams.
+func (s *Server) Close() {
+	s.mu.Lock()
+	for id, ch := range s.subs {
+		close(ch)
+		delete(s.subs, id)
+	}
+	s.mu.Unlock()
+	s.srv.CloseClientConnections()
+	s.srv.Close()
+}
+
+// WaitForSubscribers blocks until at least n trade event streams are
+// subscribed, so updates published afterwards reach them, or ctx is done.
+func (s *Server) WaitForSubscribers(ctx context.Context, n int) error {
+	for {
+		s.mu.Lock()
+		count, changed := len(s.subs), s.subscribe
+		s.mu.Unlock()
+		if count >= n {
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// SetAccount sets the account's equity, cash and previous-close equity.
+func (s *Server) SetAccount(equity, cash, lastEquity float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.account = alpaca.Account{
+		ID:             "fake-account",
+		Status:         "ACTIVE",
+		Currency:       "USD",
+		Equity:         decimal.NewFromFloat(equity),
+		LastEquity:     decimal.NewFromFloat(lastEquity),
+		Cash:           decimal.NewFromFloat(cash),
+		BuyingPower:    decimal.NewFromFloat(cash),
+		PortfolioValue: decimal.NewFromFloat(equity),
+	}
+}
+
+// SetPosition sets (or with qty 0 removes) a position marked at price.
+func (s *Server) SetPosition(symbol string, qty, avgEntry, price float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if qty == 0 {
+		delete(s.positions, symbol)
+		return
+	}
+	s.positions[symbol] = newPosition(symbol, qty, avgEntry, price)
+}
+
+// Orders returns all orders in submission order.
+func (s *Server) Orders() []alpaca.Order {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]alpaca.Order, 0, len(s.seq))
+	for _, id := range s.seq {
+		out = append(out, *s.orders[id])
+	}
+	return out
+}
+
+// Fill executes qty of an open order at price, updating the order and the
+// symbol's position and publishing a fill or partial_fill trade update.
+func (s *Server) Fill(orderID string, qty, price float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.orders[orderID]
+	if !ok {
+		return fmt.Errorf("order %s not found", orderID)
+	}
+	if !isOpen(o.Status) {
+		return fmt.Errorf("order %s is %s", orderID, o.Status)
+	}
+
+	filled := o.FilledQty.InexactFloat64()
+	avg := 0.0
+	if o.FilledAvgPrice != nil {
+		avg = o.FilledAvgPrice.InexactFloat64()
+	}
+	newFilled := filled + qty
+	newAvg := decimal.NewFromFloat((avg*filled + price*qty) / newFilled)
+	o.FilledQty = decimal.NewFromFloat(newFilled)
+	o.FilledAvgPrice = &newAvg
+	o.UpdatedAt = time.Now().UTC()
+
+	event := "partial_fill"
+	o.Status = "partially_filled"
+	if newFilled >= o.Qty.InexactFloat64() {
+		event = "fill"
+		o.Status = "filled"
+		at := o.UpdatedAt
+		o.FilledAt = &at
+	}
+
+	delta := qty
+	if o.Side == alpaca.Sell {
+		delta = -qty
+	}
+	s.applyPosition(o.Symbol, delta, price)
+
+	p := decimal.NewFromFloat(price)
+	q := decimal.NewFromFloat(qty)
+	s.publish(alpaca.TradeUpdate{Event: event, Order: *o, Price: &p, Qty: &q})
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Handlers
+// ---------------------------------------------------------------------------
+
+func (s *Server) handlePlaceOrder(w http.ResponseWriter, r *http.Request) {
+	var req alpaca.PlaceOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid body: "+err.Error())
+		return
+	}
+	if req.Symbol == "" || req.Qty == nil || !req.Qty.IsPositive() {
+		writeError(w, http.StatusUnprocessableEntity, "symbol and positive qty are required")
+		return
+	}
+	switch req.Type {
+	case alpaca.Limit, alpaca.StopLimit:
+		if req.LimitPrice == nil {
+			writeError(w, http.StatusUnprocessableEntity, "limit_price is required")
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if req.ClientOrderID != "" {
+		for _, o := range s.orders {
+			if o.ClientOrderID == req.ClientOrderID {
+				writeError(w, http.StatusUnprocessableEntity, "client_order_id must be unique")
+				return
+			}
+		}
+	}
+
+	s.nextID++
+	now := time.Now().UTC()
+	o := &alpaca.Order{
+		ID:            fmt.Sprintf("fake-%06d", s.nextID),
+		ClientOrderID: req.ClientOrderID,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		SubmittedAt:   now,
+		Symbol:        req.Symbol,
+		AssetClass:    alpaca.USEquity,
+		Type:          req.Type,
+		Side:          req.Side,
+		TimeInForce:   req.TimeInForce,
+		Status:        "new",
+		Qty:           req.Qty,
+		FilledQty:     decimal.Zero,
+		LimitPrice:    req.LimitPrice,
+		StopPrice:     req.StopPrice,
+	}
+	if o.ClientOrderID == "" {
+		o.ClientOrderID = o.ID
+	}
+	s.orders[o.ID] = o
+	s.seq = append(s.seq, o.ID)
+	s.publish(alpaca.TradeUpdate{Event: "new", Order: *o})
+
+	writeJSON(w, http.StatusOK, o)
+}
+
+func (s *Server) handleListOrders(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = "open"
+	}
+	s.mu.Lock()
+	out := make([]alpaca.Order, 0, len(s.seq))
+	for _, id := range s.seq {
+		o := s.orders[id]
+		switch {
+		case status == "all",
+			status == "open" && isOpen(o.Status),
+			status == "closed" && !isOpen(o.Status):
+			out = append(out, *o)
+		}
+	}
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) handleGetOrder(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	o, ok := s.orders[r.PathValue("id")]
+	var out alpaca.Order
+	if ok {
+		out = *o
+	}
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "order not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) handleGetOrderByClientID(w http.ResponseWriter, r *http.Request) {
+	cid := r.URL.Query().Get("client_order_id")
+	s.mu.Lock()
+	var out *alpaca.Order
+	for _, o := range s.orders {
+		if o.ClientOrderID == cid {
+			c := *o
+			out = &c
+			break
+		}
+	}
+	s.mu.Unlock()
+	if out == nil {
+		writeError(w, http.StatusNotFound, "order not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) handleCancelOrder(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.orders[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "order not found")
+		return
+	}
+	if !isOpen(o.Status) {
+		writeError(w, http.StatusUnprocessableEntity, "order is not cancelable")
+		return
+	}
+	now := time.Now().UTC()
+	o.Status = "canceled"
+	o.CanceledAt = &now
+	o.UpdatedAt = now
+	s.publish(alpaca.TradeUpdate{Event: "canceled", Order: *o})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleListPositions(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	out := make([]alpaca.Position, 0, len(s.positions))
+	for _, p := range s.positions {
+		out = append(out, *p)
+	}
+	s.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Symbol < out[j].Symbol })
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) handleAccount(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	acct := s.account
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, acct)
+}
+
+// handleTradeEvents streams trade updates as server-sent events until the
+// client disconnects or the server is closed.
+func (s *Server) handleTradeEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+
+	s.mu.Lock()
+	id := s.nextSub
+	s.nextSub++
+	ch := make(chan alpaca.TradeUpdate, 256)
+	s.subs[id] = ch
+	close(s.subscribe)
+	s.subscribe = make(chan struct{})
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		if _, ok := s.subs[id]; ok {
+			delete(s.subs, id)
+			close(ch)
+		}
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case tu, ok := <-ch:
+			if !ok {
+				return
+			}
+			data, _ := json.Marshal(tu)
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			flusher.Flush()
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Helpers (caller holds s.mu where noted)
+// ---------------------------------------------------------------------------
+
+// publish stamps and fans out a trade update (caller holds s.mu).
+func (s *Server) publish(tu alpaca.TradeUpdate) {
+	tu.At = time.Now().UTC()
+	tu.EventID = fmt.Sprintf("%d", tu.At.UnixNano())
+	for _, ch := range s.subs {
+		select {
+		case ch <- tu:
+		default:
+		}
+	}
+}
+
+// applyPosition adjusts a position by delta shares at price (caller holds s.mu).
+func (s *Server) applyPosition(symbol string, delta, price float64) {
+	cur, avg := 0.0, 0.0
+	if p, ok := s.positions[symbol]; ok {
+		cur = p.Qty.InexactFloat64()
+		avg = p.AvgEntryPrice.InexactFloat64()
+	}
+	next := cur + delta
+	switch {
+	case next == 0:
+		delete(s.positions, symbol)
+		return
+	case cur == 0 || (cur > 0) == (delta > 0):
+		avg = (avg*abs(cur) + price*abs(delta)) / abs(next)
+	case (cur > 0) != (next > 0):
+		avg = price
+	}
+	s.positions[symbol] = newPosition(symbol, next, avg, price)
+}
+
+func newPosition(symbol string, qty, avgEntry, price float64) *alpaca.Position {
+	side := "long"
+	if qty < 0 {
+		side = "short"
+	}
+	mv := decimal.NewFromFloat(qty * price)
+	pl := decimal.NewFromFloat(qty * (price - avgEntry))
+	plpc := decimal.Zero
+	if avgEntry != 0 {
+		plpc = decimal.NewFromFloat((price - avgEntry) / avgEntry)
+	}
+	cur := decimal.NewFromFloat(price)
+	return &alpaca.Position{
+		Symbol:         symbol,
+		AssetClass:     alpaca.USEquity,
+		Qty:            decimal.NewFromFloat(qty),
+		QtyAvailable:   decimal.NewFromFloat(qty),
+		AvgEntryPrice:  decimal.NewFromFloat(avgEntry),
+		Side:           side,
+		MarketValue:    &mv,
+		CostBasis:      decimal.NewFromFloat(qty * avgEntry),
+		UnrealizedPL:   &pl,
+		UnrealizedPLPC: &plpc,
+		CurrentPrice:   &cur,
+	}
+}
+
+func isOpen(status string) bool {
+	switch status {
+	case "filled", "canceled", "expired", "rejected", "replaced", "done_for_day":
+		return false
+	}
+	return true
+}
+
+// requireAuth rejects requests without Alpaca API key headers, as the real
+// API does.
+func requireAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("APCA-API-KEY-ID") == "" || r.Header.Get("APCA-API-SECRET-KEY") == "" {
+			writeError(w, http.StatusUnauthorized, "request is not authorized")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]any{"code": 40000000 + status, "message": msg})
+}
+
+func abs(x float64) float64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
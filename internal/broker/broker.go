@@ -25,3 +25,21 @@ type Broker interface {
 	// GetAccount returns a snapshot of the account's financial metrics.
 	GetAccount(ctx context.Context) (*domain.AccountInfo, error)
 }
+
+// OrderQuerier is implemented by brokers that can report the current state of
+// individual orders. The engine's reconciler uses it to repair drift between
+// the broker and the local order store.
+type OrderQuerier interface {
+	// GetOrder returns the broker's view of an order by local or broker ID.
+	GetOrder(ctx context.Context, orderID string) (*domain.Order, error)
+
+	// ListOpenOrders returns all orders the broker still considers open.
+	ListOpenOrders(ctx context.Context) ([]domain.Order, error)
+}
+
+// OrderStreamer is implemented by brokers that push order status updates.
+type OrderStreamer interface {
+	// StreamOrderUpdates calls handler for every order update until ctx is
+	// cancelled.
+	StreamOrderUpdates(ctx context.Context, handler func(domain.Order)) error
+}
@@ -1,6 +1,14 @@
 package broker
 
-import "testing"
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"jupitor/internal/broker/alpacatest"
+	"jupitor/internal/domain"
+)
 
 func TestAlpacaBrokerName(t *testing.T) {
 	b := NewAlpacaBroker("key", "secret", "https://paper-api.alpaca.markets")
@@ -15,3 +23,313 @@ func TestSimulatorBrokerName(t *testing.T) {
 		t.Errorf("SimulatorBroker.Name() = %q, want %q", got, "simulator")
 	}
 }
+
+func simBar(symbol string, day int, o, h, l, c float64, vol int64) domain.Bar {
+	return domain.Bar{
+		Symbol:    symbol,
+		Timestamp: time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC),
+		Open:      o, High: h, Low: l, Close: c,
+		Volume: vol,
+	}
+}
+
+func TestSimulatorBrokerMarketOrder(t *testing.T) {
+	ctx := context.Background()
+	b := NewSimulatorBroker()
+	b.SetCash(10000)
+	b.ProcessBar(simBar("AAPL", 2, 100, 100, 100, 100, 1000))
+
+	buy := &domain.Order{Symbol: "AAPL", Side: domain.OrderSideBuy, Type: domain.OrderTypeMarket, Qty: 10}
+	if _, err := b.SubmitOrder(ctx, buy); err != nil {
+		t.Fatalf("SubmitOrder: %v", err)
+	}
+	if buy.Status != domain.OrderStatusSubmitted {
+		t.Fatalf("buy status = %s, want submitted until next bar", buy.Status)
+	}
+
+	b.ProcessBar(simBar("AAPL", 3, 100, 112, 99, 110, 1000))
+	if buy.Status != domain.OrderStatusFilled || buy.FilledQty != 10 || buy.FilledAvgPrice != 100 {
+		t.Fatalf("buy order = %+v, want filled 10 @ 100", buy)
+	}
+	acct, _ := b.GetAccount(ctx)
+	if acct.Cash != 9000 || acct.Equity != 10100 {
+		t.Errorf("account cash=%v equity=%v, want 9000/10100", acct.Cash, acct.Equity)
+	}
+
+	sell := &domain.Order{Symbol: "AAPL", Side: domain.OrderSideSell, Type: domain.OrderTypeMarket, Qty: 10}
+	if _, err := b.SubmitOrder(ctx, sell); err != nil {
+		t.Fatalf("SubmitOrder: %v", err)
+	}
+	b.ProcessBar(simBar("AAPL", 4, 110, 110, 110, 110, 1000))
+	positions, _ := b.GetPositions(ctx)
+	if len(positions) != 0 {
+		t.Errorf("positions = %v, want none", positions)
+	}
+	fills := b.Fills()
+	if len(fills) != 2 || !fills[1].Closed || fills[1].RealizedPL != 100 {
+		t.Errorf("fills = %+v, want closing fill with +100 P&L", fills)
+	}
+}
+
+func TestSimulatorBrokerFillModel(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name      string
+		cfg       FillConfig
+		order     domain.Order
+		bars      []domain.Bar
+		wantQty   float64
+		wantPrice float64
+		wantState domain.OrderStatus
+	}{
+		{
+			name:    "limit buy fills at limit when range touches it",
+			order:   domain.Order{Type: domain.OrderTypeLimit, Side: domain.OrderSideBuy, LimitPrice: 95, TimeInForce: domain.TimeInForceGTC},
+			bars:    []domain.Bar{simBar("X", 3, 100, 101, 94, 96, 1000)},
+			wantQty: 10, wantPrice: 95, wantState: domain.OrderStatusFilled,
+		},
+		{
+			name:    "limit buy fills at open when gapping through",
+			order:   domain.Order{Type: domain.OrderTypeLimit, Side: domain.OrderSideBuy, LimitPrice: 95, TimeInForce: domain.TimeInForceGTC},
+			bars:    []domain.Bar{simBar("X", 3, 90, 92, 89, 91, 1000)},
+			wantQty: 10, wantPrice: 90, wantState: domain.OrderStatusFilled,
+		},
+		{
+			name:      "DAY limit expires on the next session",
+			order:     domain.Order{Type: domain.OrderTypeLimit, Side: domain.OrderSideBuy, LimitPrice: 80, TimeInForce: domain.TimeInForceDay},
+			bars:      []domain.Bar{simBar("X", 3, 100, 101, 99, 100, 1000), simBar("X", 4, 79, 80, 78, 79, 1000)},
+			wantState: domain.OrderStatusCancelled,
+		},
+		{
+			name:    "sell stop fills at the worse of open and stop with slippage",
+			cfg:     FillConfig{SlippageBps: 100},
+			order:   domain.Order{Type: domain.OrderTypeStop, Side: domain.OrderSideSell, StopPrice: 95, TimeInForce: domain.TimeInForceGTC},
+			bars:    []domain.Bar{simBar("X", 3, 100, 100, 94, 94, 1000)},
+			wantQty: 10, wantPrice: 95 * 0.99, wantState: domain.OrderStatusFilled,
+		},
+		{
+			name:    "stop limit triggers then rests as limit",
+			order:   domain.Order{Type: domain.OrderTypeStopLimit, Side: domain.OrderSideBuy, StopPrice: 105, LimitPrice: 104, TimeInForce: domain.TimeInForceGTC},
+			bars:    []domain.Bar{simBar("X", 3, 106, 107, 105, 106, 1000), simBar("X", 4, 105, 105, 103, 104, 1000)},
+			wantQty: 10, wantPrice: 104, wantState: domain.OrderStatusFilled,
+		},
+		{
+			name:    "stop limit fills at limit on the triggering bar when its range reaches it",
+			order:   domain.Order{Type: domain.OrderTypeStopLimit, Side: domain.OrderSideBuy, StopPrice: 105, LimitPrice: 104, TimeInForce: domain.TimeInForceGTC},
+			bars:    []domain.Bar{simBar("X", 3, 106, 107, 103, 104, 1000)},
+			wantQty: 10, wantPrice: 104, wantState: domain.OrderStatusFilled,
+		},
+		{
+			name:      "stop limit sell triggers but stays above the bar",
+			order:     domain.Order{Type: domain.OrderTypeStopLimit, Side: domain.OrderSideSell, StopPrice: 95, LimitPrice: 96, TimeInForce: domain.TimeInForceGTC},
+			bars:      []domain.Bar{simBar("X", 3, 94, 95, 93, 94, 1000)},
+			wantState: domain.OrderStatusSubmitted,
+		},
+		{
+			name:    "volume cap yields partial fill",
+			cfg:     FillConfig{MaxVolumePct: 0.1},
+			order:   domain.Order{Type: domain.OrderTypeMarket, Side: domain.OrderSideBuy, TimeInForce: domain.TimeInForceGTC},
+			bars:    []domain.Bar{simBar("X", 3, 100, 100, 100, 100, 40)},
+			wantQty: 4, wantPrice: 100, wantState: domain.OrderStatusPartial,
+		},
+		{
+			name:    "IOC cancels unfilled remainder",
+			cfg:     FillConfig{MaxVolumePct: 0.1},
+			order:   domain.Order{Type: domain.OrderTypeMarket, Side: domain.OrderSideBuy, TimeInForce: domain.TimeInForceIOC},
+			bars:    []domain.Bar{simBar("X", 3, 100, 100, 100, 100, 40), simBar("X", 4, 100, 100, 100, 100, 1000)},
+			wantQty: 4, wantPrice: 100, wantState: domain.OrderStatusCancelled,
+		},
+		{
+			name:      "FOK cancels without fill when volume is short",
+			cfg:       FillConfig{MaxVolumePct: 0.1},
+			order:     domain.Order{Type: domain.OrderTypeMarket, Side: domain.OrderSideBuy, TimeInForce: domain.TimeInForceFOK},
+			bars:      []domain.Bar{simBar("X", 3, 100, 100, 100, 100, 40)},
+			wantState: domain.OrderStatusCancelled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewSimulatorBroker()
+			b.SetFillModel(NewDefaultFillModel(tt.cfg))
+			b.SetCash(100000)
+			b.ProcessBar(simBar("X", 2, 100, 100, 100, 100, 1000))
+
+			o := tt.order
+			o.Symbol = "X"
+			o.Qty = 10
+			if _, err := b.SubmitOrder(ctx, &o); err != nil {
+				t.Fatalf("SubmitOrder: %v", err)
+			}
+			for _, bar := range tt.bars {
+				b.ProcessBar(bar)
+			}
+
+			if o.Status != tt.wantState {
+				t.Errorf("status = %s, want %s", o.Status, tt.wantState)
+			}
+			if o.FilledQty != tt.wantQty {
+				t.Errorf("filled qty = %v, want %v", o.FilledQty, tt.wantQty)
+			}
+			if tt.wantQty > 0 && math.Abs(o.FilledAvgPrice-tt.wantPrice) > 1e-9 {
+				t.Errorf("fill price = %v, want %v", o.FilledAvgPrice, tt.wantPrice)
+			}
+		})
+	}
+}
+
+func TestDefaultFillModelCommission(t *testing.T) {
+	m := NewDefaultFillModel(FillConfig{CommissionPerShare: 0.005, CommissionPct: 0.0001, MinCommission: 1})
+	if got := m.Commission(10, 50); got != 1 {
+		t.Errorf("Commission(10, 50) = %v, want minimum 1", got)
+	}
+	if got := m.Commission(1000, 50); math.Abs(got-10) > 1e-9 {
+		t.Errorf("Commission(1000, 50) = %v, want 10", got)
+	}
+}
+
+func TestSimulatorBrokerRealizedPLNetOfCommission(t *testing.T) {
+	ctx := context.Background()
+	b := NewSimulatorBroker()
+	b.SetCash(10000)
+	b.SetFillModel(NewDefaultFillModel(FillConfig{CommissionPerShare: 0.1}))
+	b.ProcessBar(simBar("AAPL", 2, 100, 100, 100, 100, 1000))
+
+	submit := func(side domain.OrderSide, qty float64) {
+		t.Helper()
+		o := &domain.Order{Symbol: "AAPL", Side: side, Type: domain.OrderTypeMarket, Qty: qty}
+		if _, err := b.SubmitOrder(ctx, o); err != nil {
+			t.Fatalf("SubmitOrder: %v", err)
+		}
+	}
+	// Buy 10 @ 100 (fee 1), sell 5 @ 110 (fee 0.5), then sell 10 @ 110
+	// (fee 1): 5 close the long, 5 open a short.
+	submit(domain.OrderSideBuy, 10)
+	b.ProcessBar(simBar("AAPL", 3, 100, 100, 100, 100, 1000))
+	submit(domain.OrderSideSell, 5)
+	b.ProcessBar(simBar("AAPL", 4, 110, 110, 110, 110, 1000))
+	submit(domain.OrderSideSell, 10)
+	b.ProcessBar(simBar("AAPL", 5, 110, 110, 110, 110, 1000))
+	submit(domain.OrderSideBuy, 5)
+	b.ProcessBar(simBar("AAPL", 6, 100, 100, 100, 100, 1000))
+
+	// Each close: 5*10 gross - 0.5 entry fee - 0.5 exit fee = 49.
+	fills := b.Fills()
+	if len(fills) != 4 {
+		t.Fatalf("fills = %+v, want 4", fills)
+	}
+	for i, want := range []float64{0, 49, 49, 49} {
+		if math.Abs(fills[i].RealizedPL-want) > 1e-9 {
+			t.Errorf("fill %d RealizedPL = %v, want %v", i, fills[i].RealizedPL, want)
+		}
+	}
+	acct, _ := b.GetAccount(ctx)
+	if math.Abs(acct.Cash-(10000+3*49)) > 1e-9 {
+		t.Errorf("cash = %v, want %v (net P&L matches cash)", acct.Cash, 10000+3*49)
+	}
+}
+
+func TestAlpacaBrokerAgainstFakeServer(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	fake := alpacatest.NewServer()
+	defer fake.Close()
+	fake.SetAccount(101000, 50000, 100000)
+
+	b := NewAlpacaBroker("key", "secret", fake.URL())
+
+	updates := make(chan domain.Order, 16)
+	go b.StreamOrderUpdates(ctx, func(o domain.Order) { updates <- o })
+	// Wait for the SSE subscription before submitting so no update is missed.
+	if err := fake.WaitForSubscribers(ctx, 1); err != nil {
+		t.Fatalf("order stream never subscribed: %v", err)
+	}
+
+	order := &domain.Order{
+		ID: "local-1", Symbol: "AAPL", Side: domain.OrderSideBuy,
+		Type: domain.OrderTypeLimit, TimeInForce: domain.TimeInForceDay,
+		Qty: 10, LimitPrice: 150,
+	}
+	got, err := b.SubmitOrder(ctx, order)
+	if err != nil {
+		t.Fatalf("SubmitOrder: %v", err)
+	}
+	if got.BrokerOrderID == "" || got.Status != domain.OrderStatusSubmitted {
+		t.Fatalf("submitted order = %+v, want broker ID and submitted status", got)
+	}
+
+	if err := fake.Fill(got.BrokerOrderID, 4, 149); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.Fill(got.BrokerOrderID, 6, 150); err != nil {
+		t.Fatal(err)
+	}
+
+	var last domain.Order
+	for last.Status != domain.OrderStatusFilled {
+		select {
+		case last = <-updates:
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for fill; last update %+v", last)
+		}
+	}
+	if last.ID != "local-1" || last.FilledQty != 10 || math.Abs(last.FilledAvgPrice-149.6) > 1e-9 {
+		t.Errorf("final update = %+v, want local-1 filled 10 @ 149.6", last)
+	}
+
+	positions, err := b.GetPositions(ctx)
+	if err != nil {
+		t.Fatalf("GetPositions: %v", err)
+	}
+	if len(positions) != 1 || positions[0].Symbol != "AAPL" || positions[0].Qty != 10 {
+		t.Errorf("positions = %+v, want 10 AAPL", positions)
+	}
+
+	acct, err := b.GetAccount(ctx)
+	if err != nil {
+		t.Fatalf("GetAccount: %v", err)
+	}
+	if acct.Equity != 101000 || acct.DailyPL != 1000 {
+		t.Errorf("account = %+v, want equity 101000, daily P&L 1000", acct)
+	}
+
+	// Cancelling a filled order fails; cancelling an open one by local ID works.
+	if err := b.CancelOrder(ctx, "local-1"); err == nil {
+		t.Error("expected error cancelling a filled order")
+	}
+	open, err := b.SubmitOrder(ctx, &domain.Order{
+		ID: "local-2", Symbol: "MSFT", Side: domain.OrderSideSell,
+		Type: domain.OrderTypeMarket, Qty: 5,
+	})
+	if err != nil {
+		t.Fatalf("SubmitOrder: %v", err)
+	}
+	if err := b.CancelOrder(ctx, "local-2"); err != nil {
+		t.Fatalf("CancelOrder: %v", err)
+	}
+	refreshed, err := b.GetOrder(ctx, open.ID)
+	if err != nil {
+		t.Fatalf("GetOrder: %v", err)
+	}
+	if refreshed.Status != domain.OrderStatusCancelled {
+		t.Errorf("status after cancel = %s, want cancelled", refreshed.Status)
+	}
+}
+
+func TestMapAlpacaStatus(t *testing.T) {
+	tests := map[string]domain.OrderStatus{
+		"new":              domain.OrderStatusSubmitted,
+		"accepted":         domain.OrderStatusSubmitted,
+		"partially_filled": domain.OrderStatusPartial,
+		"filled":           domain.OrderStatusFilled,
+		"canceled":         domain.OrderStatusCancelled,
+		"expired":          domain.OrderStatusCancelled,
+		"rejected":         domain.OrderStatusRejected,
+	}
+	for in, want := range tests {
+		if got := mapAlpacaStatus(in); got != want {
+			t.Errorf("mapAlpacaStatus(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
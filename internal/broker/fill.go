@@ -0,0 +1,201 @@
+package broker
+
+import (
+	"math"
+	"time"
+
+	"jupitor/internal/domain"
+)
+
+// Tick is the market event an open simulated order is matched against: a
+// bar, or a single trade expressed as a bar with O=H=L=C=price.
+type Tick struct {
+	Symbol    string
+	Timestamp time.Time
+	Open      float64
+	High      float64
+	Low       float64
+	Close     float64
+	Volume    int64
+}
+
+// TickFromBar converts a bar into a Tick.
+func TickFromBar(bar domain.Bar) Tick {
+	return Tick{
+		Symbol:    bar.Symbol,
+		Timestamp: bar.Timestamp,
+		Open:      bar.Open,
+		High:      bar.High,
+		Low:       bar.Low,
+		Close:     bar.Close,
+		Volume:    bar.Volume,
+	}
+}
+
+// TickFromTrade converts a trade print into a Tick.
+func TickFromTrade(t domain.Trade) Tick {
+	return Tick{
+		Symbol:    t.Symbol,
+		Timestamp: t.Timestamp,
+		Open:      t.Price,
+		High:      t.Price,
+		Low:       t.Price,
+		Close:     t.Price,
+		Volume:    t.Size,
+	}
+}
+
+// FillModel decides how simulated orders execute. The SimulatorBroker owns
+// order state and time-in-force handling; the model only prices executions,
+// limits how much volume a tick can absorb, and charges commissions.
+type FillModel interface {
+	// Price returns the execution price for order against tick, or ok=false
+	// if the order's price conditions are not met. triggered carries the
+	// stop-trigger state of stop and stop-limit orders between ticks.
+	Price(order *domain.Order, tick Tick, triggered *bool) (price float64, ok bool)
+
+	// Liquidity returns the total quantity that may execute against tick
+	// across all orders; +Inf means unlimited.
+	Liquidity(tick Tick) float64
+
+	// Commission returns the fee charged for executing qty at price.
+	Commission(qty, price float64) float64
+}
+
+// FillConfig parameterises the default fill model. The zero value fills at
+// the raw tick prices with no costs and no volume cap.
+type FillConfig struct {
+	SlippageBps        float64 // adverse slippage applied to market and stop fills
+	CommissionPerShare float64 // fee per share executed
+	CommissionPct      float64 // fee as a fraction of notional (0.001 = 10 bps)
+	MinCommission      float64 // minimum fee per execution
+	MaxVolumePct       float64 // max fraction of tick volume executable (0 = no cap)
+}
+
+// Compile-time interface check.
+var _ FillModel = (*DefaultFillModel)(nil)
+
+// DefaultFillModel executes orders against OHLC ticks:
+//
+//   - market: fills at the open plus slippage
+//   - limit: fills at the open if it is already through the limit, otherwise
+//     at the limit price if the tick's range reaches it
+//   - stop: triggers when the range touches the stop, fills at the worse of
+//     open and stop plus slippage
+//   - stop_limit: triggers like a stop, then behaves as a limit order
+type DefaultFillModel struct {
+	cfg FillConfig
+}
+
+// NewDefaultFillModel creates a DefaultFillModel with the given parameters.
+func NewDefaultFillModel(cfg FillConfig) *DefaultFillModel {
+	return &DefaultFillModel{cfg: cfg}
+}
+
+// Price implements FillModel.
+func (m *DefaultFillModel) Price(o *domain.Order, t Tick, triggered *bool) (float64, bool) {
+	buy := o.Side == domain.OrderSideBuy
+
+	switch o.Type {
+	case domain.OrderTypeLimit:
+		return limitPrice(buy, o.LimitPrice, t)
+
+	case domain.OrderTypeStop:
+		if !*triggered && !stopTouched(buy, o.StopPrice, t) {
+			return 0, false
+		}
+		*triggered = true
+		p := t.Open
+		if buy {
+			p = max(p, o.StopPrice)
+		} else {
+			p = min(p, o.StopPrice)
+		}
+		return m.slip(buy, p), true
+
+	case domain.OrderTypeStopLimit:
+		if !*triggered {
+			if !stopTouched(buy, o.StopPrice, t) {
+				return 0, false
+			}
+			*triggered = true
+			// On the triggering tick the order becomes live at the stop.
+			// If that is already beyond the limit, it can still fill at
+			// the limit when the rest of the bar's range reaches it.
+			p := t.Open
+			if buy {
+				p = max(p, o.StopPrice)
+				if p <= o.LimitPrice {
+					return p, true
+				}
+				if t.Low <= o.LimitPrice {
+					return o.LimitPrice, true
+				}
+			} else {
+				p = min(p, o.StopPrice)
+				if p >= o.LimitPrice {
+					return p, true
+				}
+				if t.High >= o.LimitPrice {
+					return o.LimitPrice, true
+				}
+			}
+			return 0, false
+		}
+		return limitPrice(buy, o.LimitPrice, t)
+
+	default:
+		return m.slip(buy, t.Open), true
+	}
+}
+
+// Liquidity implements FillModel.
+func (m *DefaultFillModel) Liquidity(t Tick) float64 {
+	if m.cfg.MaxVolumePct <= 0 {
+		return math.Inf(1)
+	}
+	return math.Floor(float64(t.Volume) * m.cfg.MaxVolumePct)
+}
+
+// Commission implements FillModel.
+func (m *DefaultFillModel) Commission(qty, price float64) float64 {
+	fee := qty*m.cfg.CommissionPerShare + qty*price*m.cfg.CommissionPct
+	return max(fee, m.cfg.MinCommission)
+}
+
+// slip moves price against the order side by the configured basis points.
+func (m *DefaultFillModel) slip(buy bool, price float64) float64 {
+	adj := price * m.cfg.SlippageBps / 10000
+	if buy {
+		return price + adj
+	}
+	return price - adj
+}
+
+// limitPrice returns the fill price of a limit order against t.
+func limitPrice(buy bool, limit float64, t Tick) (float64, bool) {
+	if buy {
+		if t.Open <= limit {
+			return t.Open, true
+		}
+		if t.Low <= limit {
+			return limit, true
+		}
+		return 0, false
+	}
+	if t.Open >= limit {
+		return t.Open, true
+	}
+	if t.High >= limit {
+		return limit, true
+	}
+	return 0, false
+}
+
+// stopTouched reports whether t's range reaches the stop price.
+func stopTouched(buy bool, stop float64, t Tick) bool {
+	if buy {
+		return t.High >= stop
+	}
+	return t.Low <= stop
+}
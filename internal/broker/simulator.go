@@ -2,63 +2,488 @@ package broker
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
 
 	"jupitor/internal/domain"
 )
 
-// Compile-time interface check.
+// Compile-time interface checks.
 var _ Broker = (*SimulatorBroker)(nil)
+var _ OrderQuerier = (*SimulatorBroker)(nil)
+
+// Fill records a single execution against a simulated order.
+type Fill struct {
+	OrderID    string
+	Symbol     string
+	Side       domain.OrderSide
+	Qty        float64
+	Price      float64
+	Commission float64
+	Timestamp  time.Time
+	RealizedPL float64 // net P&L realised by the part of the fill that reduced a position
+	Closed     bool    // true if the fill reduced an existing position
+}
 
 // SimulatorBroker implements the Broker interface for paper trading and
 // backtesting. It tracks positions and orders in memory without making
-// external API calls.
+// external API calls. Submitted orders rest until the caller feeds the next
+// bar or trade via ProcessBar/ProcessTrade, where a FillModel decides whether
+// and at what price they execute.
 type SimulatorBroker struct {
+	mu        sync.Mutex
+	cash      float64
 	positions map[string]*domain.Position
 	orders    map[string]*domain.Order
+	open      []*simOrder // resting orders in submission order
+	prices    map[string]float64
+	fills     []Fill
+	entryFees map[string]float64 // commissions paid opening each position, not yet realised
+	nextID    int64
+	now       time.Time
+	model     FillModel
+
+	dayStart       string  // "YYYY-MM-DD" of the current simulated day
+	dayStartEquity float64 // equity at the start of the current simulated day
+}
+
+// simOrder is a resting order plus the matching state the simulator keeps
+// for it between ticks.
+type simOrder struct {
+	order     *domain.Order
+	triggered bool   // stop / stop-limit has been triggered
+	activeDay string // session date a DAY order is valid for, set on first tick
 }
 
 // NewSimulatorBroker creates a new SimulatorBroker with empty position and
-// order maps.
+// order maps and a cost-free DefaultFillModel.
 func NewSimulatorBroker() *SimulatorBroker {
 	return &SimulatorBroker{
 		positions: make(map[string]*domain.Position),
 		orders:    make(map[string]*domain.Order),
+		prices:    make(map[string]float64),
+		entryFees: make(map[string]float64),
+		model:     NewDefaultFillModel(FillConfig{}),
 	}
 }
 
+// SetFillModel replaces the model used to execute resting orders.
+func (b *SimulatorBroker) SetFillModel(m FillModel) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.model = m
+}
+
 // Name returns "simulator".
 func (b *SimulatorBroker) Name() string {
 	return "simulator"
 }
 
-// SubmitOrder records the order in memory and simulates immediate execution.
+// SetCash sets the simulated cash balance, typically the initial capital of a
+// backtest or paper session.
+func (b *SimulatorBroker) SetCash(cash float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cash = cash
+	b.dayStartEquity = b.equityLocked()
+}
+
+// UpdatePrice marks symbol to price at time ts without matching orders.
+func (b *SimulatorBroker) UpdatePrice(symbol string, price float64, ts time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advanceLocked(ts)
+	b.prices[symbol] = price
+	b.markLocked(symbol)
+}
+
+// ProcessBar matches resting orders for the bar's symbol against it and then
+// marks the symbol to the bar's close.
+func (b *SimulatorBroker) ProcessBar(bar domain.Bar) {
+	b.processTick(TickFromBar(bar))
+}
+
+// ProcessTrade matches resting orders for the trade's symbol against the
+// print and then marks the symbol to the trade price.
+func (b *SimulatorBroker) ProcessTrade(trade domain.Trade) {
+	b.processTick(TickFromTrade(trade))
+}
+
+// Price returns the latest price seen for symbol.
+func (b *SimulatorBroker) Price(symbol string) (float64, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	p, ok := b.prices[symbol]
+	return p, ok
+}
+
+// SubmitOrder records the order in memory as a resting order. It executes
+// against the next bar or trade for its symbol.
 func (b *SimulatorBroker) SubmitOrder(_ context.Context, order *domain.Order) (*domain.Order, error) {
-	// TODO: simulate order fill logic — update order status, adjust positions
+	if order.Qty <= 0 {
+		return nil, fmt.Errorf("order qty must be positive, got %v", order.Qty)
+	}
+	switch order.Type {
+	case domain.OrderTypeLimit, domain.OrderTypeStopLimit:
+		if order.LimitPrice <= 0 {
+			return nil, fmt.Errorf("%s order requires a limit price", order.Type)
+		}
+	}
+	switch order.Type {
+	case domain.OrderTypeStop, domain.OrderTypeStopLimit:
+		if order.StopPrice <= 0 {
+			return nil, fmt.Errorf("%s order requires a stop price", order.Type)
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if order.ID == "" {
+		b.nextID++
+		order.ID = fmt.Sprintf("sim-%d", b.nextID)
+	}
+	if _, dup := b.orders[order.ID]; dup {
+		return nil, fmt.Errorf("duplicate order id %q", order.ID)
+	}
+	if order.BrokerOrderID == "" {
+		order.BrokerOrderID = order.ID
+	}
+	if order.CreatedAt.IsZero() {
+		order.CreatedAt = b.now
+	}
+	order.UpdatedAt = b.now
+	order.Status = domain.OrderStatusSubmitted
 	b.orders[order.ID] = order
+	b.open = append(b.open, &simOrder{order: order})
 	return order, nil
 }
 
 // CancelOrder marks the specified order as cancelled in the in-memory store.
 func (b *SimulatorBroker) CancelOrder(_ context.Context, orderID string) error {
-	// TODO: look up order, verify it is cancellable, set status to cancelled
-	if o, ok := b.orders[orderID]; ok {
-		o.Status = domain.OrderStatusCancelled
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	o, ok := b.orders[orderID]
+	if !ok {
+		return fmt.Errorf("order %q not found", orderID)
+	}
+	switch o.Status {
+	case domain.OrderStatusFilled, domain.OrderStatusCancelled, domain.OrderStatusRejected:
+		return fmt.Errorf("order %q is %s and cannot be cancelled", orderID, o.Status)
 	}
+	o.Status = domain.OrderStatusCancelled
+	o.UpdatedAt = b.now
+	b.pruneLocked()
 	return nil
 }
 
-// GetPositions returns all simulated positions.
+// GetPositions returns all simulated positions sorted by symbol.
 func (b *SimulatorBroker) GetPositions(_ context.Context) ([]domain.Position, error) {
-	// TODO: convert map to slice with proper deep copies
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
 	positions := make([]domain.Position, 0, len(b.positions))
 	for _, p := range b.positions {
 		positions = append(positions, *p)
 	}
+	sort.Slice(positions, func(i, j int) bool {
+		return positions[i].Symbol < positions[j].Symbol
+	})
 	return positions, nil
 }
 
-// GetAccount returns simulated account information.
+// GetAccount returns simulated account information computed from cash and
+// the marked value of open positions.
 func (b *SimulatorBroker) GetAccount(_ context.Context) (*domain.AccountInfo, error) {
-	// TODO: compute equity, cash, buying power from simulated state
-	return &domain.AccountInfo{}, nil
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	equity := b.equityLocked()
+	info := &domain.AccountInfo{
+		Equity:         equity,
+		Cash:           b.cash,
+		BuyingPower:    max(b.cash, 0),
+		PortfolioValue: equity,
+		DailyPL:        equity - b.dayStartEquity,
+	}
+	if b.dayStartEquity != 0 {
+		info.DailyPLPct = info.DailyPL / b.dayStartEquity
+	}
+	return info, nil
+}
+
+// OpenOrders returns copies of all resting (submitted or partially filled)
+// orders in submission order.
+func (b *SimulatorBroker) OpenOrders() []domain.Order {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make([]domain.Order, len(b.open))
+	for i, so := range b.open {
+		out[i] = *so.order
+	}
+	return out
+}
+
+// GetOrder returns a copy of the order with the given ID.
+func (b *SimulatorBroker) GetOrder(_ context.Context, orderID string) (*domain.Order, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	o, ok := b.orders[orderID]
+	if !ok {
+		return nil, fmt.Errorf("order %q not found", orderID)
+	}
+	out := *o
+	return &out, nil
+}
+
+// ListOpenOrders implements OrderQuerier; it is equivalent to OpenOrders.
+func (b *SimulatorBroker) ListOpenOrders(_ context.Context) ([]domain.Order, error) {
+	return b.OpenOrders(), nil
+}
+
+// Fills returns a copy of all executions in the order they occurred.
+func (b *SimulatorBroker) Fills() []Fill {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make([]Fill, len(b.fills))
+	copy(out, b.fills)
+	return out
+}
+
+// ---------------------------------------------------------------------------
+// Internal helpers (caller holds b.mu)
+// ---------------------------------------------------------------------------
+
+// advanceLocked moves the simulated clock forward and rolls the daily P&L
+// baseline when a new calendar day starts.
+func (b *SimulatorBroker) advanceLocked(ts time.Time) {
+	if ts.After(b.now) {
+		b.now = ts
+	}
+	day := b.now.Format("2006-01-02")
+	if day != b.dayStart {
+		b.dayStart = day
+		b.dayStartEquity = b.equityLocked()
+	}
+}
+
+// processTick runs one market event through the resting orders for its
+// symbol, applying time-in-force rules:
+//
+//   - DAY orders live for the session date of the first tick they see
+//   - GTC orders rest until filled or cancelled
+//   - IOC orders execute what they can on the first tick, rest is cancelled
+//   - FOK orders execute in full on the first tick or are cancelled
+//
+// Executions share the tick's liquidity in submission order.
+func (b *SimulatorBroker) processTick(t Tick) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advanceLocked(t.Timestamp)
+
+	day := t.Timestamp.Format("2006-01-02")
+	liquidity := b.model.Liquidity(t)
+	for _, so := range b.open {
+		o := so.order
+		if o.Symbol != t.Symbol {
+			continue
+		}
+		if o.TimeInForce == domain.TimeInForceDay || o.TimeInForce == "" {
+			if so.activeDay == "" {
+				so.activeDay = day
+			} else if so.activeDay != day {
+				b.cancelLocked(o)
+				continue
+			}
+		}
+
+		remaining := o.Qty - o.FilledQty
+		price, ok := b.model.Price(o, t, &so.triggered)
+		qty := min(remaining, liquidity)
+		if !ok {
+			qty = 0
+		}
+
+		switch o.TimeInForce {
+		case domain.TimeInForceFOK:
+			if qty < remaining {
+				b.cancelLocked(o)
+				continue
+			}
+		case domain.TimeInForceIOC:
+			if qty > 0 {
+				b.fillLocked(o, qty, price)
+				liquidity -= qty
+			}
+			if o.Status != domain.OrderStatusFilled {
+				b.cancelLocked(o)
+			}
+			continue
+		}
+		if qty > 0 {
+			b.fillLocked(o, qty, price)
+			liquidity -= qty
+		}
+	}
+	b.pruneLocked()
+
+	b.prices[t.Symbol] = t.Close
+	b.markLocked(t.Symbol)
+}
+
+// cancelLocked marks a resting order cancelled; pruneLocked removes it.
+func (b *SimulatorBroker) cancelLocked(o *domain.Order) {
+	o.Status = domain.OrderStatusCancelled
+	o.UpdatedAt = b.now
+}
+
+// pruneLocked drops orders that are no longer resting from the open list.
+func (b *SimulatorBroker) pruneLocked() {
+	kept := b.open[:0]
+	for _, so := range b.open {
+		switch so.order.Status {
+		case domain.OrderStatusSubmitted, domain.OrderStatusPartial:
+			kept = append(kept, so)
+		}
+	}
+	clear(b.open[len(kept):])
+	b.open = kept
+}
+
+// fillLocked executes qty of order at price, updating cash (including
+// commission), the position and the order's fill fields.
+func (b *SimulatorBroker) fillLocked(order *domain.Order, qty, price float64) {
+	commission := b.model.Commission(qty, price)
+	b.cash -= commission
+	realized, closed := b.applyFillLocked(order.Symbol, order.Side, qty, price, commission)
+
+	notional := order.FilledAvgPrice*order.FilledQty + price*qty
+	order.FilledQty += qty
+	order.FilledAvgPrice = notional / order.FilledQty
+	if order.FilledQty >= order.Qty {
+		order.Status = domain.OrderStatusFilled
+	} else {
+		order.Status = domain.OrderStatusPartial
+	}
+	order.UpdatedAt = b.now
+
+	b.fills = append(b.fills, Fill{
+		OrderID:    order.ID,
+		Symbol:     order.Symbol,
+		Side:       order.Side,
+		Qty:        qty,
+		Price:      price,
+		Commission: commission,
+		Timestamp:  b.now,
+		RealizedPL: realized,
+		Closed:     closed,
+	})
+}
+
+// applyFillLocked adjusts cash and the symbol's position for a fill and
+// returns the realised P&L of any portion that reduced the position, net of
+// commissions: the fill's commission is split pro rata between the closing
+// and opening parts, and the closing part also bears its share of the
+// commissions paid to open the position.
+func (b *SimulatorBroker) applyFillLocked(symbol string, side domain.OrderSide, qty, price, commission float64) (realized float64, closed bool) {
+	delta := qty
+	if side == domain.OrderSideSell {
+		delta = -qty
+	}
+	b.cash -= delta * price
+
+	pos := b.positions[symbol]
+	cur, avg := 0.0, 0.0
+	if pos != nil {
+		cur, avg = signedQty(pos), pos.AvgEntryPrice
+	}
+
+	next := cur + delta
+	switch {
+	case cur == 0 || (cur > 0) == (delta > 0):
+		// Opening or adding to a position.
+		avg = (avg*abs(cur) + price*abs(delta)) / abs(next)
+		b.entryFees[symbol] += commission
+	default:
+		// Reducing, closing or flipping.
+		closing := min(abs(delta), abs(cur))
+		if cur > 0 {
+			realized = closing * (price - avg)
+		} else {
+			realized = closing * (avg - price)
+		}
+		entry := b.entryFees[symbol] * closing / abs(cur)
+		realized -= entry + commission*closing/abs(delta)
+		b.entryFees[symbol] -= entry
+		closed = true
+		if abs(delta) > abs(cur) {
+			avg = price // flipped: remainder opens at the fill price
+			b.entryFees[symbol] = commission * (abs(delta) - closing) / abs(delta)
+		}
+	}
+
+	if next == 0 {
+		delete(b.positions, symbol)
+		delete(b.entryFees, symbol)
+		return realized, closed
+	}
+	if pos == nil || (cur > 0) != (next > 0) {
+		pos = &domain.Position{Symbol: symbol, OpenedAt: b.now}
+		b.positions[symbol] = pos
+	}
+	pos.Qty = abs(next)
+	pos.AvgEntryPrice = avg
+	pos.Side = domain.PositionSideLong
+	if next < 0 {
+		pos.Side = domain.PositionSideShort
+	}
+	b.markLocked(symbol)
+	return realized, closed
+}
+
+// markLocked revalues the symbol's position at the latest price.
+func (b *SimulatorBroker) markLocked(symbol string) {
+	pos := b.positions[symbol]
+	price, ok := b.prices[symbol]
+	if pos == nil || !ok {
+		return
+	}
+	q := signedQty(pos)
+	pos.MarketValue = q * price
+	pos.UnrealizedPL = q * (price - pos.AvgEntryPrice)
+	if cost := pos.Qty * pos.AvgEntryPrice; cost != 0 {
+		pos.UnrealizedPLPct = pos.UnrealizedPL / cost
+	}
+	pos.UpdatedAt = b.now
+}
+
+// equityLocked returns cash plus the marked value of all positions.
+func (b *SimulatorBroker) equityLocked() float64 {
+	equity := b.cash
+	for sym, pos := range b.positions {
+		if price, ok := b.prices[sym]; ok {
+			equity += signedQty(pos) * price
+		} else {
+			equity += signedQty(pos) * pos.AvgEntryPrice
+		}
+	}
+	return equity
+}
+
+// signedQty returns the position quantity, negative for shorts.
+func signedQty(p *domain.Position) float64 {
+	if p.Side == domain.PositionSideShort {
+		return -p.Qty
+	}
+	return p.Qty
+}
+
+func abs(x float64) float64 {
+	if x < 0 {
+		return -x
+	}
+	return x
 }
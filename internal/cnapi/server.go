@@ -16,7 +16,9 @@ import (
 
 	"golang.org/x/sync/errgroup"
 
+	"jupitor/internal/domain"
 	"jupitor/internal/store"
+	"jupitor/internal/util"
 )
 
 // CNServer serves the CN A-share heatmap API.
@@ -25,6 +27,8 @@ type CNServer struct {
 	referenceDir string
 	store        *store.ParquetStore
 	log          *slog.Logger
+	market       *util.MarketInfo
+	calendar     *util.TradingCalendar
 	cache        sync.Map // date → *CNHeatmapResponse
 	dates        []string // cached date list
 	datesMu      sync.RWMutex
@@ -35,12 +39,22 @@ type CNServer struct {
 
 // NewCNServer creates a new CN server.
 func NewCNServer(dataDir, referenceDir string, store *store.ParquetStore, log *slog.Logger) *CNServer {
-	return &CNServer{
+	s := &CNServer{
 		dataDir:      dataDir,
 		referenceDir: referenceDir,
 		store:        store,
 		log:          log,
 	}
+	mi, _ := util.DefaultMarketRegistry().Get(domain.MarketCN)
+	s.SetMarket(mi)
+	return s
+}
+
+// SetMarket sets the market definition served by /api/cn/market, e.g. from
+// config/markets.yaml. The default is the built-in CN market.
+func (s *CNServer) SetMarket(mi *util.MarketInfo) {
+	s.market = mi
+	s.calendar = mi.Calendar()
 }
 
 // Init loads the date list and industry map. Call before serving.
@@ -72,6 +86,7 @@ func (s *CNServer) Handler() http.Handler {
 	mux := http.NewServeMux()
 	mux.HandleFunc("GET /api/cn/heatmap", s.handleHeatmap)
 	mux.HandleFunc("GET /api/cn/dates", s.handleDates)
+	mux.HandleFunc("GET /api/cn/market", s.handleMarket)
 	mux.HandleFunc("GET /api/cn/symbol-history/{symbol}", s.handleSymbolHistory)
 	mux.HandleFunc("GET /api/cn/industry-filter", s.handleGetIndustryFilter)
 	mux.HandleFunc("PUT /api/cn/industry-filter", s.handlePutIndustryFilter)
@@ -89,6 +104,34 @@ func (s *CNServer) handleDates(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, CNDatesResponse{Dates: dates})
 }
 
+func (s *CNServer) handleMarket(w http.ResponseWriter, r *http.Request) {
+	mi := s.market
+	h := mi.Hours
+	clock := func(d time.Duration) string {
+		return fmt.Sprintf("%02d:%02d", int(d/time.Hour), int(d%time.Hour/time.Minute))
+	}
+	var sessions []CNSessionJSON
+	if h.BreakStart > 0 {
+		sessions = []CNSessionJSON{{Open: clock(h.Open), Close: clock(h.BreakStart)}, {Open: clock(h.BreakEnd), Close: clock(h.Close)}}
+	} else {
+		sessions = []CNSessionJSON{{Open: clock(h.Open), Close: clock(h.Close)}}
+	}
+
+	now := time.Now()
+	resp := CNMarketResponse{
+		Market:   string(mi.Market),
+		Name:     mi.Name,
+		Currency: mi.Currency,
+		Timezone: mi.Location.String(),
+		Sessions: sessions,
+		IsOpen:   s.calendar.IsMarketOpen(now),
+	}
+	if next := s.calendar.NextOpen(now); !next.IsZero() {
+		resp.NextOpen = next.In(mi.Location).Format(time.RFC3339)
+	}
+	writeJSON(w, resp)
+}
+
 func (s *CNServer) handleHeatmap(w http.ResponseWriter, r *http.Request) {
 	date := r.URL.Query().Get("date")
 	if date == "" {
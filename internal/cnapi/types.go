@@ -33,6 +33,23 @@ type CNDatesResponse struct {
 	Dates []string `json:"dates"`
 }
 
+// CNSessionJSON is one continuous trading session in market-local "HH:MM".
+type CNSessionJSON struct {
+	Open  string `json:"open"`
+	Close string `json:"close"`
+}
+
+// CNMarketResponse describes the market's configuration and current status.
+type CNMarketResponse struct {
+	Market   string          `json:"market"`
+	Name     string          `json:"name"`
+	Currency string          `json:"currency"`
+	Timezone string          `json:"timezone"`
+	Sessions []CNSessionJSON `json:"sessions"`
+	IsOpen   bool            `json:"isOpen"`
+	NextOpen string          `json:"nextOpen,omitempty"` // RFC 3339, market-local
+}
+
 // CNSymbolDay is one trading day in symbol history.
 type CNSymbolDay struct {
 	Date   string  `json:"date"`
@@ -0,0 +1,133 @@
+// Package conditions decodes the SIP (CTA/UTP) trade condition codes carried
+// in store.TradeRecord.Conditions, and the correction status in
+// TradeRecord.Update, into typed flags, and defines the named filter
+// profiles that decide which trades count in stock-trades files, the live
+// stream and the dashboard.
+package conditions
+
+import (
+	"fmt"
+	"strings"
+
+	"jupitor/internal/store"
+)
+
+// Flags is a set of trade attributes decoded from condition codes. A regular
+// sale (" " or "@") has no flags.
+type Flags uint32
+
+const (
+	IntermarketSweep      Flags = 1 << iota // F
+	ExtendedHours                           // T, U: reported outside regular hours (Form T)
+	OddLot                                  // I: fewer than 100 shares
+	Late                                    // L, P, Z, U: reported late or at a prior reference price
+	OutOfSequence                           // Z, U: reported out of sequence
+	DerivativelyPriced                      // 4
+	AveragePrice                            // W
+	Contingent                              // V, 7: contingent or qualified contingent trade
+	NonStandardSettlement                   // C, N, R: cash, next day or seller's option
+	Auction                                 // O, 5, 6, X: opening, reopening, closing or cross prints
+	OfficialPrice                           // Q, M, 9: market center official open/close, not a trade
+	Other                                   // any other defined code (bunched, split, stopped, ...)
+	Unknown                                 // a code this package does not know
+	Cancelled                               // Update "canceled" or "incorrect"
+	Corrected                               // Update "corrected"
+)
+
+// allFlags is the union of every flag.
+const allFlags = Corrected<<1 - 1
+
+var flagNames = []string{
+	"intermarket_sweep", "extended_hours", "odd_lot", "late", "out_of_sequence",
+	"derivatively_priced", "average_price", "contingent", "non_standard_settlement",
+	"auction", "official_price", "other", "unknown", "cancelled", "corrected",
+}
+
+// codeFlags maps each SIP condition code to its flags.
+var codeFlags = map[string]Flags{
+	" ": 0, "@": 0, // regular sale
+	"F": IntermarketSweep,
+	"T": ExtendedHours,
+	"U": ExtendedHours | Late | OutOfSequence,
+	"I": OddLot,
+	"L": Late,
+	"P": Late,
+	"Z": Late | OutOfSequence,
+	"4": DerivativelyPriced,
+	"W": AveragePrice,
+	"V": Contingent, "7": Contingent,
+	"C": NonStandardSettlement, "N": NonStandardSettlement, "R": NonStandardSettlement,
+	"O": Auction, "5": Auction, "6": Auction, "X": Auction,
+	"Q": OfficialPrice, "M": OfficialPrice, "9": OfficialPrice,
+	"A": Other, "B": Other, "D": Other, "E": Other, "G": Other, "H": Other,
+	"K": Other, "S": Other, "Y": Other, "1": Other, "8": Other,
+}
+
+// Has reports whether f contains any of the flags in g.
+func (f Flags) Has(g Flags) bool { return f&g != 0 }
+
+// String returns the flag names joined with "|", or "regular" for none.
+func (f Flags) String() string {
+	if f == 0 {
+		return "regular"
+	}
+	var names []string
+	for i, name := range flagNames {
+		if f&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// ParseFlags returns the union of the named flags, using the names printed
+// by Flags.String (e.g. "odd_lot", "late").
+func ParseFlags(names []string) (Flags, error) {
+	var f Flags
+	for _, name := range names {
+		i := indexOf(flagNames, name)
+		if i < 0 {
+			return 0, fmt.Errorf("unknown trade condition flag %q", name)
+		}
+		f |= 1 << i
+	}
+	return f, nil
+}
+
+func indexOf(names []string, name string) int {
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Code returns the flags of a single condition code.
+func Code(code string) Flags {
+	if f, ok := codeFlags[code]; ok {
+		return f
+	}
+	return Unknown
+}
+
+// Decode returns the flags of a comma-joined condition list and an update
+// (correction) status, as stored in a TradeRecord.
+func Decode(conds, update string) Flags {
+	var f Flags
+	if conds != "" {
+		for c := range strings.SplitSeq(conds, ",") {
+			f |= Code(c)
+		}
+	}
+	switch strings.ToLower(update) {
+	case "canceled", "cancelled", "incorrect":
+		f |= Cancelled
+	case "corrected":
+		f |= Corrected
+	}
+	return f
+}
+
+// DecodeRecord returns the flags of a trade record.
+func DecodeRecord(r *store.TradeRecord) Flags { return Decode(r.Conditions, r.Update) }
@@ -0,0 +1,130 @@
+package conditions
+
+import (
+	"strings"
+	"testing"
+
+	"jupitor/internal/store"
+)
+
+func TestDecode(t *testing.T) {
+	for _, tc := range []struct {
+		conds, update string
+		want          Flags
+	}{
+		{"", "", 0},
+		{"@", "", 0},
+		{"@,F,T", "", IntermarketSweep | ExtendedHours},
+		{"@,I", "", OddLot},
+		{"Z", "", Late | OutOfSequence},
+		{"@,4", "canceled", DerivativelyPriced | Cancelled},
+		{"@", "corrected", Corrected},
+		{"@", "incorrect", Cancelled},
+		{"@,&", "", Unknown},
+		{"@,", "", Unknown},
+	} {
+		if got := Decode(tc.conds, tc.update); got != tc.want {
+			t.Errorf("Decode(%q, %q) = %v, want %v", tc.conds, tc.update, got, tc.want)
+		}
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	f, err := ParseFlags([]string{"odd_lot", "late"})
+	if err != nil || f != OddLot|Late {
+		t.Fatalf("ParseFlags = %v, %v", f, err)
+	}
+	if got := f.String(); got != "odd_lot|late" {
+		t.Errorf("String() = %q", got)
+	}
+	if _, err := ParseFlags([]string{"odd-lot"}); err == nil {
+		t.Error("ParseFlags accepted an unknown name")
+	}
+	// Every flag has a name.
+	if back, _ := ParseFlags(strings.Split(allFlags.String(), "|")); back != allFlags {
+		t.Errorf("allFlags round trip = %v", back)
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	trade := func(ex, conds, update string) *store.TradeRecord {
+		return &store.TradeRecord{Symbol: "AAPL", Exchange: ex, Conditions: conds, Update: update, Price: 10, Size: 200}
+	}
+	sized := func(price float64, size int64, conds string) *store.TradeRecord {
+		return &store.TradeRecord{Symbol: "AAPL", Exchange: "Q", Conditions: conds, Price: price, Size: size}
+	}
+	for _, tc := range []struct {
+		r                                *store.TradeRecord
+		standard, strict, oddLots, broad bool
+	}{
+		// Standard matches the filter stock-trades files always used.
+		{trade("Q", "", ""), true, true, true, true},
+		{trade("Q", " ", ""), true, true, true, true},
+		{trade("Q", "@,F,T", ""), true, true, true, true},
+		{trade("D", "@", ""), false, false, false, true},
+		{trade("Q", "@,I", ""), false, false, true, true},
+		{trade("Q", "@,Z", ""), false, false, false, true},
+		{trade("Q", "@,", ""), false, false, false, true},
+		{trade("Q", "@", "canceled"), true, false, true, false},
+		{trade("Q", "@,M", ""), false, false, false, false},
+		// Only odd-lots and broad keep trades of 100 shares or fewer, or
+		// under $100.
+		{sized(10, 100, "@"), false, false, true, true},
+		{sized(10, 40, "@,I"), false, false, true, true},
+		{sized(0.5, 150, "@"), false, false, true, true},
+		{sized(1, 101, "@"), true, true, true, true},
+	} {
+		for _, p := range []struct {
+			p    Profile
+			want bool
+		}{{Standard, tc.standard}, {Strict, tc.strict}, {OddLots, tc.oddLots}, {Broad, tc.broad}} {
+			if got := p.p.Allow(tc.r); got != p.want {
+				t.Errorf("%s.Allow(%s %q %q %v@%v) = %v, want %v", p.p.Name, tc.r.Exchange, tc.r.Conditions, tc.r.Update,
+					tc.r.Size, tc.r.Price, got, p.want)
+			}
+		}
+	}
+
+	reg := NewRegistry()
+	if err := reg.Add(Profile{Name: "lit", Exclude: Late}); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.Add(Profile{Name: "broad"}); err == nil {
+		t.Error("Add redefined a built-in profile")
+	}
+	var names []string
+	for _, p := range reg.Profiles() {
+		names = append(names, p.Name)
+	}
+	if got := len(names); got != 5 || names[0] != "broad" || names[2] != "odd-lots" || names[4] != "strict" {
+		t.Errorf("Profiles() = %v", names)
+	}
+
+	lit, _ := reg.Get("lit")
+	for _, tc := range []struct {
+		p, q Profile
+		want bool
+	}{
+		{Standard, Standard, true},
+		{Strict, Standard, true},
+		{Standard, Strict, false},
+		{Broad, Standard, false},
+		{Standard, Broad, false}, // Standard keeps cancelled trades
+		{Strict, Broad, true},
+		{lit, Broad, false}, // lit keeps official prices
+		{Profile{IncludeTRF: true, Exclude: Broad.Exclude}, Broad, true},
+		{Profile{IncludeTRF: true, Exclude: allFlags}, Standard, false},
+		{OddLots, Standard, false}, // odd lots are not in standard's trades
+		{Standard, OddLots, true},
+		{OddLots, Broad, false},
+		{Profile{IncludeTRF: true, IncludeOddLots: true, Exclude: Broad.Exclude}, Broad, true},
+		{Profile{IncludeOddLots: true, Exclude: allFlags}, Strict, false},
+	} {
+		if got := tc.p.Within(tc.q); got != tc.want {
+			t.Errorf("%s.Within(%s) = %v, want %v", tc.p.Name, tc.q.Name, got, tc.want)
+		}
+	}
+	if _, err := reg.Get("nope"); err == nil {
+		t.Error("Get(unknown) succeeded")
+	}
+}
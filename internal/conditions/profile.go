@@ -0,0 +1,148 @@
+package conditions
+
+import (
+	"fmt"
+	"sort"
+
+	"jupitor/internal/store"
+)
+
+// TRFExchange is the exchange code of trades reported to a FINRA trade
+// reporting facility (off-exchange prints).
+const TRFExchange = "D"
+
+// MinLotSize and MinNotional bound the small trades a profile drops unless
+// it includes odd lots: a trade must be of more than MinLotSize shares and
+// worth at least MinNotional dollars, the size gate stock-trades files have
+// always applied.
+const (
+	MinLotSize  = 100
+	MinNotional = 100
+)
+
+// Profile is a named trade filter: a trade passes if none of its flags are
+// in Exclude, unless IncludeTRF is set it was not reported to a TRF, and
+// unless IncludeOddLots is set it passes the MinLotSize/MinNotional gate.
+type Profile struct {
+	Name           string
+	Description    string
+	IncludeTRF     bool
+	IncludeOddLots bool
+	Exclude        Flags
+}
+
+// Allow reports whether the trade passes the profile.
+func (p Profile) Allow(r *store.TradeRecord) bool {
+	if r.Exchange == TRFExchange && !p.IncludeTRF {
+		return false
+	}
+	if !p.IncludeOddLots && (r.Size <= MinLotSize || r.Price*float64(r.Size) < MinNotional) {
+		return false
+	}
+	return !DecodeRecord(r).Has(p.Exclude)
+}
+
+// Within reports whether every trade p allows is also allowed by q, so p
+// can be applied to trades that already passed q.
+func (p Profile) Within(q Profile) bool {
+	return p.Exclude&q.Exclude == q.Exclude &&
+		(q.IncludeTRF || !p.IncludeTRF) &&
+		(q.IncludeOddLots || !p.IncludeOddLots)
+}
+
+// Filter returns the trades that pass the profile.
+func (p Profile) Filter(trades []store.TradeRecord) []store.TradeRecord {
+	out := make([]store.TradeRecord, 0, len(trades))
+	for i := range trades {
+		if p.Allow(&trades[i]) {
+			out = append(out, trades[i])
+		}
+	}
+	return out
+}
+
+// Built-in profiles.
+var (
+	// Standard is the filter stock-trades files have always used: on-exchange
+	// regular, extended-hours and intermarket sweep trades of more than 100
+	// shares only. It does not look at correction status.
+	Standard = Profile{
+		Name:        "standard",
+		Description: "on-exchange regular, Form T and ISO trades",
+		Exclude:     allFlags &^ (IntermarketSweep | ExtendedHours | Cancelled | Corrected),
+	}
+
+	// Strict is Standard without cancelled trades.
+	Strict = Profile{
+		Name:        "strict",
+		Description: "standard, excluding cancelled trades",
+		Exclude:     Standard.Exclude | Cancelled,
+	}
+
+	// OddLots is Standard with odd lots and other small trades included.
+	OddLots = Profile{
+		Name:           "odd-lots",
+		Description:    "standard, including odd lots",
+		IncludeOddLots: true,
+		Exclude:        Standard.Exclude &^ OddLot,
+	}
+
+	// Broad keeps every actual trade, including TRF and late prints and odd
+	// lots; only cancelled trades and official prices (which are not trades)
+	// are dropped.
+	Broad = Profile{
+		Name:           "broad",
+		Description:    "all trades incl. TRF, late prints and odd lots, excluding cancelled trades and official prices",
+		IncludeTRF:     true,
+		IncludeOddLots: true,
+		Exclude:        Cancelled | OfficialPrice,
+	}
+)
+
+// DefaultProfile is the name of the profile used when none is configured.
+const DefaultProfile = "standard"
+
+// Registry holds the built-in profiles and any configured ones.
+type Registry struct {
+	profiles map[string]Profile
+}
+
+// NewRegistry returns a registry of the built-in profiles.
+func NewRegistry() *Registry {
+	r := &Registry{profiles: make(map[string]Profile)}
+	for _, p := range []Profile{Standard, Strict, OddLots, Broad} {
+		r.profiles[p.Name] = p
+	}
+	return r
+}
+
+// Add registers a custom profile. Built-in profiles cannot be redefined.
+func (r *Registry) Add(p Profile) error {
+	if p.Name == "" {
+		return fmt.Errorf("trade filter profile: name required")
+	}
+	if _, ok := r.profiles[p.Name]; ok {
+		return fmt.Errorf("trade filter profile %q already defined", p.Name)
+	}
+	r.profiles[p.Name] = p
+	return nil
+}
+
+// Get returns the named profile.
+func (r *Registry) Get(name string) (Profile, error) {
+	p, ok := r.profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("unknown trade filter profile %q", name)
+	}
+	return p, nil
+}
+
+// Profiles returns every profile, sorted by name.
+func (r *Registry) Profiles() []Profile {
+	out := make([]Profile, 0, len(r.profiles))
+	for _, p := range r.profiles {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
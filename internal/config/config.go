@@ -1,9 +1,15 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"jupitor/internal/conditions"
 )
 
 // ---------------------------------------------------------------------------
@@ -18,6 +24,26 @@ type Config struct {
 	Logging Logging       `yaml:"logging"`
 	Gather  GatherConfig  `yaml:"gather"`
 	Trading TradingConfig `yaml:"trading"`
+	Stream  StreamConfig  `yaml:"stream"`
+
+	// MarketsFile is the market definitions file; it defaults to
+	// markets.yaml next to the main configuration file.
+	MarketsFile string `yaml:"markets_file"`
+
+	// TradeFilter names the trade condition filter profile used to build
+	// stock-trades files and filter the live stream; it is also the
+	// dashboard's default. Built-in profiles are listed in
+	// internal/conditions; TradeFilterProfiles defines more.
+	TradeFilter         string                        `yaml:"trade_filter"`
+	TradeFilterProfiles map[string]TradeFilterProfile `yaml:"trade_filter_profiles"`
+}
+
+// TradeFilterProfile is a custom trade filter profile.
+type TradeFilterProfile struct {
+	Description    string   `yaml:"description"`
+	IncludeTRF     bool     `yaml:"include_trf"`      // keep off-exchange (TRF) prints
+	IncludeOddLots bool     `yaml:"include_odd_lots"` // keep trades of 100 shares or fewer, or under $100
+	Exclude        []string `yaml:"exclude"`          // condition flags to drop, e.g. odd_lot, late
 }
 
 // Storage holds paths for data persistence.
@@ -26,11 +52,14 @@ type Storage struct {
 	SQLitePath string `yaml:"sqlite_path"`
 }
 
-// Server holds network listener configuration.
+// Server holds network listener configuration. Trading makes jupitor-server
+// host the trading engine and strategy runner itself; it cannot then run
+// alongside jupitor-trader, which owns them otherwise.
 type Server struct {
 	Host     string `yaml:"host"`
 	Port     int    `yaml:"port"`
 	GRPCPort int    `yaml:"grpc_port"`
+	Trading  bool   `yaml:"trading"`
 }
 
 // Alpaca holds credentials and endpoints for the Alpaca broker API.
@@ -66,39 +95,196 @@ type GatherJobConfig struct {
 
 // TradingConfig defines risk and execution parameters.
 type TradingConfig struct {
-	MaxPositionPct float64 `yaml:"max_position_pct"`
-	MaxDailyLossPct float64 `yaml:"max_daily_loss_pct"`
-	PaperMode       bool    `yaml:"paper_mode"`
+	MaxPositionPct   float64 `yaml:"max_position_pct"`
+	MaxDailyLossPct  float64 `yaml:"max_daily_loss_pct"`
+	MaxOpenPositions int     `yaml:"max_open_positions"` // 0 = unlimited
+	PaperMode        bool    `yaml:"paper_mode"`
+
+	// TierMaxPositionPct caps position size per trade-universe tier
+	// (ACTIVE, MODERATE, SPORADIC) as a fraction of equity.
+	TierMaxPositionPct map[string]float64 `yaml:"tier_max_position_pct"`
+
+	// HaltedSymbols are blocked from trading at startup.
+	HaltedSymbols []string `yaml:"halted_symbols"`
+
+	// Strategies are started by the strategy runner at startup.
+	Strategies []StrategyConfig `yaml:"strategies"`
+}
+
+// StrategyConfig describes a strategy to run live.
+type StrategyConfig struct {
+	ID      string            `yaml:"id"`
+	Symbols []string          `yaml:"symbols"` // empty = all symbols
+	Params  map[string]string `yaml:"params"`  // order_qty enables order forwarding
+}
+
+// StreamConfig configures the us-stream daemon. Zero values are replaced by
+// the defaults noted on each field.
+type StreamConfig struct {
+	Host     string `yaml:"host"`      // "" = all interfaces
+	Port     int    `yaml:"port"`      // HTTP API, default 8080
+	GRPCPort int    `yaml:"grpc_port"` // live trade feed, default 50051
+
+	RefDir   string `yaml:"ref_dir"`   // reference data, default "reference/us"
+	CacheDir string `yaml:"cache_dir"` // backfill and news caches, default $TMPDIR/us-stream
+	LogDir   string `yaml:"log_dir"`   // daily log files, default $TMPDIR
+
+	// JournalDir holds the write-ahead journal of streamed trades replayed
+	// on restart; default <data_dir>/us/stream-journal.
+	JournalDir string `yaml:"journal_dir"`
+
+	NewsRefresh      time.Duration `yaml:"news_refresh"`      // default 5m
+	DaySwitch        string        `yaml:"day_switch"`        // HH:MM ET, default "03:50"
+	BackfillWorkers  int           `yaml:"backfill_workers"`  // default 4
+	BackfillInterval time.Duration `yaml:"backfill_interval"` // pause between scans, default 5m
+
+	// QuoteSymbols bounds how many symbols (watchlist first, then top
+	// movers) get streamed NBBO quotes; 0, the default, disables quotes.
+	QuoteSymbols int `yaml:"quote_symbols"`
+}
+
+// HTTPAddr returns the HTTP API listen address.
+func (s StreamConfig) HTTPAddr() string { return fmt.Sprintf("%s:%d", s.Host, s.Port) }
+
+// GRPCAddr returns the gRPC listen address.
+func (s StreamConfig) GRPCAddr() string { return fmt.Sprintf("%s:%d", s.Host, s.GRPCPort) }
+
+// DaySwitchOffset returns DaySwitch as an offset from midnight ET.
+func (s StreamConfig) DaySwitchOffset() (time.Duration, error) {
+	t, err := time.Parse("15:04", s.DaySwitch)
+	if err != nil {
+		return 0, fmt.Errorf("stream.day_switch: %q is not an HH:MM time", s.DaySwitch)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
 }
 
 // ---------------------------------------------------------------------------
 // Loading
 // ---------------------------------------------------------------------------
 
-// Load reads the YAML configuration file at the given path, parses it into a
-// Config struct, and then applies environment variable overrides.
+// Load reads the YAML configuration file at the given path, expands ${VAR}
+// references (see expandEnv), parses it into a Config struct, applies
+// environment variable overrides, fills defaults and validates the result.
 func Load(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
 
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if err := expandEnv(&doc); err != nil {
+		return nil, fmt.Errorf("expanding %s: %w", path, err)
+	}
 	cfg := &Config{}
-	if err := yaml.Unmarshal(data, cfg); err != nil {
-		return nil, err
+	if err := doc.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
 	}
 
-	applyEnvOverrides(cfg)
+	if err := applyEnvOverrides(cfg); err != nil {
+		return nil, err
+	}
+	applyDefaults(cfg)
+	if cfg.MarketsFile == "" {
+		cfg.MarketsFile = filepath.Join(filepath.Dir(path), "markets.yaml")
+	}
 
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
 	return cfg, nil
 }
 
-// applyEnvOverrides checks well-known environment variables and overrides the
-// corresponding configuration fields when they are set.
-func applyEnvOverrides(cfg *Config) {
-	// TODO: expand the set of supported environment variable overrides as the
-	// configuration surface grows.
+// Redacted returns a copy of the configuration with credentials masked, for
+// printing.
+func (c *Config) Redacted() *Config {
+	r := *c
+	if r.Alpaca.APIKey != "" {
+		r.Alpaca.APIKey = "<redacted>"
+	}
+	if r.Alpaca.APISecret != "" {
+		r.Alpaca.APISecret = "<redacted>"
+	}
+	return &r
+}
+
+// TradeFilters returns the built-in and configured trade filter profiles and
+// the profile selected by TradeFilter.
+func (c *Config) TradeFilters() (*conditions.Registry, conditions.Profile, error) {
+	reg := conditions.NewRegistry()
+	names := make([]string, 0, len(c.TradeFilterProfiles))
+	for name := range c.TradeFilterProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		spec := c.TradeFilterProfiles[name]
+		exclude, err := conditions.ParseFlags(spec.Exclude)
+		if err != nil {
+			return nil, conditions.Profile{}, fmt.Errorf("trade_filter_profiles.%s.exclude: %w", name, err)
+		}
+		p := conditions.Profile{Name: name, Description: spec.Description, IncludeTRF: spec.IncludeTRF,
+			IncludeOddLots: spec.IncludeOddLots, Exclude: exclude}
+		if err := reg.Add(p); err != nil {
+			return nil, conditions.Profile{}, fmt.Errorf("trade_filter_profiles.%s: %w", name, err)
+		}
+	}
+	name := c.TradeFilter
+	if name == "" {
+		name = conditions.DefaultProfile
+	}
+	p, err := reg.Get(name)
+	if err != nil {
+		return nil, conditions.Profile{}, fmt.Errorf("trade_filter: %w", err)
+	}
+	return reg, p, nil
+}
+
+// applyDefaults fills unset fields that have a sensible default.
+func applyDefaults(cfg *Config) {
+	if cfg.TradeFilter == "" {
+		cfg.TradeFilter = conditions.DefaultProfile
+	}
 
+	s := &cfg.Stream
+	if s.Port == 0 {
+		s.Port = 8080
+	}
+	if s.GRPCPort == 0 {
+		s.GRPCPort = 50051
+	}
+	if s.RefDir == "" {
+		s.RefDir = filepath.Join("reference", "us")
+	}
+	if s.CacheDir == "" {
+		s.CacheDir = filepath.Join(os.TempDir(), "us-stream")
+	}
+	if s.LogDir == "" {
+		s.LogDir = os.TempDir()
+	}
+	if s.JournalDir == "" && cfg.Storage.DataDir != "" {
+		s.JournalDir = filepath.Join(cfg.Storage.DataDir, "us", "stream-journal")
+	}
+	if s.NewsRefresh == 0 {
+		s.NewsRefresh = 5 * time.Minute
+	}
+	if s.DaySwitch == "" {
+		s.DaySwitch = "03:50"
+	}
+	if s.BackfillWorkers == 0 {
+		s.BackfillWorkers = 4
+	}
+	if s.BackfillInterval == 0 {
+		s.BackfillInterval = 5 * time.Minute
+	}
+}
+
+// applyEnvOverrides checks well-known environment variables and overrides the
+// corresponding configuration fields when they are set, then applies the
+// per-key JUPITOR_* overrides, which take precedence.
+func applyEnvOverrides(cfg *Config) error {
 	if v := os.Getenv("DATA_1"); v != "" {
 		cfg.Storage.DataDir = v
 	}
@@ -133,4 +319,6 @@ func applyEnvOverrides(cfg *Config) {
 	if v := os.Getenv("LOG_LEVEL"); v != "" {
 		cfg.Logging.Level = v
 	}
+
+	return applyKeyOverrides(cfg)
 }
@@ -2,7 +2,12 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"jupitor/internal/conditions"
 )
 
 func TestLoadDefaults(t *testing.T) {
@@ -169,3 +174,221 @@ storage:
 		t.Errorf("Storage.DataDir = %q, want %q (env override)", cfg.Storage.DataDir, "/env/data")
 	}
 }
+
+func TestLoadStreamDefaultsAndOverrides(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "jupitor-config-stream-*.yaml")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err := tmpFile.WriteString("stream:\n  grpc_port: 6000\n  news_refresh: 90s\n"); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+	tmpFile.Close()
+
+	t.Setenv("JUPITOR_STREAM_PORT", "9000")
+	t.Setenv("JUPITOR_STREAM_DAY_SWITCH", "04:15")
+
+	cfg, err := Load(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	s := cfg.Stream
+	if s.HTTPAddr() != ":9000" || s.GRPCAddr() != ":6000" {
+		t.Errorf("addrs = %q, %q, want :9000 (env), :6000 (YAML)", s.HTTPAddr(), s.GRPCAddr())
+	}
+	if s.NewsRefresh != 90*time.Second {
+		t.Errorf("NewsRefresh = %v, want 90s", s.NewsRefresh)
+	}
+	if s.BackfillWorkers != 4 || s.BackfillInterval != 5*time.Minute || s.RefDir != "reference/us" {
+		t.Errorf("defaults not applied: %+v", s)
+	}
+	if d, err := s.DaySwitchOffset(); err != nil || d != 4*time.Hour+15*time.Minute {
+		t.Errorf("DaySwitchOffset = %v, %v, want 4h15m", d, err)
+	}
+	if want := filepath.Join(filepath.Dir(tmpFile.Name()), "markets.yaml"); cfg.MarketsFile != want {
+		t.Errorf("MarketsFile = %q, want %q", cfg.MarketsFile, want)
+	}
+
+	t.Setenv("JUPITOR_STREAM_BACKFILL_WORKERS", "many")
+	if _, err := Load(tmpFile.Name()); err == nil || !strings.Contains(err.Error(), "JUPITOR_STREAM_BACKFILL_WORKERS") {
+		t.Errorf("Load with bad worker count = %v, want error naming the variable", err)
+	}
+}
+
+func TestLoadKeyOverrides(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/jupitor.yaml"
+	yamlContent := `
+storage:
+  data_dir: "${JUPITOR_TEST_DATA}/data"
+server:
+  port: 8080
+gather:
+  us_trade:
+    max_workers: 16
+trading:
+  max_position_pct: 0.05
+`
+	if err := os.WriteFile(path, []byte(yamlContent), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("JUPITOR_TEST_DATA", "/mnt/d1")
+	t.Setenv("JUPITOR_SERVER_PORT", "9000")
+	t.Setenv("JUPITOR_GATHER_US_TRADE_MAX_WORKERS", "4")
+	t.Setenv("JUPITOR_TRADING_MAX_POSITION_PCT", "0.1")
+	t.Setenv("JUPITOR_TRADING_PAPER_MODE", "true")
+	t.Setenv("JUPITOR_TRADING_HALTED_SYMBOLS", "gme, amc")
+	t.Setenv("JUPITOR_STREAM_BACKFILL_INTERVAL", "90s")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if cfg.Storage.DataDir != "/mnt/d1/data" {
+		t.Errorf("Storage.DataDir = %q, want ${VAR} expanded", cfg.Storage.DataDir)
+	}
+	if cfg.Server.Port != 9000 {
+		t.Errorf("Server.Port = %d, want 9000", cfg.Server.Port)
+	}
+	if cfg.Gather.USTrade.MaxWorkers != 4 {
+		t.Errorf("Gather.USTrade.MaxWorkers = %d, want 4", cfg.Gather.USTrade.MaxWorkers)
+	}
+	if cfg.Trading.MaxPositionPct != 0.1 || !cfg.Trading.PaperMode {
+		t.Errorf("Trading = %+v, want max_position_pct 0.1 and paper mode", cfg.Trading)
+	}
+	if got := strings.Join(cfg.Trading.HaltedSymbols, ","); got != "gme,amc" {
+		t.Errorf("Trading.HaltedSymbols = %q", got)
+	}
+	if cfg.Stream.BackfillInterval != 90*time.Second {
+		t.Errorf("Stream.BackfillInterval = %v, want 90s", cfg.Stream.BackfillInterval)
+	}
+
+	t.Setenv("JUPITOR_TRADING_PAPER_MODE", "sometimes")
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "JUPITOR_TRADING_PAPER_MODE (trading.paper_mode)") {
+		t.Errorf("bad boolean error = %v, want it to name the variable and key", err)
+	}
+}
+
+func TestLoadEnvExpansion(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/jupitor.yaml"
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("JUPITOR_TEST_DATA", "/mnt/d1")
+	t.Setenv("JUPITOR_TEST_EMPTY", "")
+	t.Setenv("JUPITOR_TEST_PORT", "9000")
+
+	write(`
+storage:
+  data_dir: "${JUPITOR_TEST_DATA}/data"
+  # overflow_dir: "${JUPITOR_TEST_UNSET}"
+  sqlite_path: "${JUPITOR_TEST_EMPTY:-/tmp/jupitor.db}"
+server:
+  port: ${JUPITOR_TEST_PORT}
+alpaca:
+  api_key: "${JUPITOR_TEST_UNSET:-}"
+  api_secret: "pa$$word$HOME"
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if cfg.Storage.DataDir != "/mnt/d1/data" || cfg.Storage.SQLitePath != "/tmp/jupitor.db" {
+		t.Errorf("Storage = %+v, want expanded data_dir and defaulted sqlite_path", cfg.Storage)
+	}
+	if cfg.Server.Port != 9000 {
+		t.Errorf("Server.Port = %d, want 9000", cfg.Server.Port)
+	}
+	if cfg.Alpaca.APIKey != "" || cfg.Alpaca.APISecret != "pa$$word$HOME" {
+		t.Errorf("Alpaca = %q, %q, want empty key and literal secret", cfg.Alpaca.APIKey, cfg.Alpaca.APISecret)
+	}
+
+	write(`
+storage:
+  data_dir: "${JUPITOR_TEST_UNSET}/data"
+`)
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "${JUPITOR_TEST_UNSET} is not set") {
+		t.Errorf("Load with an unset variable = %v, want error naming it", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg := &Config{}
+	applyDefaults(cfg)
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("defaults invalid: %v", err)
+	}
+
+	cfg.Server.Port = 70000
+	cfg.Logging.Level = "loud"
+	cfg.Gather.USDaily.StartDate = "2016/01/01"
+	cfg.Trading.MaxPositionPct = 1.5
+	cfg.Trading.TierMaxPositionPct = map[string]float64{"ACTIVE": 0.05, "SPORADIC": -0.1}
+	cfg.Trading.Strategies = []StrategyConfig{{ID: "sma"}, {ID: "sma"}}
+	cfg.Stream.BackfillWorkers = 0
+	cfg.Stream.DaySwitch = "25:00"
+	cfg.Stream.QuoteSymbols = -1
+	cfg.TradeFilter = "everything"
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Validate() accepted invalid config")
+	}
+	for _, key := range []string{
+		"server.port:",
+		"logging.level:",
+		"gather.us_daily.start_date:",
+		"trading.max_position_pct:",
+		"trading.tier_max_position_pct.SPORADIC:",
+		"trading.strategies[1].id: duplicate",
+		"stream.backfill_workers:",
+		"stream.day_switch:",
+		"stream.quote_symbols:",
+		"trade_filter:",
+	} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("error does not mention %q:\n%v", key, err)
+		}
+	}
+	if strings.Contains(err.Error(), "ACTIVE") {
+		t.Errorf("valid tier reported: %v", err)
+	}
+
+	if err := (&Config{}).RequireAlpaca(); err == nil || !strings.Contains(err.Error(), "alpaca.api_key") {
+		t.Errorf("RequireAlpaca() = %v, want missing alpaca.api_key", err)
+	}
+}
+
+func TestTradeFilters(t *testing.T) {
+	cfg := &Config{
+		TradeFilter: "lit",
+		TradeFilterProfiles: map[string]TradeFilterProfile{
+			"lit": {Description: "no late prints", IncludeOddLots: true, Exclude: []string{"late", "out_of_sequence"}},
+		},
+	}
+	reg, p, err := cfg.TradeFilters()
+	if err != nil {
+		t.Fatalf("TradeFilters() error: %v", err)
+	}
+	if p.Name != "lit" || p.Exclude != conditions.Late|conditions.OutOfSequence || p.IncludeTRF || !p.IncludeOddLots {
+		t.Errorf("profile = %+v", p)
+	}
+	if _, err := reg.Get("broad"); err != nil {
+		t.Errorf("built-in profile missing: %v", err)
+	}
+
+	cfg.TradeFilterProfiles["lit"] = TradeFilterProfile{Exclude: []string{"dark"}}
+	if _, _, err := cfg.TradeFilters(); err == nil || !strings.Contains(err.Error(), "trade_filter_profiles.lit.exclude:") {
+		t.Errorf("unknown flag error = %v", err)
+	}
+	cfg.TradeFilterProfiles = map[string]TradeFilterProfile{"standard": {}}
+	if _, _, err := cfg.TradeFilters(); err == nil || !strings.Contains(err.Error(), "trade_filter_profiles.standard:") {
+		t.Errorf("redefined built-in error = %v", err)
+	}
+}
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envPrefix prefixes the per-key environment overrides.
+const envPrefix = "JUPITOR_"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// envRef matches the ${VAR} and ${VAR:-default} placeholders of YAML values.
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// expandEnv replaces ${VAR} placeholders in the scalar values of a parsed
+// YAML document with environment variables. ${VAR:-default} takes default
+// when VAR is unset or empty; any other unset variable is an error. Other
+// dollar signs are kept literally, and comments are not expanded.
+func expandEnv(n *yaml.Node) error {
+	var errs []error
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		if n.Kind != yaml.ScalarNode {
+			for _, c := range n.Content {
+				walk(c)
+			}
+			return
+		}
+		v := envRef.ReplaceAllStringFunc(n.Value, func(ref string) string {
+			m := envRef.FindStringSubmatch(ref)
+			if v, ok := os.LookupEnv(m[1]); ok && (v != "" || m[2] == "") {
+				return v
+			}
+			if m[2] != "" {
+				return m[3]
+			}
+			errs = append(errs, fmt.Errorf("line %d: ${%s} is not set", n.Line, m[1]))
+			return ""
+		})
+		if v != n.Value {
+			n.Value = v
+			if n.Style == 0 {
+				n.Tag = "" // resolve the expanded plain scalar's type afresh
+			}
+		}
+	}
+	walk(n)
+	return errors.Join(errs...)
+}
+
+// EnvName returns the environment variable that overrides a dotted YAML key:
+// server.port is JUPITOR_SERVER_PORT.
+func EnvName(key string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// applyKeyOverrides overrides any scalar configuration key from the
+// environment variable named by EnvName, e.g. JUPITOR_SERVER_PORT or
+// JUPITOR_GATHER_US_TRADE_MAX_WORKERS. Durations use time.ParseDuration
+// syntax and string lists are comma-separated. Maps and lists of structs
+// (trading.tier_max_position_pct, trading.strategies) can only be set in YAML.
+func applyKeyOverrides(cfg *Config) error {
+	return walkKeys(reflect.ValueOf(cfg).Elem(), "", func(key string, v reflect.Value) error {
+		env := EnvName(key)
+		s := os.Getenv(env)
+		if s == "" {
+			return nil
+		}
+		if err := setValue(v, s); err != nil {
+			return fmt.Errorf("%s (%s): %w", env, key, err)
+		}
+		return nil
+	})
+}
+
+// walkKeys calls fn for every scalar or string-list field below the struct v,
+// passing its dotted YAML key.
+func walkKeys(v reflect.Value, prefix string, fn func(key string, v reflect.Value) error) error {
+	t := v.Type()
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+		fv := v.Field(i)
+		switch {
+		case fv.Kind() == reflect.Struct:
+			if err := walkKeys(fv, key, fn); err != nil {
+				return err
+			}
+		case overridable(fv.Type()):
+			if err := fn(key, fv); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func overridable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int64, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.String
+	}
+	return false
+}
+
+func setValue(v reflect.Value, s string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", s)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", s)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", s)
+		}
+		v.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", s)
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		var list []string
+		for _, p := range strings.Split(s, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				list = append(list, p)
+			}
+		}
+		v.Set(reflect.ValueOf(list))
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Validate reports configuration values that are out of range or malformed.
+// Every problem names the offending YAML key; all problems are joined into a
+// single error.
+func (c *Config) Validate() error {
+	var errs []error
+	bad := func(key, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+	port := func(key string, p int) {
+		if p < 0 || p > 65535 {
+			bad(key, "port %d out of range 0-65535", p)
+		}
+	}
+	fraction := func(key string, f float64) {
+		if f < 0 || f > 1 {
+			bad(key, "%v must be a fraction between 0 and 1", f)
+		}
+	}
+	nonNegative := func(key string, n int) {
+		if n < 0 {
+			bad(key, "%d must not be negative", n)
+		}
+	}
+
+	port("server.port", c.Server.Port)
+	port("server.grpc_port", c.Server.GRPCPort)
+
+	switch c.Logging.Level {
+	case "", "debug", "info", "warn", "error":
+	default:
+		bad("logging.level", "unknown level %q (want debug, info, warn or error)", c.Logging.Level)
+	}
+	switch c.Logging.Format {
+	case "", "text", "json":
+	default:
+		bad("logging.format", "unknown format %q (want text or json)", c.Logging.Format)
+	}
+
+	for _, job := range []struct {
+		key string
+		cfg GatherJobConfig
+	}{
+		{"gather.us_daily", c.Gather.USDaily},
+		{"gather.us_trade", c.Gather.USTrade},
+		{"gather.cn_daily", c.Gather.CNDaily},
+	} {
+		if job.cfg.StartDate != "" {
+			if _, err := time.Parse(time.DateOnly, job.cfg.StartDate); err != nil {
+				bad(job.key+".start_date", "%q is not a YYYY-MM-DD date", job.cfg.StartDate)
+			}
+		}
+		nonNegative(job.key+".batch_size", job.cfg.BatchSize)
+		nonNegative(job.key+".max_workers", job.cfg.MaxWorkers)
+		nonNegative(job.key+".rate_limit_per_min", job.cfg.RateLimitPerMin)
+	}
+
+	t := c.Trading
+	fraction("trading.max_position_pct", t.MaxPositionPct)
+	fraction("trading.max_daily_loss_pct", t.MaxDailyLossPct)
+	nonNegative("trading.max_open_positions", t.MaxOpenPositions)
+	tiers := make([]string, 0, len(t.TierMaxPositionPct))
+	for tier := range t.TierMaxPositionPct {
+		tiers = append(tiers, tier)
+	}
+	sort.Strings(tiers)
+	for _, tier := range tiers {
+		fraction("trading.tier_max_position_pct."+tier, t.TierMaxPositionPct[tier])
+	}
+	seen := make(map[string]bool)
+	for i, sc := range t.Strategies {
+		key := fmt.Sprintf("trading.strategies[%d].id", i)
+		switch {
+		case sc.ID == "":
+			bad(key, "required")
+		case seen[sc.ID]:
+			bad(key, "duplicate strategy %q", sc.ID)
+		}
+		seen[sc.ID] = true
+	}
+
+	s := c.Stream
+	port("stream.port", s.Port)
+	port("stream.grpc_port", s.GRPCPort)
+	if s.BackfillWorkers < 1 {
+		bad("stream.backfill_workers", "%d must be at least 1", s.BackfillWorkers)
+	}
+	if s.NewsRefresh <= 0 {
+		bad("stream.news_refresh", "%v must be positive", s.NewsRefresh)
+	}
+	if s.BackfillInterval <= 0 {
+		bad("stream.backfill_interval", "%v must be positive", s.BackfillInterval)
+	}
+	if s.QuoteSymbols < 0 {
+		bad("stream.quote_symbols", "%d must not be negative", s.QuoteSymbols)
+	}
+
+	if _, _, err := c.TradeFilters(); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := s.DaySwitchOffset(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// RequireAlpaca reports missing Alpaca credentials. Binaries that gather
+// from or trade through Alpaca call it after Load.
+func (c *Config) RequireAlpaca() error {
+	var errs []error
+	if c.Alpaca.APIKey == "" {
+		errs = append(errs, errors.New("alpaca.api_key: required (set APCA_API_KEY_ID)"))
+	}
+	if c.Alpaca.APISecret == "" {
+		errs = append(errs, errors.New("alpaca.api_secret: required (set APCA_API_SECRET_KEY)"))
+	}
+	return errors.Join(errs...)
+}
@@ -7,8 +7,6 @@ import (
 	"sort"
 	"strings"
 
-	"github.com/parquet-go/parquet-go"
-
 	"jupitor/internal/store"
 )
 
@@ -53,7 +51,7 @@ func ListHistoryDates(dataDir string) ([]string, error) {
 // consolidated parquet file at $DATA_1/us/stock-trades-ex-index/<date>.parquet.
 func LoadHistoryTrades(dataDir, date string) ([]store.TradeRecord, error) {
 	path := filepath.Join(dataDir, "us", "stock-trades-ex-index", date+".parquet")
-	records, err := parquet.ReadFile[store.TradeRecord](path)
+	records, err := store.ReadTradeRecords(path)
 	if err != nil {
 		return nil, fmt.Errorf("reading %s: %w", path, err)
 	}
@@ -68,7 +66,7 @@ func LoadPerSymbolTrades(dataDir, date string, minTS, maxTS int64, symbols []str
 	var all []store.TradeRecord
 	for _, sym := range symbols {
 		path := filepath.Join(tradesDir, sym, date+".parquet")
-		records, err := parquet.ReadFile[store.TradeRecord](path)
+		records, err := store.ReadTradeRecords(path)
 		if err != nil {
 			continue
 		}
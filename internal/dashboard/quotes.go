@@ -0,0 +1,74 @@
+package dashboard
+
+import (
+	"jupitor/internal/ettime"
+	"jupitor/internal/store"
+)
+
+// ApplyQuotes fills the spread and depth fields of d's stats from each
+// symbol's 1-second quote samples (session time, ascending): pre-market
+// stats from [b.PreOpen, b.Open) and regular stats from [b.Open, b.Close),
+// so half days end at their early close. asOfET ends the last sample's
+// interval, so a live day is not weighted towards its latest quote; pass
+// math.MaxInt64 for a finished day.
+func ApplyQuotes(d *DayData, samples func(symbol string) []store.QuoteRecord, b ettime.Bounds, asOfET int64) {
+	preStartET, open930ET, regEnd := int64(b.PreOpen), int64(b.Open), int64(b.Close)
+	for _, tier := range d.Tiers {
+		for _, c := range tier.Symbols {
+			qs := samples(c.Symbol)
+			if len(qs) == 0 {
+				continue
+			}
+			if c.Pre != nil {
+				applyQuoteStats(c.Pre, qs, preStartET, min(open930ET, asOfET))
+			}
+			if c.Reg != nil {
+				applyQuoteStats(c.Reg, qs, open930ET, min(regEnd, asOfET))
+			}
+		}
+	}
+}
+
+// QuoteFiles returns a sample lookup for ApplyQuotes that reads the quote
+// files recorded by us-stream for date. Symbols without a file have no
+// samples.
+func QuoteFiles(dataDir, date string) func(symbol string) []store.QuoteRecord {
+	return func(symbol string) []store.QuoteRecord {
+		records, err := store.ReadQuoteRecords(store.QuotePath(dataDir, symbol, date))
+		if err != nil {
+			return nil
+		}
+		return records
+	}
+}
+
+// applyQuoteStats sets s's spread and depth to their time-weighted means
+// over [from, to). Each sample holds until the next one; the sample in force
+// at from counts from there. One-sided and crossed quotes are skipped.
+func applyQuoteStats(s *SymbolStats, samples []store.QuoteRecord, from, to int64) {
+	var total, spread, bps, bid, ask float64
+	for i := range samples {
+		q := &samples[i]
+		end := to
+		if i+1 < len(samples) {
+			end = min(samples[i+1].Timestamp, to)
+		}
+		start := max(q.Timestamp, from)
+		if end <= start || q.BidPrice <= 0 || q.AskPrice < q.BidPrice {
+			continue
+		}
+		w := float64(end - start)
+		total += w
+		spread += w * (q.AskPrice - q.BidPrice)
+		bps += w * (q.AskPrice - q.BidPrice) / ((q.AskPrice + q.BidPrice) / 2) * 1e4
+		bid += w * float64(q.BidSize)
+		ask += w * float64(q.AskSize)
+	}
+	if total == 0 {
+		return
+	}
+	s.Spread = spread / total
+	s.SpreadBps = bps / total
+	s.BidDepth = bid / total
+	s.AskDepth = ask / total
+}
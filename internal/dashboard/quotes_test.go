@@ -0,0 +1,57 @@
+package dashboard
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"jupitor/internal/domain"
+	"jupitor/internal/ettime"
+	"jupitor/internal/store"
+	"jupitor/internal/util"
+)
+
+func TestApplyQuotesHalfDay(t *testing.T) {
+	// 2024-11-29 closes at 1:00 PM ET.
+	b, err := ettime.BoundsFor("2024-11-29", util.NewTradingCalendar(domain.MarketUS))
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(s ettime.SessionTime) int64 { return int64(s) }
+	samples := []store.QuoteRecord{
+		{Timestamp: at(b.Open.Add(-2 * time.Minute)), BidPrice: 10, AskPrice: 10.1, BidSize: 100, AskSize: 300},
+		{Timestamp: at(b.Open.Add(-time.Minute)), BidPrice: 0, AskPrice: 10.1, AskSize: 500},                 // one-sided
+		{Timestamp: at(b.Open), BidPrice: 10, AskPrice: 10.2, BidSize: 200, AskSize: 200},                    // held 2.5h
+		{Timestamp: at(b.Close.Add(-time.Hour)), BidPrice: 10.3, AskPrice: 10.2, BidSize: 900, AskSize: 900}, // crossed
+		{Timestamp: at(b.Close.Add(time.Minute)), BidPrice: 10, AskPrice: 11, BidSize: 9000, AskSize: 9000},  // after the early close
+	}
+	d := DayData{Tiers: []TierGroup{{Name: "ACTIVE", Symbols: []*CombinedStats{
+		{Symbol: "ABCD", Pre: &SymbolStats{}, Reg: &SymbolStats{}},
+		{Symbol: "NONE", Reg: &SymbolStats{}},
+	}}}}
+	ApplyQuotes(&d, func(sym string) []store.QuoteRecord {
+		if sym == "ABCD" {
+			return samples
+		}
+		return nil
+	}, b, math.MaxInt64)
+
+	near := func(got, want float64) bool { return math.Abs(got-want) < 1e-9 }
+	c := d.Tiers[0].Symbols[0]
+	if pre := c.Pre; !near(pre.Spread, 0.1) || !near(pre.SpreadBps, 0.1/10.05*1e4) || pre.BidDepth != 100 || pre.AskDepth != 300 {
+		t.Errorf("pre stats = %+v, want only the two-sided quote", pre)
+	}
+	if reg := c.Reg; !near(reg.Spread, 0.2) || reg.BidDepth != 200 || reg.AskDepth != 200 {
+		t.Errorf("reg stats = %+v, want only the quote in force before the crossed one", reg)
+	}
+	if reg := d.Tiers[0].Symbols[1].Reg; reg.Spread != 0 || reg.BidDepth != 0 {
+		t.Errorf("symbol without samples = %+v", reg)
+	}
+
+	// A live day ends the last sample's interval at asOfET.
+	c.Reg = &SymbolStats{}
+	ApplyQuotes(&d, func(string) []store.QuoteRecord { return samples[2:3] }, b, at(b.Open.Add(time.Hour)))
+	if !near(c.Reg.Spread, 0.2) {
+		t.Errorf("live reg stats = %+v", c.Reg)
+	}
+}
@@ -5,8 +5,8 @@ package dashboard
 import (
 	"math"
 	"sort"
-	"strings"
 
+	"jupitor/internal/ettime"
 	"jupitor/internal/store"
 )
 
@@ -27,6 +27,13 @@ type SymbolStats struct {
 	MaxDrawdown  float64 // (peakPrice - minAfterPeak) / vwap — drawdown from max gain point
 	TradeProfile    []int   // trade count per 1% VWAP bucket from low to high
 	TradeProfile30m [][]int // per-30m-period trade count profile (same buckets as TradeProfile)
+
+	// Quoted spread and depth over the session, time-weighted from 1-second
+	// quote samples; zero when the symbol's quotes were not collected.
+	Spread    float64 // mean ask - bid
+	SpreadBps float64 // mean (ask - bid) / midpoint, in basis points
+	BidDepth  float64 // mean shares at the bid
+	AskDepth  float64 // mean shares at the ask
 }
 
 // CombinedStats pairs pre-market and regular stats for a single symbol.
@@ -482,43 +489,13 @@ func FilterTradesBySymbol(trades []store.TradeRecord, symbol string) []store.Tra
 	return out
 }
 
-// allowedConds for exchange/condition filter matching consolidated trade files.
-var allowedConds = map[string]bool{" ": true, "@": true, "T": true, "F": true}
-
-// FilterTradeRecords applies the same exchange/condition filter used by the
-// consolidated stock-trades files: exchange != "D", all conditions in {" ","@","T","F"}.
-func FilterTradeRecords(trades []store.TradeRecord) []store.TradeRecord {
-	out := make([]store.TradeRecord, 0, len(trades))
-	for i := range trades {
-		r := &trades[i]
-		if r.Exchange == "D" {
-			continue
-		}
-		if r.Conditions != "" {
-			ok := true
-			for _, c := range strings.Split(r.Conditions, ",") {
-				if !allowedConds[c] {
-					ok = false
-					break
-				}
-			}
-			if !ok {
-				continue
-			}
-		}
-		out = append(out, trades[i])
-	}
-	return out
-}
-
 // ComputeDayData builds a complete DayData for a set of trades. It splits by
-// session, aggregates, merges, filters (gain>=10% and trades>=100), groups by
-// tier, and sorts within each tier.
-func ComputeDayData(label string, trades []store.TradeRecord, tierMap map[string]string, open930ET int64, sortMode int) DayData {
-	pre, reg := SplitBySession(trades, open930ET)
-	preStartET := open930ET - 330*60*1000 // 4:00 AM ET (5.5 hours before 9:30)
-	preStats := AggregateTrades(pre, preStartET)
-	regStats := AggregateTrades(reg, open930ET)
+// session at b.Open, aggregates, merges, filters (gain>=10% and trades>=100),
+// groups by tier, and sorts within each tier.
+func ComputeDayData(label string, trades []store.TradeRecord, tierMap map[string]string, b ettime.Bounds, sortMode int) DayData {
+	pre, reg := SplitBySession(trades, int64(b.Open))
+	preStats := AggregateTrades(pre, int64(b.PreOpen))
+	regStats := AggregateTrades(reg, int64(b.Open))
 
 	// Merge into combined stats per symbol.
 	combined := make(map[string]*CombinedStats)
@@ -28,12 +28,13 @@ const (
 type OrderStatus string
 
 const (
-	OrderStatusPending   OrderStatus = "pending"
-	OrderStatusSubmitted OrderStatus = "submitted"
-	OrderStatusFilled    OrderStatus = "filled"
-	OrderStatusPartial   OrderStatus = "partial"
-	OrderStatusCancelled OrderStatus = "cancelled"
-	OrderStatusRejected  OrderStatus = "rejected"
+	OrderStatusPending       OrderStatus = "pending"
+	OrderStatusSubmitted     OrderStatus = "submitted"
+	OrderStatusFilled        OrderStatus = "filled"
+	OrderStatusPartial       OrderStatus = "partial"
+	OrderStatusPendingCancel OrderStatus = "pending_cancel" // cancel requested, not yet confirmed
+	OrderStatusCancelled     OrderStatus = "cancelled"
+	OrderStatusRejected      OrderStatus = "rejected"
 )
 
 // TimeInForce specifies how long an order remains active.
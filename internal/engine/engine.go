@@ -4,55 +4,425 @@ package engine
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
 
 	"jupitor/internal/broker"
 	"jupitor/internal/domain"
 	"jupitor/internal/store"
 )
 
+// ErrOrderNotOpen is returned when cancelling an order that has already
+// reached a terminal status.
+var ErrOrderNotOpen = errors.New("order is not open")
+
 // Engine orchestrates the trading lifecycle by delegating to a broker for
 // execution, stores for persistence, and a risk manager for pre-trade checks.
+//
+// An order moves through pending → submitted → partial → filled, or ends in
+// cancelled or rejected. A cancel request moves an open order to
+// pending_cancel until the broker confirms it. Every transition is persisted
+// to the OrderStore; the reconciler (see Reconcile) repairs any drift from
+// the broker's view.
 type Engine struct {
 	broker      broker.Broker
 	orders      store.OrderStore
 	positions   store.PositionStore
 	riskChecker *RiskManager
+	log         *slog.Logger
+
+	// mu serialises submissions and cancels so risk checks see a consistent
+	// account and a cancel never races an order's broker submission.
+	mu sync.Mutex
+
+	// ordersMu guards each read-modify-write of a stored order. It is never
+	// held across a broker call, so a broker may deliver updates (through
+	// HandleOrderUpdate) before its SubmitOrder or CancelOrder returns.
+	ordersMu sync.Mutex
+
+	subsMu    sync.Mutex
+	nextSubID int
+	subs      map[int]chan domain.Order
 }
 
-// NewEngine creates a new Engine wired with the given dependencies.
+// NewEngine creates a new Engine wired with the given dependencies. If the
+// risk manager has no position source, it is pointed at the broker; so is
+// its price source if the broker quotes prices (e.g. SimulatorBroker).
 func NewEngine(
 	b broker.Broker,
 	orders store.OrderStore,
 	positions store.PositionStore,
 	riskChecker *RiskManager,
 ) *Engine {
+	if riskChecker != nil && b != nil {
+		riskChecker.mu.Lock()
+		if riskChecker.positions == nil {
+			riskChecker.positions = b.GetPositions
+		}
+		if pb, ok := b.(interface{ Price(string) (float64, bool) }); ok && riskChecker.prices == nil {
+			riskChecker.prices = pb.Price
+		}
+		riskChecker.mu.Unlock()
+	}
 	return &Engine{
 		broker:      b,
 		orders:      orders,
 		positions:   positions,
 		riskChecker: riskChecker,
+		log:         slog.Default().With("component", "engine"),
+		subs:        make(map[int]chan domain.Order),
 	}
 }
 
-// SubmitOrder validates the order against risk rules and then forwards it to
-// the broker for execution.
+// SetLogger replaces the engine's logger.
+func (e *Engine) SetLogger(l *slog.Logger) {
+	e.log = l.With("component", "engine")
+}
+
+// SubmitOrder validates the order against risk rules, persists it as
+// pending and then forwards it to the broker for execution. The returned
+// order reflects the status reported by the broker, or a later one if an
+// order update arrived first. If the broker rejects the order it is
+// persisted as rejected and the broker error is returned.
 func (e *Engine) SubmitOrder(ctx context.Context, order *domain.Order) (*domain.Order, error) {
-	// TODO: call riskChecker.CheckOrder, persist order via store, submit via broker
-	_ = ctx
-	return order, nil
+	if order.Symbol == "" {
+		return nil, errors.New("order symbol is required")
+	}
+	if order.Qty <= 0 {
+		return nil, fmt.Errorf("order qty must be positive, got %v", order.Qty)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	o := *order
+	if o.ID == "" {
+		o.ID = newOrderID()
+	}
+	if o.Type == "" {
+		o.Type = domain.OrderTypeMarket
+	}
+	if o.TimeInForce == "" {
+		o.TimeInForce = domain.TimeInForceDay
+	}
+
+	if e.riskChecker != nil {
+		account, err := e.broker.GetAccount(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("loading account for risk check: %w", err)
+		}
+		if err := e.riskChecker.CheckOrder(ctx, &o, account); err != nil {
+			return nil, fmt.Errorf("risk check: %w", err)
+		}
+	}
+
+	now := time.Now().UTC()
+	o.Status = domain.OrderStatusPending
+	o.BrokerOrderID = ""
+	o.FilledQty = 0
+	o.FilledAvgPrice = 0
+	o.CreatedAt = now
+	o.UpdatedAt = now
+	if err := e.orders.SaveOrder(ctx, &o); err != nil {
+		return nil, fmt.Errorf("persisting order: %w", err)
+	}
+	e.publish(o)
+
+	req := o
+	res, err := e.broker.SubmitOrder(ctx, &req)
+	if err != nil {
+		o.Status = domain.OrderStatusRejected
+		o.UpdatedAt = time.Now().UTC()
+		if uerr := e.orders.UpdateOrder(ctx, &o); uerr != nil {
+			e.log.Error("persisting rejected order", "id", o.ID, "error", uerr)
+		}
+		e.publish(o)
+		return &o, fmt.Errorf("submitting order %s: %w", o.ID, err)
+	}
+
+	// Merge into the stored order rather than overwriting it: an update
+	// delivered while the broker call was in flight may already be there.
+	sub := o
+	mergeBrokerState(&sub, res)
+	if sub.Status == domain.OrderStatusPending {
+		sub.Status = domain.OrderStatusSubmitted
+	}
+	sub.UpdatedAt = time.Now().UTC()
+	stored, err := e.amendOrder(ctx, o.ID, func(s *domain.Order) bool {
+		changed := s.BrokerOrderID == "" && sub.BrokerOrderID != ""
+		if changed {
+			s.BrokerOrderID = sub.BrokerOrderID
+		}
+		return applyUpdate(s, &sub) || changed
+	})
+	if err != nil {
+		// The order is live at the broker; the reconciler will catch up.
+		e.log.Error("persisting submitted order", "id", o.ID, "error", err)
+		stored = &sub
+	}
+	o = *stored
+	e.log.Info("order submitted", "id", o.ID, "symbol", o.Symbol, "side", o.Side,
+		"qty", o.Qty, "status", o.Status, "brokerOrderID", o.BrokerOrderID)
+	return &o, nil
 }
 
-// CancelOrder requests cancellation of an open order.
+// CancelOrder requests cancellation of an open order and marks it
+// pending_cancel; it becomes cancelled once the broker confirms, either
+// straight away (if the broker implements broker.OrderQuerier) or through an
+// order update or the reconciler. It returns an error wrapping
+// store.ErrNotFound for unknown orders and ErrOrderNotOpen for orders that
+// are already filled, cancelled or rejected.
+//
+// An order still pending without a broker ID (its submission was never
+// confirmed) may or may not have reached the broker. If the broker does not
+// know it yet, the cancel is queued: the order stays pending_cancel and the
+// reconciler re-sends the cancel once the broker reports it working.
 func (e *Engine) CancelOrder(ctx context.Context, orderID string) error {
-	// TODO: look up order in store, delegate to broker.CancelOrder, update status
-	_ = ctx
-	_ = orderID
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	o, err := e.orders.GetOrder(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if !isOpen(o.Status) {
+		return fmt.Errorf("order %s is %s: %w", orderID, o.Status, ErrOrderNotOpen)
+	}
+	if o.Status == domain.OrderStatusPendingCancel && o.BrokerOrderID != "" {
+		return nil // already requested
+	}
+
+	if err := e.broker.CancelOrder(ctx, brokerID(o)); err != nil {
+		if o.BrokerOrderID != "" {
+			return fmt.Errorf("cancelling order %s: %w", orderID, err)
+		}
+		e.log.Warn("cancel queued for unconfirmed order", "id", orderID, "error", err)
+	}
+
+	o, err = e.amendOrder(ctx, orderID, func(s *domain.Order) bool {
+		if !isWorking(s.Status) {
+			return false // already pending_cancel, or finished meanwhile
+		}
+		s.Status = domain.OrderStatusPendingCancel
+		s.UpdatedAt = time.Now().UTC()
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("persisting cancel request for order %s: %w", orderID, err)
+	}
+	e.log.Info("order cancel requested", "id", orderID)
+
+	if q, ok := e.broker.(broker.OrderQuerier); ok {
+		if remote, err := q.GetOrder(ctx, brokerID(o)); err == nil {
+			if _, err := e.amendOrder(ctx, orderID, func(s *domain.Order) bool { return applyUpdate(s, remote) }); err != nil {
+				// The reconciler will pick the confirmation up again.
+				e.log.Error("persisting cancelled order", "id", orderID, "error", err)
+			}
+		}
+	}
 	return nil
 }
 
-// GetPositions returns all currently open positions.
+// GetOrder returns the stored state of an order.
+func (e *Engine) GetOrder(ctx context.Context, orderID string) (*domain.Order, error) {
+	return e.orders.GetOrder(ctx, orderID)
+}
+
+// ListOrders returns stored orders with the given status ("" for all).
+func (e *Engine) ListOrders(ctx context.Context, status domain.OrderStatus) ([]domain.Order, error) {
+	return e.orders.ListOrders(ctx, status)
+}
+
+// GetPositions returns all currently open positions as last reconciled
+// into the position store.
 func (e *Engine) GetPositions(ctx context.Context) ([]domain.Position, error) {
-	// TODO: read from positions store or delegate to broker
-	_ = ctx
-	return nil, nil
+	return e.positions.ListPositions(ctx)
+}
+
+// GetAccount returns the broker's current account snapshot.
+func (e *Engine) GetAccount(ctx context.Context) (*domain.AccountInfo, error) {
+	return e.broker.GetAccount(ctx)
+}
+
+// HandleOrderUpdate applies a broker-pushed order update (e.g. from
+// broker.OrderStreamer) to the stored order. Updates for orders unknown to
+// the store are saved as new orders; updates that would move a terminal
+// order back to an open status are ignored. It may be called while the
+// order's submission is still in flight.
+func (e *Engine) HandleOrderUpdate(ctx context.Context, update domain.Order) error {
+	_, err := e.amendOrder(ctx, update.ID, func(stored *domain.Order) bool { return applyUpdate(stored, &update) })
+	if !errors.Is(err, store.ErrNotFound) {
+		return err
+	}
+	u := update
+	if u.UpdatedAt.IsZero() {
+		u.UpdatedAt = time.Now().UTC()
+	}
+	if _, err := e.adoptOrder(ctx, &u); err != nil {
+		return err
+	}
+	return nil
+}
+
+// amendOrder re-reads the stored order, applies fn to it and, if fn reports
+// a change, persists and publishes the result. It returns the order as
+// stored afterwards, or an error wrapping store.ErrNotFound.
+func (e *Engine) amendOrder(ctx context.Context, id string, fn func(*domain.Order) bool) (*domain.Order, error) {
+	e.ordersMu.Lock()
+	defer e.ordersMu.Unlock()
+	o, err := e.orders.GetOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !fn(o) {
+		return o, nil
+	}
+	if err := e.orders.UpdateOrder(ctx, o); err != nil {
+		return nil, err
+	}
+	e.publish(*o)
+	return o, nil
+}
+
+// adoptOrder saves o unless an order with its ID is already stored, and
+// reports whether it did.
+func (e *Engine) adoptOrder(ctx context.Context, o *domain.Order) (bool, error) {
+	e.ordersMu.Lock()
+	defer e.ordersMu.Unlock()
+	_, err := e.orders.GetOrder(ctx, o.ID)
+	if err == nil {
+		return false, nil
+	}
+	if !errors.Is(err, store.ErrNotFound) {
+		return false, err
+	}
+	if err := e.orders.SaveOrder(ctx, o); err != nil {
+		return false, err
+	}
+	e.publish(*o)
+	return true, nil
+}
+
+// SubscribeOrders creates a subscription channel that receives every
+// persisted order state change. Slow subscribers drop updates.
+func (e *Engine) SubscribeOrders(bufSize int) (id int, ch <-chan domain.Order) {
+	e.subsMu.Lock()
+	defer e.subsMu.Unlock()
+	id = e.nextSubID
+	e.nextSubID++
+	c := make(chan domain.Order, bufSize)
+	e.subs[id] = c
+	return id, c
+}
+
+// UnsubscribeOrders removes a subscription and closes its channel.
+func (e *Engine) UnsubscribeOrders(id int) {
+	e.subsMu.Lock()
+	defer e.subsMu.Unlock()
+	if ch, ok := e.subs[id]; ok {
+		close(ch)
+		delete(e.subs, id)
+	}
+}
+
+// OrderSubscribers returns the number of open order subscriptions.
+func (e *Engine) OrderSubscribers() int {
+	e.subsMu.Lock()
+	defer e.subsMu.Unlock()
+	return len(e.subs)
+}
+
+// publish notifies order subscribers (non-blocking send).
+func (e *Engine) publish(o domain.Order) {
+	e.subsMu.Lock()
+	defer e.subsMu.Unlock()
+	for _, ch := range e.subs {
+		select {
+		case ch <- o:
+		default:
+			// Slow subscriber, drop update.
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Helpers
+// ---------------------------------------------------------------------------
+
+// isOpen reports whether an order in status s may still execute.
+func isOpen(s domain.OrderStatus) bool {
+	return isWorking(s) || s == domain.OrderStatusPendingCancel
+}
+
+// isWorking reports whether an order in status s is open with no cancel
+// requested.
+func isWorking(s domain.OrderStatus) bool {
+	switch s {
+	case domain.OrderStatusPending, domain.OrderStatusSubmitted, domain.OrderStatusPartial:
+		return true
+	}
+	return false
+}
+
+// brokerID returns the ID to use when addressing o at the broker.
+func brokerID(o *domain.Order) string {
+	if o.BrokerOrderID != "" {
+		return o.BrokerOrderID
+	}
+	return o.ID
+}
+
+// mergeBrokerState copies broker-owned fields from remote into o.
+func mergeBrokerState(o, remote *domain.Order) {
+	if remote == nil {
+		return
+	}
+	if remote.BrokerOrderID != "" {
+		o.BrokerOrderID = remote.BrokerOrderID
+	}
+	if remote.Status != "" {
+		o.Status = remote.Status
+	}
+	o.FilledQty = remote.FilledQty
+	o.FilledAvgPrice = remote.FilledAvgPrice
+}
+
+// applyUpdate merges a broker update into stored and reports whether
+// anything changed. Terminal orders never reopen, fills never shrink and a
+// pending cancel stays pending until the broker reports a terminal status.
+func applyUpdate(stored, update *domain.Order) bool {
+	if !isOpen(stored.Status) && isOpen(update.Status) {
+		return false
+	}
+	if stored.Status == domain.OrderStatusPendingCancel && isOpen(update.Status) {
+		u := *update
+		u.Status = domain.OrderStatusPendingCancel
+		update = &u
+	}
+	if update.FilledQty < stored.FilledQty {
+		return false
+	}
+	if update.Status == stored.Status && update.FilledQty == stored.FilledQty &&
+		update.FilledAvgPrice == stored.FilledAvgPrice &&
+		(update.BrokerOrderID == "" || update.BrokerOrderID == stored.BrokerOrderID) {
+		return false
+	}
+	mergeBrokerState(stored, update)
+	stored.UpdatedAt = update.UpdatedAt
+	if stored.UpdatedAt.IsZero() {
+		stored.UpdatedAt = time.Now().UTC()
+	}
+	return true
+}
+
+// newOrderID returns a random client order ID.
+func newOrderID() string {
+	var b [8]byte
+	rand.Read(b[:])
+	return "jup-" + hex.EncodeToString(b[:])
 }
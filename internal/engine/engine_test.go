@@ -2,9 +2,14 @@ package engine
 
 import (
 	"context"
+	"errors"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"jupitor/internal/broker"
 	"jupitor/internal/domain"
+	"jupitor/internal/store"
 )
 
 func TestNewEngine(t *testing.T) {
@@ -16,6 +21,7 @@ func TestNewEngine(t *testing.T) {
 
 func TestRiskManagerCheckOrder(t *testing.T) {
 	rm := NewRiskManager(0.10, 0.02)
+	rm.SetPriceSource(func(string) (float64, bool) { return 100, true })
 
 	order := &domain.Order{
 		ID:     "test-order-1",
@@ -35,3 +41,393 @@ func TestRiskManagerCheckOrder(t *testing.T) {
 		t.Fatalf("CheckOrder returned unexpected error: %v", err)
 	}
 }
+
+// newTestEngine wires an Engine to a SimulatorBroker and a temp SQLite store.
+func newTestEngine(t *testing.T) (*Engine, *broker.SimulatorBroker, *store.SQLiteStore) {
+	t.Helper()
+	db, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "engine.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sim := broker.NewSimulatorBroker()
+	sim.SetCash(100000)
+	sim.UpdatePrice("AAPL", 100, time.Date(2025, 3, 10, 14, 30, 0, 0, time.UTC))
+	return NewEngine(sim, db, db, NewRiskManager(0.10, 0.02)), sim, db
+}
+
+func TestEngineOrderLifecycle(t *testing.T) {
+	e, sim, db := newTestEngine(t)
+	ctx := context.Background()
+
+	o, err := e.SubmitOrder(ctx, &domain.Order{Symbol: "AAPL", Side: domain.OrderSideBuy, Qty: 10})
+	if err != nil {
+		t.Fatalf("SubmitOrder: %v", err)
+	}
+	if o.ID == "" || o.Status != domain.OrderStatusSubmitted || o.BrokerOrderID == "" {
+		t.Fatalf("submitted order = %+v", o)
+	}
+	stored, err := db.GetOrder(ctx, o.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != domain.OrderStatusSubmitted || stored.Type != domain.OrderTypeMarket ||
+		stored.TimeInForce != domain.TimeInForceDay {
+		t.Errorf("stored order = %+v", stored)
+	}
+
+	// Fill at the broker, then let the reconciler pick up fills and positions.
+	sim.ProcessBar(domain.Bar{Symbol: "AAPL", Timestamp: time.Date(2025, 3, 10, 14, 31, 0, 0, time.UTC),
+		Open: 101, High: 102, Low: 100, Close: 101.5, Volume: 1000})
+	rep, err := e.Reconcile(ctx)
+	if err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if rep.OrdersUpdated != 1 || rep.PositionsUpdated != 1 {
+		t.Errorf("report = %+v", rep)
+	}
+	stored, _ = db.GetOrder(ctx, o.ID)
+	if stored.Status != domain.OrderStatusFilled || stored.FilledQty != 10 || stored.FilledAvgPrice != 101 {
+		t.Errorf("reconciled order = %+v", stored)
+	}
+	positions, err := e.GetPositions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(positions) != 1 || positions[0].Symbol != "AAPL" || positions[0].Qty != 10 {
+		t.Errorf("positions = %+v", positions)
+	}
+
+	if err := e.CancelOrder(ctx, o.ID); !errors.Is(err, ErrOrderNotOpen) {
+		t.Errorf("cancel filled order err = %v, want ErrOrderNotOpen", err)
+	}
+	if err := e.CancelOrder(ctx, "nope"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("cancel unknown order err = %v, want store.ErrNotFound", err)
+	}
+
+	// Second pass finds no drift; a position closed at the broker is removed.
+	if rep, _ := e.Reconcile(ctx); rep.Drift() {
+		t.Errorf("unexpected drift on second pass: %+v", rep)
+	}
+	db.SavePosition(ctx, &domain.Position{Symbol: "MSFT", Qty: 5, AvgEntryPrice: 300, Side: domain.PositionSideLong})
+	if rep, _ := e.Reconcile(ctx); rep.PositionsRemoved != 1 {
+		t.Errorf("stale position not removed: %+v", rep)
+	}
+}
+
+func TestEngineCancelAndReject(t *testing.T) {
+	e, _, db := newTestEngine(t)
+	ctx := context.Background()
+
+	o, err := e.SubmitOrder(ctx, &domain.Order{
+		Symbol: "AAPL", Side: domain.OrderSideBuy, Type: domain.OrderTypeLimit, Qty: 5, LimitPrice: 90,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.CancelOrder(ctx, o.ID); err != nil {
+		t.Fatalf("CancelOrder: %v", err)
+	}
+	stored, _ := db.GetOrder(ctx, o.ID)
+	if stored.Status != domain.OrderStatusCancelled {
+		t.Errorf("status = %s, want cancelled", stored.Status)
+	}
+
+	// The simulator rejects a limit order without a limit price.
+	o, err = e.SubmitOrder(ctx, &domain.Order{Symbol: "AAPL", Side: domain.OrderSideBuy, Type: domain.OrderTypeLimit, Qty: 5})
+	if err == nil {
+		t.Fatal("expected broker rejection")
+	}
+	stored, _ = db.GetOrder(ctx, o.ID)
+	if stored.Status != domain.OrderStatusRejected {
+		t.Errorf("status = %s, want rejected", stored.Status)
+	}
+}
+
+// slowCancelBroker records cancel requests without executing them, like a
+// broker that confirms cancels asynchronously.
+type slowCancelBroker struct {
+	*broker.SimulatorBroker
+	cancels []string
+}
+
+func (b *slowCancelBroker) CancelOrder(_ context.Context, id string) error {
+	b.cancels = append(b.cancels, id)
+	return nil
+}
+
+func TestEngineCancelPendingUntilConfirmed(t *testing.T) {
+	_, sim, db := newTestEngine(t)
+	slow := &slowCancelBroker{SimulatorBroker: sim}
+	e := NewEngine(slow, db, db, nil)
+	ctx := context.Background()
+
+	o, err := e.SubmitOrder(ctx, &domain.Order{
+		Symbol: "AAPL", Side: domain.OrderSideBuy, Type: domain.OrderTypeLimit, Qty: 5, LimitPrice: 90,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.CancelOrder(ctx, o.ID); err != nil {
+		t.Fatalf("CancelOrder: %v", err)
+	}
+	if len(slow.cancels) != 1 || slow.cancels[0] != o.BrokerOrderID {
+		t.Errorf("broker cancels = %v, want [%s]", slow.cancels, o.BrokerOrderID)
+	}
+
+	steps := []struct {
+		status domain.OrderStatus
+		filled float64
+		want   domain.OrderStatus
+	}{
+		{domain.OrderStatusPartial, 2, domain.OrderStatusPendingCancel}, // fill before the cancel lands
+		{domain.OrderStatusCancelled, 2, domain.OrderStatusCancelled},
+	}
+	for _, s := range steps {
+		if stored, _ := db.GetOrder(ctx, o.ID); stored.Status != domain.OrderStatusPendingCancel {
+			t.Fatalf("status before %s update = %s, want pending_cancel", s.status, stored.Status)
+		}
+		u := *o
+		u.Status, u.FilledQty = s.status, s.filled
+		if err := e.HandleOrderUpdate(ctx, u); err != nil {
+			t.Fatal(err)
+		}
+		stored, _ := db.GetOrder(ctx, o.ID)
+		if stored.Status != s.want || stored.FilledQty != s.filled {
+			t.Errorf("after %s update order = %s/%v, want %s/%v", s.status, stored.Status, stored.FilledQty, s.want, s.filled)
+		}
+	}
+}
+
+func TestEngineCancelQueuedUntilBrokerKnowsOrder(t *testing.T) {
+	e, sim, db := newTestEngine(t)
+	ctx := context.Background()
+
+	// A submission that was persisted as pending but never confirmed.
+	o := &domain.Order{ID: "jup-lost", Symbol: "AAPL", Side: domain.OrderSideBuy, Type: domain.OrderTypeLimit,
+		TimeInForce: domain.TimeInForceDay, Qty: 5, LimitPrice: 90, Status: domain.OrderStatusPending}
+	if err := db.SaveOrder(ctx, o); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.CancelOrder(ctx, o.ID); err != nil {
+		t.Fatalf("CancelOrder: %v", err)
+	}
+	if stored, _ := db.GetOrder(ctx, o.ID); stored.Status != domain.OrderStatusPendingCancel {
+		t.Fatalf("status = %s, want pending_cancel", stored.Status)
+	}
+
+	// The order reaches the broker late; the reconciler cancels it there.
+	late := *o
+	if _, err := sim.SubmitOrder(ctx, &late); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Reconcile(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if remote, _ := sim.GetOrder(ctx, o.ID); remote.Status != domain.OrderStatusCancelled {
+		t.Errorf("broker order status = %s, want cancelled", remote.Status)
+	}
+	if stored, _ := db.GetOrder(ctx, o.ID); stored.Status != domain.OrderStatusCancelled {
+		t.Errorf("stored status = %s, want cancelled", stored.Status)
+	}
+}
+
+// streamingBroker pushes each order's fill through the engine's update path
+// before SubmitOrder returns, like an order stream that is faster than the
+// submission response.
+type streamingBroker struct {
+	*broker.SimulatorBroker
+	engine *Engine
+}
+
+func (b *streamingBroker) SubmitOrder(ctx context.Context, o *domain.Order) (*domain.Order, error) {
+	res, err := b.SimulatorBroker.SubmitOrder(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+	fill := *res
+	fill.Status, fill.FilledQty, fill.FilledAvgPrice = domain.OrderStatusFilled, o.Qty, 100
+	if err := b.engine.HandleOrderUpdate(ctx, fill); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func TestEngineFillDuringSubmit(t *testing.T) {
+	_, sim, db := newTestEngine(t)
+	b := &streamingBroker{SimulatorBroker: sim}
+	e := NewEngine(b, db, db, nil)
+	b.engine = e
+	ctx := context.Background()
+
+	o, err := e.SubmitOrder(ctx, &domain.Order{Symbol: "AAPL", Side: domain.OrderSideBuy, Qty: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Status != domain.OrderStatusFilled {
+		t.Errorf("returned status = %s, want filled", o.Status)
+	}
+	stored, err := db.GetOrder(ctx, o.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != domain.OrderStatusFilled || stored.FilledQty != 10 {
+		t.Errorf("stored order = %s/%v, want filled/10", stored.Status, stored.FilledQty)
+	}
+}
+
+func TestEngineHandleOrderUpdate(t *testing.T) {
+	e, _, db := newTestEngine(t)
+	ctx := context.Background()
+
+	o, err := e.SubmitOrder(ctx, &domain.Order{Symbol: "AAPL", Side: domain.OrderSideBuy, Qty: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		status domain.OrderStatus
+		filled float64
+		want   domain.OrderStatus
+	}{
+		{domain.OrderStatusPartial, 4, domain.OrderStatusPartial},
+		{domain.OrderStatusFilled, 10, domain.OrderStatusFilled},
+		{domain.OrderStatusSubmitted, 0, domain.OrderStatusFilled}, // stale update ignored
+	}
+	for _, s := range steps {
+		u := *o
+		u.Status, u.FilledQty, u.FilledAvgPrice = s.status, s.filled, 100
+		if err := e.HandleOrderUpdate(ctx, u); err != nil {
+			t.Fatal(err)
+		}
+		stored, _ := db.GetOrder(ctx, o.ID)
+		if stored.Status != s.want {
+			t.Errorf("after %s update status = %s, want %s", s.status, stored.Status, s.want)
+		}
+	}
+
+	// Updates for unknown orders are adopted.
+	if err := e.HandleOrderUpdate(ctx, domain.Order{ID: "ext-1", Symbol: "MSFT", Side: domain.OrderSideSell,
+		Type: domain.OrderTypeMarket, TimeInForce: domain.TimeInForceDay, Qty: 1, Status: domain.OrderStatusSubmitted}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.GetOrder(ctx, "ext-1"); err != nil {
+		t.Errorf("external order not adopted: %v", err)
+	}
+}
+
+func TestRiskManagerRules(t *testing.T) {
+	account := &domain.AccountInfo{Equity: 100000, Cash: 100000}
+	losing := &domain.AccountInfo{Equity: 97000, DailyPL: -3000}
+	long := func(sym string, qty, price float64) domain.Position {
+		return domain.Position{Symbol: sym, Qty: qty, MarketValue: qty * price, Side: domain.PositionSideLong}
+	}
+	buy := func(sym string, qty, limit float64) *domain.Order {
+		return &domain.Order{Symbol: sym, Side: domain.OrderSideBuy, Type: domain.OrderTypeLimit, Qty: qty, LimitPrice: limit}
+	}
+	sell := func(sym string, qty float64) *domain.Order {
+		return &domain.Order{Symbol: sym, Side: domain.OrderSideSell, Type: domain.OrderTypeMarket, Qty: qty}
+	}
+
+	tests := []struct {
+		name      string
+		setup     func(rm *RiskManager)
+		positions []domain.Position
+		account   *domain.AccountInfo
+		order     *domain.Order
+		want      error
+	}{
+		{"within position limit", nil, nil, account, buy("AAPL", 50, 100), nil},
+		{"position limit", nil, nil, account, buy("AAPL", 101, 100), ErrPositionLimit},
+		{"position limit includes holding", nil, []domain.Position{long("AAPL", 60, 100)}, account, buy("AAPL", 50, 100), ErrPositionLimit},
+		{"market order priced from holding", nil, []domain.Position{long("AAPL", 60, 100)},
+			account, &domain.Order{Symbol: "AAPL", Side: domain.OrderSideBuy, Qty: 50}, ErrPositionLimit},
+		{"unpriced market order", nil, nil, account, &domain.Order{Symbol: "AAPL", Side: domain.OrderSideBuy, Qty: 1}, ErrNoPrice},
+		{"market order priced from source",
+			func(rm *RiskManager) { rm.SetPriceSource(func(string) (float64, bool) { return 100, true }) },
+			nil, account, &domain.Order{Symbol: "AAPL", Side: domain.OrderSideBuy, Qty: 101}, ErrPositionLimit},
+		{"reducing order ignores limit", nil, []domain.Position{long("AAPL", 200, 100)}, account, sell("AAPL", 50), nil},
+		{"unpriced reducing order", nil, []domain.Position{{Symbol: "AAPL", Qty: 10, Side: domain.PositionSideLong}}, account, sell("AAPL", 5), nil},
+		{"daily loss blocks entry", nil, nil, losing, buy("AAPL", 1, 100), ErrDailyLossLimit},
+		{"daily loss allows exit", nil, []domain.Position{long("AAPL", 10, 100)}, losing, sell("AAPL", 10), nil},
+		{"daily loss blocks flip", nil, []domain.Position{long("AAPL", 10, 100)}, losing, sell("AAPL", 20), ErrDailyLossLimit},
+		{"max open positions",
+			func(rm *RiskManager) { rm.SetMaxOpenPositions(2) },
+			[]domain.Position{long("AAPL", 1, 100), long("MSFT", 1, 100)}, account, buy("AMD", 1, 100), ErrMaxOpenPositions},
+		{"max open positions allows adding",
+			func(rm *RiskManager) { rm.SetMaxOpenPositions(2) },
+			[]domain.Position{long("AAPL", 1, 100), long("MSFT", 1, 100)}, account, buy("AAPL", 1, 100), nil},
+		{"tier limit",
+			func(rm *RiskManager) {
+				rm.SetTierLimits(map[string]string{"XYZ": "SPORADIC"}, map[string]float64{"SPORADIC": 0.01})
+			},
+			nil, account, buy("XYZ", 20, 100), ErrTierLimit},
+		{"untiered symbol",
+			func(rm *RiskManager) {
+				rm.SetTierLimits(map[string]string{"XYZ": "SPORADIC"}, map[string]float64{"SPORADIC": 0.01})
+			},
+			nil, account, buy("AAPL", 20, 100), nil},
+		{"halted symbol",
+			func(rm *RiskManager) { rm.HaltSymbol("AAPL") },
+			[]domain.Position{long("AAPL", 10, 100)}, account, sell("AAPL", 10), ErrSymbolHalted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rm := NewRiskManager(0.10, 0.02)
+			rm.SetPositionSource(func(context.Context) ([]domain.Position, error) { return tt.positions, nil })
+			if tt.setup != nil {
+				tt.setup(rm)
+			}
+			err := rm.CheckOrder(context.Background(), tt.order, tt.account)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("CheckOrder err = %v, want %v", err, tt.want)
+			}
+			if tt.want != nil {
+				var re *RiskError
+				if !errors.As(err, &re) || re.Rule == "" || re.Symbol != tt.order.Symbol {
+					t.Errorf("want *RiskError with rule and symbol, got %#v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestRiskManagerKillSwitchSession(t *testing.T) {
+	rm := NewRiskManager(0, 0.02)
+	day1 := time.Date(2025, 3, 10, 15, 0, 0, 0, time.UTC)
+	rm.now = func() time.Time { return day1 }
+	ctx := context.Background()
+	order := &domain.Order{Symbol: "AAPL", Side: domain.OrderSideBuy, Qty: 1}
+
+	if err := rm.CheckOrder(ctx, order, &domain.AccountInfo{Equity: 97000, DailyPL: -3000}); !errors.Is(err, ErrDailyLossLimit) {
+		t.Fatalf("err = %v, want ErrDailyLossLimit", err)
+	}
+	// The switch stays tripped for the session even if P&L recovers.
+	if err := rm.CheckOrder(ctx, order, &domain.AccountInfo{Equity: 100500, DailyPL: 500}); !errors.Is(err, ErrDailyLossLimit) {
+		t.Errorf("recovered P&L err = %v, want ErrDailyLossLimit", err)
+	}
+	if !rm.KillSwitchActive() {
+		t.Error("KillSwitchActive = false, want true")
+	}
+
+	rm.now = func() time.Time { return day1.Add(24 * time.Hour) }
+	if err := rm.CheckOrder(ctx, order, &domain.AccountInfo{Equity: 100000}); err != nil {
+		t.Errorf("next session err = %v, want nil", err)
+	}
+}
+
+func TestEngineSubmitOrderRiskRejection(t *testing.T) {
+	e, _, db := newTestEngine(t)
+	ctx := context.Background()
+
+	_, err := e.SubmitOrder(ctx, &domain.Order{Symbol: "AAPL", Side: domain.OrderSideBuy, Type: domain.OrderTypeLimit, Qty: 1000, LimitPrice: 100})
+	var re *RiskError
+	if !errors.As(err, &re) || re.Rule != "position_limit" {
+		t.Fatalf("err = %v, want position_limit RiskError", err)
+	}
+	if orders, _ := db.ListOrders(ctx, ""); len(orders) != 0 {
+		t.Errorf("rejected order persisted: %+v", orders)
+	}
+}
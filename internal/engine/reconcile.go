@@ -0,0 +1,172 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"jupitor/internal/broker"
+	"jupitor/internal/domain"
+)
+
+// ReconcileReport summarises the repairs made by one reconciliation pass.
+type ReconcileReport struct {
+	PositionsUpdated int // positions whose qty, side or cost differed (or were missing)
+	PositionsRemoved int // stored positions the broker no longer holds
+	OrdersUpdated    int // stored orders whose status or fills differed
+	OrdersAdded      int // open broker orders missing from the store
+}
+
+// Drift reports whether the pass found any difference.
+func (r ReconcileReport) Drift() bool {
+	return r.PositionsUpdated+r.PositionsRemoved+r.OrdersUpdated+r.OrdersAdded > 0
+}
+
+// RunReconciler reconciles immediately and then every interval until ctx is
+// cancelled. Errors are logged and retried on the next tick.
+func (e *Engine) RunReconciler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		rep, err := e.Reconcile(ctx)
+		if err != nil && ctx.Err() == nil {
+			e.log.Error("reconcile failed", "error", err)
+		} else if rep.Drift() {
+			e.log.Warn("reconciled drift",
+				"positionsUpdated", rep.PositionsUpdated, "positionsRemoved", rep.PositionsRemoved,
+				"ordersUpdated", rep.OrdersUpdated, "ordersAdded", rep.OrdersAdded)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reconcile diffs the broker's positions and orders against the stores and
+// repairs the stores to match the broker, which is the source of truth.
+// Order reconciliation requires the broker to implement
+// broker.OrderQuerier; otherwise only positions are reconciled.
+func (e *Engine) Reconcile(ctx context.Context) (ReconcileReport, error) {
+	var rep ReconcileReport
+	if err := e.reconcilePositions(ctx, &rep); err != nil {
+		return rep, err
+	}
+	if q, ok := e.broker.(broker.OrderQuerier); ok {
+		if err := e.reconcileOrders(ctx, q, &rep); err != nil {
+			return rep, err
+		}
+	}
+	return rep, nil
+}
+
+func (e *Engine) reconcilePositions(ctx context.Context, rep *ReconcileReport) error {
+	remote, err := e.broker.GetPositions(ctx)
+	if err != nil {
+		return fmt.Errorf("loading broker positions: %w", err)
+	}
+	local, err := e.positions.ListPositions(ctx)
+	if err != nil {
+		return fmt.Errorf("loading stored positions: %w", err)
+	}
+
+	stored := make(map[string]domain.Position, len(local))
+	for _, p := range local {
+		stored[p.Symbol] = p
+	}
+
+	held := make(map[string]bool, len(remote))
+	for i := range remote {
+		p := remote[i]
+		held[p.Symbol] = true
+		prev, ok := stored[p.Symbol]
+		if !ok || prev.Qty != p.Qty || prev.Side != p.Side || prev.AvgEntryPrice != p.AvgEntryPrice {
+			rep.PositionsUpdated++
+			e.log.Info("position drift", "symbol", p.Symbol, "storedQty", prev.Qty, "brokerQty", p.Qty)
+		}
+		if ok && !prev.OpenedAt.IsZero() && prev.Side == p.Side {
+			p.OpenedAt = prev.OpenedAt
+		}
+		// Always save so market value and unrealized P&L stay fresh.
+		if err := e.positions.SavePosition(ctx, &p); err != nil {
+			return fmt.Errorf("saving position %s: %w", p.Symbol, err)
+		}
+	}
+
+	for sym := range stored {
+		if held[sym] {
+			continue
+		}
+		if err := e.positions.DeletePosition(ctx, sym); err != nil {
+			return fmt.Errorf("deleting position %s: %w", sym, err)
+		}
+		rep.PositionsRemoved++
+		e.log.Info("position closed at broker", "symbol", sym)
+	}
+	return nil
+}
+
+func (e *Engine) reconcileOrders(ctx context.Context, q broker.OrderQuerier, rep *ReconcileReport) error {
+	var open []domain.Order
+	for _, s := range []domain.OrderStatus{domain.OrderStatusPending, domain.OrderStatusSubmitted, domain.OrderStatusPartial, domain.OrderStatusPendingCancel} {
+		orders, err := e.orders.ListOrders(ctx, s)
+		if err != nil {
+			return fmt.Errorf("loading %s orders: %w", s, err)
+		}
+		open = append(open, orders...)
+	}
+
+	for i := range open {
+		o := &open[i]
+		remote, err := q.GetOrder(ctx, brokerID(o))
+		if err != nil {
+			// Usually transient; a pending order may also not have reached
+			// the broker yet. Try again on the next pass.
+			e.log.Warn("reconcile: broker order lookup failed", "id", o.ID, "error", err)
+			continue
+		}
+		if o.Status == domain.OrderStatusPendingCancel && isWorking(remote.Status) {
+			// The broker has not seen the cancel (e.g. it was queued before
+			// the order reached the broker): send it again.
+			if err := e.broker.CancelOrder(ctx, brokerID(remote)); err != nil {
+				e.log.Warn("reconcile: re-sending cancel failed", "id", o.ID, "error", err)
+			} else if r, err := q.GetOrder(ctx, brokerID(remote)); err == nil {
+				remote = r
+			}
+		}
+		// Apply to the order as stored now, not as listed above: a
+		// submission or streamed update may have moved it on since.
+		changed := false
+		stored, err := e.amendOrder(ctx, o.ID, func(s *domain.Order) bool {
+			changed = applyUpdate(s, remote)
+			return changed
+		})
+		if err != nil {
+			return fmt.Errorf("updating order %s: %w", o.ID, err)
+		}
+		if !changed {
+			continue
+		}
+		rep.OrdersUpdated++
+		e.log.Info("order drift", "id", o.ID, "status", stored.Status, "filledQty", stored.FilledQty)
+	}
+
+	brokerOpen, err := q.ListOpenOrders(ctx)
+	if err != nil {
+		return fmt.Errorf("listing broker orders: %w", err)
+	}
+	for i := range brokerOpen {
+		bo := &brokerOpen[i]
+		added, err := e.adoptOrder(ctx, bo)
+		if err != nil {
+			return fmt.Errorf("adopting broker order %s: %w", bo.ID, err)
+		}
+		if !added {
+			continue
+		}
+		rep.OrdersAdded++
+		e.log.Info("adopted broker order", "id", bo.ID, "symbol", bo.Symbol)
+	}
+	return nil
+}
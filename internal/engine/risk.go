@@ -2,15 +2,117 @@ package engine
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"jupitor/internal/domain"
+	"jupitor/internal/ettime"
 )
 
+// ---------------------------------------------------------------------------
+// Typed rejections
+// ---------------------------------------------------------------------------
+
+// Sentinel errors identifying which risk rule rejected an order. Rejections
+// are returned as *RiskError, which unwraps to one of these.
+var (
+	ErrPositionLimit    = errors.New("position limit exceeded")
+	ErrDailyLossLimit   = errors.New("daily loss limit reached")
+	ErrMaxOpenPositions = errors.New("max open positions reached")
+	ErrTierLimit        = errors.New("tier position limit exceeded")
+	ErrSymbolHalted     = errors.New("symbol halted")
+	ErrNoPrice          = errors.New("order cannot be priced")
+)
+
+// RiskError describes a rejected order. Rule is a stable identifier that
+// API layers can surface to clients (e.g. "position_limit").
+type RiskError struct {
+	Rule   string
+	Symbol string
+	Reason string
+	Err    error // one of the Err* sentinels
+}
+
+func (e *RiskError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", e.Symbol, e.Err, e.Reason)
+}
+
+func (e *RiskError) Unwrap() error { return e.Err }
+
+// ---------------------------------------------------------------------------
+// Rule interface
+// ---------------------------------------------------------------------------
+
+// RiskCheck is the state a RiskRule evaluates an order against.
+type RiskCheck struct {
+	Order     *domain.Order
+	Account   *domain.AccountInfo
+	Positions map[string]domain.Position // by symbol
+	Price     float64                    // reference price for the order; 0 if unknown
+	Now       time.Time
+}
+
+// CurrentQty returns the signed quantity held in the order's symbol.
+func (c *RiskCheck) CurrentQty() float64 {
+	p, ok := c.Positions[c.Order.Symbol]
+	if !ok {
+		return 0
+	}
+	if p.Side == domain.PositionSideShort {
+		return -p.Qty
+	}
+	return p.Qty
+}
+
+// ProjectedQty returns the signed quantity held if the order fills fully.
+func (c *RiskCheck) ProjectedQty() float64 {
+	if c.Order.Side == domain.OrderSideSell {
+		return c.CurrentQty() - c.Order.Qty
+	}
+	return c.CurrentQty() + c.Order.Qty
+}
+
+// IncreasesExposure reports whether the order opens, grows or flips a
+// position rather than only reducing one.
+func (c *RiskCheck) IncreasesExposure() bool {
+	cur, proj := c.CurrentQty(), c.ProjectedQty()
+	if cur*proj < 0 {
+		return true
+	}
+	return math.Abs(proj) > math.Abs(cur)
+}
+
+// RiskRule is a single pre-trade check. Check returns a *RiskError to reject
+// the order and nil to allow it.
+type RiskRule interface {
+	Name() string
+	Check(ctx context.Context, c *RiskCheck) error
+}
+
+// ---------------------------------------------------------------------------
+// RiskManager
+// ---------------------------------------------------------------------------
+
 // RiskManager enforces pre-trade risk rules such as position sizing limits
-// and maximum daily loss constraints.
+// and maximum daily loss constraints. Rules are evaluated in order and the
+// first rejection wins.
 type RiskManager struct {
 	maxPositionPct  float64
 	maxDailyLossPct float64
+
+	dailyLoss *DailyLossRule
+	halted    *HaltedSymbolsRule
+
+	mu        sync.Mutex
+	rules     []RiskRule
+	positions func(ctx context.Context) ([]domain.Position, error)
+	prices    func(symbol string) (float64, bool)
+	now       func() time.Time
 }
 
 // NewRiskManager creates a RiskManager with the specified risk thresholds.
@@ -19,19 +121,351 @@ type RiskManager struct {
 //     (e.g. 0.10 for 10%).
 //   - maxDailyLossPct: maximum fraction of equity that may be lost in a single
 //     trading day (e.g. 0.02 for 2%).
+//
+// A threshold of 0 disables the corresponding rule. The halted-symbol
+// blocklist is always installed; further rules can be added with AddRule,
+// SetMaxOpenPositions and SetTierLimits.
 func NewRiskManager(maxPositionPct, maxDailyLossPct float64) *RiskManager {
-	return &RiskManager{
+	rm := &RiskManager{
 		maxPositionPct:  maxPositionPct,
 		maxDailyLossPct: maxDailyLossPct,
+		halted:          NewHaltedSymbolsRule(),
+		now:             time.Now,
+	}
+	rm.rules = append(rm.rules, rm.halted)
+	if maxDailyLossPct > 0 {
+		rm.dailyLoss = NewDailyLossRule(maxDailyLossPct)
+		rm.rules = append(rm.rules, rm.dailyLoss)
+	}
+	if maxPositionPct > 0 {
+		rm.rules = append(rm.rules, &PositionLimitRule{MaxPct: maxPositionPct})
+	}
+	return rm
+}
+
+// AddRule appends a rule to the rule set.
+func (rm *RiskManager) AddRule(r RiskRule) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.rules = append(rm.rules, r)
+}
+
+// SetMaxOpenPositions limits the number of distinct symbols held at once.
+func (rm *RiskManager) SetMaxOpenPositions(n int) {
+	if n > 0 {
+		rm.AddRule(&MaxOpenPositionsRule{Max: n})
+	}
+}
+
+// SetTierLimits caps position size per trade-universe tier. tiers maps
+// symbol → tier (ACTIVE, MODERATE, SPORADIC) as produced by
+// dashboard.LoadTierMap; maxPct maps tier → fraction of equity.
+func (rm *RiskManager) SetTierLimits(tiers map[string]string, maxPct map[string]float64) {
+	if len(maxPct) > 0 {
+		rm.AddRule(&TierLimitRule{Tiers: tiers, MaxPct: maxPct})
+	}
+}
+
+// SetPositionSource sets the function used to load current positions.
+// Without one, position-dependent rules see an empty book.
+func (rm *RiskManager) SetPositionSource(fn func(ctx context.Context) ([]domain.Position, error)) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.positions = fn
+}
+
+// SetPriceSource sets the function used to price market orders, e.g.
+// LiveModel.LastPrice. Notional limits reject orders that increase exposure
+// when neither the order, the source nor a marked position gives a price.
+func (rm *RiskManager) SetPriceSource(fn func(symbol string) (float64, bool)) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.prices = fn
+}
+
+// HaltSymbol blocks new orders in symbol until ResumeSymbol is called.
+func (rm *RiskManager) HaltSymbol(symbol string) { rm.halted.Halt(symbol) }
+
+// ResumeSymbol removes symbol from the halted blocklist.
+func (rm *RiskManager) ResumeSymbol(symbol string) { rm.halted.Resume(symbol) }
+
+// HaltedSymbols returns the halted blocklist, sorted.
+func (rm *RiskManager) HaltedSymbols() []string { return rm.halted.Symbols() }
+
+// KillSwitchActive reports whether the daily loss limit has blocked new
+// entries for the current session.
+func (rm *RiskManager) KillSwitchActive() bool {
+	return rm.dailyLoss != nil && rm.dailyLoss.Tripped(rm.now())
+}
+
+// ResetKillSwitch re-enables new entries after the daily loss limit tripped.
+// The switch trips again on the next entry if the loss still exceeds the
+// limit.
+func (rm *RiskManager) ResetKillSwitch() {
+	if rm.dailyLoss != nil {
+		rm.dailyLoss.Reset()
 	}
 }
 
 // CheckOrder evaluates whether the proposed order complies with the
-// configured risk limits given the current account state.
-func (rm *RiskManager) CheckOrder(_ context.Context, _ *domain.Order, _ *domain.AccountInfo) error {
-	// TODO: check that the order's notional value does not exceed
-	// maxPositionPct of account equity.
-	// TODO: check that filling this order would not push daily P&L loss
-	// beyond maxDailyLossPct of account equity.
+// configured risk limits given the current account state. Rejections are
+// returned as *RiskError.
+func (rm *RiskManager) CheckOrder(ctx context.Context, order *domain.Order, account *domain.AccountInfo) error {
+	rm.mu.Lock()
+	rules := append([]RiskRule(nil), rm.rules...)
+	posFn, priceFn, now := rm.positions, rm.prices, rm.now()
+	rm.mu.Unlock()
+
+	c := &RiskCheck{
+		Order:     order,
+		Account:   account,
+		Positions: make(map[string]domain.Position),
+		Now:       now,
+	}
+	if posFn != nil {
+		positions, err := posFn(ctx)
+		if err != nil {
+			return fmt.Errorf("loading positions: %w", err)
+		}
+		for _, p := range positions {
+			c.Positions[p.Symbol] = p
+		}
+	}
+	c.Price = referencePrice(order, c.Positions, priceFn)
+
+	for _, r := range rules {
+		if err := r.Check(ctx, c); err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// referencePrice picks the price used to value an order: the limit or stop
+// price, else the price source, else the mark of an existing position.
+func referencePrice(o *domain.Order, positions map[string]domain.Position, prices func(string) (float64, bool)) float64 {
+	if o.LimitPrice > 0 {
+		return o.LimitPrice
+	}
+	if o.StopPrice > 0 {
+		return o.StopPrice
+	}
+	if prices != nil {
+		if p, ok := prices(o.Symbol); ok && p > 0 {
+			return p
+		}
+	}
+	if p, ok := positions[o.Symbol]; ok && p.Qty != 0 && p.MarketValue != 0 {
+		return math.Abs(p.MarketValue / p.Qty)
+	}
+	return 0
+}
+
+// ---------------------------------------------------------------------------
+// Rules
+// ---------------------------------------------------------------------------
+
+// PositionLimitRule caps the notional value of any single position at MaxPct
+// of equity. Orders that increase exposure but cannot be priced are rejected
+// with ErrNoPrice.
+type PositionLimitRule struct {
+	MaxPct float64
+}
+
+func (r *PositionLimitRule) Name() string { return "position_limit" }
+
+func (r *PositionLimitRule) Check(_ context.Context, c *RiskCheck) error {
+	return checkNotional(c, r.Name(), r.MaxPct, ErrPositionLimit)
+}
+
+// TierLimitRule caps position notional per trade-universe tier. Symbols
+// without a tier, or in a tier with no cap, are not limited by this rule.
+type TierLimitRule struct {
+	Tiers  map[string]string  // symbol → tier
+	MaxPct map[string]float64 // tier → fraction of equity
+}
+
+func (r *TierLimitRule) Name() string { return "tier_limit" }
+
+func (r *TierLimitRule) Check(_ context.Context, c *RiskCheck) error {
+	tier, ok := r.Tiers[c.Order.Symbol]
+	if !ok {
+		return nil
+	}
+	pct, ok := r.MaxPct[strings.ToUpper(tier)]
+	if !ok {
+		return nil
+	}
+	return checkNotional(c, r.Name(), pct, ErrTierLimit)
+}
+
+func checkNotional(c *RiskCheck, rule string, maxPct float64, sentinel error) error {
+	if maxPct <= 0 || c.Account == nil || !c.IncreasesExposure() {
+		return nil
+	}
+	if c.Price <= 0 {
+		return &RiskError{
+			Rule:   rule,
+			Symbol: c.Order.Symbol,
+			Reason: "no reference price to check the position limit",
+			Err:    ErrNoPrice,
+		}
+	}
+	notional := math.Abs(c.ProjectedQty()) * c.Price
+	limit := maxPct * c.Account.Equity
+	if notional <= limit {
+		return nil
+	}
+	return &RiskError{
+		Rule:   rule,
+		Symbol: c.Order.Symbol,
+		Reason: fmt.Sprintf("position notional %.2f exceeds %.2f (%.2f%% of equity)", notional, limit, maxPct*100),
+		Err:    sentinel,
+	}
+}
+
+// MaxOpenPositionsRule rejects orders that would open a new position when
+// Max symbols are already held.
+type MaxOpenPositionsRule struct {
+	Max int
+}
+
+func (r *MaxOpenPositionsRule) Name() string { return "max_open_positions" }
+
+func (r *MaxOpenPositionsRule) Check(_ context.Context, c *RiskCheck) error {
+	if r.Max <= 0 || c.CurrentQty() != 0 || !c.IncreasesExposure() {
+		return nil
+	}
+	open := 0
+	for _, p := range c.Positions {
+		if p.Qty != 0 {
+			open++
+		}
+	}
+	if open < r.Max {
+		return nil
+	}
+	return &RiskError{
+		Rule:   r.Name(),
+		Symbol: c.Order.Symbol,
+		Reason: fmt.Sprintf("%d positions open, max %d", open, r.Max),
+		Err:    ErrMaxOpenPositions,
+	}
+}
+
+// DailyLossRule is a kill-switch: once the session's realized plus
+// unrealized loss (AccountInfo.DailyPL) reaches MaxPct of the starting
+// equity, it blocks new entries for the rest of the ET trading day. Orders
+// that only reduce exposure are always allowed.
+type DailyLossRule struct {
+	MaxPct float64
+
+	mu         sync.Mutex
+	loc        *time.Location
+	trippedDay string // ET date (YYYY-MM-DD) the switch tripped on
+}
+
+// NewDailyLossRule creates a DailyLossRule for the given loss fraction.
+func NewDailyLossRule(maxPct float64) *DailyLossRule {
+	return &DailyLossRule{MaxPct: maxPct, loc: ettime.Location()}
+}
+
+func (r *DailyLossRule) Name() string { return "daily_loss" }
+
+// Tripped reports whether the switch is active for the session containing now.
+func (r *DailyLossRule) Tripped(now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.trippedDay != "" && r.trippedDay == now.In(r.loc).Format("2006-01-02")
+}
+
+// Reset clears the switch for the current session.
+func (r *DailyLossRule) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.trippedDay = ""
+}
+
+func (r *DailyLossRule) Check(_ context.Context, c *RiskCheck) error {
+	if !c.IncreasesExposure() {
+		return nil
+	}
+	day := c.Now.In(r.loc).Format("2006-01-02")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.trippedDay != day && c.Account != nil {
+		start := c.Account.Equity - c.Account.DailyPL
+		if start > 0 && c.Account.DailyPL <= -r.MaxPct*start {
+			r.trippedDay = day
+		}
+	}
+	if r.trippedDay != day {
+		return nil
+	}
+	return &RiskError{
+		Rule:   r.Name(),
+		Symbol: c.Order.Symbol,
+		Reason: fmt.Sprintf("new entries blocked for session %s (limit %.2f%%)", day, r.MaxPct*100),
+		Err:    ErrDailyLossLimit,
+	}
+}
+
+// HaltedSymbolsRule rejects all orders in blocklisted symbols.
+type HaltedSymbolsRule struct {
+	mu      sync.RWMutex
+	symbols map[string]bool
+}
+
+// NewHaltedSymbolsRule creates an empty blocklist.
+func NewHaltedSymbolsRule(symbols ...string) *HaltedSymbolsRule {
+	r := &HaltedSymbolsRule{symbols: make(map[string]bool)}
+	for _, s := range symbols {
+		r.symbols[s] = true
+	}
+	return r
+}
+
+func (r *HaltedSymbolsRule) Name() string { return "symbol_halted" }
+
+// Halt adds symbol to the blocklist.
+func (r *HaltedSymbolsRule) Halt(symbol string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.symbols[symbol] = true
+}
+
+// Resume removes symbol from the blocklist.
+func (r *HaltedSymbolsRule) Resume(symbol string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.symbols, symbol)
+}
+
+// Symbols returns the blocklisted symbols, sorted.
+func (r *HaltedSymbolsRule) Symbols() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]string, 0, len(r.symbols))
+	for s := range r.symbols {
+		out = append(out, s)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (r *HaltedSymbolsRule) Check(_ context.Context, c *RiskCheck) error {
+	r.mu.RLock()
+	halted := r.symbols[c.Order.Symbol]
+	r.mu.RUnlock()
+	if !halted {
+		return nil
+	}
+	return &RiskError{
+		Rule:   r.Name(),
+		Symbol: c.Order.Symbol,
+		Reason: "trading in symbol is halted",
+		Err:    ErrSymbolHalted,
+	}
+}
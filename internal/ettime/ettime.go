@@ -0,0 +1,133 @@
+// Package ettime models US equity session time. Trade records store
+// timestamps as SessionTime: the Eastern wall-clock reading encoded as if it
+// were UTC, so that a record's calendar date and clock can be read without a
+// time zone. This package is the single place that converts between that
+// convention and real instants; conversions resolve the UTC offset from the
+// wall clock itself, which keeps them correct on DST transition days.
+package ettime
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	_ "time/tzdata" // session math must not depend on the host's zoneinfo
+
+	"jupitor/internal/domain"
+	"jupitor/internal/util"
+)
+
+var location = sync.OnceValue(func() *time.Location {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		panic(fmt.Sprintf("ettime: loading America/New_York: %v", err))
+	}
+	return loc
+})
+
+// Location returns the America/New_York time zone.
+func Location() *time.Location { return location() }
+
+// SessionTime is an Eastern wall-clock instant in milliseconds: the Unix ms
+// of the same wall-clock reading in UTC. It is the timestamp convention of
+// store.TradeRecord and the live model.
+type SessionTime int64
+
+// FromTime returns the session time of instant t.
+func FromTime(t time.Time) SessionTime {
+	w := t.In(Location())
+	y, mo, d := w.Date()
+	return SessionTime(time.Date(y, mo, d, w.Hour(), w.Minute(), w.Second(), w.Nanosecond(), time.UTC).UnixMilli())
+}
+
+// FromUnixMilli returns the session time of a real Unix millisecond instant.
+func FromUnixMilli(ms int64) SessionTime {
+	return FromTime(time.UnixMilli(ms))
+}
+
+// At returns hour:minute Eastern on date (YYYY-MM-DD).
+func At(date string, hour, minute int) (SessionTime, error) {
+	d, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		return 0, err
+	}
+	return SessionTime(time.Date(d.Year(), d.Month(), d.Day(), hour, minute, 0, 0, time.UTC).UnixMilli()), nil
+}
+
+// OnDate returns hour:minute Eastern on the Eastern date of instant t.
+func OnDate(t time.Time, hour, minute int) SessionTime {
+	y, mo, d := t.In(Location()).Date()
+	return SessionTime(time.Date(y, mo, d, hour, minute, 0, 0, time.UTC).UnixMilli())
+}
+
+// DateOf returns the Eastern calendar date (YYYY-MM-DD) of instant t.
+func DateOf(t time.Time) string {
+	return t.In(Location()).Format(time.DateOnly)
+}
+
+// Time returns the real instant, in the Eastern time zone. Wall-clock
+// readings repeated when DST ends resolve to the earlier (EDT) instant.
+func (s SessionTime) Time() time.Time {
+	w := s.Wall()
+	y, mo, d := w.Date()
+	return time.Date(y, mo, d, w.Hour(), w.Minute(), w.Second(), w.Nanosecond(), Location())
+}
+
+// UnixMilli returns the real Unix millisecond instant.
+func (s SessionTime) UnixMilli() int64 { return s.Time().UnixMilli() }
+
+// Wall returns the wall-clock reading as a UTC time, for formatting and date
+// arithmetic that must not shift across DST.
+func (s SessionTime) Wall() time.Time { return time.UnixMilli(int64(s)).UTC() }
+
+// Date returns the Eastern calendar date (YYYY-MM-DD).
+func (s SessionTime) Date() string { return s.Wall().Format(time.DateOnly) }
+
+// Add returns s shifted by d of wall-clock time.
+func (s SessionTime) Add(d time.Duration) SessionTime { return s + SessionTime(d.Milliseconds()) }
+
+// String formats s as its Eastern wall clock.
+func (s SessionTime) String() string { return s.Wall().Format("2006-01-02 15:04:05.000") + " ET" }
+
+// Bounds are one date's US equity session boundaries.
+type Bounds struct {
+	PreOpen   SessionTime // 04:00
+	Open      SessionTime // 09:30
+	Close     SessionTime // 16:00, 13:00 on half days
+	PostClose SessionTime // 20:00, 17:00 on half days
+}
+
+// BoundsFor returns the session boundaries of date (YYYY-MM-DD). When cal is
+// given and date is a trading day, its hours (including half days) are used;
+// otherwise the regular hours are.
+func BoundsFor(date string, cal *util.TradingCalendar) (Bounds, error) {
+	d, err := time.ParseInLocation(time.DateOnly, date, Location())
+	if err != nil {
+		return Bounds{}, err
+	}
+	return BoundsOn(d, cal), nil
+}
+
+// BoundsOn returns the session boundaries of the Eastern date of instant t;
+// see BoundsFor.
+func BoundsOn(t time.Time, cal *util.TradingCalendar) Bounds {
+	y, mo, d := t.In(Location()).Date()
+	if cal != nil {
+		if s, ok := cal.Session(time.Date(y, mo, d, 12, 0, 0, 0, Location())); ok {
+			return Bounds{
+				PreOpen:   FromTime(s.PreOpen),
+				Open:      FromTime(s.Open),
+				Close:     FromTime(s.Close),
+				PostClose: FromTime(s.PostClose),
+			}
+		}
+	}
+	h := util.DefaultSessionHours(domain.MarketUS)
+	base := SessionTime(time.Date(y, mo, d, 0, 0, 0, 0, time.UTC).UnixMilli())
+	return Bounds{
+		PreOpen:   base.Add(h.PreOpen),
+		Open:      base.Add(h.Open),
+		Close:     base.Add(h.Close),
+		PostClose: base.Add(h.PostClose),
+	}
+}
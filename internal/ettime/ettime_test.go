@@ -0,0 +1,116 @@
+package ettime
+
+import (
+	"testing"
+	"time"
+
+	"jupitor/internal/domain"
+	"jupitor/internal/util"
+)
+
+func TestSessionTimeRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		utc  time.Time
+		wall string
+	}{
+		{"winter", time.Date(2024, 1, 2, 14, 30, 0, 0, time.UTC), "2024-01-02 09:30"},
+		{"summer", time.Date(2024, 7, 1, 13, 30, 0, 0, time.UTC), "2024-07-01 09:30"},
+		// 2024-03-10: clocks jump 02:00 EST -> 03:00 EDT.
+		{"before spring forward", time.Date(2024, 3, 10, 6, 59, 0, 0, time.UTC), "2024-03-10 01:59"},
+		{"after spring forward", time.Date(2024, 3, 10, 7, 0, 0, 0, time.UTC), "2024-03-10 03:00"},
+		{"pre-market spring forward", time.Date(2024, 3, 11, 8, 0, 0, 0, time.UTC), "2024-03-11 04:00"},
+		// 2024-11-03: clocks fall back 02:00 EDT -> 01:00 EST.
+		{"fall back first 01:30", time.Date(2024, 11, 3, 5, 30, 0, 0, time.UTC), "2024-11-03 01:30"},
+		{"after fall back", time.Date(2024, 11, 3, 7, 0, 0, 0, time.UTC), "2024-11-03 02:00"},
+		{"evening UTC next day", time.Date(2024, 11, 5, 1, 0, 0, 0, time.UTC), "2024-11-04 20:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := FromTime(tt.utc)
+			if got := s.Wall().Format("2006-01-02 15:04"); got != tt.wall {
+				t.Errorf("wall = %s, want %s", got, tt.wall)
+			}
+			if got := s.UnixMilli(); got != tt.utc.UnixMilli() {
+				t.Errorf("UnixMilli = %d, want %d", got, tt.utc.UnixMilli())
+			}
+			if got := FromUnixMilli(tt.utc.UnixMilli()); got != s {
+				t.Errorf("FromUnixMilli = %v, want %v", got, s)
+			}
+			if got := s.Date(); got != tt.wall[:10] {
+				t.Errorf("Date = %s, want %s", got, tt.wall[:10])
+			}
+		})
+	}
+}
+
+func TestSessionTimeAmbiguousHour(t *testing.T) {
+	// 01:30 occurs twice on 2024-11-03; the second (EST) reading maps back to
+	// the first (EDT) instant.
+	second := time.Date(2024, 11, 3, 6, 30, 0, 0, time.UTC)
+	s := FromTime(second)
+	if got := s.Wall().Format("15:04"); got != "01:30" {
+		t.Fatalf("wall = %s, want 01:30", got)
+	}
+	if got, want := s.Time(), second.Add(-time.Hour); !got.Equal(want) {
+		t.Errorf("Time = %v, want %v", got, want)
+	}
+}
+
+func TestAtAndOnDate(t *testing.T) {
+	open, err := At("2024-03-11", 9, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := open.Time(), time.Date(2024, 3, 11, 13, 30, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("At(2024-03-11 09:30).Time() = %v, want %v", got, want)
+	}
+	if got := OnDate(time.Date(2024, 3, 12, 2, 0, 0, 0, time.UTC), 9, 30); got != open {
+		t.Errorf("OnDate at 22:00 ET = %v, want %v", got, open)
+	}
+	if got := DateOf(time.Date(2024, 3, 12, 2, 0, 0, 0, time.UTC)); got != "2024-03-11" {
+		t.Errorf("DateOf = %s", got)
+	}
+	if _, err := At("03/11/2024", 9, 30); err == nil {
+		t.Error("At accepted a malformed date")
+	}
+}
+
+func TestBoundsFor(t *testing.T) {
+	cal := util.NewTradingCalendar(domain.MarketUS)
+	tests := []struct {
+		date             string
+		cal              *util.TradingCalendar
+		close, postClose string
+	}{
+		{"2024-07-01", cal, "16:00", "20:00"},
+		{"2024-07-03", cal, "13:00", "17:00"}, // half day
+		{"2024-07-03", nil, "16:00", "20:00"},
+		{"2024-07-04", cal, "16:00", "20:00"}, // holiday falls back to regular hours
+	}
+	for _, tt := range tests {
+		b, err := BoundsFor(tt.date, tt.cal)
+		if err != nil {
+			t.Fatalf("BoundsFor(%s): %v", tt.date, err)
+		}
+		if got := b.PreOpen.String(); got != tt.date+" 04:00:00.000 ET" {
+			t.Errorf("%s PreOpen = %s", tt.date, got)
+		}
+		if got := b.Open.Wall().Format("15:04"); got != "09:30" {
+			t.Errorf("%s Open = %s", tt.date, got)
+		}
+		if got := b.Close.Wall().Format("15:04"); got != tt.close {
+			t.Errorf("%s Close = %s, want %s", tt.date, got, tt.close)
+		}
+		if got := b.PostClose.Wall().Format("15:04"); got != tt.postClose {
+			t.Errorf("%s PostClose = %s, want %s", tt.date, got, tt.postClose)
+		}
+		d, _ := time.ParseInLocation(time.DateOnly, tt.date, Location())
+		if on := BoundsOn(d.Add(23*time.Hour), tt.cal); on != b {
+			t.Errorf("BoundsOn(%s 23:00) = %+v, want %+v", tt.date, on, b)
+		}
+	}
+	if _, err := BoundsFor("07/01/2024", cal); err == nil {
+		t.Error("BoundsFor accepted a malformed date")
+	}
+}
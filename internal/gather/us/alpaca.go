@@ -18,12 +18,14 @@ import (
 	alpacaapi "github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
 	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
 	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata/stream"
-	"github.com/parquet-go/parquet-go"
 
+	"jupitor/internal/conditions"
 	"jupitor/internal/domain"
+	"jupitor/internal/ettime"
 	"jupitor/internal/gather"
 	"jupitor/internal/live"
 	"jupitor/internal/store"
+	"jupitor/internal/util"
 )
 
 // ---------------------------------------------------------------------------
@@ -33,16 +35,6 @@ import (
 var _ gather.Gatherer = (*DailyBarGatherer)(nil)
 var _ gather.Gatherer = (*StreamGatherer)(nil)
 
-// utcToETTime converts a UTC time to an ET-as-UTC time.Time. The returned
-// time has ET clock values (hour, minute, second) but is tagged as UTC,
-// making .UnixMilli() return ET-shifted milliseconds and .Format("2006-01-02")
-// return the ET-calendar date.
-func utcToETTime(t time.Time, loc *time.Location) time.Time {
-	et := t.In(loc)
-	_, offset := et.Zone()
-	return time.UnixMilli(t.UnixMilli() + int64(offset)*1000).UTC()
-}
-
 // ---------------------------------------------------------------------------
 // DailyBarGatherer — long-running daemon for daily bars + trade backfill.
 // ---------------------------------------------------------------------------
@@ -66,6 +58,8 @@ type DailyBarGatherer struct {
 	baseURL      string // live trading API for calendar
 	refData      *ReferenceData
 	exIndexOnly  bool // when true, trade backfill skips ETFs and index (SPX/NDX) stocks
+	market       *util.MarketInfo
+	calendar     *util.TradingCalendar
 	loc          *time.Location
 	log          *slog.Logger
 }
@@ -88,7 +82,7 @@ func NewDailyBarGatherer(
 		opts.BaseURL = dataURL
 	}
 
-	loc, _ := time.LoadLocation("America/New_York")
+	market := defaultMarket()
 
 	return &DailyBarGatherer{
 		client:       marketdata.NewClient(opts),
@@ -103,7 +97,8 @@ func NewDailyBarGatherer(
 		apiSecret:    apiSecret,
 		baseURL:      baseURL,
 		refData:      LoadReferenceData(refDir),
-		loc:          loc,
+		market:       market,
+		loc:          market.Location,
 		log:          slog.Default().With("daemon", "us-alpaca-data"),
 	}
 }
@@ -114,6 +109,9 @@ func (g *DailyBarGatherer) Name() string { return "us-alpaca-data" }
 // Run is the main daemon loop. It runs forever, alternating between daily bar
 // updates (triggered at 8:05 PM ET) and trade backfill (latest dates first).
 func (g *DailyBarGatherer) Run(ctx context.Context) error {
+	if g.calendar == nil {
+		g.calendar = NewCalendar(g.market, g.apiKey, g.apiSecret, g.baseURL, g.dataDir(), g.log)
+	}
 	for {
 		if ctx.Err() != nil {
 			return nil
@@ -163,23 +161,11 @@ func (g *DailyBarGatherer) Run(ctx context.Context) error {
 // Daily bar update trigger
 // ---------------------------------------------------------------------------
 
-// shouldRunDailyUpdate returns true if it's after 8:05 PM ET on a trading day
-// and today's bars haven't been fetched yet.
+// shouldRunDailyUpdate returns true once the latest trading day's session has
+// settled (8:05 PM ET, 5:05 PM on half days) and its bars haven't been
+// fetched yet.
 func (g *DailyBarGatherer) shouldRunDailyUpdate() bool {
-	et, err := time.LoadLocation("America/New_York")
-	if err != nil {
-		g.log.Error("loading ET timezone", "error", err)
-		return false
-	}
-
-	now := time.Now().In(et)
-	cutoff := time.Date(now.Year(), now.Month(), now.Day(), 20, 5, 0, 0, et)
-	if now.Before(cutoff) {
-		return false
-	}
-
-	// Check if latest trading day's bars are already done.
-	endDate, err := LatestFinishedTradingDay(g.apiKey, g.apiSecret, g.baseURL)
+	endDate, err := LatestFinishedTradingDay(g.calendar, time.Now())
 	if err != nil {
 		g.log.Error("checking trading calendar", "error", err)
 		return false
@@ -208,7 +194,7 @@ func (g *DailyBarGatherer) runDailyUpdate(ctx context.Context) error {
 	}
 
 	// 1. Determine end date from trading calendar.
-	endDate, err := LatestFinishedTradingDay(g.apiKey, g.apiSecret, g.baseURL)
+	endDate, err := LatestFinishedTradingDay(g.calendar, time.Now())
 	if err != nil {
 		return fmt.Errorf("determining end date: %w", err)
 	}
@@ -531,6 +517,20 @@ func (g *DailyBarGatherer) SetExIndexOnly(v bool) {
 	g.exIndexOnly = v
 }
 
+// SetCalendar sets the trading calendar. By default Run builds one from the
+// Alpaca calendar cache; see NewCalendar.
+func (g *DailyBarGatherer) SetCalendar(cal *util.TradingCalendar) {
+	g.calendar = cal
+}
+
+// SetMarket sets the market definition (timezone, session hours, holiday
+// rules), e.g. from config/markets.yaml. The default is the built-in US
+// market.
+func (g *DailyBarGatherer) SetMarket(mi *util.MarketInfo) {
+	g.market = mi
+	g.loc = mi.Location
+}
+
 // tradeUniverseStep generates trade-universe CSVs for universe dates that have
 // index files but no existing CSV. Returns the number of CSVs written.
 func (g *DailyBarGatherer) tradeUniverseStep(ctx context.Context) (int, error) {
@@ -838,23 +838,19 @@ func (g *DailyBarGatherer) writeEmptyTradeFile(symbol string, day time.Time) {
 		return // already exists
 	}
 	// WriteTrades with an empty slice is a no-op, so write directly.
-	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
-		return
-	}
-	_ = parquet.WriteFile(path, []store.TradeRecord{})
+	_ = store.WriteTradeRecordsUTC(path, []store.TradeRecord{})
 }
 
 // fetchMultiTrades fetches trades for multiple symbols in a single API call
-// for one trading day (4AM–8PM ET). Timestamps are stored as ET-shifted
-// milliseconds. Only trades with size > 100 AND price * size >= 100 are returned.
+// for one trading day (pre-open to post-close, 4AM–8PM ET). All trades are returned; the trade filter profile is applied when stock-trades files are generated.
 func (g *DailyBarGatherer) fetchMultiTrades(ctx context.Context, symbols []string, day time.Time) ([]domain.Trade, error) {
 	if ctx.Err() != nil {
 		return nil, ctx.Err()
 	}
 
-	// Query window: 4AM–8PM ET on this trading day.
-	startET := time.Date(day.Year(), day.Month(), day.Day(), 4, 0, 0, 0, g.loc)
-	endET := time.Date(day.Year(), day.Month(), day.Day(), 20, 0, 0, 0, g.loc)
+	// Query window: the extended session on this trading day.
+	startET := atClock(day, g.market.Hours.PreOpen, g.loc)
+	endET := atClock(day, g.market.Hours.PostClose, g.loc)
 
 	multiTrades, err := g.client.GetMultiTrades(symbols, marketdata.GetTradesRequest{
 		Start: startET,
@@ -868,20 +864,16 @@ func (g *DailyBarGatherer) fetchMultiTrades(ctx context.Context, symbols []strin
 	var trades []domain.Trade
 	for symbol, sdkTrades := range multiTrades {
 		for _, t := range sdkTrades {
-			size := int64(t.Size)
-			amount := t.Price * float64(size)
-			if size > 100 && amount >= 100 {
-				trades = append(trades, domain.Trade{
-					Symbol:     strings.ToUpper(symbol),
-					Timestamp:  utcToETTime(t.Timestamp, g.loc),
-					Price:      t.Price,
-					Size:       size,
-					Exchange:   t.Exchange,
-					ID:         strconv.FormatInt(t.ID, 10),
-					Conditions: strings.Join(t.Conditions, ","),
-					Update:     t.Update,
-				})
-			}
+			trades = append(trades, domain.Trade{
+				Symbol:     strings.ToUpper(symbol),
+				Timestamp:  t.Timestamp,
+				Price:      t.Price,
+				Size:       int64(t.Size),
+				Exchange:   t.Exchange,
+				ID:         strconv.FormatInt(t.ID, 10),
+				Conditions: strings.Join(t.Conditions, ","),
+				Update:     t.Update,
+			})
 		}
 	}
 	return trades, nil
@@ -919,12 +911,35 @@ type StreamGatherer struct {
 	log       *slog.Logger
 	ready     chan struct{}
 
+	cacheRoot        string        // per-day backfill caches live under <cacheRoot>/<date>
+	daySwitch        time.Duration // day switch time as an offset from midnight ET
+	backfillWorkers  int
+	backfillInterval time.Duration // pause between full backfill scans
+	journalDir       string        // stream trade journal; "" disables it
+	tradeFilter      conditions.Profile
+	journal          *live.Journal
+	persistWG        sync.WaitGroup // runDaySwitch and the session writes it starts
+
+	statusMu       sync.Mutex
+	status         StreamStatus
+	disconnectedAt time.Time      // start of the current outage; zero when connected
+	gaps           chan streamGap // outages awaiting gap backfill
+
+	quoteMax     int             // max symbols with streamed quotes; 0 disables quotes
+	quotes       *live.QuoteBook // nil when quotes are disabled
+	quoteMu      sync.Mutex      // protects quoteSymbols, quoteSubs, client
+	quoteSymbols func() []string
+	quoteSubs    map[string]bool
+	client       *stream.StocksClient // current stream client
+
 	stockSyms    map[string]bool // ex-index stock symbols (fast lookup)
+	market       *util.MarketInfo
+	calendar     *util.TradingCalendar
 	loc          *time.Location
 	dateMu       sync.RWMutex // protects today, prevDate, prevCloseUTC
 	today        string       // "YYYY-MM-DD"
 	prevDate     string       // previous trading day
-	prevCloseUTC time.Time    // prevDate 4PM ET in UTC
+	prevCloseUTC time.Time    // prevDate regular close (4PM ET, or early close)
 }
 
 // NewStreamGatherer creates a StreamGatherer that loads symbols from the
@@ -939,9 +954,57 @@ func NewStreamGatherer(apiKey, apiSecret, baseURL, dataDir, csvPath, refDir stri
 		refDir:    refDir,
 		log:       slog.Default().With("gatherer", "us-stream"),
 		ready:     make(chan struct{}),
+		market:    defaultMarket(),
+		gaps:      make(chan streamGap, 16),
+
+		cacheRoot:        filepath.Join(os.TempDir(), "us-stream"),
+		daySwitch:        3*time.Hour + 50*time.Minute,
+		backfillWorkers:  4,
+		backfillInterval: 5 * time.Minute,
+		tradeFilter:      conditions.Standard,
 	}
 }
 
+// SetCacheDir sets the root of the per-day backfill cache. The default is
+// $TMPDIR/us-stream.
+func (g *StreamGatherer) SetCacheDir(dir string) {
+	g.cacheRoot = dir
+}
+
+// SetDaySwitch sets when, as an offset from midnight ET, the stream rolls
+// over to the next trading day. The default is 3:50 AM.
+func (g *StreamGatherer) SetDaySwitch(offset time.Duration) {
+	g.daySwitch = offset
+}
+
+// SetJournalDir enables the write-ahead journal of streamed trades in dir.
+// On startup the journal is replayed into the model, so a restart during the
+// session does not lose trades streamed since the last backfill scan.
+func (g *StreamGatherer) SetJournalDir(dir string) {
+	g.journalDir = dir
+}
+
+// SetMarket sets the market definition (timezone, session hours, holiday
+// rules), e.g. from config/markets.yaml. The default is the built-in US
+// market. The live model's session frame is ET, so the market must use
+// America/New_York.
+func (g *StreamGatherer) SetMarket(mi *util.MarketInfo) {
+	g.market = mi
+}
+
+// SetTradeFilter sets the condition filter applied to streamed and
+// backfilled trades. The default is conditions.Standard.
+func (g *StreamGatherer) SetTradeFilter(p conditions.Profile) {
+	g.tradeFilter = p
+}
+
+// SetBackfill sets the number of REST backfill workers and the pause between
+// full backfill scans. The defaults are 4 workers and 5 minutes.
+func (g *StreamGatherer) SetBackfill(workers int, interval time.Duration) {
+	g.backfillWorkers = max(workers, 1)
+	g.backfillInterval = interval
+}
+
 // Name returns the gatherer identifier.
 func (g *StreamGatherer) Name() string { return "us-stream" }
 
@@ -955,17 +1018,16 @@ func (g *StreamGatherer) Ready() <-chan struct{} { return g.ready }
 // Run starts backfill + streaming. It blocks until ctx is cancelled.
 func (g *StreamGatherer) Run(ctx context.Context) error {
 	var err error
-	g.loc, err = time.LoadLocation("America/New_York")
-	if err != nil {
-		return fmt.Errorf("loading timezone: %w", err)
+	g.loc = g.market.Location
+	if g.calendar == nil {
+		g.calendar = NewCalendar(g.market, g.apiKey, g.apiSecret, g.baseURL, g.dataDir, g.log)
 	}
 
-	// Determine today's trading day. Before 3:50 AM ET the current trading
-	// session still belongs to the previous calendar date (day switch hasn't
-	// fired yet), so use yesterday's date.
+	// Determine today's trading day. Before the day switch (3:50 AM ET by
+	// default) the current trading session still belongs to the previous
+	// calendar date, so use yesterday's date.
 	now := time.Now().In(g.loc)
-	cutoff350 := time.Date(now.Year(), now.Month(), now.Day(), 3, 50, 0, 0, g.loc)
-	if now.Before(cutoff350) {
+	if now.Before(daySwitchAt(now, g.daySwitch)) {
 		g.today = now.AddDate(0, 0, -1).Format("2006-01-02")
 	} else {
 		g.today = now.Format("2006-01-02")
@@ -987,9 +1049,9 @@ func (g *StreamGatherer) Run(ctx context.Context) error {
 
 	g.log.Info("loaded symbols", "exIndexStocks", len(g.stockSyms))
 
-	// Compute todayCutoff = D 4PM ET in the ET-shifted millisecond frame
-	// (must match utcToETMilli output so the model classifies correctly).
-	todayCutoff, err := g.etClose(g.today)
+	// Compute todayCutoff = D's close (4PM ET, or the early close) in
+	// session time, the model's frame.
+	todayCutoff, err := live.CutoffFor(g.today, g.calendar)
 	if err != nil {
 		return fmt.Errorf("computing today cutoff: %w", err)
 	}
@@ -998,24 +1060,30 @@ func (g *StreamGatherer) Run(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("parsing prev date: %w", err)
 	}
-	g.prevCloseUTC = time.Date(prevDateT.Year(), prevDateT.Month(), prevDateT.Day(), 16, 0, 0, 0, g.loc)
+	g.prevCloseUTC = g.sessionClose(prevDateT)
 
 	g.model = live.NewLiveModel(todayCutoff)
+	if g.quoteMax > 0 {
+		g.quotes = live.NewQuoteBook()
+	}
 
-	// Load backfill cache from /tmp (if exists from earlier run today).
+	// Load backfill cache (if it exists from an earlier run today).
 	g.loadBackfillCache()
 
-	// Start WebSocket stream immediately (captures from NOW).
-	streamClient := stream.NewStocksClient(
-		marketdata.SIP,
-		stream.WithCredentials(g.apiKey, g.apiSecret),
-		stream.WithTrades(func(t stream.Trade) {
-			g.handleStreamTrade(t)
-		}, "*"),
-	)
+	// Replay trades streamed before a restart.
+	if g.journalDir != "" {
+		if err := g.openJournal(); err != nil {
+			return err
+		}
+		defer g.journal.Close()
+		go g.runJournalSync(ctx)
+	}
 
-	if err := streamClient.Connect(ctx); err != nil {
-		return fmt.Errorf("connecting WebSocket: %w", err)
+	// Start WebSocket stream immediately (captures from NOW), retrying with
+	// the same backoff superviseStream uses once the stream is running.
+	streamClient := g.connectStream(ctx)
+	if streamClient == nil {
+		return ctx.Err()
 	}
 
 	g.log.Info("WebSocket stream connected")
@@ -1025,20 +1093,29 @@ func (g *StreamGatherer) Run(ctx context.Context) error {
 
 	// Start background goroutines.
 	go g.runBackfill(ctx)
-	go g.runDaySwitch(ctx)
+	go g.runGapBackfill(ctx)
+	g.persistWG.Add(1)
+	go func() {
+		defer g.persistWG.Done()
+		g.runDaySwitch(ctx)
+	}()
 	go g.logStatus(ctx)
-
-	// Wait for context cancellation or stream termination.
-	select {
-	case <-ctx.Done():
-		g.log.Info("context cancelled, shutting down")
-	case err := <-streamClient.Terminated():
-		if err != nil {
-			g.log.Error("stream terminated", "error", err)
-			return fmt.Errorf("stream terminated: %w", err)
-		}
+	quotesDone := make(chan struct{})
+	if g.quotes != nil {
+		go g.runQuoteSubscriptions(ctx)
+		go func() {
+			defer close(quotesDone)
+			g.runQuoteSampler(ctx)
+		}()
+	} else {
+		close(quotesDone)
 	}
 
+	g.superviseStream(ctx, streamClient)
+	<-quotesDone
+	// Let a session being persisted at shutdown finish writing.
+	g.persistWG.Wait()
+
 	tIdx, tExIdx, nIdx, nExIdx := g.model.Counts()
 	g.log.Info("final counts",
 		"todayIndex", tIdx,
@@ -1122,34 +1199,384 @@ func (g *StreamGatherer) handleStreamTrade(t stream.Trade) {
 		return
 	}
 
-	// Apply size filter: size > 100 AND price*size >= 100.
-	if int64(t.Size) <= 100 || t.Price*float64(t.Size) < 100 {
-		return
-	}
-
-	conditions := strings.Join(t.Conditions, ",")
+	conds := strings.Join(t.Conditions, ",")
 	record := store.TradeRecord{
 		Symbol:     t.Symbol,
-		Timestamp:  g.utcToETMilli(t.Timestamp),
+		Timestamp:  int64(ettime.FromTime(t.Timestamp)),
 		Price:      t.Price,
 		Size:       int64(t.Size),
 		Exchange:   t.Exchange,
 		ID:         strconv.FormatInt(t.ID, 10),
-		Conditions: conditions,
+		Conditions: conds,
 	}
 
-	// Apply exchange/condition filter.
-	if !filterTradeRecord(record) {
+	// Apply the trade filter profile (exchange, conditions and size).
+	if !g.tradeFilter.Allow(&record) {
 		return
 	}
 
 	// Always ex-index (index stocks are excluded from stockSyms).
-	g.model.Add(record, t.ID, false)
+	g.addLive(record, t.ID)
+}
+
+// handleStreamCancel marks a cancelled or erroneous trade in the model the
+// way REST reports it, removing it if the trade filter then drops it.
+func (g *StreamGatherer) handleStreamCancel(tce stream.TradeCancelError) {
+	if !g.stockSyms[tce.Symbol] {
+		return
+	}
+	update := "canceled"
+	if tce.CancelErrorAction == "E" {
+		update = "incorrect"
+	}
+	g.amendLive(tce.Symbol, tce.ID, tce.Exchange, func(r *store.TradeRecord) { r.Update = update })
+}
+
+// handleStreamCorrection marks the original of a corrected trade as
+// incorrect, like handleStreamCancel, and adds the corrected trade.
+func (g *StreamGatherer) handleStreamCorrection(tc stream.TradeCorrection) {
+	if !g.stockSyms[tc.Symbol] {
+		return
+	}
+	g.amendLive(tc.Symbol, tc.OriginalID, tc.Exchange, func(r *store.TradeRecord) { r.Update = "incorrect" })
+
+	// The corrected trade passes the same filter as a new one.
+	record := store.TradeRecord{
+		Symbol:     tc.Symbol,
+		Timestamp:  int64(ettime.FromTime(tc.Timestamp)),
+		Price:      tc.CorrectedPrice,
+		Size:       int64(tc.CorrectedSize),
+		Exchange:   tc.Exchange,
+		ID:         strconv.FormatInt(tc.CorrectedID, 10),
+		Conditions: strings.Join(tc.CorrectedConditions, ","),
+		Update:     "corrected",
+	}
+	if !g.tradeFilter.Allow(&record) {
+		return
+	}
+	g.addLive(record, tc.CorrectedID)
+}
+
+// amendLive applies fn to a trade held by the model, removes the trade if
+// the trade filter no longer allows it, and journals the change.
+func (g *StreamGatherer) amendLive(symbol string, rawID int64, exchange string, fn func(r *store.TradeRecord)) {
+	var amended store.TradeRecord
+	var removed bool
+	found := g.model.Amend(symbol, rawID, exchange, func(r *store.TradeRecord) bool {
+		fn(r)
+		amended, removed = *r, !g.tradeFilter.Allow(r)
+		return !removed
+	})
+	if !found {
+		return
+	}
+	g.log.Debug("amended live trade", "symbol", symbol, "id", rawID, "exchange", exchange,
+		"update", amended.Update, "removed", removed)
+	if g.journal != nil {
+		if err := g.journal.AppendAmend(amended, removed); err != nil {
+			g.log.Error("journaling trade amendment", "symbol", symbol, "error", err)
+		}
+	}
+}
+
+// addLive adds a trade received outside of the periodic backfill to the
+// model, notifying subscribers, and journals it if it is new.
+func (g *StreamGatherer) addLive(record store.TradeRecord, rawID int64) bool {
+	if !g.model.Add(record, rawID, false) {
+		return false
+	}
+	if g.journal != nil {
+		if err := g.journal.Append(record, false); err != nil {
+			g.log.Error("journaling trade", "symbol", record.Symbol, "error", err)
+		}
+	}
+	return true
+}
+
+// journalSyncInterval is how often the trade journal is flushed to disk; a
+// crash loses at most this much of the stream.
+const journalSyncInterval = time.Second
+
+// openJournal opens the trade journal and replays it into the model. Only
+// trades after the previous close are replayed, so a journal left over from
+// an earlier session contributes just its post-market trades.
+func (g *StreamGatherer) openJournal() error {
+	j, err := live.OpenJournal(g.journalDir)
+	if err != nil {
+		return fmt.Errorf("opening trade journal: %w", err)
+	}
+	from := int64(ettime.FromTime(g.prevCloseUTC))
+	added, err := j.Replay(g.model, from)
+	if err != nil {
+		j.Close()
+		return fmt.Errorf("replaying trade journal: %w", err)
+	}
+	g.journal = j
+	g.log.Info("replayed trade journal", "dir", g.journalDir, "added", added)
+	return nil
+}
+
+// runJournalSync flushes the trade journal every journalSyncInterval.
+func (g *StreamGatherer) runJournalSync(ctx context.Context) {
+	ticker := time.NewTicker(journalSyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := g.journal.Sync(); err != nil {
+				g.log.Error("syncing trade journal", "error", err)
+			}
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Stream supervision: reconnect with backoff and backfill the outage window.
+// ---------------------------------------------------------------------------
+
+const (
+	// reconnectBaseDelay and reconnectMaxDelay bound the exponential backoff
+	// between attempts to replace a terminated stream.
+	reconnectBaseDelay = time.Second
+	reconnectMaxDelay  = time.Minute
+
+	// gapMargin widens a gap backfill window on both sides, covering trades
+	// in flight around the disconnect and before the resubscribe.
+	gapMargin = 5 * time.Second
+
+	// minBackfillGap is the shortest outage that gets a gap backfill.
+	// Shorter ones are left to the next periodic backfill scan, which
+	// resumes every symbol from its latest cached trade anyway.
+	minBackfillGap = 30 * time.Second
+
+	// gapFetchesPerMinute caps the REST calls of gap backfills, which share
+	// the API rate limit with the periodic backfill.
+	gapFetchesPerMinute = 1000
+)
+
+// StreamStatus reports the health of the WebSocket trade feed.
+type StreamStatus struct {
+	Connected      bool
+	Reconnects     int           // successful reconnects since startup
+	LastDisconnect time.Time     // start of the most recent outage; zero if none
+	LastGap        time.Duration // length of the most recent outage
+	TotalGap       time.Duration // total time disconnected since startup
+	GapTrades      int           // trades recovered by gap backfill
+}
+
+// streamGap is a window during which the stream was disconnected.
+type streamGap struct {
+	start, end time.Time
+}
+
+// Status returns the stream's connection status.
+func (g *StreamGatherer) Status() StreamStatus {
+	g.statusMu.Lock()
+	defer g.statusMu.Unlock()
+	return g.status
+}
+
+// newStreamClient returns an unconnected trade stream client whose connect
+// and disconnect callbacks track outages, including those the client
+// recovers from by itself.
+func (g *StreamGatherer) newStreamClient() *stream.StocksClient {
+	opts := []stream.StockOption{
+		stream.WithCredentials(g.apiKey, g.apiSecret),
+		stream.WithTrades(func(t stream.Trade) {
+			g.handleStreamTrade(t)
+		}, "*"),
+		stream.WithCancelErrors(g.handleStreamCancel),
+		stream.WithCorrections(g.handleStreamCorrection),
+		stream.WithConnectCallback(g.onStreamConnect),
+		stream.WithDisconnectCallback(g.onStreamDisconnect),
+	}
+	if g.quotes != nil {
+		opts = append(opts, stream.WithQuotes(g.handleStreamQuote, g.subscribedQuotes()...))
+	}
+	return stream.NewStocksClient(marketdata.SIP, opts...)
+}
+
+func (g *StreamGatherer) onStreamDisconnect() {
+	g.statusMu.Lock()
+	defer g.statusMu.Unlock()
+	g.status.Connected = false
+	if g.disconnectedAt.IsZero() {
+		g.disconnectedAt = time.Now()
+		g.status.LastDisconnect = g.disconnectedAt
+		g.log.Warn("WebSocket stream disconnected")
+	}
+}
+
+func (g *StreamGatherer) onStreamConnect() {
+	g.statusMu.Lock()
+	defer g.statusMu.Unlock()
+	g.status.Connected = true
+	if g.disconnectedAt.IsZero() {
+		return // initial connect
+	}
+	gap := streamGap{start: g.disconnectedAt, end: time.Now()}
+	g.disconnectedAt = time.Time{}
+	g.status.Reconnects++
+	g.status.LastGap = gap.end.Sub(gap.start)
+	g.status.TotalGap += g.status.LastGap
+	g.log.Info("WebSocket stream reconnected",
+		"gap", g.status.LastGap.Round(time.Millisecond),
+		"reconnects", g.status.Reconnects,
+	)
+	select {
+	case g.gaps <- gap:
+	default:
+		g.log.Warn("gap backfill queue full; periodic backfill will cover the gap",
+			"start", gap.start, "end", gap.end)
+	}
+}
+
+// superviseStream blocks until ctx is cancelled. Whenever the stream client
+// terminates (after exhausting its own reconnect attempts) it is replaced by
+// a new one; see connectStream.
+func (g *StreamGatherer) superviseStream(ctx context.Context, client *stream.StocksClient) {
+	for {
+		select {
+		case <-ctx.Done():
+			g.log.Info("context cancelled, shutting down")
+			return
+		case err := <-client.Terminated():
+			g.onStreamDisconnect()
+			g.log.Error("stream terminated; reconnecting", "error", err)
+		}
+
+		if client = g.connectStream(ctx); client == nil {
+			return
+		}
+	}
+}
+
+// connectStream connects a new stream client, retrying with exponential
+// backoff until it succeeds. It returns nil if ctx is cancelled first.
+func (g *StreamGatherer) connectStream(ctx context.Context) *stream.StocksClient {
+	delay := reconnectBaseDelay
+	for {
+		client := g.newStreamClient()
+		err := client.Connect(ctx)
+		if err == nil {
+			g.setStreamClient(client)
+			return client
+		}
+		g.log.Error("connecting WebSocket", "error", err, "retryIn", delay)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, reconnectMaxDelay)
+	}
+}
+
+// nextGap waits for the next outage worth a gap backfill, merging it with
+// any others already queued so a burst of reconnects costs one pass over
+// the symbols. Outages shorter than minBackfillGap are dropped. It returns
+// false if ctx is cancelled first.
+func (g *StreamGatherer) nextGap(ctx context.Context) (streamGap, bool) {
+	var merged streamGap
+	for {
+		var gap streamGap
+		if merged.start.IsZero() {
+			select {
+			case <-ctx.Done():
+				return streamGap{}, false
+			case gap = <-g.gaps:
+			}
+		} else {
+			select {
+			case gap = <-g.gaps:
+			default:
+				return merged, true
+			}
+		}
+		if d := gap.end.Sub(gap.start); d < minBackfillGap {
+			g.log.Info("skipping gap backfill; periodic backfill will cover the gap",
+				"gap", d.Round(time.Millisecond))
+			continue
+		}
+		if merged.start.IsZero() || gap.start.Before(merged.start) {
+			merged.start = gap.start
+		}
+		if gap.end.After(merged.end) {
+			merged.end = gap.end
+		}
+	}
+}
+
+// runGapBackfill fetches, per symbol, the trades of each outage window
+// reported by onStreamConnect so the model has no holes. Recovered trades
+// are treated like streamed ones: subscribers are notified and the trades
+// are journaled. Fetches are limited to gapFetchesPerMinute.
+func (g *StreamGatherer) runGapBackfill(ctx context.Context) {
+	client := marketdata.NewClient(marketdata.ClientOpts{
+		APIKey:    g.apiKey,
+		APISecret: g.apiSecret,
+		HTTPClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	})
+	limiter := util.NewRateLimiter(gapFetchesPerMinute)
+	for {
+		gap, ok := g.nextGap(ctx)
+		if !ok {
+			return
+		}
+		start, end := gap.start.Add(-gapMargin), gap.end.Add(gapMargin)
+
+		symCh := make(chan string, len(g.stockSyms))
+		for sym := range g.stockSyms {
+			symCh <- sym
+		}
+		close(symCh)
+
+		var wg sync.WaitGroup
+		var added atomic.Int64
+		began := time.Now()
+		for i := 0; i < g.backfillWorkers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for sym := range symCh {
+					if limiter.Wait(ctx) != nil {
+						return
+					}
+					records, ids, err := fetchSymbolTrades(client, sym, start, end, g.tradeFilter)
+					if err != nil {
+						g.log.Error("gap backfill fetch failed", "symbol", sym, "error", err)
+						continue
+					}
+					for i := range records {
+						if g.addLive(records[i], ids[i]) {
+							added.Add(1)
+						}
+					}
+				}
+			}()
+		}
+		wg.Wait()
+
+		g.statusMu.Lock()
+		g.status.GapTrades += int(added.Load())
+		g.statusMu.Unlock()
+		g.log.Info("gap backfill complete",
+			"gap", gap.end.Sub(gap.start).Round(time.Millisecond),
+			"symbols", len(g.stockSyms),
+			"addedToModel", added.Load(),
+			"elapsed", time.Since(began).Round(time.Second),
+		)
+	}
 }
 
-// runBackfill uses 4 workers to fetch trades per-symbol from prevDate 4PM ET
-// → now. Each symbol gets its own cache file for incremental resume. After a
-// full scan, waits 5 min and rescans (stream fills the gap).
+// runBackfill uses backfillWorkers workers (4 by default) to fetch trades
+// per-symbol from prevDate 4PM ET → now. Each symbol gets its own cache file
+// for incremental resume. After a full scan, waits backfillInterval and
+// rescans (stream fills the gap).
 func (g *StreamGatherer) runBackfill(ctx context.Context) {
 	client := marketdata.NewClient(marketdata.ClientOpts{
 		APIKey:    g.apiKey,
@@ -1166,7 +1593,7 @@ func (g *StreamGatherer) runBackfill(ctx context.Context) {
 		prevCloseUTC := g.prevCloseUTC
 		g.dateMu.RUnlock()
 
-		cacheDir := filepath.Join(os.TempDir(), "us-stream", today, "backfill")
+		cacheDir := filepath.Join(g.cacheRoot, today, "backfill")
 
 		// Build shuffled symbol list for fair distribution.
 		symbols := make([]string, 0, len(g.stockSyms))
@@ -1191,7 +1618,7 @@ func (g *StreamGatherer) runBackfill(ctx context.Context) {
 		)
 		scanStart := time.Now()
 
-		for i := 0; i < 4; i++ {
+		for i := 0; i < g.backfillWorkers; i++ {
 			wg.Add(1)
 			go func() {
 				defer wg.Done()
@@ -1231,7 +1658,7 @@ func (g *StreamGatherer) runBackfill(ctx context.Context) {
 		select {
 		case <-ctx.Done():
 			return
-		case <-time.After(5 * time.Minute):
+		case <-time.After(g.backfillInterval):
 		}
 	}
 }
@@ -1251,16 +1678,16 @@ func (g *StreamGatherer) backfillSymbol(ctx context.Context, client *marketdata.
 	var existing []store.TradeRecord
 	start := prevCloseUTC
 
-	if records, err := parquet.ReadFile[store.TradeRecord](cachePath); err == nil && len(records) > 0 {
+	if records, err := store.ReadTradeRecords(cachePath); err == nil && len(records) > 0 {
 		existing = records
-		// Find latest timestamp (ET ms) and convert back to UTC.
+		// Find latest timestamp (session time) and convert back to UTC.
 		var latestET int64
 		for _, r := range records {
 			if r.Timestamp > latestET {
 				latestET = r.Timestamp
 			}
 		}
-		start = g.etMilliToUTC(latestET).Add(time.Millisecond)
+		start = ettime.SessionTime(latestET).Time().Add(time.Millisecond)
 	}
 
 	end := time.Now().UTC()
@@ -1268,64 +1695,66 @@ func (g *StreamGatherer) backfillSymbol(ctx context.Context, client *marketdata.
 		return 0, 0
 	}
 
+	newRecords, newIDs, err := fetchSymbolTrades(client, sym, start, end, g.tradeFilter)
+	if err != nil {
+		g.log.Error("backfill fetch failed", "symbol", sym, "error", err)
+		return 0, 0
+	}
+
+	if len(newRecords) == 0 {
+		return 0, 0
+	}
+
+	// Append to existing cache and write back.
+	all := append(existing, newRecords...)
+	g.writeSymbolCache(cachePath, all)
+
+	// Add only new records to model (stream may already have them).
+	added := g.model.AddBatch(newRecords, newIDs, false)
+	return len(newRecords), added
+}
+
+// fetchSymbolTrades fetches sym's trades in [start, end) via REST, applying
+// the given trade filter like the stream does.
+func fetchSymbolTrades(client *marketdata.Client, sym string, start, end time.Time, filter conditions.Profile) ([]store.TradeRecord, []int64, error) {
 	trades, err := client.GetTrades(sym, marketdata.GetTradesRequest{
 		Start: start,
 		End:   end,
 		Feed:  marketdata.SIP,
 	})
 	if err != nil {
-		g.log.Error("backfill fetch failed", "symbol", sym, "error", err)
-		return 0, 0
+		return nil, nil, err
 	}
 
 	// Filter and convert.
-	var newRecords []store.TradeRecord
-	var newIDs []int64
+	var records []store.TradeRecord
+	var ids []int64
 	for _, t := range trades {
-		if int64(t.Size) <= 100 || t.Price*float64(t.Size) < 100 {
-			continue
-		}
-
-		conditions := strings.Join(t.Conditions, ",")
+		conds := strings.Join(t.Conditions, ",")
 		record := store.TradeRecord{
 			Symbol:     sym,
-			Timestamp:  g.utcToETMilli(t.Timestamp),
+			Timestamp:  int64(ettime.FromTime(t.Timestamp)),
 			Price:      t.Price,
 			Size:       int64(t.Size),
 			Exchange:   t.Exchange,
 			ID:         strconv.FormatInt(t.ID, 10),
-			Conditions: conditions,
+			Conditions: conds,
 			Update:     t.Update,
 		}
 
-		if !filterTradeRecord(record) {
+		if !filter.Allow(&record) {
 			continue
 		}
 
-		newRecords = append(newRecords, record)
-		newIDs = append(newIDs, t.ID)
-	}
-
-	if len(newRecords) == 0 {
-		return 0, 0
+		records = append(records, record)
+		ids = append(ids, t.ID)
 	}
-
-	// Append to existing cache and write back.
-	all := append(existing, newRecords...)
-	g.writeSymbolCache(cachePath, all)
-
-	// Add only new records to model (stream may already have them).
-	added := g.model.AddBatch(newRecords, newIDs, false)
-	return len(newRecords), added
+	return records, ids, nil
 }
 
 // writeSymbolCache writes trade records to a per-symbol cache parquet file.
 func (g *StreamGatherer) writeSymbolCache(path string, records []store.TradeRecord) {
-	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
-		g.log.Error("creating backfill cache dir", "error", err)
-		return
-	}
-	if err := parquet.WriteFile(path, records); err != nil {
+	if err := store.WriteTradeRecordsUTC(path, records); err != nil {
 		g.log.Error("writing backfill cache", "path", path, "error", err)
 	}
 }
@@ -1340,12 +1769,19 @@ func (g *StreamGatherer) logStatus(ctx context.Context) {
 			return
 		case <-ticker.C:
 			tIdx, tExIdx, nIdx, nExIdx := g.model.Counts()
+			st := g.Status()
 			g.log.Info("model status",
 				"todayIndex", tIdx,
 				"todayExIndex", tExIdx,
 				"nextIndex", nIdx,
 				"nextExIndex", nExIdx,
 				"seen", g.model.SeenCount(),
+				"connected", st.Connected,
+				"reconnects", st.Reconnects,
+				"lastGap", st.LastGap.Round(time.Millisecond),
+				"totalGap", st.TotalGap.Round(time.Millisecond),
+				"gapTrades", st.GapTrades,
+				"quoteSymbols", len(g.subscribedQuotes()),
 			)
 		}
 	}
@@ -1368,32 +1804,12 @@ func (g *StreamGatherer) findPrevTradingDay() (string, error) {
 	return "", fmt.Errorf("no previous trading day found before %s", g.today)
 }
 
-// etClose returns 4PM ET on the given date as ET-shifted milliseconds
-// (consistent with utcToETMilli). Use this for LiveModel cutoffs.
-func (g *StreamGatherer) etClose(dateStr string) (int64, error) {
-	return regularClose(dateStr)
-}
-
-// utcToETMilli converts a UTC time.Time to ET Unix milliseconds.
-func (g *StreamGatherer) utcToETMilli(t time.Time) int64 {
-	et := t.In(g.loc)
-	_, offset := et.Zone()
-	return t.UnixMilli() + int64(offset)*1000
-}
-
-// etMilliToUTC converts an ET Unix millisecond timestamp back to a UTC time.
-func (g *StreamGatherer) etMilliToUTC(etMs int64) time.Time {
-	approx := time.UnixMilli(etMs)
-	_, offset := approx.In(g.loc).Zone()
-	return time.UnixMilli(etMs - int64(offset)*1000)
-}
-
 // ---------------------------------------------------------------------------
-// Backfill cache: /tmp/us-stream/<YYYY-MM-DD>/backfill/<SYMBOL>.parquet
+// Backfill cache: <cacheRoot>/<YYYY-MM-DD>/backfill/<SYMBOL>.parquet
 // ---------------------------------------------------------------------------
 
 func (g *StreamGatherer) cacheDir() string {
-	return filepath.Join(os.TempDir(), "us-stream", g.today)
+	return filepath.Join(g.cacheRoot, g.today)
 }
 
 // loadBackfillCache loads cached trades for qualified symbols (stockSyms) from
@@ -1406,7 +1822,7 @@ func (g *StreamGatherer) loadBackfillCache() {
 	totalFiles := 0
 	for sym := range g.stockSyms {
 		path := filepath.Join(dir, sym+".parquet")
-		records, err := parquet.ReadFile[store.TradeRecord](path)
+		records, err := store.ReadTradeRecords(path)
 		if err != nil || len(records) == 0 {
 			continue
 		}
@@ -1433,42 +1849,65 @@ func (g *StreamGatherer) loadBackfillCache() {
 }
 
 // ---------------------------------------------------------------------------
-// Day switching: advances the model to a new trading day at 3:50 AM ET.
+// Day switching: advances the model to a new trading day at the day switch
+// time (3:50 AM ET by default).
 // ---------------------------------------------------------------------------
 
-// isTradingDay checks whether the given date is a trading day using the
-// Alpaca Calendar API.
-func (g *StreamGatherer) isTradingDay(date string) (bool, error) {
-	client := alpacaapi.NewClient(alpacaapi.ClientOpts{
-		APIKey:    g.apiKey,
-		APISecret: g.apiSecret,
-		BaseURL:   g.baseURL,
-	})
-	d, err := time.Parse("2006-01-02", date)
-	if err != nil {
-		return false, err
+// SetCalendar sets the trading calendar used for day switching. By default
+// Run builds one from the Alpaca calendar cache; see NewCalendar.
+func (g *StreamGatherer) SetCalendar(cal *util.TradingCalendar) {
+	g.calendar = cal
+}
+
+// persistSession writes a finished session's trades to the stock-trades
+// layout as provisional .live files, giving same-night history until the
+// REST backfill replaces them; see WriteLiveSession.
+func (g *StreamGatherer) persistSession(date string, index, exIndex []store.TradeRecord) {
+	st := g.Status()
+	prov := LiveProvenance{
+		Source:      g.Name(),
+		Written:     time.Now().UTC(),
+		Reconnects:  st.Reconnects,
+		TotalGapSec: st.TotalGap.Seconds(),
+	}
+	if err := WriteLiveSession(g.dataDir, date, index, exIndex, prov); err != nil {
+		g.log.Error("persisting live session", "date", date, "error", err)
+		return
 	}
-	cal, err := client.GetCalendar(alpacaapi.GetCalendarRequest{Start: d, End: d})
-	if err != nil {
-		return false, err
+	g.log.Info("persisted live session", "date", date, "index", len(index), "exIndex", len(exIndex))
+}
+
+// sessionClose returns the regular close of day's session per the calendar,
+// or the market's configured close if day is not a trading day.
+func (g *StreamGatherer) sessionClose(day time.Time) time.Time {
+	if s, ok := g.calendar.Session(day); ok {
+		return s.Close
 	}
-	return len(cal) > 0 && cal[0].Date == date, nil
+	return atClock(day, g.market.Hours.Close, g.loc)
+}
+
+// daySwitchAt returns the day switch instant on now's calendar date, in
+// now's location. offset is applied to the wall clock so DST days keep the
+// configured time.
+func daySwitchAt(now time.Time, offset time.Duration) time.Time {
+	return atClock(now, offset, now.Location())
 }
 
-// runDaySwitch fires at 3:50 AM ET each day and, on trading days, promotes
-// the next-day bucket to today and resets backfill for the new window.
+// runDaySwitch fires at the day switch time each day and, on trading days,
+// promotes the next-day bucket to today and resets backfill for the new
+// window.
 func (g *StreamGatherer) runDaySwitch(ctx context.Context) {
 	for {
-		// Sleep until next 3:50 AM ET.
+		// Sleep until the next day switch.
 		now := time.Now().In(g.loc)
-		next350 := time.Date(now.Year(), now.Month(), now.Day(), 3, 50, 0, 0, g.loc)
-		if !now.Before(next350) {
-			next350 = next350.AddDate(0, 0, 1)
+		next := daySwitchAt(now, g.daySwitch)
+		if !now.Before(next) {
+			next = daySwitchAt(now.AddDate(0, 0, 1), g.daySwitch)
 		}
 		select {
 		case <-ctx.Done():
 			return
-		case <-time.After(time.Until(next350)):
+		case <-time.After(time.Until(next)):
 		}
 
 		newDay := time.Now().In(g.loc).Format("2006-01-02")
@@ -1481,23 +1920,25 @@ func (g *StreamGatherer) runDaySwitch(ctx context.Context) {
 			continue // shouldn't happen, but guard
 		}
 
-		isTrading, err := g.isTradingDay(newDay)
-		if err != nil {
-			g.log.Error("calendar check failed", "error", err)
-			continue
-		}
-		if !isTrading {
+		if !g.calendar.IsTradingDay(time.Now()) {
 			g.log.Info("day switch skipped (non-trading day)", "date", newDay)
 			continue
 		}
 
 		// Compute new cutoff + prev close.
-		newCutoff, _ := g.etClose(newDay)
+		newCutoff, _ := live.CutoffFor(newDay, g.calendar)
 		oldTodayT, _ := time.ParseInLocation("2006-01-02", oldToday, g.loc)
-		newPrevCloseUTC := time.Date(oldTodayT.Year(), oldTodayT.Month(), oldTodayT.Day(), 16, 0, 0, 0, g.loc)
+		newPrevCloseUTC := g.sessionClose(oldTodayT)
 
-		// Switch model.
+		// Snapshot the finished session, switch the model, then drop the old
+		// day from the journal.
+		sessIdx, sessExIdx := g.model.TodaySnapshot()
 		g.model.SwitchDay(newCutoff)
+		if g.journal != nil {
+			if err := g.journal.Reset(g.model); err != nil {
+				g.log.Error("resetting trade journal", "error", err)
+			}
+		}
 
 		// Update gatherer date fields.
 		g.dateMu.Lock()
@@ -1506,13 +1947,19 @@ func (g *StreamGatherer) runDaySwitch(ctx context.Context) {
 		g.prevCloseUTC = newPrevCloseUTC
 		g.dateMu.Unlock()
 
+		g.persistWG.Add(1)
+		go func() {
+			defer g.persistWG.Done()
+			g.persistSession(oldToday, sessIdx, sessExIdx)
+		}()
+
 		// Clean old cache dir (best-effort).
-		oldCacheDir := filepath.Join(os.TempDir(), "us-stream", oldToday)
+		oldCacheDir := filepath.Join(g.cacheRoot, oldToday)
 		os.RemoveAll(oldCacheDir)
 
 		g.log.Info("day switch complete",
 			"newToday", newDay, "prevDate", oldToday,
-			"newCutoff", time.UnixMilli(newCutoff).In(g.loc).Format("2006-01-02 15:04"),
+			"newCutoff", ettime.SessionTime(newCutoff).String(),
 		)
 	}
 }
@@ -1,6 +1,18 @@
 package us
 
-import "testing"
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata/stream"
+
+	"jupitor/internal/conditions"
+	"jupitor/internal/domain"
+	"jupitor/internal/live"
+	"jupitor/internal/util"
+)
 
 func TestDailyBarGathererName(t *testing.T) {
 	g := NewDailyBarGatherer("key", "secret", "https://data.alpaca.markets",
@@ -79,3 +91,169 @@ func TestStreamGathererName(t *testing.T) {
 		t.Errorf("StreamGatherer.Name() = %q, want %q", got, "us-stream")
 	}
 }
+
+func TestLatestFinishedTradingDay(t *testing.T) {
+	cal := util.NewTradingCalendar(domain.MarketUS)
+	loc := cal.Location()
+	cases := []struct {
+		now  time.Time
+		want string
+	}{
+		{time.Date(2024, 11, 27, 20, 4, 0, 0, loc), "2024-11-26"}, // before settle
+		{time.Date(2024, 11, 27, 20, 5, 0, 0, loc), "2024-11-27"}, // settled
+		{time.Date(2024, 11, 28, 12, 0, 0, 0, loc), "2024-11-27"}, // Thanksgiving
+		{time.Date(2024, 11, 29, 17, 5, 0, 0, loc), "2024-11-29"}, // half day settles at 17:05
+		{time.Date(2024, 12, 2, 3, 0, 0, 0, loc), "2024-11-29"},   // Monday before the session
+	}
+	for _, c := range cases {
+		got, err := LatestFinishedTradingDay(cal, c.now)
+		if err != nil || got.Format("2006-01-02") != c.want {
+			t.Errorf("LatestFinishedTradingDay(%v) = %v, %v; want %s", c.now, got, err, c.want)
+		}
+	}
+}
+
+func TestDaySwitchAt(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("tzdata unavailable")
+	}
+	offset := 3*time.Hour + 50*time.Minute
+	// 2024-03-10 springs forward at 2:00; the switch must stay at 3:50 EDT.
+	for _, day := range []time.Time{
+		time.Date(2024, 3, 9, 12, 0, 0, 0, loc),
+		time.Date(2024, 3, 10, 12, 0, 0, 0, loc),
+		time.Date(2024, 11, 3, 12, 0, 0, 0, loc),
+	} {
+		got := daySwitchAt(day, offset)
+		if got.Hour() != 3 || got.Minute() != 50 || got.Day() != day.Day() {
+			t.Errorf("daySwitchAt(%s) = %v, want 03:50 local", day.Format("2006-01-02"), got)
+		}
+	}
+}
+
+func TestStreamGathererOutageTracking(t *testing.T) {
+	g := NewStreamGatherer("key", "secret", "https://api.alpaca.markets", "/tmp", "", "")
+
+	g.onStreamConnect() // initial connect is not a reconnect
+	if st := g.Status(); !st.Connected || st.Reconnects != 0 {
+		t.Fatalf("after connect: %+v", st)
+	}
+
+	g.onStreamDisconnect()
+	first := g.Status().LastDisconnect
+	time.Sleep(5 * time.Millisecond)
+	g.onStreamDisconnect() // Terminated after the client's own retries
+	if st := g.Status(); st.Connected || !st.LastDisconnect.Equal(first) {
+		t.Fatalf("after disconnect: %+v", st)
+	}
+
+	g.onStreamConnect()
+	st := g.Status()
+	if !st.Connected || st.Reconnects != 1 || st.LastGap < 5*time.Millisecond || st.TotalGap != st.LastGap {
+		t.Errorf("after reconnect: %+v", st)
+	}
+	select {
+	case gap := <-g.gaps:
+		if !gap.start.Equal(first) || gap.end.Sub(gap.start) != st.LastGap {
+			t.Errorf("gap = %+v", gap)
+		}
+	default:
+		t.Error("no gap queued for backfill")
+	}
+}
+
+func TestStreamGathererNextGap(t *testing.T) {
+	g := NewStreamGatherer("key", "secret", "https://api.alpaca.markets", "/tmp", "", "")
+	t0 := time.Date(2024, 11, 27, 15, 0, 0, 0, time.UTC)
+	g.gaps <- streamGap{start: t0, end: t0.Add(time.Second)} // too short
+	g.gaps <- streamGap{start: t0.Add(time.Minute), end: t0.Add(2 * time.Minute)}
+	g.gaps <- streamGap{start: t0.Add(5 * time.Minute), end: t0.Add(5*time.Minute + 5*time.Second)}
+	g.gaps <- streamGap{start: t0.Add(10 * time.Minute), end: t0.Add(11 * time.Minute)}
+
+	gap, ok := g.nextGap(context.Background())
+	if !ok || !gap.start.Equal(t0.Add(time.Minute)) || !gap.end.Equal(t0.Add(11*time.Minute)) {
+		t.Errorf("nextGap = %+v, %v; want the two long outages merged", gap, ok)
+	}
+
+	// Only short outages queued: nothing to backfill before ctx ends.
+	g.gaps <- streamGap{start: t0, end: t0.Add(time.Second)}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if gap, ok := g.nextGap(ctx); ok {
+		t.Errorf("nextGap = %+v, want none", gap)
+	}
+}
+
+func TestStreamGathererCorrections(t *testing.T) {
+	ts := time.Date(2024, 11, 27, 15, 0, 0, 0, time.UTC)
+	trade := func(id int64) stream.Trade {
+		return stream.Trade{Symbol: "ABCD", ID: id, Exchange: "V", Price: 5, Size: 200, Timestamp: ts, Conditions: []string{"@"}}
+	}
+	for _, tc := range []struct {
+		filter conditions.Profile
+		want   int // trades left in the model
+	}{
+		{conditions.Standard, 3},
+		{conditions.Strict, 1},
+	} {
+		g := NewStreamGatherer("key", "secret", "https://api.alpaca.markets", "/tmp", "", "")
+		g.SetTradeFilter(tc.filter)
+		g.stockSyms = map[string]bool{"ABCD": true}
+		g.model = live.NewLiveModel(math.MaxInt64)
+		g.handleStreamTrade(trade(1))
+		g.handleStreamTrade(trade(2))
+
+		g.handleStreamCancel(stream.TradeCancelError{Symbol: "ABCD", ID: 1, Exchange: "V", CancelErrorAction: "C"})
+		g.handleStreamCorrection(stream.TradeCorrection{
+			Symbol: "ABCD", Exchange: "V", Timestamp: ts,
+			OriginalID: 2, CorrectedID: 3, CorrectedPrice: 5.5, CorrectedSize: 300, CorrectedConditions: []string{"@"},
+		})
+
+		_, ex := g.model.TodaySnapshot()
+		updates := map[string]string{}
+		for _, r := range ex {
+			updates[r.ID] = r.Update
+		}
+		if len(ex) != tc.want || updates["3"] != "corrected" {
+			t.Errorf("%s: trades = %+v", tc.filter.Name, ex)
+		}
+		if tc.filter.Name == "standard" && (updates["1"] != "canceled" || updates["2"] != "incorrect") {
+			t.Errorf("%s: updates = %v", tc.filter.Name, updates)
+		}
+	}
+}
+
+func TestStreamGathererOddLots(t *testing.T) {
+	ts := time.Date(2024, 11, 27, 15, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		filter conditions.Profile
+		want   int
+	}{
+		{conditions.Standard, 1},
+		{conditions.OddLots, 3},
+	} {
+		g := NewStreamGatherer("key", "secret", "https://api.alpaca.markets", "/tmp", "", "")
+		g.SetTradeFilter(tc.filter)
+		g.stockSyms = map[string]bool{"ABCD": true}
+		g.model = live.NewLiveModel(math.MaxInt64)
+		g.handleStreamTrade(stream.Trade{Symbol: "ABCD", ID: 1, Exchange: "V", Price: 5, Size: 200, Timestamp: ts, Conditions: []string{"@"}})
+		g.handleStreamTrade(stream.Trade{Symbol: "ABCD", ID: 2, Exchange: "V", Price: 5, Size: 100, Timestamp: ts, Conditions: []string{"@"}})
+		g.handleStreamTrade(stream.Trade{Symbol: "ABCD", ID: 3, Exchange: "V", Price: 5, Size: 40, Timestamp: ts, Conditions: []string{"@", "I"}})
+
+		if _, ex := g.model.TodaySnapshot(); len(ex) != tc.want {
+			t.Errorf("%s: trades = %+v, want %d", tc.filter.Name, ex, tc.want)
+		}
+	}
+}
+
+func TestBoundedSymbols(t *testing.T) {
+	// Watchlist first, then movers; duplicates and blanks do not use up slots.
+	got := boundedSymbols([]string{"aapl", "TSLA", " ", "AAPL", "NVDA", "GME"}, 3)
+	if len(got) != 3 || !got["AAPL"] || !got["TSLA"] || !got["NVDA"] {
+		t.Errorf("boundedSymbols = %v", got)
+	}
+	if got := boundedSymbols([]string{"AAPL"}, 0); len(got) != 0 {
+		t.Errorf("boundedSymbols with no slots = %v", got)
+	}
+}
@@ -2,61 +2,125 @@ package us
 
 import (
 	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+
+	"jupitor/internal/domain"
+	"jupitor/internal/util"
 )
 
-// LatestFinishedTradingDay returns the most recent trading day whose market
-// session has ended (i.e. after 20:05 ET to account for extended hours data
-// settling). It uses the Alpaca trading calendar API.
-func LatestFinishedTradingDay(apiKey, apiSecret, baseURL string) (time.Time, error) {
+const (
+	// calendarCacheTTL is how long the cached Alpaca calendar is trusted
+	// before it is refreshed.
+	calendarCacheTTL = 24 * time.Hour
+
+	// sessionSettleDelay is how long after the post-market close a session's
+	// data is considered complete.
+	sessionSettleDelay = 5 * time.Minute
+)
+
+// CalendarCachePath returns where the Alpaca calendar is cached under dataDir.
+func CalendarCachePath(dataDir string) string {
+	return filepath.Join(dataDir, "us", "calendar.json")
+}
+
+// NewCalendar returns the trading calendar for market (the built-in US
+// market if nil), seeded from the Alpaca calendar cached under dataDir. A
+// missing or stale cache is refreshed from the API when credentials are
+// given; if that fails the rule-based calendar (or the stale cache) is used.
+func NewCalendar(market *util.MarketInfo, apiKey, apiSecret, baseURL, dataDir string, log *slog.Logger) *util.TradingCalendar {
+	if market == nil {
+		market = defaultMarket()
+	}
+	cal := market.Calendar()
+	path := CalendarCachePath(dataDir)
+
+	days, err := util.LoadCalendarCache(path)
+	fresh := false
+	if fi, statErr := os.Stat(path); statErr == nil {
+		fresh = time.Since(fi.ModTime()) < calendarCacheTTL
+	}
+	if !fresh && apiKey != "" {
+		now := time.Now()
+		start := time.Date(now.Year()-1, time.January, 1, 0, 0, 0, 0, time.UTC)
+		end := time.Date(now.Year()+1, time.December, 31, 0, 0, 0, 0, time.UTC)
+		fetched, fetchErr := FetchAlpacaCalendar(apiKey, apiSecret, baseURL, start, end)
+		if fetchErr != nil {
+			log.Warn("refreshing Alpaca calendar", "error", fetchErr)
+		} else {
+			days, err = fetched, nil
+			if saveErr := util.SaveCalendarCache(path, days); saveErr != nil {
+				log.Warn("saving calendar cache", "path", path, "error", saveErr)
+			}
+		}
+	}
+	if err != nil && !os.IsNotExist(err) {
+		log.Warn("loading calendar cache", "path", path, "error", err)
+	}
+	if err := cal.Override(days); err != nil {
+		log.Warn("applying Alpaca calendar", "error", err)
+	}
+	return cal
+}
+
+// defaultMarket returns the built-in US market definition, used until a
+// gatherer is given one from config/markets.yaml.
+func defaultMarket() *util.MarketInfo {
+	mi, _ := util.DefaultMarketRegistry().Get(domain.MarketUS)
+	return mi
+}
+
+// hourMinute splits a clock offset from midnight into hours and minutes.
+func hourMinute(d time.Duration) (h, m int) {
+	return int(d / time.Hour), int(d % time.Hour / time.Minute)
+}
+
+// atClock returns the wall-clock time offset from midnight on day's calendar
+// date in loc, so DST days keep their local hours.
+func atClock(day time.Time, offset time.Duration, loc *time.Location) time.Time {
+	h, m := hourMinute(offset)
+	return time.Date(day.Year(), day.Month(), day.Day(), h, m, 0, 0, loc)
+}
+
+// FetchAlpacaCalendar returns Alpaca's trading calendar for [start, end].
+func FetchAlpacaCalendar(apiKey, apiSecret, baseURL string, start, end time.Time) ([]util.CalendarDay, error) {
 	client := alpaca.NewClient(alpaca.ClientOpts{
 		APIKey:    apiKey,
 		APISecret: apiSecret,
 		BaseURL:   baseURL,
 	})
-
-	et, err := time.LoadLocation("America/New_York")
+	calendar, err := client.GetCalendar(alpaca.GetCalendarRequest{Start: start, End: end})
 	if err != nil {
-		return time.Time{}, fmt.Errorf("loading ET timezone: %w", err)
+		return nil, fmt.Errorf("GetCalendar: %w", err)
 	}
-
-	now := time.Now().In(et)
-	start := now.AddDate(0, 0, -7)
-
-	calendar, err := client.GetCalendar(alpaca.GetCalendarRequest{
-		Start: start,
-		End:   now,
-	})
-	if err != nil {
-		return time.Time{}, fmt.Errorf("GetCalendar: %w", err)
+	days := make([]util.CalendarDay, len(calendar))
+	for i, d := range calendar {
+		days[i] = util.CalendarDay{Date: d.Date, Open: d.Open, Close: d.Close}
 	}
+	return days, nil
+}
 
-	if len(calendar) == 0 {
-		return time.Time{}, fmt.Errorf("no trading days returned from calendar")
+// LatestFinishedTradingDay returns the most recent trading day whose session,
+// including extended hours, ended at least five minutes before now (20:05
+// ET on regular days). The date is returned as midnight UTC.
+func LatestFinishedTradingDay(cal *util.TradingCalendar, now time.Time) (time.Time, error) {
+	day := now
+	if !cal.IsTradingDay(day) {
+		day = cal.PrevTradingDay(day)
 	}
-
-	today := now.Format("2006-01-02")
-	cutoff := time.Date(now.Year(), now.Month(), now.Day(), 20, 5, 0, 0, et)
-
-	for i := len(calendar) - 1; i >= 0; i-- {
-		day := calendar[i]
-		if day.Date == today {
-			if now.After(cutoff) {
-				t, _ := time.Parse("2006-01-02", day.Date)
-				return t, nil
-			}
-			continue
+	for range 2 {
+		if day.IsZero() {
+			break
 		}
-		dayDate, err := time.Parse("2006-01-02", day.Date)
-		if err != nil {
-			continue
-		}
-		if dayDate.Before(now) {
-			return dayDate, nil
+		if s, ok := cal.Session(day); ok && !now.Before(s.PostClose.Add(sessionSettleDelay)) {
+			y, m, d := s.Date.Date()
+			return time.Date(y, m, d, 0, 0, 0, 0, time.UTC), nil
 		}
+		day = cal.PrevTradingDay(day)
 	}
-
 	return time.Time{}, fmt.Errorf("could not determine latest finished trading day")
 }
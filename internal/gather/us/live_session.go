@@ -0,0 +1,225 @@
+package us
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"jupitor/internal/store"
+)
+
+// ---------------------------------------------------------------------------
+// Live session files: us-stream writes the day's trades into the canonical
+// stock-trades layout at the day switch, marked provisional with a
+// <date>.parquet.live file. GenerateStockTrades later rebuilds the date from
+// the REST-backfilled per-symbol files and reconciles the two.
+// ---------------------------------------------------------------------------
+
+// liveMarkerExt is appended to a stock-trades file path to form its
+// provenance marker.
+const liveMarkerExt = ".live"
+
+// LiveProvenance is the content of a .live marker: where a stock-trades file
+// came from and how complete the stream was while recording it.
+type LiveProvenance struct {
+	Source      string    `json:"source"` // "us-stream"
+	Written     time.Time `json:"written"`
+	Trades      int       `json:"trades"`
+	Reconnects  int       `json:"reconnects"`  // since us-stream started
+	TotalGapSec float64   `json:"totalGapSec"` // time disconnected since us-stream started
+}
+
+// liveMarkerPath returns the provenance marker path for a stock-trades file.
+func liveMarkerPath(path string) string { return path + liveMarkerExt }
+
+// IsLiveStockTrades reports whether the stock-trades file at path was written
+// from the live stream and has not yet been replaced by the REST version.
+func IsLiveStockTrades(path string) bool { return fileExists(liveMarkerPath(path)) }
+
+// canonicalExists reports whether path holds a REST-built stock-trades file.
+func canonicalExists(path string) bool { return fileExists(path) && !IsLiveStockTrades(path) }
+
+// WriteLiveSession writes a live session's trades to
+// stock-trades-{index,ex-index}/<date>.parquet, each with a .live marker. A
+// kind with no trades is skipped (the stream only carries ex-index stocks),
+// as is one whose REST-built file already exists.
+func WriteLiveSession(dataDir, date string, index, exIndex []store.TradeRecord, prov LiveProvenance) error {
+	for _, part := range []struct {
+		dir     string
+		records []store.TradeRecord
+	}{
+		{"stock-trades-index", index},
+		{"stock-trades-ex-index", exIndex},
+	} {
+		path := filepath.Join(dataDir, "us", part.dir, date+".parquet")
+		if len(part.records) == 0 || canonicalExists(path) {
+			continue
+		}
+		records := make([]store.TradeRecord, len(part.records))
+		copy(records, part.records)
+		sort.SliceStable(records, func(i, j int) bool { return records[i].Timestamp < records[j].Timestamp })
+
+		p := prov
+		p.Trades = len(records)
+		marker, err := json.MarshalIndent(p, "", "  ")
+		if err != nil {
+			return err
+		}
+		// The marker goes first so a crash never leaves an unmarked live file.
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return fmt.Errorf("creating %s dir: %w", part.dir, err)
+		}
+		if err := os.WriteFile(liveMarkerPath(path), marker, 0o644); err != nil {
+			return fmt.Errorf("writing live marker: %w", err)
+		}
+		if err := store.WriteTradeRecordsUTC(path, records); err != nil {
+			return fmt.Errorf("writing live %s for %s: %w", part.dir, date, err)
+		}
+	}
+	return nil
+}
+
+// SymbolDiff is one symbol's trade count in the live and REST versions of a
+// stock-trades file.
+type SymbolDiff struct {
+	Symbol string `json:"symbol"`
+	Live   int    `json:"live"`
+	REST   int    `json:"rest"`
+	Delta  int    `json:"delta"` // Live - REST
+}
+
+// ReconcileReport compares the live and REST versions of a stock-trades file.
+// Trades are matched by (symbol, trade ID, exchange).
+type ReconcileReport struct {
+	Date        string       `json:"date"`
+	Kind        string       `json:"kind"` // stock-trades directory, e.g. "stock-trades-ex-index"
+	LiveTrades  int          `json:"liveTrades"`
+	RESTTrades  int          `json:"restTrades"`
+	OnlyLive    int          `json:"onlyLive"`
+	OnlyREST    int          `json:"onlyRest"`
+	LiveSymbols int          `json:"liveSymbols"`
+	RESTSymbols int          `json:"restSymbols"`
+	Symbols     []SymbolDiff `json:"symbols"` // symbols whose counts differ, largest |Delta| first
+}
+
+// CompareTrades builds a ReconcileReport for the live and REST trade sets.
+func CompareTrades(liveTrades, restTrades []store.TradeRecord) ReconcileReport {
+	type key struct {
+		symbol, id, exchange string
+	}
+	rep := ReconcileReport{LiveTrades: len(liveTrades), RESTTrades: len(restTrades)}
+
+	restKeys := make(map[key]bool, len(restTrades))
+	counts := make(map[string]*SymbolDiff)
+	count := func(sym string) *SymbolDiff {
+		d, ok := counts[sym]
+		if !ok {
+			d = &SymbolDiff{Symbol: sym}
+			counts[sym] = d
+		}
+		return d
+	}
+	for _, r := range restTrades {
+		restKeys[key{r.Symbol, r.ID, r.Exchange}] = true
+		count(r.Symbol).REST++
+	}
+	liveKeys := make(map[key]bool, len(liveTrades))
+	for _, r := range liveTrades {
+		k := key{r.Symbol, r.ID, r.Exchange}
+		liveKeys[k] = true
+		if !restKeys[k] {
+			rep.OnlyLive++
+		}
+		count(r.Symbol).Live++
+	}
+	for k := range restKeys {
+		if !liveKeys[k] {
+			rep.OnlyREST++
+		}
+	}
+
+	for _, d := range counts {
+		if d.Live > 0 {
+			rep.LiveSymbols++
+		}
+		if d.REST > 0 {
+			rep.RESTSymbols++
+		}
+		if d.Delta = d.Live - d.REST; d.Delta != 0 {
+			rep.Symbols = append(rep.Symbols, *d)
+		}
+	}
+	sort.Slice(rep.Symbols, func(i, j int) bool {
+		ai, aj := abs(rep.Symbols[i].Delta), abs(rep.Symbols[j].Delta)
+		if ai != aj {
+			return ai > aj
+		}
+		return rep.Symbols[i].Symbol < rep.Symbols[j].Symbol
+	})
+	return rep
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// reconcileLive compares the live file at path with the REST-built records
+// about to replace it, saves the report as <date>.reconcile.json next to it
+// and logs a summary. It returns false, leaving the live file in place, while
+// the REST version is incomplete: missing lists the symbols the trades
+// backfill has not written date's file for yet.
+func reconcileLive(path, date, kind string, rest []store.TradeRecord, missing []string, log *slog.Logger) (bool, error) {
+	if len(missing) > 0 || len(rest) == 0 {
+		log.Info("keeping live stock trades until REST backfill is complete",
+			"date", date, "kind", kind, "missingSymbols", len(missing))
+		return false, nil
+	}
+	liveTrades, err := store.ReadTradeRecords(path)
+	if os.IsNotExist(err) {
+		return true, nil // marker left by an interrupted write
+	}
+	if err != nil {
+		return false, fmt.Errorf("reading live %s: %w", kind, err)
+	}
+	rep := CompareTrades(liveTrades, rest)
+	rep.Date, rep.Kind = date, kind
+
+	data, err := json.MarshalIndent(rep, "", "  ")
+	if err != nil {
+		return false, err
+	}
+	reportPath := filepath.Join(filepath.Dir(path), date+".reconcile.json")
+	if err := os.WriteFile(reportPath, data, 0o644); err != nil {
+		return false, fmt.Errorf("writing reconcile report: %w", err)
+	}
+
+	top := make([]string, 0, 5)
+	for i := 0; i < len(rep.Symbols) && i < 5; i++ {
+		d := rep.Symbols[i]
+		top = append(top, d.Symbol+":"+strconv.Itoa(d.Delta))
+	}
+	log.Info("reconciled live stock trades",
+		"date", date, "kind", kind,
+		"live", rep.LiveTrades, "rest", rep.RESTTrades,
+		"onlyLive", rep.OnlyLive, "onlyRest", rep.OnlyREST,
+		"symbolsDiffering", len(rep.Symbols), "top", top,
+		"report", reportPath,
+	)
+	return true, nil
+}
+
+// finishReconcile removes a replaced live file's marker and the files derived
+// from it (rolling bars, daily summary) so they are rebuilt from REST data.
+func finishReconcile(dataDir, path, date string) {
+	os.Remove(liveMarkerPath(path))
+	os.Remove(filepath.Join(dataDir, "us", "stock-trades-ex-index-rolling", date+".parquet"))
+	os.Remove(filepath.Join(dataDir, "us", "stock-trades-daily", date+".parquet"))
+}
@@ -0,0 +1,129 @@
+package us
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"jupitor/internal/conditions"
+	"jupitor/internal/domain"
+	"jupitor/internal/ettime"
+	"jupitor/internal/store"
+	"jupitor/internal/util"
+)
+
+func TestWriteLiveSession(t *testing.T) {
+	dataDir := t.TempDir()
+	exIdx := []store.TradeRecord{
+		{Symbol: "ABCD", Timestamp: 2000, Price: 5, Size: 200, Exchange: "V", ID: "2"},
+		{Symbol: "ABCD", Timestamp: 1000, Price: 5, Size: 300, Exchange: "V", ID: "1"},
+	}
+	if err := WriteLiveSession(dataDir, "2025-03-10", nil, exIdx, LiveProvenance{Source: "us-stream"}); err != nil {
+		t.Fatal(err)
+	}
+
+	exPath := filepath.Join(dataDir, "us", "stock-trades-ex-index", "2025-03-10.parquet")
+	if !IsLiveStockTrades(exPath) || canonicalExists(exPath) {
+		t.Error("ex-index file not marked live")
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "us", "stock-trades-index", "2025-03-10.parquet")); !os.IsNotExist(err) {
+		t.Error("empty index session written")
+	}
+	got, err := store.ReadTradeRecords(exPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ID != "1" || got[0].Timestamp != 1000 {
+		t.Errorf("live file = %+v, want sorted session-time records", got)
+	}
+
+	// A REST-built file is never overwritten.
+	os.Remove(liveMarkerPath(exPath))
+	if err := WriteLiveSession(dataDir, "2025-03-10", nil, exIdx[:1], LiveProvenance{}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := store.ReadTradeRecords(exPath); len(got) != 2 || IsLiveStockTrades(exPath) {
+		t.Error("REST-built file replaced by live session")
+	}
+}
+
+func TestGenerateStockTradesReplacesCompleteLive(t *testing.T) {
+	dataDir := t.TempDir()
+	cal := util.NewTradingCalendar(domain.MarketUS)
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	for _, date := range []string{"2025-03-07", "2025-03-10"} {
+		path := filepath.Join(dataDir, "us", "trade-universe", date+".csv")
+		os.MkdirAll(filepath.Dir(path), 0o755)
+		if err := os.WriteFile(path, []byte("symbol,type,spx,ndx\nAAAA,STOCK,false,false\nBBBB,STOCK,false,false\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b, err := ettime.BoundsFor("2025-03-10", cal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trade := store.TradeRecord{Symbol: "AAAA", Timestamp: int64(b.Open) + 1000, Price: 5, Size: 200, Exchange: "V", ID: "1"}
+	exPath := filepath.Join(dataDir, "us", "stock-trades-ex-index", "2025-03-10.parquet")
+	if err := WriteLiveSession(dataDir, "2025-03-10", nil, []store.TradeRecord{trade, trade}, LiveProvenance{}); err != nil {
+		t.Fatal(err)
+	}
+	writeSym := func(sym string, records []store.TradeRecord) {
+		t.Helper()
+		if err := store.WriteTradeRecordsUTC(filepath.Join(dataDir, "us", "trades", sym, "2025-03-10.parquet"), records); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// BBBB has not been backfilled: the live file stays and nothing counts
+	// as written.
+	writeSym("AAAA", []store.TradeRecord{trade})
+	n, err := GenerateStockTrades(context.Background(), dataDir, 0, true, conditions.Standard, cal, log)
+	if err != nil || n != 0 {
+		t.Fatalf("incomplete backfill: wrote %d, %v; want 0", n, err)
+	}
+	if got, _ := store.ReadTradeRecords(exPath); len(got) != 2 || !IsLiveStockTrades(exPath) {
+		t.Errorf("live file replaced by incomplete REST version: %+v", got)
+	}
+
+	writeSym("BBBB", []store.TradeRecord{})
+	n, err = GenerateStockTrades(context.Background(), dataDir, 0, true, conditions.Standard, cal, log)
+	if err != nil || n != 1 {
+		t.Fatalf("complete backfill: wrote %d, %v; want 1", n, err)
+	}
+	if got, _ := store.ReadTradeRecords(exPath); len(got) != 1 || IsLiveStockTrades(exPath) {
+		t.Errorf("live file not replaced by REST version: %+v", got)
+	}
+}
+
+func TestCompareTrades(t *testing.T) {
+	tr := func(sym, id, ex string) store.TradeRecord {
+		return store.TradeRecord{Symbol: sym, ID: id, Exchange: ex}
+	}
+	liveTrades := []store.TradeRecord{tr("AAA", "1", "V"), tr("AAA", "2", "V"), tr("BBB", "1", "K"), tr("CCC", "9", "P")}
+	restTrades := []store.TradeRecord{tr("AAA", "1", "V"), tr("BBB", "1", "K"), tr("BBB", "2", "K"), tr("BBB", "3", "Q"), tr("DDD", "1", "V")}
+
+	rep := CompareTrades(liveTrades, restTrades)
+	if rep.LiveTrades != 4 || rep.RESTTrades != 5 || rep.OnlyLive != 2 || rep.OnlyREST != 3 {
+		t.Errorf("totals = %+v", rep)
+	}
+	if rep.LiveSymbols != 3 || rep.RESTSymbols != 3 {
+		t.Errorf("symbols = %d live, %d rest", rep.LiveSymbols, rep.RESTSymbols)
+	}
+	want := []SymbolDiff{
+		{Symbol: "BBB", Live: 1, REST: 3, Delta: -2},
+		{Symbol: "AAA", Live: 2, REST: 1, Delta: 1},
+		{Symbol: "CCC", Live: 1, REST: 0, Delta: 1},
+		{Symbol: "DDD", Live: 0, REST: 1, Delta: -1},
+	}
+	if len(rep.Symbols) != len(want) {
+		t.Fatalf("diffs = %+v", rep.Symbols)
+	}
+	for i := range want {
+		if rep.Symbols[i] != want[i] {
+			t.Errorf("diff[%d] = %+v, want %+v", i, rep.Symbols[i], want[i])
+		}
+	}
+}
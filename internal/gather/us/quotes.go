@@ -0,0 +1,222 @@
+package us
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata/stream"
+
+	"jupitor/internal/domain"
+	"jupitor/internal/live"
+	"jupitor/internal/store"
+)
+
+// ---------------------------------------------------------------------------
+// Quotes: NBBO for a bounded symbol set (watchlist + top movers), sampled
+// once a second into <dataDir>/us/quotes/<SYMBOL>/<date>.parquet.
+// ---------------------------------------------------------------------------
+
+const (
+	// quoteRefreshInterval is how often the quote symbol set is recomputed.
+	quoteRefreshInterval = time.Minute
+
+	// quoteFlushInterval is how often new quote samples are written out; a
+	// crash loses at most this much of the samples.
+	quoteFlushInterval = time.Minute
+)
+
+// SetQuotes enables NBBO quote collection for at most maxSymbols symbols,
+// chosen by the SetQuoteSymbols source. 0, the default, disables it.
+func (g *StreamGatherer) SetQuotes(maxSymbols int) {
+	g.quoteMax = max(maxSymbols, 0)
+}
+
+// SetQuoteSymbols sets the source of symbols to collect quotes for, in
+// priority order, e.g. DashboardServer.QuoteSymbols. It is polled every
+// quoteRefreshInterval; symbols beyond the SetQuotes limit are ignored.
+func (g *StreamGatherer) SetQuoteSymbols(fn func() []string) {
+	g.quoteMu.Lock()
+	g.quoteSymbols = fn
+	g.quoteMu.Unlock()
+}
+
+// Quotes returns the quote book (available after Run starts), or nil if
+// quotes are disabled.
+func (g *StreamGatherer) Quotes() *live.QuoteBook { return g.quotes }
+
+// handleStreamQuote records a quote from the WebSocket stream.
+func (g *StreamGatherer) handleStreamQuote(q stream.Quote) {
+	g.quotes.Update(domain.Quote{
+		Symbol:    q.Symbol,
+		Timestamp: q.Timestamp,
+		BidPrice:  q.BidPrice,
+		AskPrice:  q.AskPrice,
+		BidSize:   int64(q.BidSize),
+		AskSize:   int64(q.AskSize),
+	})
+}
+
+// setStreamClient records the connected stream client, which quote
+// subscription changes go to.
+func (g *StreamGatherer) setStreamClient(c *stream.StocksClient) {
+	g.quoteMu.Lock()
+	g.client = c
+	g.quoteMu.Unlock()
+}
+
+// subscribedQuotes returns the symbols currently subscribed to quotes.
+func (g *StreamGatherer) subscribedQuotes() []string {
+	g.quoteMu.Lock()
+	defer g.quoteMu.Unlock()
+	out := make([]string, 0, len(g.quoteSubs))
+	for sym := range g.quoteSubs {
+		out = append(out, sym)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// boundedSymbols returns the first n distinct non-empty symbols, upper-cased.
+func boundedSymbols(symbols []string, n int) map[string]bool {
+	out := make(map[string]bool, n)
+	for _, sym := range symbols {
+		if len(out) >= n {
+			break
+		}
+		if sym = strings.ToUpper(strings.TrimSpace(sym)); sym != "" {
+			out[sym] = true
+		}
+	}
+	return out
+}
+
+// runQuoteSubscriptions keeps the quote subscriptions in line with the
+// symbol source, checking every quoteRefreshInterval.
+func (g *StreamGatherer) runQuoteSubscriptions(ctx context.Context) {
+	ticker := time.NewTicker(quoteRefreshInterval)
+	defer ticker.Stop()
+	for {
+		g.refreshQuoteSubs()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refreshQuoteSubs subscribes to quotes for symbols that entered the set and
+// unsubscribes from those that left it. A failed change is retried at the
+// next refresh.
+func (g *StreamGatherer) refreshQuoteSubs() {
+	g.quoteMu.Lock()
+	fn := g.quoteSymbols
+	g.quoteMu.Unlock()
+	if fn == nil {
+		return
+	}
+	want := boundedSymbols(fn(), g.quoteMax)
+
+	g.dateMu.RLock()
+	today := g.today
+	g.dateMu.RUnlock()
+
+	g.quoteMu.Lock()
+	defer g.quoteMu.Unlock()
+	var add, remove []string
+	for sym := range want {
+		if !g.quoteSubs[sym] {
+			add = append(add, sym)
+		}
+	}
+	for sym := range g.quoteSubs {
+		if !want[sym] {
+			remove = append(remove, sym)
+		}
+	}
+	if len(add) == 0 && len(remove) == 0 {
+		return
+	}
+	sort.Strings(add)
+	sort.Strings(remove)
+
+	if len(remove) > 0 {
+		if err := g.client.UnsubscribeFromQuotes(remove...); err != nil {
+			g.log.Warn("unsubscribing from quotes", "symbols", len(remove), "error", err)
+			return
+		}
+		for _, sym := range remove {
+			delete(g.quoteSubs, sym)
+			g.quotes.Remove(sym)
+		}
+	}
+	if len(add) > 0 {
+		// Resume today's samples from before a restart so the flush does not
+		// overwrite them.
+		for _, sym := range add {
+			if g.quotes.HasSamples(sym) {
+				continue
+			}
+			if records, err := store.ReadQuoteRecords(store.QuotePath(g.dataDir, sym, today)); err == nil {
+				g.quotes.Load(sym, records)
+			} else if !os.IsNotExist(err) {
+				g.log.Warn("loading quote samples", "symbol", sym, "error", err)
+			}
+		}
+		if err := g.client.SubscribeToQuotes(g.handleStreamQuote, add...); err != nil {
+			g.log.Warn("subscribing to quotes", "symbols", len(add), "error", err)
+			return
+		}
+		if g.quoteSubs == nil {
+			g.quoteSubs = make(map[string]bool, len(add))
+		}
+		for _, sym := range add {
+			g.quoteSubs[sym] = true
+		}
+	}
+	g.log.Info("quote subscriptions updated", "added", len(add), "removed", len(remove), "total", len(g.quoteSubs))
+}
+
+// runQuoteSampler samples the quote book every second and writes the samples
+// out every quoteFlushInterval, at the day switch and on shutdown.
+func (g *StreamGatherer) runQuoteSampler(ctx context.Context) {
+	sample := time.NewTicker(time.Second)
+	defer sample.Stop()
+	flush := time.NewTicker(quoteFlushInterval)
+	defer flush.Stop()
+
+	g.dateMu.RLock()
+	date := g.today
+	g.dateMu.RUnlock()
+	for {
+		select {
+		case <-ctx.Done():
+			g.writeQuotes(date, g.quotes.TakeDirty())
+			return
+		case <-flush.C:
+			g.writeQuotes(date, g.quotes.TakeDirty())
+		case now := <-sample.C:
+			g.dateMu.RLock()
+			today := g.today
+			g.dateMu.RUnlock()
+			if today != date {
+				g.writeQuotes(date, g.quotes.SwitchDay())
+				date = today
+			}
+			g.quotes.Sample(now)
+		}
+	}
+}
+
+// writeQuotes writes each symbol's samples to its quote file for date,
+// replacing the file.
+func (g *StreamGatherer) writeQuotes(date string, samples map[string][]store.QuoteRecord) {
+	for sym, records := range samples {
+		if err := store.WriteQuoteRecordsUTC(store.QuotePath(g.dataDir, sym, date), records); err != nil {
+			g.log.Error("writing quote samples", "symbol", sym, "date", date, "error", err)
+		}
+	}
+}
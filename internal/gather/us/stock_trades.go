@@ -9,11 +9,13 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
-	"time"
 
 	"github.com/parquet-go/parquet-go"
 
+	"jupitor/internal/conditions"
+	"jupitor/internal/ettime"
 	"jupitor/internal/store"
+	"jupitor/internal/util"
 )
 
 // DailyRecord is the Parquet schema for per-symbol daily trade aggregates.
@@ -29,14 +31,14 @@ type DailyRecord struct {
 	High     float64 `parquet:"high"`
 }
 
-// allowedConds defines the set of trade condition codes that pass the filter.
-var allowedConds = map[string]bool{" ": true, "@": true, "T": true, "F": true}
-
 // GenerateStockTrades scans consecutive trade-universe date pairs (P, D)
-// and builds filtered stock-trades parquet files. Skips if output exists.
+// and builds stock-trades parquet files of the trades passing filter
+// (normally the configured trade_filter profile), splitting sessions at the
+// closes in cal. Skips dates whose output
+// exists, unless it was written from the live stream (see WriteLiveSession).
 // When maxDates > 0, only the latest maxDates pairs are considered.
-// Returns the number of files written.
-func GenerateStockTrades(ctx context.Context, dataDir string, maxDates int, skipIndex bool, log *slog.Logger) (int, error) {
+// Returns the number of dates with a file written.
+func GenerateStockTrades(ctx context.Context, dataDir string, maxDates int, skipIndex bool, filter conditions.Profile, cal *util.TradingCalendar, log *slog.Logger) (int, error) {
 	tuDir := filepath.Join(dataDir, "us", "trade-universe")
 	dates, err := listTradeUniverseDates(tuDir)
 	if err != nil {
@@ -63,17 +65,20 @@ func GenerateStockTrades(ctx context.Context, dataDir string, maxDates int, skip
 
 		idxPath := filepath.Join(dataDir, "us", "stock-trades-index", date+".parquet")
 		exPath := filepath.Join(dataDir, "us", "stock-trades-ex-index", date+".parquet")
-		idxExists := skipIndex || fileExists(idxPath)
-		exExists := fileExists(exPath)
+		idxExists := skipIndex || canonicalExists(idxPath)
+		exExists := canonicalExists(exPath)
 		if idxExists && exExists {
 			continue
 		}
 
-		if err := processStockTradesForDate(dataDir, prevDate, date, idxExists, exExists, log); err != nil {
+		ok, err := processStockTradesForDate(dataDir, prevDate, date, idxExists, exExists, filter, cal, log)
+		if err != nil {
 			log.Error("processing stock trades", "date", date, "error", err)
 			continue
 		}
-		wrote++
+		if ok {
+			wrote++
+		}
 	}
 
 	return wrote, nil
@@ -81,27 +86,33 @@ func GenerateStockTrades(ctx context.Context, dataDir string, maxDates int, skip
 
 // processStockTradesForDate reads STOCK symbols from D's trade-universe CSV,
 // reads trades from both P and D per-symbol files, filters by timestamp
-// window (P 4PM ET, D 4PM ET] + exchange/condition filters, writes output.
-// skipIdx/skipEx indicate which output files already exist and can be skipped.
-func processStockTradesForDate(dataDir string, prevDate, date string, skipIdx, skipEx bool, log *slog.Logger) error {
+// window (P 4PM ET, D 4PM ET] and the exchange/condition filter, writes output.
+// skipIdx/skipEx indicate which output files already exist and can be skipped;
+// files written from the live stream are reconciled and replaced. Reports
+// whether any file was written.
+func processStockTradesForDate(dataDir string, prevDate, date string, skipIdx, skipEx bool, filter conditions.Profile, cal *util.TradingCalendar, log *slog.Logger) (bool, error) {
 	csvPath := filepath.Join(dataDir, "us", "trade-universe", date+".csv")
 	symbols, indexSyms, _, err := readStockSymbols(csvPath)
 	if err != nil {
-		return fmt.Errorf("reading stock symbols for %s: %w", date, err)
+		return false, fmt.Errorf("reading stock symbols for %s: %w", date, err)
 	}
 
-	prevClose, err := regularClose(prevDate)
+	prevBounds, err := ettime.BoundsFor(prevDate, cal)
 	if err != nil {
-		return fmt.Errorf("computing P close for %s: %w", prevDate, err)
+		return false, fmt.Errorf("computing P close for %s: %w", prevDate, err)
 	}
-	dateClose, err := regularClose(date)
+	prevClose := prevBounds.Close
+	dateBounds, err := ettime.BoundsFor(date, cal)
 	if err != nil {
-		return fmt.Errorf("computing D close for %s: %w", date, err)
+		return false, fmt.Errorf("computing D close for %s: %w", date, err)
 	}
+	dateClose := dateBounds.Close
 
 	tradesDir := filepath.Join(dataDir, "us", "trades")
 	var indexTrades []store.TradeRecord
 	var exIndexTrades []store.TradeRecord
+	// Symbols whose D file the trades backfill has not written yet.
+	var indexMissing, exIndexMissing []string
 
 	for _, sym := range symbols {
 		symDir := filepath.Join(tradesDir, strings.ToUpper(sym))
@@ -111,9 +122,9 @@ func processStockTradesForDate(dataDir string, prevDate, date string, skipIdx, s
 
 		// Read P's trade file: filter timestamp > prevClose
 		pPath := filepath.Join(symDir, prevDate+".parquet")
-		if records, err := parquet.ReadFile[store.TradeRecord](pPath); err == nil {
+		if records, err := store.ReadTradeRecords(pPath); err == nil {
 			for _, r := range records {
-				if r.Timestamp > prevClose && filterTradeRecord(r) {
+				if ettime.SessionTime(r.Timestamp) > prevClose && filter.Allow(&r) {
 					symTrades = append(symTrades, r)
 				}
 			}
@@ -121,9 +132,10 @@ func processStockTradesForDate(dataDir string, prevDate, date string, skipIdx, s
 
 		// Read D's trade file: filter timestamp <= dateClose
 		dPath := filepath.Join(symDir, date+".parquet")
-		if records, err := parquet.ReadFile[store.TradeRecord](dPath); err == nil {
+		records, err := store.ReadTradeRecords(dPath)
+		if err == nil {
 			for _, r := range records {
-				if r.Timestamp <= dateClose && filterTradeRecord(r) {
+				if ettime.SessionTime(r.Timestamp) <= dateClose && filter.Allow(&r) {
 					symTrades = append(symTrades, r)
 				}
 			}
@@ -131,8 +143,14 @@ func processStockTradesForDate(dataDir string, prevDate, date string, skipIdx, s
 
 		if isIndex {
 			indexTrades = append(indexTrades, symTrades...)
+			if err != nil {
+				indexMissing = append(indexMissing, sym)
+			}
 		} else {
 			exIndexTrades = append(exIndexTrades, symTrades...)
+			if err != nil {
+				exIndexMissing = append(exIndexMissing, sym)
+			}
 		}
 	}
 
@@ -142,29 +160,45 @@ func processStockTradesForDate(dataDir string, prevDate, date string, skipIdx, s
 		})
 	}
 
-	if !skipIdx {
-		sortByTS(indexTrades)
-		idxPath := filepath.Join(dataDir, "us", "stock-trades-index", date+".parquet")
-		if err := os.MkdirAll(filepath.Dir(idxPath), 0o755); err != nil {
-			return fmt.Errorf("creating stock-trades-index dir: %w", err)
+	wrote := false
+	for _, part := range []struct {
+		dir     string
+		skip    bool
+		trades  []store.TradeRecord
+		missing []string
+	}{
+		{"stock-trades-index", skipIdx, indexTrades, indexMissing},
+		{"stock-trades-ex-index", skipEx, exIndexTrades, exIndexMissing},
+	} {
+		if part.skip {
+			continue
 		}
-		if err := parquet.WriteFile(idxPath, indexTrades); err != nil {
-			return fmt.Errorf("writing index stock trades for %s: %w", date, err)
+		sortByTS(part.trades)
+		path := filepath.Join(dataDir, "us", part.dir, date+".parquet")
+
+		// A file written by us-stream at the day switch is replaced by the
+		// REST version once that is complete, after reporting the differences.
+		live := IsLiveStockTrades(path)
+		if live {
+			ok, err := reconcileLive(path, date, part.dir, part.trades, part.missing, log)
+			if err != nil {
+				return false, err
+			}
+			if !ok {
+				continue
+			}
 		}
-	}
 
-	if !skipEx {
-		sortByTS(exIndexTrades)
-		exPath := filepath.Join(dataDir, "us", "stock-trades-ex-index", date+".parquet")
-		if err := os.MkdirAll(filepath.Dir(exPath), 0o755); err != nil {
-			return fmt.Errorf("creating stock-trades-ex-index dir: %w", err)
+		if err := store.WriteTradeRecordsUTC(path, part.trades); err != nil {
+			return wrote, fmt.Errorf("writing %s for %s: %w", part.dir, date, err)
 		}
-		if err := parquet.WriteFile(exPath, exIndexTrades); err != nil {
-			return fmt.Errorf("writing ex-index stock trades for %s: %w", date, err)
+		wrote = true
+		if live {
+			finishReconcile(dataDir, path, date)
 		}
 	}
 
-	return nil
+	return wrote, nil
 }
 
 // aggregateDailyRecords groups trades by symbol and computes per-symbol daily
@@ -269,10 +303,10 @@ func GenerateDailySummaries(ctx context.Context, dataDir string, maxDates int, l
 		exPath := filepath.Join(exDir, date+".parquet")
 
 		var allTrades []store.TradeRecord
-		if records, err := parquet.ReadFile[store.TradeRecord](idxPath); err == nil {
+		if records, err := store.ReadTradeRecords(idxPath); err == nil {
 			allTrades = append(allTrades, records...)
 		}
-		if records, err := parquet.ReadFile[store.TradeRecord](exPath); err == nil {
+		if records, err := store.ReadTradeRecords(exPath); err == nil {
 			allTrades = append(allTrades, records...)
 		}
 
@@ -335,33 +369,6 @@ func readStockSymbols(csvPath string) (symbols []string, indexSyms map[string]bo
 	return symbols, indexSyms, tiers, scanner.Err()
 }
 
-// filterTradeRecord returns true if exchange != "D" and all conditions are allowed.
-func filterTradeRecord(r store.TradeRecord) bool {
-	if r.Exchange == "D" {
-		return false
-	}
-	if r.Conditions == "" {
-		return true
-	}
-	for _, c := range strings.Split(r.Conditions, ",") {
-		if !allowedConds[c] {
-			return false
-		}
-	}
-	return true
-}
-
-// regularClose returns 4:00 PM ET on the given date as ET-shifted milliseconds
-// (the ET clock reading encoded as-if-UTC).
-func regularClose(dateStr string) (int64, error) {
-	t, err := time.Parse("2006-01-02", dateStr)
-	if err != nil {
-		return 0, err
-	}
-	close4pm := time.Date(t.Year(), t.Month(), t.Day(), 16, 0, 0, 0, time.UTC)
-	return close4pm.UnixMilli(), nil
-}
-
 func fileExists(path string) bool {
 	_, err := os.Stat(path)
 	return err == nil
@@ -416,9 +423,10 @@ type binStats struct {
 
 // GenerateRollingBars scans ex-index parquet files and generates rolling
 // 5-minute forward-looking bar files. Skips dates with existing output.
-// When maxDates > 0, only the latest maxDates files are considered.
-// Returns the number of files written.
-func GenerateRollingBars(ctx context.Context, dataDir string, maxDates int, log *slog.Logger) (int, error) {
+// When maxDates > 0, only the latest maxDates files are considered. cal, if
+// non-nil, supplies the session boundaries. Returns the number of files
+// written.
+func GenerateRollingBars(ctx context.Context, dataDir string, maxDates int, cal *util.TradingCalendar, log *slog.Logger) (int, error) {
 	exDir := filepath.Join(dataDir, "us", "stock-trades-ex-index")
 	dates, err := listExIndexDates(exDir)
 	if err != nil {
@@ -441,7 +449,7 @@ func GenerateRollingBars(ctx context.Context, dataDir string, maxDates int, log
 			continue
 		}
 
-		if err := processRollingBarsForDate(dataDir, date, log); err != nil {
+		if err := processRollingBarsForDate(dataDir, date, cal, log); err != nil {
 			log.Error("processing rolling bars", "date", date, "error", err)
 			continue
 		}
@@ -455,7 +463,7 @@ func GenerateRollingBars(ctx context.Context, dataDir string, maxDates int, log
 // 5-second intervals per symbol, computes VWAP per bin, then builds:
 //   - Backward 5m window: gain_pct_5m, trades_5m, turnover_5m over past 60 bins
 //   - Forward gain: gain_pct_future = (max future vwap - current vwap) / current vwap * 100
-func processRollingBarsForDate(dataDir, date string, log *slog.Logger) error {
+func processRollingBarsForDate(dataDir, date string, cal *util.TradingCalendar, log *slog.Logger) error {
 	// Read tiers from trade-universe CSV for this date.
 	csvPath := tradeUniversePath(dataDir, date)
 	_, _, tiers, tierErr := readStockSymbols(csvPath)
@@ -465,7 +473,7 @@ func processRollingBarsForDate(dataDir, date string, log *slog.Logger) error {
 	}
 
 	inPath := filepath.Join(dataDir, "us", "stock-trades-ex-index", date+".parquet")
-	records, err := parquet.ReadFile[store.TradeRecord](inPath)
+	records, err := store.ReadTradeRecords(inPath)
 	if err != nil {
 		return fmt.Errorf("reading ex-index trades for %s: %w", date, err)
 	}
@@ -512,13 +520,23 @@ func processRollingBarsForDate(dataDir, date string, log *slog.Logger) error {
 	const windowSize = 60             // 60 bins × 5s = 5 minutes
 	const gapThreshold = 60 * binSize // max gap between consecutive active bins
 
-	// Session boundaries (ET-as-UTC ms). Gaps between sessions are bridged;
-	// the 5-min gap threshold only applies within the same session.
-	dateT, _ := time.Parse("2006-01-02", date)
-	prev := dateT.AddDate(0, 0, -1)
-	postEnd := time.Date(prev.Year(), prev.Month(), prev.Day(), 20, 0, 0, 0, time.UTC).UnixMilli()
-	preStart := time.Date(dateT.Year(), dateT.Month(), dateT.Day(), 4, 0, 0, 0, time.UTC).UnixMilli()
-	regStart := time.Date(dateT.Year(), dateT.Month(), dateT.Day(), 9, 30, 0, 0, time.UTC).UnixMilli()
+	// Session boundaries (session time). Gaps between sessions are bridged;
+	// the 5-min gap threshold only applies within the same session. The
+	// post-market is the previous trading day's, which ends early on half
+	// days and may be several calendar days back.
+	bounds, err := ettime.BoundsFor(date, cal)
+	if err != nil {
+		return fmt.Errorf("parsing date %s: %w", date, err)
+	}
+	prevDay := bounds.PreOpen.Time().AddDate(0, 0, -1)
+	if cal != nil {
+		if p := cal.PrevTradingDay(bounds.PreOpen.Time()); !p.IsZero() {
+			prevDay = p
+		}
+	}
+	postEnd := int64(ettime.BoundsOn(prevDay, cal).PostClose)
+	preStart := int64(bounds.PreOpen)
+	regStart := int64(bounds.Open)
 	sessionOf := func(ts int64) int {
 		if ts < postEnd {
 			return 0 // post-market
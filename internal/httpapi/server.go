@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"math"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -19,12 +20,16 @@ import (
 	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
 	"github.com/parquet-go/parquet-go"
 
+	"jupitor/internal/conditions"
 	"jupitor/internal/dashboard"
+	"jupitor/internal/domain"
+	"jupitor/internal/ettime"
 	us "jupitor/internal/gather/us"
 	"jupitor/internal/live"
 	"jupitor/internal/news"
 	"jupitor/internal/store"
 	"jupitor/internal/tradeparams"
+	"jupitor/internal/util"
 )
 
 // NewsRecord matches the parquet schema in us-news-history.
@@ -43,6 +48,9 @@ type DashboardServer struct {
 	loc     *time.Location
 	log     *slog.Logger
 
+	// Trading calendar for session bounds (half days, holidays).
+	calendar *util.TradingCalendar
+
 	// Tier map (latest, loaded once at startup).
 	tierMap map[string]string
 
@@ -58,8 +66,11 @@ type DashboardServer struct {
 	// Alpaca marketdata client for news (nil if not configured).
 	mdClient *marketdata.Client
 
-	// Background news cache: "SYMBOL:DATE" -> []NewsArticleJSON
-	newsCache sync.Map
+	// Background news cache: "SYMBOL:DATE" -> []NewsArticleJSON, persisted
+	// under newsCacheDir and refreshed every newsRefresh.
+	newsCache    sync.Map
+	newsCacheDir string
+	newsRefresh  time.Duration
 	// StockTwits rate limiter for background news refresh.
 	stLimiter *time.Ticker
 	// Accumulated set of symbols that ever appeared on the dashboard for today.
@@ -76,6 +87,17 @@ type DashboardServer struct {
 	// Reference data directory for trade-universe generation.
 	refDir string
 
+	// Live feed status (nil if not configured).
+	streamStatus func() us.StreamStatus
+
+	// Streamed quote samples for spread stats (nil if not collected).
+	quotes *live.QuoteBook
+
+	// Trade filter profiles selectable per request; tradeFilter is the
+	// configured one, which stored and live trades have already passed.
+	filters     *conditions.Registry
+	tradeFilter conditions.Profile
+
 	// Replay cache: date -> sorted trades + tier map.
 	replayMu    sync.RWMutex
 	replayCache map[string][]store.TradeRecord
@@ -100,21 +122,59 @@ func NewDashboardServer(
 		dataDir:      dataDir,
 		loc:          loc,
 		log:          log,
+		calendar:     util.NewTradingCalendar(domain.MarketUS),
 		tierMap:      tierMap,
 		historyDates: historyDates,
 		alpacaClient: alpacaClient,
 		watchlistIDs: make(map[string]string),
 		mdClient:     mdClient,
+		newsCacheDir: os.TempDir(),
+		newsRefresh:  5 * time.Minute,
 		stLimiter:    time.NewTicker(500 * time.Millisecond),
 		tradeParams:  tradeParams,
 		refDir:       refDir,
 		replayCache:  make(map[string][]store.TradeRecord),
 		replayTier:   make(map[string]map[string]string),
+		filters:      conditions.NewRegistry(),
+		tradeFilter:  conditions.Standard,
 	}
 
 	return s
 }
 
+// SetCalendar sets the trading calendar whose session hours split the
+// dashboard's sessions. The default is the built-in US calendar.
+func (s *DashboardServer) SetCalendar(cal *util.TradingCalendar) {
+	s.calendar = cal
+}
+
+// SetNewsCache sets where the per-date news cache is persisted and how often
+// it is refreshed. The defaults are $TMPDIR and 5 minutes. Call before Start.
+func (s *DashboardServer) SetNewsCache(dir string, refresh time.Duration) {
+	s.newsCacheDir = dir
+	s.newsRefresh = refresh
+}
+
+// SetStreamStatus sets the source of the live feed status served by
+// /api/stream/status, typically StreamGatherer.Status.
+func (s *DashboardServer) SetStreamStatus(fn func() us.StreamStatus) {
+	s.streamStatus = fn
+}
+
+// SetQuotes sets the quote book whose samples fill the spread and depth
+// stats of the live dashboard, typically StreamGatherer.Quotes.
+func (s *DashboardServer) SetQuotes(book *live.QuoteBook) {
+	s.quotes = book
+}
+
+// SetTradeFilters sets the trade filter profiles selectable with the filter
+// query parameter and the configured default, as returned by
+// config.TradeFilters. The default also builds stock-trades files.
+func (s *DashboardServer) SetTradeFilters(reg *conditions.Registry, def conditions.Profile) {
+	s.filters = reg
+	s.tradeFilter = def
+}
+
 // Start launches background goroutines (news refresh, history backfill). Call
 // this after creating the server, tied to the daemon's context for graceful shutdown.
 func (s *DashboardServer) Start(ctx context.Context) {
@@ -130,13 +190,13 @@ func (s *DashboardServer) getHistoryDates() []string {
 }
 
 // newsCacheFile returns the path to the news cache JSON file for a date.
-func newsCacheFile(date string) string {
-	return fmt.Sprintf("/tmp/us-stream-news-%s.json", date)
+func (s *DashboardServer) newsCacheFile(date string) string {
+	return filepath.Join(s.newsCacheDir, fmt.Sprintf("us-stream-news-%s.json", date))
 }
 
 // loadNewsFromDisk loads the persisted news cache for a date into memory.
 func (s *DashboardServer) loadNewsFromDisk(date string) int {
-	data, err := os.ReadFile(newsCacheFile(date))
+	data, err := os.ReadFile(s.newsCacheFile(date))
 	if err != nil {
 		return 0
 	}
@@ -171,13 +231,18 @@ func (s *DashboardServer) saveNewsToDisk(date string) {
 		s.log.Error("marshalling news cache", "error", err)
 		return
 	}
-	if err := os.WriteFile(newsCacheFile(date), data, 0644); err != nil {
+	if err := os.MkdirAll(s.newsCacheDir, 0o755); err != nil {
+		s.log.Error("creating news cache dir", "error", err)
+		return
+	}
+	if err := os.WriteFile(s.newsCacheFile(date), data, 0644); err != nil {
 		s.log.Error("writing news cache", "error", err)
 	}
 }
 
 // startNewsRefresh periodically fetches news from all sources for today's top
-// symbols and caches the results. Runs every 5 minutes.
+// symbols and caches the results. Runs every newsRefresh (5 minutes by
+// default).
 func (s *DashboardServer) startNewsRefresh(ctx context.Context) {
 	// Load persisted cache for today before fetching.
 	today := time.Now().In(s.loc).Format("2006-01-02")
@@ -185,10 +250,10 @@ func (s *DashboardServer) startNewsRefresh(ctx context.Context) {
 		s.log.Info("loaded news cache from disk", "date", today, "articles", n)
 	}
 
-	// Run immediately on startup, then every 5 minutes.
+	// Run immediately on startup, then every newsRefresh.
 	s.refreshNewsCache()
 
-	ticker := time.NewTicker(5 * time.Minute)
+	ticker := time.NewTicker(s.newsRefresh)
 	defer ticker.Stop()
 	defer s.stLimiter.Stop()
 
@@ -202,32 +267,16 @@ func (s *DashboardServer) startNewsRefresh(ctx context.Context) {
 	}
 }
 
-// refreshNewsCache fetches news for all dashboard symbols from all 4 sources.
-// Uses the same ComputeDayData logic as the dashboard endpoint so the symbol
-// set matches what the bubble chart shows (session-aware filterTopN).
-// Symbols are accumulated across refresh cycles: once a stock appears on the
-// dashboard it stays in the refresh set for the rest of the day.
-func (s *DashboardServer) refreshNewsCache() {
-	if s.mdClient == nil {
-		return
-	}
-
-	now := time.Now().In(s.loc)
-	date := now.Format("2006-01-02")
-
+// dashboardSymbols returns the symbols the dashboard currently shows in its
+// TODAY and NEXT sections, computed the same way as handleDashboard.
+func (s *DashboardServer) dashboardSymbols(now time.Time) map[string]bool {
+	symbolSet := make(map[string]bool)
 	_, todayExIdx := s.model.TodaySnapshot()
 	if len(todayExIdx) == 0 {
-		s.log.Debug("news refresh: no trades yet")
-		return
+		return symbolSet
 	}
 
-	// Compute dashboard the same way as handleDashboard to get exact bubble chart symbols.
-	todayOpen930 := time.Date(now.Year(), now.Month(), now.Day(), 9, 30, 0, 0, s.loc).UnixMilli()
-	_, off := now.Zone()
-	todayOpen930ET := todayOpen930 + int64(off)*1000
-	todayData := dashboard.ComputeDayData("TODAY", todayExIdx, s.tierMap, todayOpen930ET, dashboard.SortPreTrades)
-
-	symbolSet := make(map[string]bool)
+	todayData := dashboard.ComputeDayData("TODAY", todayExIdx, s.tierMap, ettime.BoundsOn(now, s.calendar), dashboard.SortPreTrades)
 	for _, tier := range todayData.Tiers {
 		for _, cs := range tier.Symbols {
 			symbolSet[cs.Symbol] = true
@@ -237,14 +286,67 @@ func (s *DashboardServer) refreshNewsCache() {
 	// Include NEXT session symbols.
 	_, nextExIdx := s.model.NextSnapshot()
 	if len(nextExIdx) > 0 {
-		nextOpen930ET := todayOpen930ET + 24*60*60*1000
-		nextData := dashboard.ComputeDayData("NEXT", nextExIdx, s.tierMap, nextOpen930ET, dashboard.SortPreTrades)
+		nextData := dashboard.ComputeDayData("NEXT", nextExIdx, s.tierMap, s.nextBounds(now), dashboard.SortPreTrades)
 		for _, tier := range nextData.Tiers {
 			for _, cs := range tier.Symbols {
 				symbolSet[cs.Symbol] = true
 			}
 		}
 	}
+	return symbolSet
+}
+
+// QuoteSymbols returns the symbols worth collecting quotes for, in priority
+// order: today's watchlist, then the dashboard's top movers. It is the
+// symbol source for StreamGatherer.SetQuoteSymbols.
+func (s *DashboardServer) QuoteSymbols() []string {
+	now := time.Now().In(s.loc)
+	var out []string
+	seen := make(map[string]bool)
+	if s.alpacaClient != nil {
+		if wlID, err := s.resolveWatchlistID(now.Format("2006-01-02")); err == nil {
+			if wl, err := s.alpacaClient.GetWatchlist(wlID); err == nil {
+				for _, a := range wl.Assets {
+					if !seen[a.Symbol] {
+						seen[a.Symbol] = true
+						out = append(out, a.Symbol)
+					}
+				}
+			} else {
+				s.log.Warn("quote symbols: fetching watchlist", "error", err)
+			}
+		}
+	}
+	sort.Strings(out)
+
+	var movers []string
+	for sym := range s.dashboardSymbols(now) {
+		if !seen[sym] {
+			movers = append(movers, sym)
+		}
+	}
+	sort.Strings(movers)
+	return append(out, movers...)
+}
+
+// refreshNewsCache fetches news for all dashboard symbols from all 4 sources.
+// Uses the same ComputeDayData logic as the dashboard endpoint so the symbol
+// set matches what the bubble chart shows (session-aware filterTopN).
+// Symbols are accumulated across refresh cycles: once a stock appears on the
+// dashboard it stays in the refresh set for the rest of the day.
+func (s *DashboardServer) refreshNewsCache() {
+	if s.mdClient == nil {
+		return
+	}
+
+	now := time.Now().In(s.loc)
+	date := now.Format("2006-01-02")
+
+	symbolSet := s.dashboardSymbols(now)
+	if len(symbolSet) == 0 {
+		s.log.Debug("news refresh: no trades yet")
+		return
+	}
 
 	// Accumulate "ever seen" symbols for this date; track new arrivals.
 	s.newsSeenMu.Lock()
@@ -603,7 +705,7 @@ func (s *DashboardServer) runHistoryPipeline(ctx context.Context) {
 	}
 
 	// Step 3: Generate stock-trades-ex-index for recent dates (limit to latest 10).
-	if wrote, err := us.GenerateStockTrades(ctx, s.dataDir, 10, true, s.log); err != nil {
+	if wrote, err := us.GenerateStockTrades(ctx, s.dataDir, 10, true, s.tradeFilter, s.calendar, s.log); err != nil {
 		s.log.Warn("auto stock-trades-ex-index generation", "error", err)
 	} else if wrote > 0 {
 		s.log.Info("auto stock-trades-ex-index generation complete", "files", wrote)
@@ -900,6 +1002,8 @@ func (s *DashboardServer) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /api/dashboard/replay", s.handleReplay)
 	mux.HandleFunc("GET /api/dashboard/history/{date}", s.handleHistory)
 	mux.HandleFunc("GET /api/dates", s.handleDates)
+	mux.HandleFunc("GET /api/stream/status", s.handleStreamStatus)
+	mux.HandleFunc("GET /api/trade-filters", s.handleTradeFilters)
 	mux.HandleFunc("GET /api/watchlist", s.handleGetWatchlist)
 	mux.HandleFunc("PUT /api/watchlist/{symbol}", s.handleAddWatchlist)
 	mux.HandleFunc("DELETE /api/watchlist/{symbol}", s.handleRemoveWatchlist)
@@ -957,24 +1061,10 @@ func parseSortMode(r *http.Request) int {
 	return n
 }
 
-// open930ET computes 9:30 AM ET as ET-shifted milliseconds for a date.
-func open930ET(date string, loc *time.Location) int64 {
-	t, _ := time.ParseInLocation("2006-01-02", date, loc)
-	open930 := time.Date(t.Year(), t.Month(), t.Day(), 9, 30, 0, 0, loc)
-	_, off := open930.Zone()
-	return open930.UnixMilli() + int64(off)*1000
-}
-
-// postMarketStartET returns 4PM ET on the given date as ET-shifted milliseconds.
-func postMarketStartET(date string) int64 {
-	t, _ := time.Parse("2006-01-02", date)
-	return time.Date(t.Year(), t.Month(), t.Day(), 16, 0, 0, 0, time.UTC).UnixMilli()
-}
-
-// postMarketEndET returns 8PM ET on the given date as ET-shifted milliseconds.
-func postMarketEndET(date string) int64 {
-	t, _ := time.Parse("2006-01-02", date)
-	return time.Date(t.Year(), t.Month(), t.Day(), 20, 0, 0, 0, time.UTC).UnixMilli()
+// nextBounds returns the session bounds of the first trading day after now's
+// ET date, the session of the NEXT section.
+func (s *DashboardServer) nextBounds(now time.Time) ettime.Bounds {
+	return ettime.BoundsOn(s.calendar.NextTradingDay(now), s.calendar)
 }
 
 // nextDateFor returns the next history date after the given date, or "".
@@ -1069,19 +1159,27 @@ func (s *DashboardServer) loadNewsCounts(date string, until int64) map[string]*S
 
 func (s *DashboardServer) handleDashboard(w http.ResponseWriter, r *http.Request) {
 	sortMode := parseSortMode(r)
+	filter, ok := s.requestFilter(w, r)
+	if !ok {
+		return
+	}
 	now := time.Now().In(s.loc)
 	date := now.Format("2006-01-02")
 
-	// Compute today's 9:30 AM ET cutoff.
-	todayOpen930 := time.Date(now.Year(), now.Month(), now.Day(), 9, 30, 0, 0, s.loc).UnixMilli()
-	_, off := now.Zone()
-	todayOpen930ET := todayOpen930 + int64(off)*1000
-	nextOpen930ET := todayOpen930ET + 24*60*60*1000
+	// Today's and the next session's bounds per the calendar.
+	todayBounds := ettime.BoundsOn(now, s.calendar)
+	nextBounds := s.nextBounds(now)
 
 	_, todayExIdx := s.model.TodaySnapshot()
 	_, nextExIdx := s.model.NextSnapshot()
+	todayExIdx = s.refilter(filter, todayExIdx)
+	nextExIdx = s.refilter(filter, nextExIdx)
 
-	todayData := dashboard.ComputeDayData("TODAY", todayExIdx, s.tierMap, todayOpen930ET, sortMode)
+	todayData := dashboard.ComputeDayData("TODAY", todayExIdx, s.tierMap, todayBounds, sortMode)
+	nowET := int64(ettime.FromTime(now))
+	if s.quotes != nil {
+		dashboard.ApplyQuotes(&todayData, s.quotes.Samples, todayBounds, nowET)
+	}
 	newsCounts := s.computeNewsCounts(date, 0)
 	todayJSON := convertDayData(todayData, newsCounts)
 	todayJSON.Date = date
@@ -1091,10 +1189,17 @@ func (s *DashboardServer) handleDashboard(w http.ResponseWriter, r *http.Request
 		Today:     todayJSON,
 		SortMode:  sortMode,
 		SortLabel: dashboard.SortModeLabel(sortMode),
+		Filter:    filter.Name,
 	}
 
 	if len(nextExIdx) > 0 {
-		nextData := dashboard.ComputeDayData("NEXT DAY", nextExIdx, s.tierMap, nextOpen930ET, sortMode)
+		nextData := dashboard.ComputeDayData("NEXT DAY", nextExIdx, s.tierMap, nextBounds, sortMode)
+		if s.quotes != nil {
+			// The next day's pre-market starts at today's close.
+			quoteBounds := nextBounds
+			quoteBounds.PreOpen = todayBounds.Close
+			dashboard.ApplyQuotes(&nextData, s.quotes.Samples, quoteBounds, nowET)
+		}
 		nd := convertDayData(nextData, newsCounts)
 		resp.Next = &nd
 	}
@@ -1109,7 +1214,16 @@ func (s *DashboardServer) handleHistory(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	bounds, err := ettime.BoundsFor(date, s.calendar)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid date")
+		return
+	}
 	sortMode := parseSortMode(r)
+	filter, ok := s.requestFilter(w, r)
+	if !ok {
+		return
+	}
 
 	// Load tier map for this specific date.
 	tierMap, err := dashboard.LoadTierMapForDate(s.dataDir, date)
@@ -1123,9 +1237,10 @@ func (s *DashboardServer) handleHistory(w http.ResponseWriter, r *http.Request)
 		writeError(w, http.StatusNotFound, fmt.Sprintf("trades not found for %s", date))
 		return
 	}
+	trades = s.refilter(filter, trades)
 
-	open930 := open930ET(date, s.loc)
-	data := dashboard.ComputeDayData(date, trades, tierMap, open930, sortMode)
+	data := dashboard.ComputeDayData(date, trades, tierMap, bounds, sortMode)
+	dashboard.ApplyQuotes(&data, dashboard.QuoteFiles(s.dataDir, date), bounds, math.MaxInt64)
 	newsCounts := s.loadNewsCounts(date, 0)
 	todayJSON := convertDayData(data, newsCounts)
 	todayJSON.Date = date
@@ -1135,6 +1250,7 @@ func (s *DashboardServer) handleHistory(w http.ResponseWriter, r *http.Request)
 		Today:     todayJSON,
 		SortMode:  sortMode,
 		SortLabel: dashboard.SortModeLabel(sortMode),
+		Filter:    filter.Name,
 	}
 
 	// Load next day data.
@@ -1143,16 +1259,19 @@ func (s *DashboardServer) handleHistory(w http.ResponseWriter, r *http.Request)
 		nextTrades, err := dashboard.LoadHistoryTrades(s.dataDir, nextDate)
 		if err == nil && len(nextTrades) > 0 {
 			// Filter to post-market window (4PM-8PM ET).
-			postEnd := postMarketEndET(date)
+			postEnd := int64(bounds.PostClose)
 			var filtered []store.TradeRecord
 			for i := range nextTrades {
 				if nextTrades[i].Timestamp <= postEnd {
 					filtered = append(filtered, nextTrades[i])
 				}
 			}
-			if len(filtered) > 0 {
-				nextOpen930 := open930ET(nextDate, s.loc)
-				nextData := dashboard.ComputeDayData("NEXT: "+nextDate, filtered, tierMap, nextOpen930, sortMode)
+			filtered = s.refilter(filter, filtered)
+			nextBounds, err := ettime.BoundsFor(nextDate, s.calendar)
+			if err != nil {
+				s.log.Warn("history next date", "date", nextDate, "error", err)
+			} else if len(filtered) > 0 {
+				nextData := dashboard.ComputeDayData("NEXT: "+nextDate, filtered, tierMap, nextBounds, sortMode)
 				nd := convertDayData(nextData, newsCounts)
 				nd.Date = nextDate
 				resp.Next = &nd
@@ -1160,18 +1279,18 @@ func (s *DashboardServer) handleHistory(w http.ResponseWriter, r *http.Request)
 		}
 	} else if hd := s.getHistoryDates(); len(hd) > 0 && date == hd[len(hd)-1] {
 		// Latest date: read per-symbol trade files for post-market window.
-		postStart := postMarketStartET(date)
-		postEnd := postMarketEndET(date)
+		postStart := int64(bounds.Close)
+		postEnd := int64(bounds.PostClose)
 		symbols := make([]string, 0, len(tierMap))
 		for sym := range tierMap {
 			symbols = append(symbols, sym)
 		}
-		filtered := dashboard.LoadPerSymbolTrades(s.dataDir, date, postStart, postEnd, symbols)
+		// Per-symbol files are unfiltered, so apply the whole profile.
+		filtered := filter.Filter(dashboard.LoadPerSymbolTrades(s.dataDir, date, postStart, postEnd, symbols))
 		if len(filtered) > 0 {
 			now := time.Now().In(s.loc)
 			nextDateLabel := now.Format("2006-01-02")
-			nextOpen930 := open930ET(nextDateLabel, s.loc)
-			nextData := dashboard.ComputeDayData("NEXT: "+nextDateLabel, filtered, tierMap, nextOpen930, sortMode)
+			nextData := dashboard.ComputeDayData("NEXT: "+nextDateLabel, filtered, tierMap, ettime.BoundsOn(now, s.calendar), sortMode)
 			nd := convertDayData(nextData, newsCounts)
 			nd.Date = nextDateLabel
 			resp.Next = &nd
@@ -1197,14 +1316,20 @@ func (s *DashboardServer) handleReplay(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "invalid until timestamp")
 		return
 	}
+	bounds, err := ettime.BoundsFor(date, s.calendar)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid date")
+		return
+	}
 	sortMode := parseSortMode(r)
+	filter, ok := s.requestFilter(w, r)
+	if !ok {
+		return
+	}
 
-	// Determine ET offset for this date to convert between real Unix ms and
-	// the internal ET-shifted convention (ET clock time stored as UTC).
-	dateTime, _ := time.ParseInLocation("2006-01-02", date, s.loc)
-	_, etOff := dateTime.Zone()             // e.g. -18000 for EST
-	etOffMs := int64(etOff) * 1000          // e.g. -18000000
-	untilET := until + etOffMs              // convert real → ET-shifted
+	// Trades are in session time (ET clock stored as UTC); until is real
+	// Unix ms. Convert per instant so DST transition days line up.
+	untilET := int64(ettime.FromUnixMilli(until))
 
 	// Load trades + tier map (from live model or replay cache).
 	today := time.Now().In(s.loc).Format("2006-01-02")
@@ -1238,7 +1363,7 @@ func (s *DashboardServer) handleReplay(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Compute time range from full trades (ET-shifted), then convert to real Unix ms.
+	// Compute time range from full trades (session time), then convert to real Unix ms.
 	var timeRange *TimeRange
 	if len(trades) > 0 {
 		minTS, maxTS := trades[0].Timestamp, trades[0].Timestamp
@@ -1250,10 +1375,13 @@ func (s *DashboardServer) handleReplay(w http.ResponseWriter, r *http.Request) {
 				maxTS = trades[i].Timestamp
 			}
 		}
-		timeRange = &TimeRange{Start: minTS - etOffMs, End: maxTS - etOffMs}
+		timeRange = &TimeRange{
+			Start: ettime.SessionTime(minTS).UnixMilli(),
+			End:   ettime.SessionTime(maxTS).UnixMilli(),
+		}
 	}
 
-	// Filter trades to timestamp <= untilET (ET-shifted comparison).
+	// Filter trades to timestamp <= untilET (session-time comparison).
 	var filtered []store.TradeRecord
 	if date == today {
 		// Live trades may not be sorted; do linear scan.
@@ -1269,8 +1397,8 @@ func (s *DashboardServer) handleReplay(w http.ResponseWriter, r *http.Request) {
 		})
 		filtered = trades[:idx]
 	}
+	filtered = s.refilter(filter, filtered)
 
-	open930 := open930ET(date, s.loc)
 	// Load news counts filtered by replay time (real Unix ms, not ET-shifted).
 	var newsCounts map[string]*SymbolNewsCounts
 	if date == today {
@@ -1279,7 +1407,7 @@ func (s *DashboardServer) handleReplay(w http.ResponseWriter, r *http.Request) {
 		newsCounts = s.loadNewsCounts(date, until)
 	}
 
-	data := dashboard.ComputeDayData(date, filtered, tierMap, open930, sortMode)
+	data := dashboard.ComputeDayData(date, filtered, tierMap, bounds, sortMode)
 	todayJSON := convertDayData(data, newsCounts)
 	todayJSON.Date = date
 
@@ -1288,6 +1416,7 @@ func (s *DashboardServer) handleReplay(w http.ResponseWriter, r *http.Request) {
 		Today:     todayJSON,
 		SortMode:  sortMode,
 		SortLabel: dashboard.SortModeLabel(sortMode),
+		Filter:    filter.Name,
 		TimeRange: timeRange,
 	}
 
@@ -1330,6 +1459,75 @@ func (s *DashboardServer) handleDates(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, DatesResponse{Dates: s.getHistoryDates()})
 }
 
+func (s *DashboardServer) handleStreamStatus(w http.ResponseWriter, r *http.Request) {
+	if s.streamStatus == nil {
+		writeError(w, http.StatusServiceUnavailable, "stream status not available")
+		return
+	}
+	st := s.streamStatus()
+	resp := StreamStatusResponse{
+		Connected:   st.Connected,
+		Reconnects:  st.Reconnects,
+		LastGapSec:  st.LastGap.Seconds(),
+		TotalGapSec: st.TotalGap.Seconds(),
+		GapTrades:   st.GapTrades,
+	}
+	if !st.LastDisconnect.IsZero() {
+		resp.LastDisconnect = st.LastDisconnect.UnixMilli()
+	}
+	writeJSON(w, resp)
+}
+
+// handleTradeFilters lists the profiles requestFilter accepts: those no
+// broader than the configured one.
+func (s *DashboardServer) handleTradeFilters(w http.ResponseWriter, r *http.Request) {
+	resp := TradeFiltersResponse{Default: s.tradeFilter.Name}
+	for _, p := range s.filters.Profiles() {
+		if !p.Within(s.tradeFilter) {
+			continue
+		}
+		resp.Profiles = append(resp.Profiles, TradeFilterJSON{
+			Name:           p.Name,
+			Description:    p.Description,
+			IncludeTRF:     p.IncludeTRF,
+			IncludeOddLots: p.IncludeOddLots,
+			Exclude:        p.Exclude.String(),
+		})
+	}
+	writeJSON(w, resp)
+}
+
+// requestFilter returns the trade filter profile named by the request's
+// filter parameter, or the configured one. It writes a 400 and returns false
+// for an unknown name or a profile broader than the configured one, whose
+// extra trades the live model and stock-trades files do not hold.
+func (s *DashboardServer) requestFilter(w http.ResponseWriter, r *http.Request) (conditions.Profile, bool) {
+	name := r.URL.Query().Get("filter")
+	if name == "" {
+		return s.tradeFilter, true
+	}
+	p, err := s.filters.Get(name)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return conditions.Profile{}, false
+	}
+	if !p.Within(s.tradeFilter) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("trade filter profile %q is broader than the configured %q", p.Name, s.tradeFilter.Name))
+		return conditions.Profile{}, false
+	}
+	return p, true
+}
+
+// refilter applies p, which requestFilter checked is within the configured
+// profile, to trades that already passed the configured profile (the live
+// model and stock-trades files).
+func (s *DashboardServer) refilter(p conditions.Profile, trades []store.TradeRecord) []store.TradeRecord {
+	if p.Name == s.tradeFilter.Name {
+		return trades
+	}
+	return p.Filter(trades)
+}
+
 func (s *DashboardServer) handleGetWatchlist(w http.ResponseWriter, r *http.Request) {
 	if s.alpacaClient == nil {
 		writeJSON(w, WatchlistResponse{Symbols: []string{}})
@@ -1474,6 +1672,10 @@ func (s *DashboardServer) handleSymbolHistory(w http.ResponseWriter, r *http.Req
 	symbol := strings.ToUpper(r.PathValue("symbol"))
 	before := r.URL.Query().Get("before")
 	until := r.URL.Query().Get("until")
+	filter, ok := s.requestFilter(w, r)
+	if !ok {
+		return
+	}
 	limit := 200
 	if ls := r.URL.Query().Get("limit"); ls != "" {
 		if n, err := strconv.Atoi(ls); err == nil && n > 0 {
@@ -1535,7 +1737,7 @@ func (s *DashboardServer) handleSymbolHistory(w http.ResponseWriter, r *http.Req
 		if idx > 0 {
 			prevDate = allDates[idx-1]
 		}
-		entry := s.loadSymbolDateStats(symbol, date, prevDate)
+		entry := s.loadSymbolDateStats(symbol, date, prevDate, filter)
 		if entry != nil {
 			dates = append(dates, *entry)
 		}
@@ -1546,13 +1748,11 @@ func (s *DashboardServer) handleSymbolHistory(w http.ResponseWriter, r *http.Req
 	if before == "" && (until == "" || until >= todayDate) {
 		_, todayExIdx := s.model.TodaySnapshot()
 		if len(todayExIdx) > 0 {
-			symTrades := dashboard.FilterTradesBySymbol(todayExIdx, symbol)
+			symTrades := s.refilter(filter, dashboard.FilterTradesBySymbol(todayExIdx, symbol))
 			if len(symTrades) > 0 {
 				now := time.Now().In(s.loc)
 				todayDate := now.Format("2006-01-02")
-				todayOpen930 := time.Date(now.Year(), now.Month(), now.Day(), 9, 30, 0, 0, s.loc).UnixMilli()
-				_, off := now.Zone()
-				todayOpen930ET := todayOpen930 + int64(off)*1000
+				todayOpen930ET := int64(ettime.BoundsOn(now, s.calendar).Open)
 
 				pre, reg := dashboard.SplitBySession(symTrades, todayOpen930ET)
 				preStats := dashboard.AggregateTrades(pre)
@@ -1670,29 +1870,29 @@ func (s *DashboardServer) handleTargetStream(w http.ResponseWriter, r *http.Requ
 
 // loadSymbolDateStats reads per-symbol trade files using the same (P 4PM, D 4PM]
 // window as consolidated files: after-hours from prevDate's file + current date's
-// file up to 4PM. Results are cached forever (history is immutable).
-func (s *DashboardServer) loadSymbolDateStats(symbol, date, prevDate string) *SymbolDateStats {
-	cacheKey := symbol + ":" + date
+// file up to 4PM, filtered by the given profile. Results are cached forever
+// (history is immutable).
+func (s *DashboardServer) loadSymbolDateStats(symbol, date, prevDate string, filter conditions.Profile) *SymbolDateStats {
+	cacheKey := symbol + ":" + date + ":" + filter.Name
 	if v, ok := s.symbolHistoryCache.Load(cacheKey); ok {
 		return v.(*SymbolDateStats)
 	}
 
-	tradesDir := filepath.Join(s.dataDir, "us", "trades", symbol)
-
-	// regularClose returns 4PM as ET-shifted millis (same convention as stock_trades.go).
-	close4pm := func(d string) int64 {
-		t, _ := time.Parse("2006-01-02", d)
-		return time.Date(t.Year(), t.Month(), t.Day(), 16, 0, 0, 0, time.UTC).UnixMilli()
+	bounds, err := ettime.BoundsFor(date, s.calendar)
+	if err != nil {
+		s.log.Warn("symbol history date", "date", date, "error", err)
+		return nil
 	}
+	tradesDir := filepath.Join(s.dataDir, "us", "trades", symbol)
 
-	dateClose := close4pm(date)
+	dateClose := int64(bounds.Close)
 	var trades []store.TradeRecord
 
 	// Read previous date's file: trades after P 4PM (after-hours → pre-market).
-	if prevDate != "" {
-		prevClose := close4pm(prevDate)
+	if prevBounds, err := ettime.BoundsFor(prevDate, s.calendar); err == nil {
+		prevClose := int64(prevBounds.Close)
 		pPath := filepath.Join(tradesDir, prevDate+".parquet")
-		if records, err := parquet.ReadFile[store.TradeRecord](pPath); err == nil {
+		if records, err := store.ReadTradeRecords(pPath); err == nil {
 			for _, r := range records {
 				if r.Timestamp > prevClose {
 					trades = append(trades, r)
@@ -1703,7 +1903,7 @@ func (s *DashboardServer) loadSymbolDateStats(symbol, date, prevDate string) *Sy
 
 	// Read current date's file: trades up to D 4PM.
 	dPath := filepath.Join(tradesDir, date+".parquet")
-	if records, err := parquet.ReadFile[store.TradeRecord](dPath); err == nil {
+	if records, err := store.ReadTradeRecords(dPath); err == nil {
 		for _, r := range records {
 			if r.Timestamp <= dateClose {
 				trades = append(trades, r)
@@ -1711,14 +1911,14 @@ func (s *DashboardServer) loadSymbolDateStats(symbol, date, prevDate string) *Sy
 		}
 	}
 
-	// Apply exchange/condition filter (same as consolidated files).
-	filtered := dashboard.FilterTradeRecords(trades)
+	// Apply the exchange/condition filter (by default the one consolidated
+	// files use).
+	filtered := filter.Filter(trades)
 	if len(filtered) == 0 {
 		return nil
 	}
 
-	open930 := open930ET(date, s.loc)
-	pre, reg := dashboard.SplitBySession(filtered, open930)
+	pre, reg := dashboard.SplitBySession(filtered, int64(bounds.Open))
 	preStats := dashboard.AggregateTrades(pre)
 	regStats := dashboard.AggregateTrades(reg)
 
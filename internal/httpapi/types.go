@@ -23,6 +23,10 @@ type SymbolStatsJSON struct {
 	MaxDrawdown  float64 `json:"maxDrawdown,omitempty"`
 	TradeProfile    []int   `json:"tradeProfile,omitempty"`
 	TradeProfile30m [][]int `json:"tradeProfile30m,omitempty"`
+	Spread    float64 `json:"spread,omitempty"`
+	SpreadBps float64 `json:"spreadBps,omitempty"`
+	BidDepth  float64 `json:"bidDepth,omitempty"`
+	AskDepth  float64 `json:"askDepth,omitempty"`
 }
 
 // CombinedStatsJSON pairs pre-market and regular session stats.
@@ -74,9 +78,36 @@ type DashboardResponse struct {
 	Next      *DayDataJSON `json:"next,omitempty"`
 	SortMode  int         `json:"sortMode"`
 	SortLabel string      `json:"sortLabel"`
+	Filter    string      `json:"filter,omitempty"` // trade filter profile applied
 	TimeRange *TimeRange  `json:"timeRange,omitempty"`
 }
 
+// StreamStatusResponse reports the live feed's connection health.
+type StreamStatusResponse struct {
+	Connected      bool    `json:"connected"`
+	Reconnects     int     `json:"reconnects"`
+	LastDisconnect int64   `json:"lastDisconnect,omitempty"` // Unix ms; omitted if never disconnected
+	LastGapSec     float64 `json:"lastGapSec"`
+	TotalGapSec    float64 `json:"totalGapSec"`
+	GapTrades      int     `json:"gapTrades"` // trades recovered by gap backfill
+}
+
+// TradeFiltersResponse lists the trade filter profiles selectable with the
+// filter query parameter.
+type TradeFiltersResponse struct {
+	Default  string            `json:"default"`
+	Profiles []TradeFilterJSON `json:"profiles"`
+}
+
+// TradeFilterJSON describes one trade filter profile.
+type TradeFilterJSON struct {
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	IncludeTRF     bool   `json:"includeTrf"`
+	IncludeOddLots bool   `json:"includeOddLots"`
+	Exclude        string `json:"exclude"` // excluded condition flags, "|"-joined
+}
+
 // DatesResponse lists available history dates.
 type DatesResponse struct {
 	Dates []string `json:"dates"`
@@ -137,6 +168,10 @@ func convertSymbolStats(s *dashboard.SymbolStats) *SymbolStatsJSON {
 		MaxDrawdown:  s.MaxDrawdown,
 		TradeProfile:    s.TradeProfile,
 		TradeProfile30m: s.TradeProfile30m,
+		Spread:    s.Spread,
+		SpreadBps: s.SpreadBps,
+		BidDepth:  s.BidDepth,
+		AskDepth:  s.AskDepth,
 	}
 }
 
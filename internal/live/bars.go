@@ -0,0 +1,138 @@
+package live
+
+import (
+	"sort"
+	"time"
+
+	"jupitor/internal/domain"
+	"jupitor/internal/ettime"
+	"jupitor/internal/store"
+)
+
+// TradeFromRecord converts a live record, whose timestamp is session time,
+// into a domain.Trade with a true instant timestamp.
+func TradeFromRecord(rec *store.TradeRecord) domain.Trade {
+	return domain.Trade{
+		Symbol:     rec.Symbol,
+		Timestamp:  ettime.SessionTime(rec.Timestamp).Time(),
+		Price:      rec.Price,
+		Size:       rec.Size,
+		Exchange:   rec.Exchange,
+		ID:         rec.ID,
+		Conditions: rec.Conditions,
+		Update:     rec.Update,
+	}
+}
+
+// BarAggregator builds fixed-interval OHLCV bars from a time-ordered trade
+// stream. All symbols share one window: a trade at or past the end of the
+// current window closes every open bar. It is not safe for concurrent use.
+type BarAggregator struct {
+	interval time.Duration
+	bars     map[string]*domain.Bar // in-progress bar per symbol
+	end      time.Time              // end of the current window
+}
+
+// NewBarAggregator creates an aggregator producing bars of the given interval.
+func NewBarAggregator(interval time.Duration) *BarAggregator {
+	return &BarAggregator{interval: interval, bars: make(map[string]*domain.Bar)}
+}
+
+// Interval returns the bar interval.
+func (a *BarAggregator) Interval() time.Duration { return a.interval }
+
+// WindowEnd returns the end of the current window, or the zero time before
+// the first trade.
+func (a *BarAggregator) WindowEnd() time.Time { return a.end }
+
+// Add folds t into its symbol's bar and returns the bars closed by it, if
+// any, sorted by symbol.
+func (a *BarAggregator) Add(t domain.Trade) []domain.Bar {
+	var closed []domain.Bar
+	if !a.end.IsZero() && !t.Timestamp.Before(a.end) {
+		closed = a.Flush()
+	}
+	if a.end.IsZero() {
+		a.end = t.Timestamp.Truncate(a.interval).Add(a.interval)
+	}
+
+	b, ok := a.bars[t.Symbol]
+	if !ok {
+		b = &domain.Bar{
+			Symbol:    t.Symbol,
+			Timestamp: a.end.Add(-a.interval),
+			Open:      t.Price,
+			High:      t.Price,
+			Low:       t.Price,
+		}
+		a.bars[t.Symbol] = b
+	}
+	b.High = max(b.High, t.Price)
+	b.Low = min(b.Low, t.Price)
+	b.Close = t.Price
+	b.Volume += t.Size
+	b.TradeCount++
+	if b.Volume > 0 {
+		b.VWAP += (t.Price - b.VWAP) * float64(t.Size) / float64(b.Volume)
+	}
+	return closed
+}
+
+// Flush closes the current window and returns its bars sorted by symbol.
+func (a *BarAggregator) Flush() []domain.Bar {
+	out := make([]domain.Bar, 0, len(a.bars))
+	for _, b := range a.bars {
+		out = append(out, *b)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Symbol < out[j].Symbol })
+	clear(a.bars)
+	a.end = time.Time{}
+	return out
+}
+
+// DefaultBarFlushDelay is how long past the end of a bar window a BarStream
+// consumer should wait for a later trade before closing the window itself.
+const DefaultBarFlushDelay = 2 * time.Second
+
+// BarStream emits bars from a live trade feed. A window closes when a later
+// trade arrives or, on a quiet feed, flushDelay after its end; trades for a
+// window already emitted are dropped. It is not safe for concurrent use.
+type BarStream struct {
+	agg        *BarAggregator
+	flushDelay time.Duration
+	emitted    time.Time // end of the last window emitted
+}
+
+// NewBarStream creates a BarStream producing bars of the given interval.
+func NewBarStream(interval, flushDelay time.Duration) *BarStream {
+	return &BarStream{agg: NewBarAggregator(interval), flushDelay: flushDelay}
+}
+
+// Interval returns the bar interval.
+func (s *BarStream) Interval() time.Duration { return s.agg.Interval() }
+
+// Add folds t into the current window and returns the bars closed by it.
+// Trades older than the last emitted window are ignored.
+func (s *BarStream) Add(t domain.Trade) []domain.Bar {
+	if t.Timestamp.Before(s.emitted) {
+		return nil
+	}
+	return s.note(s.agg.Add(t))
+}
+
+// Tick returns the current window's bars if now is at least flushDelay past
+// its end, and nil otherwise.
+func (s *BarStream) Tick(now time.Time) []domain.Bar {
+	end := s.agg.WindowEnd()
+	if end.IsZero() || now.Sub(end) < s.flushDelay {
+		return nil
+	}
+	return s.note(s.agg.Flush())
+}
+
+func (s *BarStream) note(bars []domain.Bar) []domain.Bar {
+	if len(bars) > 0 {
+		s.emitted = bars[0].Timestamp.Add(s.agg.Interval())
+	}
+	return bars
+}
@@ -0,0 +1,41 @@
+package live
+
+import (
+	"testing"
+	"time"
+
+	"jupitor/internal/domain"
+)
+
+func TestBarStream(t *testing.T) {
+	s := NewBarStream(time.Minute, 2*time.Second)
+	base := time.Date(2024, 1, 2, 14, 30, 0, 0, time.UTC)
+	trade := func(d time.Duration, price float64) domain.Trade {
+		return domain.Trade{Symbol: "AAPL", Timestamp: base.Add(d), Price: price, Size: 10}
+	}
+
+	if bars := s.Add(trade(10*time.Second, 100)); len(bars) != 0 {
+		t.Fatalf("first trade closed %d bars", len(bars))
+	}
+	if bars := s.Tick(base.Add(61 * time.Second)); bars != nil {
+		t.Errorf("Tick inside the flush delay = %+v, want nil", bars)
+	}
+	bars := s.Tick(base.Add(62 * time.Second))
+	if len(bars) != 1 || !bars[0].Timestamp.Equal(base) || bars[0].Close != 100 {
+		t.Fatalf("Tick after the flush delay = %+v, want the 14:30 bar", bars)
+	}
+	if bars := s.Tick(base.Add(time.Hour)); bars != nil {
+		t.Errorf("Tick with no open window = %+v, want nil", bars)
+	}
+
+	// A late trade for the emitted window is dropped; a later one opens and
+	// then closes the next window.
+	if bars := s.Add(trade(50*time.Second, 99)); bars != nil {
+		t.Errorf("late trade returned %+v", bars)
+	}
+	s.Add(trade(70*time.Second, 101))
+	bars = s.Add(trade(125*time.Second, 102))
+	if len(bars) != 1 || !bars[0].Timestamp.Equal(base.Add(time.Minute)) || bars[0].Open != 101 || bars[0].TradeCount != 1 {
+		t.Errorf("bars closed by a later trade = %+v", bars)
+	}
+}
@@ -0,0 +1,46 @@
+package live
+
+import (
+	"context"
+	"time"
+
+	"jupitor/internal/ettime"
+	"jupitor/internal/util"
+)
+
+// CutoffFor returns date's regular-session close per cal in session time
+// (4PM ET, or the early close on half days), the cutoff a LiveModel splits
+// the session's trades from the next session's by.
+func CutoffFor(date string, cal *util.TradingCalendar) (int64, error) {
+	b, err := ettime.BoundsFor(date, cal)
+	if err != nil {
+		return 0, err
+	}
+	return int64(b.Close), nil
+}
+
+// TodayCutoff returns the cutoff of now's ET date; see CutoffFor.
+func TodayCutoff(now time.Time, cal *util.TradingCalendar) int64 {
+	return int64(ettime.BoundsOn(now, cal).Close)
+}
+
+// RunDaySwitch switches model to the new session's cutoff at each ET
+// midnight that starts a trading day until ctx is cancelled, so a
+// long-running mirror follows the current session rather than the one it
+// started in. Like us-stream, it keeps the last session over closed days.
+func RunDaySwitch(ctx context.Context, model *LiveModel, cal *util.TradingCalendar) {
+	for {
+		y, m, d := time.Now().In(ettime.Location()).Date()
+		next := time.Date(y, m, d+1, 0, 0, 0, 0, ettime.Location())
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(next)):
+		}
+		now := time.Now()
+		if !cal.IsTradingDay(now) {
+			continue
+		}
+		model.SwitchDay(TodayCutoff(now, cal))
+	}
+}
@@ -0,0 +1,27 @@
+package live
+
+import (
+	"testing"
+	"time"
+
+	"jupitor/internal/domain"
+	"jupitor/internal/ettime"
+	"jupitor/internal/util"
+)
+
+func TestTodayCutoff(t *testing.T) {
+	cal := util.NewTradingCalendar(domain.MarketUS)
+	for _, tc := range []struct {
+		now        time.Time
+		hour, mins int
+	}{
+		{time.Date(2024, 11, 27, 15, 0, 0, 0, time.UTC), 16, 0}, // regular day
+		{time.Date(2024, 11, 29, 15, 0, 0, 0, time.UTC), 13, 0}, // day after Thanksgiving
+		{time.Date(2024, 11, 30, 15, 0, 0, 0, time.UTC), 16, 0}, // Saturday: default close
+	} {
+		want, _ := ettime.At(ettime.DateOf(tc.now), tc.hour, tc.mins)
+		if got := TodayCutoff(tc.now, cal); got != int64(want) {
+			t.Errorf("TodayCutoff(%v) = %v, want %v", tc.now, ettime.SessionTime(got), want)
+		}
+	}
+}
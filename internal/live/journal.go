@@ -0,0 +1,405 @@
+package live
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"jupitor/internal/store"
+)
+
+// DefaultSegmentSize is the size at which the journal starts a new segment.
+const DefaultSegmentSize = 64 << 20
+
+// journalExt is the file extension of journal segments.
+const journalExt = ".wal"
+
+// Entry flags, stored in an entry's first byte.
+const (
+	entryIndex   byte = 1 << iota // the trade is an index stock's
+	entryAmend                    // replaces the held trade with the same key
+	entryRemoved                  // with entryAmend: removes the held trade
+)
+
+// Journal is an append-only write-ahead log of trades added to a LiveModel,
+// so a restarted process can rebuild the model without gaps. It is stored as
+// numbered segment files (00000001.wal, ...) in one directory; a new segment
+// is started on open and whenever the current one exceeds the segment size.
+//
+// Each entry is framed as [length uint32][crc32 uint32][payload], so a torn
+// write at the end of a segment (from a crash) is detected and skipped on
+// replay.
+type Journal struct {
+	dir     string
+	maxSize int64
+
+	mu   sync.Mutex
+	f    *os.File
+	w    *bufio.Writer
+	seq  int
+	size int64
+	buf  []byte
+}
+
+// OpenJournal opens the journal in dir, creating the directory if needed.
+// Appends go to a new segment after any existing ones.
+func OpenJournal(dir string) (*Journal, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating journal dir: %w", err)
+	}
+	segs, err := journalSegments(dir)
+	if err != nil {
+		return nil, err
+	}
+	j := &Journal{dir: dir, maxSize: DefaultSegmentSize}
+	if len(segs) > 0 {
+		j.seq = segs[len(segs)-1]
+	}
+	if err := j.rotate(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// SetSegmentSize sets the size at which a new segment is started. The
+// default is DefaultSegmentSize.
+func (j *Journal) SetSegmentSize(n int64) {
+	j.mu.Lock()
+	j.maxSize = n
+	j.mu.Unlock()
+}
+
+// Dir returns the journal directory.
+func (j *Journal) Dir() string { return j.dir }
+
+// Append records a trade. Entries are buffered; call Sync to make them
+// durable.
+func (j *Journal) Append(record store.TradeRecord, isIndex bool) error {
+	var flags byte
+	if isIndex {
+		flags = entryIndex
+	}
+	return j.appendLocked(record, flags)
+}
+
+// AppendAmend records an amendment made with LiveModel.Amend: record
+// replaces the held trade with the same key, or, if removed, the trade is
+// dropped. Replay applies it to the model in journal order.
+func (j *Journal) AppendAmend(record store.TradeRecord, removed bool) error {
+	flags := entryAmend
+	if removed {
+		flags |= entryRemoved
+	}
+	return j.appendLocked(record, flags)
+}
+
+func (j *Journal) appendLocked(record store.TradeRecord, flags byte) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.f == nil {
+		return errors.New("journal closed")
+	}
+	return j.append(record, flags)
+}
+
+func (j *Journal) append(record store.TradeRecord, flags byte) error {
+	if j.size >= j.maxSize {
+		if err := j.rotate(); err != nil {
+			return err
+		}
+	}
+	j.buf = encodeEntry(j.buf[:0], record, flags)
+	var hdr [8]byte
+	binary.LittleEndian.PutUint32(hdr[0:4], uint32(len(j.buf)))
+	binary.LittleEndian.PutUint32(hdr[4:8], crc32.ChecksumIEEE(j.buf))
+	if _, err := j.w.Write(hdr[:]); err != nil {
+		return err
+	}
+	if _, err := j.w.Write(j.buf); err != nil {
+		return err
+	}
+	j.size += int64(len(hdr) + len(j.buf))
+	return nil
+}
+
+// Sync flushes buffered entries and fsyncs the current segment.
+func (j *Journal) Sync() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.f == nil {
+		return nil
+	}
+	if err := j.w.Flush(); err != nil {
+		return err
+	}
+	return j.f.Sync()
+}
+
+// Close syncs and closes the journal.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.closeSegment()
+}
+
+// Replay adds every journaled trade with a timestamp after from (session
+// time, Unix ms) to m and applies the amendments journaled after it. The
+// model classifies them against its own cutoff and dedups them by trade key,
+// so replaying into a model that already holds some of the trades (e.g. from
+// the backfill cache) is safe. Returns the number of trades added.
+func (j *Journal) Replay(m *LiveModel, from int64) (int, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.w != nil {
+		if err := j.w.Flush(); err != nil {
+			return 0, err
+		}
+	}
+	segs, err := journalSegments(j.dir)
+	if err != nil {
+		return 0, err
+	}
+
+	added := 0
+	var batch [2][]store.TradeRecord // [ex-index, index]
+	var ids [2][]int64
+	flush := func() {
+		for i := range batch {
+			added += m.AddBatch(batch[i], ids[i], i == 1)
+			batch[i], ids[i] = batch[i][:0], ids[i][:0]
+		}
+	}
+	for _, seq := range segs {
+		err := readSegment(j.segmentPath(seq), func(rec store.TradeRecord, flags byte) {
+			if rec.Timestamp <= from {
+				return
+			}
+			id, _ := strconv.ParseInt(rec.ID, 10, 64)
+			if flags&entryAmend != 0 {
+				flush() // the amended trade may still be batched
+				m.Amend(rec.Symbol, id, rec.Exchange, func(r *store.TradeRecord) bool {
+					*r = rec
+					return flags&entryRemoved == 0
+				})
+				return
+			}
+			i := 0
+			if flags&entryIndex != 0 {
+				i = 1
+			}
+			batch[i] = append(batch[i], rec)
+			ids[i] = append(ids[i], id)
+			if len(batch[i]) >= 10000 {
+				flush()
+			}
+		})
+		if err != nil {
+			return added, err
+		}
+	}
+	flush()
+	return added, nil
+}
+
+// Reset replaces the journal's contents with the trades m currently holds.
+// Call it after SwitchDay so the journal only covers the new trading day.
+// Appends made while Reset runs wait for it, so no trade is lost; one added
+// to m just before the snapshot may be journaled twice, which replay dedups.
+// The old segments are removed only once the new one is durable.
+func (j *Journal) Reset(m *LiveModel) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.closeSegment(); err != nil {
+		return err
+	}
+	segs, err := journalSegments(j.dir)
+	if err != nil {
+		return err
+	}
+	if err := j.rotate(); err != nil {
+		return err
+	}
+
+	todayIdx, todayExIdx := m.TodaySnapshot()
+	nextIdx, nextExIdx := m.NextSnapshot()
+	for _, part := range []struct {
+		records []store.TradeRecord
+		isIndex bool
+	}{{todayIdx, true}, {todayExIdx, false}, {nextIdx, true}, {nextExIdx, false}} {
+		var flags byte
+		if part.isIndex {
+			flags = entryIndex
+		}
+		for _, rec := range part.records {
+			if err := j.append(rec, flags); err != nil {
+				return err
+			}
+		}
+	}
+	if err := j.w.Flush(); err != nil {
+		return err
+	}
+	if err := j.f.Sync(); err != nil {
+		return err
+	}
+
+	for _, seq := range segs {
+		if err := os.Remove(j.segmentPath(seq)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// rotate closes the current segment, if any, and starts the next one.
+func (j *Journal) rotate() error {
+	if err := j.closeSegment(); err != nil {
+		return err
+	}
+	j.seq++
+	f, err := os.OpenFile(j.segmentPath(j.seq), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("creating journal segment: %w", err)
+	}
+	j.f, j.w, j.size = f, bufio.NewWriterSize(f, 256<<10), 0
+	return nil
+}
+
+func (j *Journal) closeSegment() error {
+	if j.f == nil {
+		return nil
+	}
+	err := j.w.Flush()
+	if serr := j.f.Sync(); err == nil {
+		err = serr
+	}
+	if cerr := j.f.Close(); err == nil {
+		err = cerr
+	}
+	j.f, j.w = nil, nil
+	return err
+}
+
+func (j *Journal) segmentPath(seq int) string {
+	return filepath.Join(j.dir, fmt.Sprintf("%08d%s", seq, journalExt))
+}
+
+// journalSegments returns the sequence numbers of dir's segments in order.
+func journalSegments(dir string) ([]int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var segs []int
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), journalExt)
+		if !ok || e.IsDir() {
+			continue
+		}
+		if seq, err := strconv.Atoi(name); err == nil {
+			segs = append(segs, seq)
+		}
+	}
+	sort.Ints(segs)
+	return segs, nil
+}
+
+// readSegment calls fn for each intact entry in the segment at path. It stops
+// without error at the first truncated or corrupt entry, which is what a
+// crash mid-write leaves behind.
+func readSegment(path string, fn func(store.TradeRecord, byte)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r := bufio.NewReaderSize(f, 256<<10)
+
+	var hdr [8]byte
+	var payload []byte
+	for {
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return nil
+			}
+			return err
+		}
+		n := binary.LittleEndian.Uint32(hdr[0:4])
+		if n > 1<<20 {
+			return nil // garbage length: torn header
+		}
+		if cap(payload) < int(n) {
+			payload = make([]byte, n)
+		}
+		payload = payload[:n]
+		if _, err := io.ReadFull(r, payload); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return nil
+			}
+			return err
+		}
+		if crc32.ChecksumIEEE(payload) != binary.LittleEndian.Uint32(hdr[4:8]) {
+			return nil
+		}
+		rec, flags, ok := decodeEntry(payload)
+		if !ok {
+			return nil
+		}
+		fn(rec, flags)
+	}
+}
+
+// encodeEntry appends the binary encoding of a journal entry to b.
+func encodeEntry(b []byte, rec store.TradeRecord, flags byte) []byte {
+	b = append(b, flags)
+	b = binary.AppendVarint(b, rec.Timestamp)
+	b = binary.LittleEndian.AppendUint64(b, math.Float64bits(rec.Price))
+	b = binary.AppendVarint(b, rec.Size)
+	for _, s := range []string{rec.Symbol, rec.Exchange, rec.ID, rec.Conditions, rec.Update} {
+		b = binary.AppendUvarint(b, uint64(len(s)))
+		b = append(b, s...)
+	}
+	return b
+}
+
+// decodeEntry decodes an entry written by encodeEntry.
+func decodeEntry(b []byte) (rec store.TradeRecord, flags byte, ok bool) {
+	if len(b) < 1 {
+		return rec, 0, false
+	}
+	flags = b[0]
+	b = b[1:]
+	var n int
+	if rec.Timestamp, n = binary.Varint(b); n <= 0 {
+		return rec, 0, false
+	}
+	b = b[n:]
+	if len(b) < 8 {
+		return rec, 0, false
+	}
+	rec.Price = math.Float64frombits(binary.LittleEndian.Uint64(b))
+	b = b[8:]
+	if rec.Size, n = binary.Varint(b); n <= 0 {
+		return rec, 0, false
+	}
+	b = b[n:]
+	for _, dst := range []*string{&rec.Symbol, &rec.Exchange, &rec.ID, &rec.Conditions, &rec.Update} {
+		l, n := binary.Uvarint(b)
+		if n <= 0 || uint64(len(b)-n) < l {
+			return rec, 0, false
+		}
+		*dst = string(b[n : n+int(l)])
+		b = b[n+int(l):]
+	}
+	return rec, flags, true
+}
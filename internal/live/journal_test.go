@@ -0,0 +1,134 @@
+package live
+
+import (
+	"os"
+	"strconv"
+	"testing"
+
+	"jupitor/internal/store"
+)
+
+func journalTrade(id int64, ts int64) store.TradeRecord {
+	return store.TradeRecord{
+		Symbol: "AAPL", Timestamp: ts, Price: 187.25, Size: 200,
+		Exchange: "V", ID: strconv.FormatInt(id, 10), Conditions: "@,I",
+	}
+}
+
+func TestJournalReplay(t *testing.T) {
+	dir := t.TempDir()
+	j, err := OpenJournal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j.SetSegmentSize(256) // force several segments
+
+	for i := int64(1); i <= 20; i++ {
+		if err := j.Append(journalTrade(i, i*100), i%5 == 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+	segs, _ := journalSegments(dir)
+	if len(segs) < 3 {
+		t.Fatalf("segments = %v, want rotation", segs)
+	}
+
+	// Simulate a crash mid-write: a torn entry at the end of the last segment.
+	last := j.segmentPath(segs[len(segs)-1])
+	f, err := os.OpenFile(last, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte{40, 0, 0, 0, 1, 2, 3, 4, 5})
+	f.Close()
+
+	// Replay after a restart: trades at or before from are dropped, the rest
+	// are split at the model's cutoff.
+	j, err = OpenJournal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+	m := NewLiveModel(1500)
+	n, err := j.Replay(m, 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 18 {
+		t.Errorf("replayed %d trades, want 18", n)
+	}
+	tIdx, tExIdx, nIdx, nExIdx := m.Counts()
+	if tIdx != 3 || tExIdx != 10 || nIdx != 1 || nExIdx != 4 {
+		t.Errorf("counts = %d %d %d %d", tIdx, tExIdx, nIdx, nExIdx)
+	}
+	idx, _ := m.TodaySnapshot()
+	if idx[0] != journalTrade(5, 500) {
+		t.Errorf("first index trade = %+v", idx[0])
+	}
+
+	// Replaying again (e.g. over the backfill cache) adds nothing.
+	if n, _ := j.Replay(m, 200); n != 0 {
+		t.Errorf("second replay added %d", n)
+	}
+}
+
+func TestJournalReset(t *testing.T) {
+	dir := t.TempDir()
+	j, err := OpenJournal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+
+	m := NewLiveModel(1000)
+	for i := int64(1); i <= 4; i++ {
+		rec := journalTrade(i, i*400) // 400, 800 today; 1200, 1600 next day
+		m.Add(rec, i, false)
+		j.Append(rec, false)
+	}
+	m.SwitchDay(2000)
+	if err := j.Reset(m); err != nil {
+		t.Fatal(err)
+	}
+	j.Append(journalTrade(5, 1800), false)
+	if err := j.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	fresh := NewLiveModel(2000)
+	if n, err := j.Replay(fresh, 1000); err != nil || n != 3 {
+		t.Fatalf("Replay = %d, %v, want 3 surviving trades", n, err)
+	}
+	if segs, _ := journalSegments(dir); len(segs) != 1 {
+		t.Errorf("segments after reset = %v, want 1", segs)
+	}
+}
+
+func TestJournalAmend(t *testing.T) {
+	j, err := OpenJournal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+
+	for i := int64(1); i <= 3; i++ {
+		j.Append(journalTrade(i, i*100), false)
+	}
+	cancelled := journalTrade(1, 100)
+	cancelled.Update = "canceled"
+	j.AppendAmend(cancelled, false)
+	j.AppendAmend(journalTrade(2, 200), true)
+	j.AppendAmend(journalTrade(9, 900), true) // never journaled: ignored
+
+	m := NewLiveModel(1000)
+	if n, err := j.Replay(m, 0); err != nil || n != 3 {
+		t.Fatalf("Replay = %d, %v, want 3", n, err)
+	}
+	_, ex := m.TodaySnapshot()
+	if len(ex) != 2 || ex[0] != cancelled || ex[1] != journalTrade(3, 300) {
+		t.Errorf("trades after replay = %+v", ex)
+	}
+}
@@ -32,8 +32,9 @@ type LiveModel struct {
 	todayExIdx  []store.TradeRecord
 	nextIndex   []store.TradeRecord
 	nextExIdx   []store.TradeRecord
-	seen        map[tradeKey]bool // (trade_id, exchange) for dedup
-	todayCutoff int64             // D 4PM ET as Unix ms
+	seen        map[tradeKey]bool            // (trade_id, exchange) for dedup
+	todayCutoff int64                        // D 4PM ET as Unix ms
+	last        map[string]store.TradeRecord // latest trade per symbol, kept across day switches
 
 	subsMu    sync.Mutex
 	nextSubID int
@@ -46,6 +47,7 @@ func NewLiveModel(todayCutoff int64) *LiveModel {
 	return &LiveModel{
 		seen:        make(map[tradeKey]bool),
 		todayCutoff: todayCutoff,
+		last:        make(map[string]store.TradeRecord),
 		subs:        make(map[int]chan TradeEvent),
 	}
 }
@@ -60,6 +62,7 @@ func (m *LiveModel) Add(record store.TradeRecord, rawID int64, isIndex bool) boo
 		return false
 	}
 	m.seen[key] = true
+	m.noteLastLocked(&record)
 
 	isToday := record.Timestamp <= m.todayCutoff
 	if isToday {
@@ -106,6 +109,7 @@ func (m *LiveModel) AddBatch(records []store.TradeRecord, rawIDs []int64, isInde
 			continue
 		}
 		m.seen[key] = true
+		m.noteLastLocked(&records[i])
 		added++
 
 		if records[i].Timestamp <= m.todayCutoff {
@@ -125,6 +129,68 @@ func (m *LiveModel) AddBatch(records []store.TradeRecord, rawIDs []int64, isInde
 	return added
 }
 
+// Amend applies fn to the held trade with the given key, e.g. for a trade
+// cancel or correction, and removes the trade if fn returns false. The key
+// stays seen, so a removed trade is not added back by a later backfill.
+// Subscribers are not notified; the change shows in the next snapshot.
+// Reports whether the trade was found.
+func (m *LiveModel) Amend(symbol string, rawID int64, exchange string, fn func(r *store.TradeRecord) (keep bool)) bool {
+	id := strconv.FormatInt(rawID, 10)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.seen[tradeKey{Symbol: symbol, ID: rawID, Exchange: exchange}] {
+		return false
+	}
+	buckets := []*[]store.TradeRecord{&m.todayIndex, &m.todayExIdx, &m.nextIndex, &m.nextExIdx}
+	for _, bucket := range buckets {
+		b := *bucket
+		for i := range b {
+			r := &b[i]
+			if r.Symbol != symbol || r.ID != id || r.Exchange != exchange {
+				continue
+			}
+			isLast := m.last[symbol].ID == id && m.last[symbol].Exchange == exchange
+			if fn(r) {
+				if isLast {
+					m.last[symbol] = *r
+				}
+				return true
+			}
+			*bucket = append(b[:i], b[i+1:]...)
+			if isLast {
+				// Fall back to the symbol's latest remaining trade.
+				delete(m.last, symbol)
+				for _, bucket := range buckets {
+					for j := range *bucket {
+						if (*bucket)[j].Symbol == symbol {
+							m.noteLastLocked(&(*bucket)[j])
+						}
+					}
+				}
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// noteLastLocked records r as its symbol's latest trade if it is newer
+// (caller holds m.mu).
+func (m *LiveModel) noteLastLocked(r *store.TradeRecord) {
+	if prev, ok := m.last[r.Symbol]; !ok || r.Timestamp >= prev.Timestamp {
+		m.last[r.Symbol] = *r
+	}
+}
+
+// LastPrice returns the price of the latest trade seen for symbol, e.g. as
+// an engine.RiskManager price source.
+func (m *LiveModel) LastPrice(symbol string) (float64, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	r, ok := m.last[symbol]
+	return r.Price, ok
+}
+
 // TodaySnapshot returns copies of the current trading day's trades.
 func (m *LiveModel) TodaySnapshot() (index, exIndex []store.TradeRecord) {
 	m.mu.RLock()
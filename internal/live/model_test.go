@@ -0,0 +1,57 @@
+package live
+
+import (
+	"testing"
+
+	"jupitor/internal/store"
+)
+
+func TestLiveModelLastPrice(t *testing.T) {
+	m := NewLiveModel(1000)
+	if _, ok := m.LastPrice("AAPL"); ok {
+		t.Fatal("LastPrice before any trade reported a price")
+	}
+	m.Add(store.TradeRecord{Symbol: "AAPL", Timestamp: 500, Price: 190, Exchange: "Q", ID: "1"}, 1, false)
+	m.AddBatch([]store.TradeRecord{
+		{Symbol: "AAPL", Timestamp: 400, Price: 180, Exchange: "Q", ID: "2"}, // older backfill
+		{Symbol: "AAPL", Timestamp: 1500, Price: 191, Exchange: "Q", ID: "3"},
+	}, []int64{2, 3}, false)
+	if p, ok := m.LastPrice("AAPL"); !ok || p != 191 {
+		t.Errorf("LastPrice = %v, %v, want 191", p, ok)
+	}
+	m.SwitchDay(2000)
+	if p, _ := m.LastPrice("AAPL"); p != 191 {
+		t.Errorf("LastPrice after day switch = %v, want 191", p)
+	}
+}
+
+func TestLiveModelAmend(t *testing.T) {
+	m := NewLiveModel(1000)
+	m.Add(store.TradeRecord{Symbol: "AAPL", Timestamp: 500, Price: 190, Exchange: "Q", ID: "1"}, 1, false)
+	m.Add(store.TradeRecord{Symbol: "AAPL", Timestamp: 1500, Price: 191, Exchange: "Q", ID: "2"}, 2, false)
+
+	if m.Amend("AAPL", 1, "V", func(*store.TradeRecord) bool { return true }) {
+		t.Error("Amend found a trade on another exchange")
+	}
+	if !m.Amend("AAPL", 1, "Q", func(r *store.TradeRecord) bool { r.Update = "canceled"; return true }) {
+		t.Fatal("Amend did not find the trade")
+	}
+	if _, ex := m.TodaySnapshot(); len(ex) != 1 || ex[0].Update != "canceled" {
+		t.Errorf("today after amend = %+v", ex)
+	}
+
+	// Removing the latest trade falls back to the previous one's price, and
+	// the removed trade is not added back.
+	if !m.Amend("AAPL", 2, "Q", func(*store.TradeRecord) bool { return false }) {
+		t.Fatal("Amend did not find the trade")
+	}
+	if _, ex := m.NextSnapshot(); len(ex) != 0 {
+		t.Errorf("next after removal = %+v", ex)
+	}
+	if p, _ := m.LastPrice("AAPL"); p != 190 {
+		t.Errorf("LastPrice after removal = %v, want 190", p)
+	}
+	if m.Add(store.TradeRecord{Symbol: "AAPL", Timestamp: 1500, Price: 191, Exchange: "Q", ID: "2"}, 2, false) {
+		t.Error("removed trade added back")
+	}
+}
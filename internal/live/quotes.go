@@ -0,0 +1,155 @@
+package live
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"jupitor/internal/domain"
+	"jupitor/internal/ettime"
+	"jupitor/internal/store"
+)
+
+// QuoteBook holds the latest NBBO quote for each subscribed symbol and the
+// day's once-per-second samples of it. It implements api.QuoteSource.
+//
+// Samples are sparse: Sample records a symbol only when its quote changed
+// since the previous sample, so each sample holds until the next one.
+type QuoteBook struct {
+	mu      sync.RWMutex
+	latest  map[string]domain.Quote
+	changed map[string]bool                // quote updated since the last sample
+	samples map[string][]store.QuoteRecord // session time, ascending
+	dirty   map[string]bool                // samples not yet taken by TakeDirty
+}
+
+// NewQuoteBook creates an empty quote book.
+func NewQuoteBook() *QuoteBook {
+	return &QuoteBook{
+		latest:  make(map[string]domain.Quote),
+		changed: make(map[string]bool),
+		samples: make(map[string][]store.QuoteRecord),
+		dirty:   make(map[string]bool),
+	}
+}
+
+// Update records a quote from the stream. Quotes older than the one held
+// for the symbol are ignored.
+func (b *QuoteBook) Update(q domain.Quote) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if prev, ok := b.latest[q.Symbol]; ok && q.Timestamp.Before(prev.Timestamp) {
+		return
+	}
+	b.latest[q.Symbol] = q
+	b.changed[q.Symbol] = true
+}
+
+// Remove drops symbol's latest quote, e.g. when it is unsubscribed and the
+// quote would go stale. Its samples are kept.
+func (b *QuoteBook) Remove(symbol string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.latest, symbol)
+	delete(b.changed, symbol)
+}
+
+// Latest returns symbol's latest quote.
+func (b *QuoteBook) Latest(symbol string) (domain.Quote, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	q, ok := b.latest[symbol]
+	return q, ok
+}
+
+// LatestQuote returns symbol's latest quote, or an error wrapping
+// store.ErrNotFound if the symbol is not subscribed.
+func (b *QuoteBook) LatestQuote(_ context.Context, symbol string) (*domain.Quote, error) {
+	q, ok := b.Latest(symbol)
+	if !ok {
+		return nil, fmt.Errorf("quote %s: %w", symbol, store.ErrNotFound)
+	}
+	return &q, nil
+}
+
+// Sample appends, for each symbol whose quote changed since the last call,
+// a sample stamped now truncated to the second. Returns the number of
+// samples taken.
+func (b *QuoteBook) Sample(now time.Time) int {
+	ts := int64(ettime.FromTime(now.Truncate(time.Second)))
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := 0
+	for sym := range b.changed {
+		q := b.latest[sym]
+		b.samples[sym] = append(b.samples[sym], store.QuoteRecord{
+			Symbol:    sym,
+			Timestamp: ts,
+			BidPrice:  q.BidPrice,
+			BidSize:   q.BidSize,
+			AskPrice:  q.AskPrice,
+			AskSize:   q.AskSize,
+		})
+		b.dirty[sym] = true
+		n++
+	}
+	clear(b.changed)
+	return n
+}
+
+// Samples returns symbol's samples for the day. The slice must not be
+// modified.
+func (b *QuoteBook) Samples(symbol string) []store.QuoteRecord {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	s := b.samples[symbol]
+	return s[:len(s):len(s)]
+}
+
+// HasSamples reports whether the book holds any samples for symbol.
+func (b *QuoteBook) HasSamples(symbol string) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.samples[symbol]) > 0
+}
+
+// Load seeds symbol's samples, e.g. from the day's quote file after a
+// restart. Samples taken since are kept after the loaded ones.
+func (b *QuoteBook) Load(symbol string, records []store.QuoteRecord) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.samples[symbol] = append(append([]store.QuoteRecord(nil), records...), b.samples[symbol]...)
+}
+
+// TakeDirty returns the full sample set of every symbol that gained samples
+// since the previous call.
+func (b *QuoteBook) TakeDirty() map[string][]store.QuoteRecord {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make(map[string][]store.QuoteRecord, len(b.dirty))
+	for sym := range b.dirty {
+		s := b.samples[sym]
+		out[sym] = s[:len(s):len(s)]
+	}
+	clear(b.dirty)
+	return out
+}
+
+// SwitchDay clears the day's samples and returns those of every symbol that
+// gained samples since the last TakeDirty, so they can be persisted. Latest
+// quotes are kept and sampled again at the next Sample, opening the new day.
+func (b *QuoteBook) SwitchDay() map[string][]store.QuoteRecord {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make(map[string][]store.QuoteRecord, len(b.dirty))
+	for sym := range b.dirty {
+		out[sym] = b.samples[sym]
+	}
+	b.samples = make(map[string][]store.QuoteRecord)
+	clear(b.dirty)
+	for sym := range b.latest {
+		b.changed[sym] = true
+	}
+	return out
+}
@@ -0,0 +1,80 @@
+package live
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"jupitor/internal/domain"
+	"jupitor/internal/ettime"
+	"jupitor/internal/store"
+)
+
+func TestQuoteBookSampling(t *testing.T) {
+	b := NewQuoteBook()
+	t0 := time.Date(2025, 3, 14, 14, 30, 0, 0, time.UTC) // 10:30 EDT
+	quote := func(sym string, at time.Time, bid, ask float64) domain.Quote {
+		return domain.Quote{Symbol: sym, Timestamp: at, BidPrice: bid, AskPrice: ask, BidSize: 100, AskSize: 200}
+	}
+
+	b.Update(quote("AAPL", t0, 190.00, 190.02))
+	b.Update(quote("AAPL", t0.Add(300*time.Millisecond), 190.01, 190.03))
+	b.Update(quote("AAPL", t0.Add(100*time.Millisecond), 189.00, 189.50)) // late, ignored
+	b.Update(quote("MSFT", t0, 410.00, 410.10))
+	if n := b.Sample(t0.Add(900 * time.Millisecond)); n != 2 {
+		t.Fatalf("first Sample = %d, want 2", n)
+	}
+	// Only AAPL changes in the next second.
+	b.Update(quote("AAPL", t0.Add(1500*time.Millisecond), 190.02, 190.04))
+	if n := b.Sample(t0.Add(1900 * time.Millisecond)); n != 1 {
+		t.Fatalf("second Sample = %d, want 1", n)
+	}
+	if n := b.Sample(t0.Add(2900 * time.Millisecond)); n != 0 {
+		t.Fatalf("unchanged Sample = %d, want 0", n)
+	}
+
+	got := b.Samples("AAPL")
+	if len(got) != 2 || got[0].BidPrice != 190.01 || got[1].BidPrice != 190.02 {
+		t.Fatalf("AAPL samples = %+v", got)
+	}
+	if want := int64(ettime.FromTime(t0)); got[0].Timestamp != want {
+		t.Errorf("sample timestamp = %d, want session time %d", got[0].Timestamp, want)
+	}
+
+	dirty := b.TakeDirty()
+	if len(dirty) != 2 || len(dirty["AAPL"]) != 2 || len(dirty["MSFT"]) != 1 {
+		t.Errorf("TakeDirty = %v", dirty)
+	}
+	if len(b.TakeDirty()) != 0 {
+		t.Error("second TakeDirty returned samples")
+	}
+
+	// A restart reloads the day's file ahead of new samples.
+	b.Load("TSLA", []store.QuoteRecord{{Symbol: "TSLA", Timestamp: 1, BidPrice: 250, AskPrice: 250.5}})
+	if !b.HasSamples("TSLA") || b.HasSamples("NVDA") {
+		t.Error("HasSamples after Load")
+	}
+
+	// The day switch hands back unsaved samples and re-samples every quote.
+	b.Update(quote("MSFT", t0.Add(3*time.Second), 410.05, 410.15))
+	b.Sample(t0.Add(3 * time.Second))
+	if prev := b.SwitchDay(); len(prev) != 1 || len(prev["MSFT"]) != 2 {
+		t.Errorf("SwitchDay = %v, want MSFT's 2 samples", prev)
+	}
+	if b.HasSamples("AAPL") {
+		t.Error("samples survived the day switch")
+	}
+	if n := b.Sample(t0.Add(24 * time.Hour)); n != 2 {
+		t.Errorf("Sample after switch = %d, want 2", n)
+	}
+
+	b.Remove("MSFT")
+	ctx := context.Background()
+	if q, err := b.LatestQuote(ctx, "AAPL"); err != nil || q.AskPrice != 190.04 {
+		t.Errorf("LatestQuote(AAPL) = %+v, %v", q, err)
+	}
+	if _, err := b.LatestQuote(ctx, "MSFT"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("LatestQuote(removed) error = %v, want ErrNotFound", err)
+	}
+}
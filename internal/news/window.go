@@ -0,0 +1,28 @@
+package news
+
+import (
+	"time"
+
+	"jupitor/internal/util"
+)
+
+// Window returns the news time range for date ("2006-01-02") per cal: from
+// the previous trading day's close through date's post-market end (4PM ET to
+// 8PM ET on regular days), along with the previous trading day. Without a
+// previous trading day the range starts at date's midnight.
+func Window(cal *util.TradingCalendar, date string) (start, end time.Time, prevDate string) {
+	loc := cal.Location()
+	t, _ := time.ParseInLocation("2006-01-02", date, loc)
+	start = t
+	end = time.Date(t.Year(), t.Month(), t.Day(), 20, 0, 0, 0, loc)
+	if s, ok := cal.Session(t); ok {
+		end = s.PostClose
+	}
+	if p := cal.PrevTradingDay(t); !p.IsZero() {
+		prevDate = p.Format("2006-01-02")
+		if s, ok := cal.Session(p); ok {
+			start = s.Close
+		}
+	}
+	return start, end, prevDate
+}
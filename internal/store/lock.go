@@ -0,0 +1,33 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// ErrLocked is returned (wrapped) by LockEngine when another process already
+// runs a trading engine against the same SQLite store.
+var ErrLocked = errors.New("locked by another process")
+
+// LockEngine takes an exclusive lock on sqlitePath + ".engine.lock". Only
+// one process may reconcile and submit orders against a SQLite store (and
+// the broker account behind it); jupitor-trader and a trading jupitor-server
+// both take this lock. The lock is held until release is called or the
+// process exits.
+func LockEngine(sqlitePath string) (release func() error, err error) {
+	path := sqlitePath + ".engine.lock"
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("opening engine lock: %w", err)
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, fmt.Errorf("engine lock %s: %w", path, ErrLocked)
+		}
+		return nil, fmt.Errorf("engine lock %s: %w", path, err)
+	}
+	return f.Close, nil
+}
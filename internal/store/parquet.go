@@ -12,6 +12,7 @@ import (
 	"github.com/parquet-go/parquet-go"
 
 	"jupitor/internal/domain"
+	"jupitor/internal/ettime"
 )
 
 // Compile-time interface checks.
@@ -196,7 +197,9 @@ func (s *ParquetStore) ListSymbols(_ context.Context, market string) ([]string,
 // TradeStore implementation
 // ---------------------------------------------------------------------------
 
-// WriteTrades writes trade data to Parquet files organized by symbol and date.
+// WriteTrades writes trade data to Parquet files organized by symbol and
+// Eastern date. Files are written as schema v2, with real UTC timestamps
+// (see TimestampKey).
 func (s *ParquetStore) WriteTrades(_ context.Context, trades []domain.Trade) error {
 	if len(trades) == 0 {
 		return nil
@@ -208,10 +211,11 @@ func (s *ParquetStore) WriteTrades(_ context.Context, trades []domain.Trade) err
 	}
 	groups := make(map[key][]TradeRecord)
 	for _, t := range trades {
-		k := key{symbol: t.Symbol, date: t.Timestamp.Format("2006-01-02")}
+		st := ettime.FromTime(t.Timestamp)
+		k := key{symbol: t.Symbol, date: st.Date()}
 		groups[k] = append(groups[k], TradeRecord{
 			Symbol:     t.Symbol,
-			Timestamp:  t.Timestamp.UnixMilli(),
+			Timestamp:  int64(st),
 			Price:      t.Price,
 			Size:       t.Size,
 			Exchange:   t.Exchange,
@@ -225,10 +229,10 @@ func (s *ParquetStore) WriteTrades(_ context.Context, trades []domain.Trade) err
 		t, _ := time.Parse("2006-01-02", k.date)
 		path := s.tradePath(k.symbol, t)
 
-		existing, _ := readParquetFile[TradeRecord](path)
+		existing, _ := ReadTradeRecords(path)
 		merged := mergeTradeRecords(existing, records)
 
-		if err := writeParquetFile(path, merged); err != nil {
+		if err := WriteTradeRecordsUTC(path, merged); err != nil {
 			return fmt.Errorf("writing trades for %s/%s: %w", k.symbol, k.date, err)
 		}
 	}
@@ -237,15 +241,30 @@ func (s *ParquetStore) WriteTrades(_ context.Context, trades []domain.Trade) err
 
 // ReadTrades reads trade data from Parquet files for the given symbol and time range.
 func (s *ParquetStore) ReadTrades(_ context.Context, symbol string, start, end time.Time) ([]domain.Trade, error) {
+	return s.readTrades(symbol, start, end, 0)
+}
+
+// ReadTradesLimit implements TradePageReader. Day files are read in order
+// and reading stops at the first day that completes the page.
+func (s *ParquetStore) ReadTradesLimit(_ context.Context, symbol string, start, end time.Time, limit int) ([]domain.Trade, error) {
+	return s.readTrades(symbol, start, end, limit)
+}
+
+// readTrades returns the trades for symbol within [start, end], oldest
+// first, stopping after limit trades when limit > 0.
+func (s *ParquetStore) readTrades(symbol string, start, end time.Time, limit int) ([]domain.Trade, error) {
 	var trades []domain.Trade
-	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+	from := start.In(ettime.Location())
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, ettime.Location())
+	for d := day; !d.After(end); d = d.AddDate(0, 0, 1) {
 		path := s.tradePath(symbol, d)
-		records, err := readParquetFile[TradeRecord](path)
+		records, err := ReadTradeRecords(path)
 		if err != nil {
 			continue
 		}
+		sort.SliceStable(records, func(i, j int) bool { return records[i].Timestamp < records[j].Timestamp })
 		for _, r := range records {
-			ts := time.UnixMilli(r.Timestamp)
+			ts := ettime.SessionTime(r.Timestamp).Time().UTC()
 			if (ts.Equal(start) || ts.After(start)) && (ts.Equal(end) || ts.Before(end)) {
 				trades = append(trades, domain.Trade{
 					Symbol:     r.Symbol,
@@ -259,6 +278,9 @@ func (s *ParquetStore) ReadTrades(_ context.Context, symbol string, start, end t
 				})
 			}
 		}
+		if limit > 0 && len(trades) >= limit {
+			return trades[:limit], nil
+		}
 	}
 	return trades, nil
 }
@@ -392,8 +414,12 @@ func mergeTradeRecords(existing, incoming []TradeRecord) []TradeRecord {
 	for _, r := range seen {
 		merged = append(merged, r)
 	}
+	// Order ties by ID so the file order does not depend on map iteration.
 	sort.Slice(merged, func(i, j int) bool {
-		return merged[i].Timestamp < merged[j].Timestamp
+		if merged[i].Timestamp != merged[j].Timestamp {
+			return merged[i].Timestamp < merged[j].Timestamp
+		}
+		return merged[i].ID < merged[j].ID
 	})
 	return merged
 }
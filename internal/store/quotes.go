@@ -0,0 +1,35 @@
+package store
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// QuoteRecord is the Parquet schema for a sampled NBBO quote. Like
+// TradeRecord, Timestamp is session time in memory and real UTC on disk.
+type QuoteRecord struct {
+	Symbol    string  `parquet:"symbol"`
+	Timestamp int64   `parquet:"timestamp,timestamp(millisecond)"` // Unix ms
+	BidPrice  float64 `parquet:"bid_price"`
+	BidSize   int64   `parquet:"bid_size"`
+	AskPrice  float64 `parquet:"ask_price"`
+	AskSize   int64   `parquet:"ask_size"`
+}
+
+// QuotePath returns the sampled-quote file for symbol on date:
+// <dataDir>/us/quotes/<SYMBOL>/<date>.parquet.
+func QuotePath(dataDir, symbol, date string) string {
+	return filepath.Join(dataDir, "us", "quotes", strings.ToUpper(symbol), date+".parquet")
+}
+
+// ReadQuoteRecords reads a quote file, returning records with session-time
+// timestamps.
+func ReadQuoteRecords(path string) ([]QuoteRecord, error) {
+	return readSessionRecords[QuoteRecord](path)
+}
+
+// WriteQuoteRecordsUTC writes records, whose timestamps are session time, to
+// path with real UTC timestamps. The file is replaced atomically.
+func WriteQuoteRecordsUTC(path string, records []QuoteRecord) error {
+	return writeSessionRecordsUTC(path, records)
+}
@@ -3,8 +3,15 @@ package store
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
 
 	"jupitor/internal/domain"
+	"jupitor/migrations"
 
 	_ "modernc.org/sqlite" // Pure-Go SQLite driver.
 )
@@ -20,47 +27,137 @@ type SQLiteStore struct {
 	db *sql.DB
 }
 
-// NewSQLiteStore opens (or creates) a SQLite database at dbPath and returns
-// a ready-to-use SQLiteStore.
+// NewSQLiteStore opens (or creates) a SQLite database at dbPath, applies any
+// pending embedded migrations and returns a ready-to-use SQLiteStore.
 func NewSQLiteStore(dbPath string) (*SQLiteStore, error) {
-	db, err := sql.Open("sqlite", dbPath)
+	db, err := sql.Open("sqlite", sqliteDSN(dbPath))
 	if err != nil {
 		return nil, err
 	}
-	// TODO: run migrations / create tables
+	if err := migrate(db, migrations.FS); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrating %s: %w", dbPath, err)
+	}
 	return &SQLiteStore{db: db}, nil
 }
 
+// sqliteDSN appends the connection pragmas to dbPath, which may be a plain
+// path or a "file:" URI with its own query string.
+func sqliteDSN(dbPath string) string {
+	pragmas := url.Values{"_pragma": {"busy_timeout(5000)", "journal_mode(WAL)", "foreign_keys(1)"}}
+	sep := "?"
+	if strings.Contains(dbPath, "?") {
+		sep = "&"
+	}
+	return dbPath + sep + pragmas.Encode()
+}
+
 // Close closes the underlying database connection.
 func (s *SQLiteStore) Close() error {
 	return s.db.Close()
 }
 
+// SchemaVersion returns the highest migration version applied to the
+// database.
+func (s *SQLiteStore) SchemaVersion() (int, error) {
+	return schemaVersion(s.db)
+}
+
 // ---------------------------------------------------------------------------
 // OrderStore implementation
 // ---------------------------------------------------------------------------
 
+const orderColumns = `id, symbol, side, type, time_in_force, qty, limit_price, stop_price,
+	status, filled_qty, filled_avg_price, strategy_id, broker_order_id, created_at, updated_at`
+
 // SaveOrder inserts a new order into the database.
-func (s *SQLiteStore) SaveOrder(_ context.Context, _ *domain.Order) error {
-	// TODO: implement INSERT INTO orders
+func (s *SQLiteStore) SaveOrder(ctx context.Context, order *domain.Order) error {
+	now := time.Now().UTC()
+	if order.CreatedAt.IsZero() {
+		order.CreatedAt = now
+	}
+	if order.UpdatedAt.IsZero() {
+		order.UpdatedAt = order.CreatedAt
+	}
+	_, err := s.db.ExecContext(ctx, `INSERT INTO orders (`+orderColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		order.ID, order.Symbol, string(order.Side), string(order.Type), string(order.TimeInForce),
+		order.Qty, nullFloat(order.LimitPrice), nullFloat(order.StopPrice),
+		string(order.Status), order.FilledQty, nullFloat(order.FilledAvgPrice),
+		nullString(order.StrategyID), nullString(order.BrokerOrderID),
+		order.CreatedAt.UTC(), order.UpdatedAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("saving order %s: %w", order.ID, err)
+	}
 	return nil
 }
 
-// GetOrder retrieves a single order by its ID.
-func (s *SQLiteStore) GetOrder(_ context.Context, _ string) (*domain.Order, error) {
-	// TODO: implement SELECT FROM orders WHERE id = ?
-	return nil, nil
+// GetOrder retrieves a single order by its ID. It returns ErrNotFound if no
+// such order exists.
+func (s *SQLiteStore) GetOrder(ctx context.Context, id string) (*domain.Order, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+orderColumns+` FROM orders WHERE id = ?`, id)
+	o, err := scanOrder(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("order %s: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting order %s: %w", id, err)
+	}
+	return o, nil
 }
 
-// ListOrders returns all orders matching the given status.
-func (s *SQLiteStore) ListOrders(_ context.Context, _ domain.OrderStatus) ([]domain.Order, error) {
-	// TODO: implement SELECT FROM orders WHERE status = ?
-	return nil, nil
+// ListOrders returns all orders matching the given status, oldest first. An
+// empty status returns every order.
+func (s *SQLiteStore) ListOrders(ctx context.Context, status domain.OrderStatus) ([]domain.Order, error) {
+	query := `SELECT ` + orderColumns + ` FROM orders`
+	var args []any
+	if status != "" {
+		query += ` WHERE status = ?`
+		args = append(args, string(status))
+	}
+	query += ` ORDER BY created_at, id`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing orders: %w", err)
+	}
+	defer rows.Close()
+
+	var orders []domain.Order
+	for rows.Next() {
+		o, err := scanOrder(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning order: %w", err)
+		}
+		orders = append(orders, *o)
+	}
+	return orders, rows.Err()
 }
 
-// UpdateOrder persists changes to an existing order.
-func (s *SQLiteStore) UpdateOrder(_ context.Context, _ *domain.Order) error {
-	// TODO: implement UPDATE orders SET ... WHERE id = ?
+// UpdateOrder persists changes to an existing order. It returns ErrNotFound
+// if the order was never saved.
+func (s *SQLiteStore) UpdateOrder(ctx context.Context, order *domain.Order) error {
+	if order.UpdatedAt.IsZero() {
+		order.UpdatedAt = time.Now().UTC()
+	}
+	res, err := s.db.ExecContext(ctx, `UPDATE orders SET
+		symbol = ?, side = ?, type = ?, time_in_force = ?, qty = ?, limit_price = ?, stop_price = ?,
+		status = ?, filled_qty = ?, filled_avg_price = ?, strategy_id = ?, broker_order_id = ?,
+		updated_at = ?
+		WHERE id = ?`,
+		order.Symbol, string(order.Side), string(order.Type), string(order.TimeInForce),
+		order.Qty, nullFloat(order.LimitPrice), nullFloat(order.StopPrice),
+		string(order.Status), order.FilledQty, nullFloat(order.FilledAvgPrice),
+		nullString(order.StrategyID), nullString(order.BrokerOrderID),
+		order.UpdatedAt.UTC(), order.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("updating order %s: %w", order.ID, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("order %s: %w", order.ID, ErrNotFound)
+	}
 	return nil
 }
 
@@ -68,27 +165,77 @@ func (s *SQLiteStore) UpdateOrder(_ context.Context, _ *domain.Order) error {
 // PositionStore implementation
 // ---------------------------------------------------------------------------
 
-// SavePosition inserts or updates a position for a symbol.
-func (s *SQLiteStore) SavePosition(_ context.Context, _ *domain.Position) error {
-	// TODO: implement INSERT OR REPLACE INTO positions
+const positionColumns = `symbol, qty, avg_entry_price, market_value, unrealized_pl,
+	unrealized_pl_pct, side, opened_at, updated_at`
+
+// SavePosition inserts or updates a position for a symbol. The original
+// opened_at is kept when an existing position is updated.
+func (s *SQLiteStore) SavePosition(ctx context.Context, pos *domain.Position) error {
+	now := time.Now().UTC()
+	if pos.OpenedAt.IsZero() {
+		pos.OpenedAt = now
+	}
+	if pos.UpdatedAt.IsZero() {
+		pos.UpdatedAt = now
+	}
+	_, err := s.db.ExecContext(ctx, `INSERT INTO positions (`+positionColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(symbol) DO UPDATE SET
+			qty = excluded.qty,
+			avg_entry_price = excluded.avg_entry_price,
+			market_value = excluded.market_value,
+			unrealized_pl = excluded.unrealized_pl,
+			unrealized_pl_pct = excluded.unrealized_pl_pct,
+			side = excluded.side,
+			updated_at = excluded.updated_at`,
+		pos.Symbol, pos.Qty, pos.AvgEntryPrice, pos.MarketValue, pos.UnrealizedPL,
+		pos.UnrealizedPLPct, string(pos.Side), pos.OpenedAt.UTC(), pos.UpdatedAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("saving position %s: %w", pos.Symbol, err)
+	}
 	return nil
 }
 
-// GetPosition retrieves the current position for a symbol.
-func (s *SQLiteStore) GetPosition(_ context.Context, _ string) (*domain.Position, error) {
-	// TODO: implement SELECT FROM positions WHERE symbol = ?
-	return nil, nil
+// GetPosition retrieves the current position for a symbol. It returns
+// ErrNotFound if no position is held.
+func (s *SQLiteStore) GetPosition(ctx context.Context, symbol string) (*domain.Position, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+positionColumns+` FROM positions WHERE symbol = ?`, symbol)
+	p, err := scanPosition(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("position %s: %w", symbol, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting position %s: %w", symbol, err)
+	}
+	return p, nil
 }
 
-// ListPositions returns all open positions.
-func (s *SQLiteStore) ListPositions(_ context.Context) ([]domain.Position, error) {
-	// TODO: implement SELECT FROM positions
-	return nil, nil
+// ListPositions returns all open positions sorted by symbol.
+func (s *SQLiteStore) ListPositions(ctx context.Context) ([]domain.Position, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+positionColumns+` FROM positions ORDER BY symbol`)
+	if err != nil {
+		return nil, fmt.Errorf("listing positions: %w", err)
+	}
+	defer rows.Close()
+
+	var positions []domain.Position
+	for rows.Next() {
+		p, err := scanPosition(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning position: %w", err)
+		}
+		positions = append(positions, *p)
+	}
+	return positions, rows.Err()
 }
 
-// DeletePosition removes the position for a symbol.
-func (s *SQLiteStore) DeletePosition(_ context.Context, _ string) error {
-	// TODO: implement DELETE FROM positions WHERE symbol = ?
+// DeletePosition removes the position for a symbol. Deleting a symbol with
+// no position is not an error.
+func (s *SQLiteStore) DeletePosition(ctx context.Context, symbol string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM positions WHERE symbol = ?`, symbol); err != nil {
+		return fmt.Errorf("deleting position %s: %w", symbol, err)
+	}
 	return nil
 }
 
@@ -96,14 +243,133 @@ func (s *SQLiteStore) DeletePosition(_ context.Context, _ string) error {
 // SignalStore implementation
 // ---------------------------------------------------------------------------
 
-// SaveSignal inserts a new signal into the database.
-func (s *SQLiteStore) SaveSignal(_ context.Context, _ *domain.Signal) error {
-	// TODO: implement INSERT INTO signals
+// SaveSignal inserts a new signal into the database and sets signal.ID to the
+// assigned row ID. Metadata is stored as a JSON object.
+func (s *SQLiteStore) SaveSignal(ctx context.Context, signal *domain.Signal) error {
+	if signal.CreatedAt.IsZero() {
+		signal.CreatedAt = time.Now().UTC()
+	}
+	var meta sql.NullString
+	if len(signal.Metadata) > 0 {
+		b, err := json.Marshal(signal.Metadata)
+		if err != nil {
+			return fmt.Errorf("encoding signal metadata: %w", err)
+		}
+		meta = sql.NullString{String: string(b), Valid: true}
+	}
+
+	res, err := s.db.ExecContext(ctx, `INSERT INTO signals (strategy_id, symbol, type, strength, metadata, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		signal.StrategyID, signal.Symbol, string(signal.Type), signal.Strength, meta, signal.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("saving signal: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("reading signal id: %w", err)
+	}
+	signal.ID = id
 	return nil
 }
 
-// ListSignals returns the most recent signals for a strategy, up to limit.
-func (s *SQLiteStore) ListSignals(_ context.Context, _ string, _ int) ([]domain.Signal, error) {
-	// TODO: implement SELECT FROM signals WHERE strategy_id = ? ORDER BY created_at DESC LIMIT ?
-	return nil, nil
+// ListSignals returns the most recent signals for a strategy, newest first,
+// up to limit. An empty strategyID matches all strategies; limit <= 0 means
+// no limit.
+func (s *SQLiteStore) ListSignals(ctx context.Context, strategyID string, limit int) ([]domain.Signal, error) {
+	query := `SELECT id, strategy_id, symbol, type, strength, metadata, created_at FROM signals`
+	var args []any
+	if strategyID != "" {
+		query += ` WHERE strategy_id = ?`
+		args = append(args, strategyID)
+	}
+	query += ` ORDER BY created_at DESC, id DESC LIMIT ?`
+	if limit <= 0 {
+		limit = -1
+	}
+	args = append(args, limit)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing signals: %w", err)
+	}
+	defer rows.Close()
+
+	var signals []domain.Signal
+	for rows.Next() {
+		var (
+			sig  domain.Signal
+			typ  string
+			meta sql.NullString
+		)
+		if err := rows.Scan(&sig.ID, &sig.StrategyID, &sig.Symbol, &typ, &sig.Strength, &meta, &sig.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning signal: %w", err)
+		}
+		sig.Type = domain.SignalType(typ)
+		if meta.Valid && meta.String != "" {
+			if err := json.Unmarshal([]byte(meta.String), &sig.Metadata); err != nil {
+				return nil, fmt.Errorf("decoding metadata of signal %d: %w", sig.ID, err)
+			}
+		}
+		signals = append(signals, sig)
+	}
+	return signals, rows.Err()
+}
+
+// ---------------------------------------------------------------------------
+// Row helpers
+// ---------------------------------------------------------------------------
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanOrder(r rowScanner) (*domain.Order, error) {
+	var (
+		o                         domain.Order
+		side, typ, tif, status    string
+		limit, stop, avg          sql.NullFloat64
+		strategyID, brokerOrderID sql.NullString
+	)
+	if err := r.Scan(&o.ID, &o.Symbol, &side, &typ, &tif, &o.Qty, &limit, &stop,
+		&status, &o.FilledQty, &avg, &strategyID, &brokerOrderID, &o.CreatedAt, &o.UpdatedAt); err != nil {
+		return nil, err
+	}
+	o.Side = domain.OrderSide(side)
+	o.Type = domain.OrderType(typ)
+	o.TimeInForce = domain.TimeInForce(tif)
+	o.Status = domain.OrderStatus(status)
+	o.LimitPrice = limit.Float64
+	o.StopPrice = stop.Float64
+	o.FilledAvgPrice = avg.Float64
+	o.StrategyID = strategyID.String
+	o.BrokerOrderID = brokerOrderID.String
+	return &o, nil
+}
+
+func scanPosition(r rowScanner) (*domain.Position, error) {
+	var (
+		p      domain.Position
+		side   string
+		mv, pl sql.NullFloat64
+		plPct  sql.NullFloat64
+	)
+	if err := r.Scan(&p.Symbol, &p.Qty, &p.AvgEntryPrice, &mv, &pl, &plPct, &side, &p.OpenedAt, &p.UpdatedAt); err != nil {
+		return nil, err
+	}
+	p.MarketValue = mv.Float64
+	p.UnrealizedPL = pl.Float64
+	p.UnrealizedPLPct = plPct.Float64
+	p.Side = domain.PositionSide(side)
+	return &p, nil
+}
+
+// nullFloat stores zero prices as NULL (e.g. no limit price on market orders).
+func nullFloat(f float64) sql.NullFloat64 {
+	return sql.NullFloat64{Float64: f, Valid: f != 0}
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
 }
@@ -4,11 +4,15 @@ package store
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"jupitor/internal/domain"
 )
 
+// ErrNotFound is returned (wrapped) when a requested record does not exist.
+var ErrNotFound = errors.New("not found")
+
 // BarStore persists and retrieves OHLCV bar data.
 type BarStore interface {
 	// WriteBars persists a batch of bars to storage.
@@ -30,12 +34,23 @@ type TradeStore interface {
 	ReadTrades(ctx context.Context, symbol string, start, end time.Time) ([]domain.Trade, error)
 }
 
+// TradePageReader is implemented by trade stores that can stop reading once
+// a page of trades is complete, so paging through a range does not re-read
+// all of it for every page.
+type TradePageReader interface {
+	// ReadTradesLimit returns the first limit trades for the given symbol
+	// within [start, end], oldest first. Trades sharing a timestamp keep
+	// storage order, which is stable across calls.
+	ReadTradesLimit(ctx context.Context, symbol string, start, end time.Time, limit int) ([]domain.Trade, error)
+}
+
 // OrderStore persists and retrieves order records.
 type OrderStore interface {
 	// SaveOrder inserts a new order into storage.
 	SaveOrder(ctx context.Context, order *domain.Order) error
 
-	// GetOrder retrieves a single order by its ID.
+	// GetOrder retrieves a single order by its ID. It returns an error
+	// wrapping ErrNotFound if no such order exists.
 	GetOrder(ctx context.Context, id string) (*domain.Order, error)
 
 	// ListOrders returns all orders matching the given status.
@@ -50,7 +65,8 @@ type PositionStore interface {
 	// SavePosition inserts or updates a position for a symbol.
 	SavePosition(ctx context.Context, pos *domain.Position) error
 
-	// GetPosition retrieves the current position for a symbol.
+	// GetPosition retrieves the current position for a symbol. It returns an
+	// error wrapping ErrNotFound if no position is held.
 	GetPosition(ctx context.Context, symbol string) (*domain.Position, error)
 
 	// ListPositions returns all open positions.
@@ -2,12 +2,14 @@ package store
 
 import (
 	"context"
+	"errors"
 	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"jupitor/internal/domain"
+	"jupitor/internal/ettime"
 )
 
 func TestParquetStorePath(t *testing.T) {
@@ -209,6 +211,114 @@ func TestMergeTradeRecordsEmpty(t *testing.T) {
 	}
 }
 
+func TestParquetStoreReadTradesLimit(t *testing.T) {
+	ps := NewParquetStore(t.TempDir())
+	ctx := context.Background()
+	day1 := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	trades := []domain.Trade{
+		{Symbol: "AAPL", Timestamp: day1, Price: 1, Size: 1, ID: "b"},
+		{Symbol: "AAPL", Timestamp: day1, Price: 2, Size: 1, ID: "a"},
+		{Symbol: "AAPL", Timestamp: day1.Add(time.Second), Price: 3, Size: 1, ID: "c"},
+		{Symbol: "AAPL", Timestamp: day2, Price: 4, Size: 1, ID: "d"},
+	}
+	if err := ps.WriteTrades(ctx, trades); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ps.ReadTradesLimit(ctx, "AAPL", day1, day2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ID != "a" || got[1].ID != "b" {
+		t.Errorf("ReadTradesLimit(2) = %+v, want a, b (ties ordered by ID)", got)
+	}
+	if got, _ := ps.ReadTradesLimit(ctx, "AAPL", day1, day2, 10); len(got) != 4 || got[3].ID != "d" {
+		t.Errorf("ReadTradesLimit(10) = %+v, want all 4 trades", got)
+	}
+}
+
+func TestTradeTimestampMigration(t *testing.T) {
+	ps := NewParquetStore(t.TempDir())
+	// 2024-03-10 is the spring-forward day: 08:00 UTC is 04:00 EDT.
+	instants := []time.Time{
+		time.Date(2024, 3, 10, 6, 30, 0, 0, time.UTC), // 01:30 EST
+		time.Date(2024, 3, 10, 8, 0, 0, 0, time.UTC),  // 04:00 EDT
+	}
+	var trades []domain.Trade
+	for i, ts := range instants {
+		trades = append(trades, domain.Trade{Symbol: "AAPL", Timestamp: ts, Price: 100, Size: 200, ID: string(rune('a' + i))})
+	}
+	if err := ps.WriteTrades(context.Background(), trades); err != nil {
+		t.Fatal(err)
+	}
+	path := ps.tradePath("AAPL", instants[0])
+
+	before, err := ReadTradeRecords(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantWall := []string{"01:30", "04:00"}
+	for i, r := range before {
+		if got := time.UnixMilli(r.Timestamp).UTC().Format("15:04"); got != wantWall[i] {
+			t.Errorf("record %d session wall clock = %s, want %s", i, got, wantWall[i])
+		}
+	}
+
+	if conv, _ := TradeFileTimestamps(path); conv != TimestampsUTC {
+		t.Fatalf("written file convention = %q, want %q", conv, TimestampsUTC)
+	}
+	if ok, err := MigrateTradeFile(path); ok || err != nil {
+		t.Fatalf("MigrateTradeFile on a new file = %v, %v, want no-op", ok, err)
+	}
+
+	// Files from before the marker hold session time and carry no key.
+	if err := writeParquetFile(path, before); err != nil {
+		t.Fatal(err)
+	}
+	if conv, _ := TradeFileTimestamps(path); conv != TimestampsSession {
+		t.Fatalf("unmarked file convention = %q", conv)
+	}
+	if ok, err := MigrateTradeFile(path); !ok || err != nil {
+		t.Fatalf("MigrateTradeFile = %v, %v", ok, err)
+	}
+	if conv, _ := TradeFileTimestamps(path); conv != TimestampsUTC {
+		t.Fatalf("migrated file convention = %q", conv)
+	}
+	if ok, err := MigrateTradeFile(path); ok || err != nil {
+		t.Fatalf("second MigrateTradeFile = %v, %v, want no-op", ok, err)
+	}
+
+	raw, err := readParquetFile[TradeRecord](path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, r := range raw {
+		if r.Timestamp != instants[i].UnixMilli() {
+			t.Errorf("on-disk record %d = %d, want real UTC %d", i, r.Timestamp, instants[i].UnixMilli())
+		}
+	}
+	after, err := ReadTradeRecords(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range after {
+		if after[i].Timestamp != before[i].Timestamp {
+			t.Errorf("record %d read back as %d, want %d", i, after[i].Timestamp, before[i].Timestamp)
+		}
+	}
+
+	got, err := ps.ReadTrades(context.Background(), "AAPL", instants[0], instants[1])
+	if err != nil || len(got) != 2 {
+		t.Fatalf("ReadTrades = %d trades, %v", len(got), err)
+	}
+	for i := range got {
+		if !got[i].Timestamp.Equal(instants[i]) {
+			t.Errorf("trade %d at %v, want %v", i, got[i].Timestamp, instants[i])
+		}
+	}
+}
+
 func TestSQLiteStoreOpen(t *testing.T) {
 	dir := t.TempDir()
 	dbPath := filepath.Join(dir, "test.db")
@@ -228,3 +338,279 @@ func TestSQLiteStoreOpen(t *testing.T) {
 		t.Fatalf("db.Ping() returned error: %v", err)
 	}
 }
+
+func TestSQLiteStoreURIWithQuery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	s, err := NewSQLiteStore("file:" + path + "?mode=rwc")
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer s.Close()
+
+	var fk int
+	var mode string
+	if err := s.db.QueryRow("PRAGMA foreign_keys").Scan(&fk); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.db.QueryRow("PRAGMA journal_mode").Scan(&mode); err != nil {
+		t.Fatal(err)
+	}
+	if fk != 1 || mode != "wal" {
+		t.Errorf("foreign_keys=%d journal_mode=%q, want 1 and wal", fk, mode)
+	}
+}
+
+func newTestSQLiteStore(t *testing.T) (*SQLiteStore, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.db")
+	s, err := NewSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s, path
+}
+
+func TestLockEngine(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "trading.db")
+	release, err := LockEngine(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LockEngine(dbPath); !errors.Is(err, ErrLocked) {
+		t.Errorf("second LockEngine err = %v, want ErrLocked", err)
+	}
+	if err := release(); err != nil {
+		t.Fatal(err)
+	}
+	release, err = LockEngine(dbPath)
+	if err != nil {
+		t.Fatalf("LockEngine after release: %v", err)
+	}
+	release()
+}
+
+func TestSQLiteStoreMigrations(t *testing.T) {
+	s, path := newTestSQLiteStore(t)
+
+	v, err := s.SchemaVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != 2 {
+		t.Errorf("SchemaVersion = %d, want 2", v)
+	}
+	s.Close()
+
+	// Reopening must not re-apply migrations.
+	s2, err := NewSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer s2.Close()
+	var n int
+	if err := s2.db.QueryRow(`SELECT COUNT(*) FROM schema_version`).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("schema_version rows = %d, want 2", n)
+	}
+
+	// A database from a newer build is rejected.
+	if _, err := s2.db.Exec(`INSERT INTO schema_version (version, name, applied_at) VALUES (99, '099_future.sql', ?)`, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	s2.Close()
+	if _, err := NewSQLiteStore(path); err == nil {
+		t.Error("expected error opening database with newer schema version")
+	}
+}
+
+func TestSQLiteStoreOrders(t *testing.T) {
+	s, _ := newTestSQLiteStore(t)
+	ctx := context.Background()
+	t0 := time.Date(2025, 3, 10, 14, 30, 0, 0, time.UTC)
+
+	o1 := &domain.Order{
+		ID: "o1", Symbol: "AAPL", Side: domain.OrderSideBuy, Type: domain.OrderTypeLimit,
+		TimeInForce: domain.TimeInForceDay, Qty: 10, LimitPrice: 150.5,
+		Status: domain.OrderStatusPending, StrategyID: "sma", CreatedAt: t0,
+	}
+	o2 := &domain.Order{
+		ID: "o2", Symbol: "MSFT", Side: domain.OrderSideSell, Type: domain.OrderTypeMarket,
+		TimeInForce: domain.TimeInForceGTC, Qty: 5, Status: domain.OrderStatusSubmitted,
+		CreatedAt: t0.Add(time.Minute),
+	}
+	for _, o := range []*domain.Order{o1, o2} {
+		if err := s.SaveOrder(ctx, o); err != nil {
+			t.Fatalf("SaveOrder(%s): %v", o.ID, err)
+		}
+	}
+	if err := s.SaveOrder(ctx, o1); err == nil {
+		t.Error("expected error saving duplicate order ID")
+	}
+
+	got, err := s.GetOrder(ctx, "o1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Symbol != "AAPL" || got.LimitPrice != 150.5 || got.StopPrice != 0 ||
+		got.StrategyID != "sma" || got.Status != domain.OrderStatusPending || !got.CreatedAt.Equal(t0) {
+		t.Errorf("GetOrder = %+v", got)
+	}
+
+	if _, err := s.GetOrder(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetOrder(missing) err = %v, want ErrNotFound", err)
+	}
+
+	o1.Status = domain.OrderStatusFilled
+	o1.FilledQty = 10
+	o1.FilledAvgPrice = 150.25
+	o1.BrokerOrderID = "b-1"
+	o1.UpdatedAt = t0.Add(2 * time.Minute)
+	if err := s.UpdateOrder(ctx, o1); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = s.GetOrder(ctx, "o1")
+	if got.Status != domain.OrderStatusFilled || got.FilledAvgPrice != 150.25 || got.BrokerOrderID != "b-1" {
+		t.Errorf("after update = %+v", got)
+	}
+	if err := s.UpdateOrder(ctx, &domain.Order{ID: "missing"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("UpdateOrder(missing) err = %v, want ErrNotFound", err)
+	}
+
+	filled, err := s.ListOrders(ctx, domain.OrderStatusFilled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filled) != 1 || filled[0].ID != "o1" {
+		t.Errorf("ListOrders(filled) = %+v", filled)
+	}
+	all, _ := s.ListOrders(ctx, "")
+	if len(all) != 2 || all[0].ID != "o1" || all[1].ID != "o2" {
+		t.Errorf("ListOrders(all) = %+v", all)
+	}
+}
+
+func TestSQLiteStorePositions(t *testing.T) {
+	s, _ := newTestSQLiteStore(t)
+	ctx := context.Background()
+	opened := time.Date(2025, 3, 10, 14, 30, 0, 0, time.UTC)
+
+	pos := &domain.Position{
+		Symbol: "AAPL", Qty: 10, AvgEntryPrice: 150, MarketValue: 1550,
+		UnrealizedPL: 50, UnrealizedPLPct: 0.0333, Side: domain.PositionSideLong,
+		OpenedAt: opened, UpdatedAt: opened,
+	}
+	if err := s.SavePosition(ctx, pos); err != nil {
+		t.Fatal(err)
+	}
+
+	// Upsert keeps the original opened_at.
+	update := *pos
+	update.Qty = 20
+	update.OpenedAt = opened.Add(time.Hour)
+	update.UpdatedAt = opened.Add(time.Hour)
+	if err := s.SavePosition(ctx, &update); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.GetPosition(ctx, "AAPL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Qty != 20 || got.UnrealizedPL != 50 || got.UnrealizedPLPct != 0.0333 ||
+		got.Side != domain.PositionSideLong || !got.OpenedAt.Equal(opened) {
+		t.Errorf("GetPosition = %+v", got)
+	}
+
+	s.SavePosition(ctx, &domain.Position{Symbol: "AMD", Qty: 1, AvgEntryPrice: 100, Side: domain.PositionSideShort})
+	list, err := s.ListPositions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].Symbol != "AAPL" || list[1].Symbol != "AMD" {
+		t.Errorf("ListPositions = %+v", list)
+	}
+
+	if err := s.DeletePosition(ctx, "AAPL"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetPosition(ctx, "AAPL"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetPosition after delete err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestSQLiteStoreSignals(t *testing.T) {
+	s, _ := newTestSQLiteStore(t)
+	ctx := context.Background()
+	t0 := time.Date(2025, 3, 10, 14, 30, 0, 0, time.UTC)
+
+	for i, strat := range []string{"a", "b", "a", "a"} {
+		sig := &domain.Signal{
+			StrategyID: strat, Symbol: "AAPL", Type: domain.SignalTypeBuy, Strength: float64(i),
+			Metadata:  map[string]string{"i": strings.Repeat("x", i)},
+			CreatedAt: t0.Add(time.Duration(i) * time.Minute),
+		}
+		if i == 0 {
+			sig.Metadata = nil
+		}
+		if err := s.SaveSignal(ctx, sig); err != nil {
+			t.Fatal(err)
+		}
+		if sig.ID != int64(i+1) {
+			t.Errorf("signal %d ID = %d, want %d", i, sig.ID, i+1)
+		}
+	}
+
+	got, err := s.ListSignals(ctx, "a", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ID != 4 || got[1].ID != 3 {
+		t.Fatalf("ListSignals(a, 2) = %+v", got)
+	}
+	if got[0].Metadata["i"] != "xxx" || got[0].Strength != 3 || !got[0].CreatedAt.Equal(t0.Add(3*time.Minute)) {
+		t.Errorf("signal round-trip = %+v", got[0])
+	}
+
+	all, _ := s.ListSignals(ctx, "", 0)
+	if len(all) != 4 {
+		t.Errorf("ListSignals(all) len = %d, want 4", len(all))
+	}
+	if all[3].Metadata != nil {
+		t.Errorf("empty metadata = %v, want nil", all[3].Metadata)
+	}
+}
+
+func TestQuoteRecordsRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	path := QuotePath(dir, "aapl", "2025-03-14")
+	if want := filepath.Join(dir, "us", "quotes", "AAPL", "2025-03-14.parquet"); path != want {
+		t.Fatalf("QuotePath = %s, want %s", path, want)
+	}
+
+	at := time.Date(2025, 3, 14, 14, 30, 0, 0, time.UTC) // 10:30 EDT
+	session := int64(ettime.FromTime(at))
+	recs := []QuoteRecord{
+		{Symbol: "AAPL", Timestamp: session, BidPrice: 190, BidSize: 100, AskPrice: 190.02, AskSize: 300},
+		{Symbol: "AAPL", Timestamp: session + 1000, BidPrice: 190.01, BidSize: 200, AskPrice: 190.03, AskSize: 100},
+	}
+	if err := WriteQuoteRecordsUTC(path, recs); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := readParquetFile[QuoteRecord](path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw[0].Timestamp != at.UnixMilli() {
+		t.Errorf("on-disk timestamp = %d, want real UTC %d", raw[0].Timestamp, at.UnixMilli())
+	}
+	got, err := ReadQuoteRecords(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0] != recs[0] || got[1] != recs[1] {
+		t.Errorf("ReadQuoteRecords = %+v, want %+v", got, recs)
+	}
+}
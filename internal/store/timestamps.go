@@ -0,0 +1,147 @@
+package store
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/parquet-go/parquet-go"
+
+	"jupitor/internal/ettime"
+)
+
+// Trade files record their timestamp convention under the TimestampKey
+// parquet key-value metadata. New files are always written as schema v2 (real
+// UTC) by WriteTradeRecordsUTC. Files without the key predate it and hold
+// session time (ET wall clock stored as UTC); us-migrate-timestamps rewrites
+// them to real UTC. In memory, TradeRecord.Timestamp is always session time.
+const (
+	TimestampKey      = "jupitor.timestamps"
+	TimestampsSession = "et-session-ms" // schema v1
+	TimestampsUTC     = "utc-ms"        // schema v2
+)
+
+// sessionRecord is a record type whose Timestamp is session time in memory,
+// with the file convention recorded under TimestampKey.
+type sessionRecord[T any] interface {
+	*T
+	timestampMS() *int64
+}
+
+func (r *TradeRecord) timestampMS() *int64 { return &r.Timestamp }
+func (r *QuoteRecord) timestampMS() *int64 { return &r.Timestamp }
+
+// ReadTradeRecords reads a trade parquet file, returning records with
+// session-time timestamps whichever convention the file uses.
+func ReadTradeRecords(path string) ([]TradeRecord, error) {
+	return readSessionRecords[TradeRecord](path)
+}
+
+// readSessionRecords reads a parquet file of T, converting real UTC
+// timestamps to session time when the file is tagged TimestampsUTC.
+func readSessionRecords[T any, P sessionRecord[T]](path string) ([]T, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	pf, err := parquet.OpenFile(f, fi.Size())
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+
+	rows := make([]T, pf.NumRows())
+	r := parquet.NewGenericReader[T](pf)
+	defer r.Close()
+	n, err := r.Read(rows)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	rows = rows[:n]
+
+	if v, _ := pf.Lookup(TimestampKey); v == TimestampsUTC {
+		for i := range rows {
+			ts := P(&rows[i]).timestampMS()
+			*ts = int64(ettime.FromUnixMilli(*ts))
+		}
+	}
+	return rows, nil
+}
+
+// TradeFileTimestamps returns the timestamp convention of a trade file.
+func TradeFileTimestamps(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	pf, err := parquet.OpenFile(f, fi.Size(), parquet.SkipPageIndex(true), parquet.SkipBloomFilters(true))
+	if err != nil {
+		return "", fmt.Errorf("opening %s: %w", path, err)
+	}
+	if v, ok := pf.Lookup(TimestampKey); ok {
+		return v, nil
+	}
+	return TimestampsSession, nil
+}
+
+// WriteTradeRecordsUTC writes records, whose timestamps are session time, to
+// path as a schema v2 file with real UTC timestamps. The file is replaced
+// atomically.
+func WriteTradeRecordsUTC(path string, records []TradeRecord) error {
+	return writeSessionRecordsUTC(path, records)
+}
+
+// writeSessionRecordsUTC writes records to path tagged TimestampsUTC,
+// converting their session-time timestamps to real UTC, and replaces the
+// file atomically.
+func writeSessionRecordsUTC[T any, P sessionRecord[T]](path string, records []T) error {
+	out := make([]T, len(records))
+	copy(out, records)
+	for i := range out {
+		ts := P(&out[i]).timestampMS()
+		*ts = ettime.SessionTime(*ts).UnixMilli()
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := parquet.WriteFile(tmp, out, parquet.KeyValueMetadata(TimestampKey, TimestampsUTC)); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// TradeDirs are the directories under <DataDir>/us holding TradeRecord files.
+var TradeDirs = []string{"trades", "stock-trades-index", "stock-trades-ex-index"}
+
+// MigrateTradeFile rewrites a session-time trade file to schema v2 (real UTC
+// timestamps). It reports whether the file was rewritten; files already on v2
+// are left alone, so it is safe to run repeatedly.
+func MigrateTradeFile(path string) (bool, error) {
+	conv, err := TradeFileTimestamps(path)
+	if err != nil {
+		return false, err
+	}
+	if conv == TimestampsUTC {
+		return false, nil
+	}
+	records, err := ReadTradeRecords(path)
+	if err != nil {
+		return false, err
+	}
+	if err := WriteTradeRecordsUTC(path, records); err != nil {
+		return false, fmt.Errorf("writing %s: %w", path, err)
+	}
+	return true, nil
+}
@@ -1,12 +1,21 @@
 package strategy
 
 import (
+	"container/heap"
 	"context"
+	"fmt"
+	"math"
+	"sort"
 	"time"
 
+	"jupitor/internal/broker"
+	"jupitor/internal/domain"
 	"jupitor/internal/store"
 )
 
+// tradingDaysPerYear annualises per-bar Sharpe ratios computed on daily bars.
+const tradingDaysPerYear = 252
+
 // BacktestResult holds the summary metrics produced by a backtest run.
 type BacktestResult struct {
 	TotalReturn  float64
@@ -15,37 +24,428 @@ type BacktestResult struct {
 	TotalTrades  int
 	WinRate      float64
 	ProfitFactor float64
+	FinalEquity  float64
+	EquityCurve  []EquityPoint
+	Signals      int
+	SignalLog    []domain.Signal // every emitted signal, in order
+}
+
+// InitError reports that a strategy rejected its configuration in Init,
+// as opposed to a failure reading data or running the simulation.
+type InitError struct {
+	Strategy string
+	Err      error
+}
+
+func (e *InitError) Error() string {
+	return fmt.Sprintf("init strategy %s: %v", e.Strategy, e.Err)
+}
+
+func (e *InitError) Unwrap() error { return e.Err }
+
+// EquityPoint is a single sample of account equity during a backtest.
+type EquityPoint struct {
+	Timestamp time.Time
+	Equity    float64
 }
 
 // Backtester replays historical bar data through a strategy and computes
 // performance metrics.
 type Backtester struct {
-	store    store.BarStore
-	registry *Registry
+	store       store.BarStore
+	tradeStore  store.TradeStore // optional; nil = bars only
+	registry    *Registry
+	market      string
+	barInterval time.Duration    // period each bar covers
+	fillModel   broker.FillModel // optional; nil = simulator default
 }
 
 // NewBacktester creates a Backtester that reads bars from the given store and
 // looks up strategies in the provided registry.
 func NewBacktester(barStore store.BarStore, registry *Registry) *Backtester {
 	return &Backtester{
-		store:    barStore,
-		registry: registry,
+		store:       barStore,
+		registry:    registry,
+		market:      string(domain.MarketUS),
+		barInterval: 24 * time.Hour,
 	}
 }
 
+// SetTradeStore enables trade replay: trades for the requested symbols are
+// merged with bars in timestamp order and delivered via Strategy.OnTrade.
+func (bt *Backtester) SetTradeStore(ts store.TradeStore) {
+	bt.tradeStore = ts
+}
+
+// SetFillModel sets the execution model used by the simulated broker, e.g.
+// broker.NewDefaultFillModel with slippage, commissions and a volume cap.
+func (bt *Backtester) SetFillModel(m broker.FillModel) {
+	bt.fillModel = m
+}
+
+// SetBarInterval sets the period the stored bars cover (default 1 day). A
+// bar is replayed at the end of its period, after the trades within it.
+func (bt *Backtester) SetBarInterval(d time.Duration) {
+	bt.barInterval = d
+}
+
+// SetMarket selects the market whose bars are read (default "us").
+func (bt *Backtester) SetMarket(market string) {
+	bt.market = market
+}
+
 // Run executes a backtest for the named strategy over the specified symbols
 // and date range, starting with initialCapital.
+//
+// Bars (and trades, when a trade store is set) for all symbols are merged
+// into a single time-ordered event stream, each bar at the end of its
+// period, so a strategy never sees a bar before the trades it summarises. Each event is first fed to
+// the simulated broker, which executes resting orders against it, and is then
+// passed to the strategy. Orders created from signals therefore fill on the
+// next bar or trade for the symbol, never on the event that produced them.
+// A buy signal opens an equal-weight long position (equity / len(symbols))
+// if none is held or pending; a sell signal closes the position. Equity is
+// sampled after every bar, and positions still open at the end are marked to
+// market.
 func (bt *Backtester) Run(
-	_ context.Context,
-	_ string,
-	_ []string,
-	_, _ time.Time,
-	_ float64,
+	ctx context.Context,
+	strategyName string,
+	symbols []string,
+	start, end time.Time,
+	initialCapital float64,
 ) (*BacktestResult, error) {
-	// TODO: look up strategy by name from registry
-	// TODO: iterate over date range, read bars from store
-	// TODO: feed each bar to strategy.OnBar, collect signals
-	// TODO: simulate order execution and track equity curve
-	// TODO: compute and return BacktestResult metrics
-	return &BacktestResult{}, nil
+	strat, ok := bt.registry.Get(strategyName)
+	if !ok {
+		return nil, fmt.Errorf("strategy %q not found", strategyName)
+	}
+	return bt.RunStrategy(ctx, strat, symbols, start, end, initialCapital)
+}
+
+// RunStrategy is Run for a strategy instance that need not be registered,
+// e.g. one created with Registry.New and configured for this run only.
+func (bt *Backtester) RunStrategy(
+	ctx context.Context,
+	strat Strategy,
+	symbols []string,
+	start, end time.Time,
+	initialCapital float64,
+) (*BacktestResult, error) {
+	strategyName := strat.Name()
+	if len(symbols) == 0 {
+		return nil, fmt.Errorf("no symbols given")
+	}
+	if initialCapital <= 0 {
+		return nil, fmt.Errorf("initial capital must be positive, got %v", initialCapital)
+	}
+	if err := strat.Init(ctx); err != nil {
+		return nil, &InitError{Strategy: strategyName, Err: err}
+	}
+
+	events, err := bt.loadEvents(ctx, symbols, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	sim := broker.NewSimulatorBroker()
+	sim.SetCash(initialCapital)
+	if bt.fillModel != nil {
+		sim.SetFillModel(bt.fillModel)
+	}
+
+	result := &BacktestResult{}
+	for events.Len() > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		ev := events.next()
+
+		var signals []domain.Signal
+		if ev.bar != nil {
+			sim.ProcessBar(*ev.bar)
+			signals, err = strat.OnBar(ctx, *ev.bar)
+		} else {
+			sim.ProcessTrade(*ev.trade)
+			signals, err = strat.OnTrade(ctx, *ev.trade)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("strategy %s at %s: %w", strategyName, ev.ts.Format(time.RFC3339), err)
+		}
+
+		for i := range signals {
+			result.Signals++
+			if signals[i].StrategyID == "" {
+				signals[i].StrategyID = strategyName
+			}
+			if signals[i].CreatedAt.IsZero() {
+				signals[i].CreatedAt = ev.ts
+			}
+			result.SignalLog = append(result.SignalLog, signals[i])
+			if err := bt.execute(ctx, sim, &signals[i], len(symbols)); err != nil {
+				return nil, err
+			}
+		}
+
+		if ev.bar != nil {
+			acct, _ := sim.GetAccount(ctx)
+			result.EquityCurve = appendEquity(result.EquityCurve, ev.ts, acct.Equity)
+		}
+	}
+
+	acct, _ := sim.GetAccount(ctx)
+	result.FinalEquity = acct.Equity
+	result.TotalReturn = acct.Equity/initialCapital - 1
+	result.SharpeRatio = sharpeRatio(result.EquityCurve, initialCapital)
+	result.MaxDrawdown = maxDrawdown(result.EquityCurve, initialCapital)
+	tradeStats(sim.Fills(), result)
+	return result, nil
+}
+
+// execute converts a strategy signal into a simulated market order.
+func (bt *Backtester) execute(ctx context.Context, sim *broker.SimulatorBroker, sig *domain.Signal, nSymbols int) error {
+	positions, err := sim.GetPositions(ctx)
+	if err != nil {
+		return err
+	}
+	var held float64
+	for _, p := range positions {
+		if p.Symbol == sig.Symbol && p.Side == domain.PositionSideLong {
+			held = p.Qty
+		}
+	}
+	for _, o := range sim.OpenOrders() {
+		if o.Symbol == sig.Symbol {
+			return nil // previous signal still working
+		}
+	}
+
+	order := &domain.Order{
+		Symbol:      sig.Symbol,
+		Type:        domain.OrderTypeMarket,
+		TimeInForce: domain.TimeInForceDay,
+		StrategyID:  sig.StrategyID,
+	}
+	switch sig.Type {
+	case domain.SignalTypeBuy:
+		if held > 0 {
+			return nil
+		}
+		acct, err := sim.GetAccount(ctx)
+		if err != nil {
+			return err
+		}
+		price, ok := sim.Price(sig.Symbol)
+		if !ok || price <= 0 {
+			return nil
+		}
+		alloc := min(acct.Equity/float64(nSymbols), acct.Cash)
+		qty := math.Floor(alloc / price)
+		if qty <= 0 {
+			return nil
+		}
+		order.Side = domain.OrderSideBuy
+		order.Qty = qty
+	case domain.SignalTypeSell:
+		if held <= 0 {
+			return nil
+		}
+		order.Side = domain.OrderSideSell
+		order.Qty = held
+	default:
+		return nil
+	}
+
+	if _, err := sim.SubmitOrder(ctx, order); err != nil {
+		return fmt.Errorf("simulating %s %s: %w", order.Side, order.Symbol, err)
+	}
+	return nil
+}
+
+// appendEquity records equity at ts, replacing the last sample when several
+// bars share a timestamp so the curve has one point per period.
+func appendEquity(curve []EquityPoint, ts time.Time, equity float64) []EquityPoint {
+	if n := len(curve); n > 0 && curve[n-1].Timestamp.Equal(ts) {
+		curve[n-1].Equity = equity
+		return curve
+	}
+	return append(curve, EquityPoint{Timestamp: ts, Equity: equity})
+}
+
+// ---------------------------------------------------------------------------
+// Metrics
+// ---------------------------------------------------------------------------
+
+// sharpeRatio returns the annualised Sharpe ratio (zero risk-free rate) of
+// per-period equity returns.
+func sharpeRatio(curve []EquityPoint, initial float64) float64 {
+	if len(curve) < 2 {
+		return 0
+	}
+	returns := make([]float64, 0, len(curve))
+	prev := initial
+	for _, p := range curve {
+		if prev != 0 {
+			returns = append(returns, p.Equity/prev-1)
+		}
+		prev = p.Equity
+	}
+
+	var mean float64
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+
+	var variance float64
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	variance /= float64(len(returns) - 1)
+	if variance == 0 {
+		return 0
+	}
+	return mean / math.Sqrt(variance) * math.Sqrt(tradingDaysPerYear)
+}
+
+// maxDrawdown returns the largest peak-to-trough decline as a positive
+// fraction of the peak.
+func maxDrawdown(curve []EquityPoint, initial float64) float64 {
+	peak, dd := initial, 0.0
+	for _, p := range curve {
+		if p.Equity > peak {
+			peak = p.Equity
+		}
+		if peak > 0 {
+			dd = max(dd, (peak-p.Equity)/peak)
+		}
+	}
+	return dd
+}
+
+// tradeStats fills TotalTrades, WinRate and ProfitFactor from the fills that
+// closed (part of) a position. ProfitFactor is +Inf when there are winning
+// trades but no losing ones.
+func tradeStats(fills []broker.Fill, r *BacktestResult) {
+	var wins int
+	var grossProfit, grossLoss float64
+	for _, f := range fills {
+		if !f.Closed {
+			continue
+		}
+		r.TotalTrades++
+		if f.RealizedPL > 0 {
+			wins++
+			grossProfit += f.RealizedPL
+		} else {
+			grossLoss -= f.RealizedPL
+		}
+	}
+	if r.TotalTrades > 0 {
+		r.WinRate = float64(wins) / float64(r.TotalTrades)
+	}
+	switch {
+	case grossLoss > 0:
+		r.ProfitFactor = grossProfit / grossLoss
+	case grossProfit > 0:
+		r.ProfitFactor = math.Inf(1)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Event stream
+// ---------------------------------------------------------------------------
+
+// event is a single bar or trade in the merged replay stream.
+type event struct {
+	ts    time.Time // trade time, or bar period end
+	bar   *domain.Bar
+	trade *domain.Trade
+}
+
+// cursor walks one symbol's time-ordered events.
+type cursor struct {
+	events []event
+	pos    int
+}
+
+// eventQueue is a min-heap of per-symbol cursors keyed by the timestamp of
+// each cursor's next event, yielding a k-way merge across symbols.
+type eventQueue []*cursor
+
+func (q eventQueue) Len() int { return len(q) }
+func (q eventQueue) Less(i, j int) bool {
+	a, b := q[i].events[q[i].pos], q[j].events[q[j].pos]
+	if !a.ts.Equal(b.ts) {
+		return a.ts.Before(b.ts)
+	}
+	// A bar ends where the next period's first trade may start, so the bar
+	// comes first.
+	return a.bar != nil && b.trade != nil
+}
+func (q eventQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *eventQueue) Push(x any)   { *q = append(*q, x.(*cursor)) }
+func (q *eventQueue) Pop() any {
+	old := *q
+	n := len(old)
+	c := old[n-1]
+	*q = old[:n-1]
+	return c
+}
+
+// next removes and returns the earliest pending event.
+func (q *eventQueue) next() event {
+	c := (*q)[0]
+	ev := c.events[c.pos]
+	c.pos++
+	if c.pos < len(c.events) {
+		heap.Fix(q, 0)
+	} else {
+		heap.Pop(q)
+	}
+	return ev
+}
+
+// loadEvents reads bars (and trades) for each symbol and returns a queue
+// that merges them in time order, bars keyed by their period end.
+func (bt *Backtester) loadEvents(ctx context.Context, symbols []string, start, end time.Time) (*eventQueue, error) {
+	q := &eventQueue{}
+	for _, sym := range symbols {
+		bars, err := bt.store.ReadBars(ctx, sym, bt.market, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("reading bars for %s: %w", sym, err)
+		}
+		if len(bars) > 0 {
+			c := &cursor{events: make([]event, len(bars))}
+			for i := range bars {
+				c.events[i] = event{ts: bars[i].Timestamp.Add(bt.barInterval), bar: &bars[i]}
+			}
+			sortEvents(c.events)
+			*q = append(*q, c)
+		}
+
+		if bt.tradeStore == nil {
+			continue
+		}
+		trades, err := bt.tradeStore.ReadTrades(ctx, sym, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("reading trades for %s: %w", sym, err)
+		}
+		if len(trades) > 0 {
+			c := &cursor{events: make([]event, len(trades))}
+			for i := range trades {
+				c.events[i] = event{ts: trades[i].Timestamp, trade: &trades[i]}
+			}
+			sortEvents(c.events)
+			*q = append(*q, c)
+		}
+	}
+	heap.Init(q)
+	return q, nil
+}
+
+// sortEvents orders a single cursor's events by timestamp (stable, so
+// same-timestamp events keep their storage order).
+func sortEvents(events []event) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].ts.Before(events[j].ts)
+	})
 }
@@ -4,13 +4,17 @@ package builtins
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 
 	"jupitor/internal/domain"
 	"jupitor/internal/strategy"
 )
 
-// Compile-time interface check.
+// Compile-time interface checks.
 var _ strategy.Strategy = (*SMACross)(nil)
+var _ strategy.Configurable = (*SMACross)(nil)
+var _ strategy.Describer = (*SMACross)(nil)
 
 // SMACross implements a simple moving average crossover strategy. It generates
 // a buy signal when the short-period SMA crosses above the long-period SMA,
@@ -18,6 +22,8 @@ var _ strategy.Strategy = (*SMACross)(nil)
 type SMACross struct {
 	shortPeriod int
 	longPeriod  int
+
+	closes map[string][]float64 // per-symbol closes, capped at longPeriod+1
 }
 
 // NewSMACross creates a new SMACross strategy with the specified short and
@@ -34,24 +40,92 @@ func (s *SMACross) Name() string {
 	return "sma-cross"
 }
 
+// Description summarises the strategy and its current periods.
+func (s *SMACross) Description() string {
+	return fmt.Sprintf("SMA crossover (short=%d, long=%d): buy when the short SMA crosses above the long SMA, sell when it crosses below", s.shortPeriod, s.longPeriod)
+}
+
+// Configure sets the "short" and "long" SMA periods.
+func (s *SMACross) Configure(params map[string]string) error {
+	for k, v := range params {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("sma-cross: param %s: %w", k, err)
+		}
+		switch k {
+		case "short":
+			s.shortPeriod = n
+		case "long":
+			s.longPeriod = n
+		default:
+			return fmt.Errorf("sma-cross: unknown param %q", k)
+		}
+	}
+	return nil
+}
+
 // Init performs any setup required by the SMA crossover strategy.
+// Calling Init again discards all price history.
 func (s *SMACross) Init(_ context.Context) error {
-	// TODO: pre-allocate price buffers for SMA computation
+	if s.shortPeriod <= 0 || s.longPeriod <= s.shortPeriod {
+		return fmt.Errorf("sma-cross: need 0 < short < long, got %d/%d", s.shortPeriod, s.longPeriod)
+	}
+	s.closes = make(map[string][]float64)
 	return nil
 }
 
 // OnBar processes a new bar and returns trading signals based on SMA crossover
 // logic.
-func (s *SMACross) OnBar(_ context.Context, _ domain.Bar) ([]domain.Signal, error) {
-	// TODO: append bar close to price history
-	// TODO: compute short and long SMAs when enough data is available
-	// TODO: detect crossover and generate buy/sell signal
-	return nil, nil
+func (s *SMACross) OnBar(_ context.Context, bar domain.Bar) ([]domain.Signal, error) {
+	if s.closes == nil {
+		s.closes = make(map[string][]float64)
+	}
+	hist := append(s.closes[bar.Symbol], bar.Close)
+	if len(hist) > s.longPeriod+1 {
+		hist = hist[len(hist)-s.longPeriod-1:]
+	}
+	s.closes[bar.Symbol] = hist
+	if len(hist) <= s.longPeriod {
+		return nil, nil
+	}
+
+	prev, cur := hist[:len(hist)-1], hist[1:]
+	prevDiff := sma(prev, s.shortPeriod) - sma(prev, s.longPeriod)
+	curDiff := sma(cur, s.shortPeriod) - sma(cur, s.longPeriod)
+
+	var typ domain.SignalType
+	switch {
+	case prevDiff <= 0 && curDiff > 0:
+		typ = domain.SignalTypeBuy
+	case prevDiff >= 0 && curDiff < 0:
+		typ = domain.SignalTypeSell
+	default:
+		return nil, nil
+	}
+	return []domain.Signal{{
+		StrategyID: s.Name(),
+		Symbol:     bar.Symbol,
+		Type:       typ,
+		Strength:   1,
+		Metadata: map[string]string{
+			"short_sma": fmt.Sprintf("%.4f", sma(cur, s.shortPeriod)),
+			"long_sma":  fmt.Sprintf("%.4f", sma(cur, s.longPeriod)),
+		},
+		CreatedAt: bar.Timestamp,
+	}}, nil
 }
 
 // OnTrade processes a new trade tick. The SMA crossover strategy does not
 // generate signals from individual trades.
 func (s *SMACross) OnTrade(_ context.Context, _ domain.Trade) ([]domain.Signal, error) {
-	// TODO: this strategy operates on bars; trade-level signals are not used
 	return nil, nil
 }
+
+// sma returns the mean of the last n values.
+func sma(values []float64, n int) float64 {
+	var sum float64
+	for _, v := range values[len(values)-n:] {
+		sum += v
+	}
+	return sum / float64(n)
+}
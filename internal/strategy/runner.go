@@ -0,0 +1,513 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"jupitor/internal/domain"
+	"jupitor/internal/live"
+	"jupitor/internal/store"
+)
+
+// Runner status values, matching StrategyInfo.status in proto/strategy.proto.
+const (
+	StatusRunning = "running"
+	StatusStopped = "stopped"
+	StatusError   = "error"
+)
+
+// ParamOrderQty is the runner-level start parameter that sets the share
+// quantity of orders forwarded for a strategy's signals. It is not passed on
+// to Configurable strategies.
+const ParamOrderQty = "order_qty"
+
+// Errors returned by Runner.Start, Runner.Stop and Registry.New.
+var (
+	ErrUnknownStrategy = errors.New("unknown strategy")
+	ErrAlreadyRunning  = errors.New("strategy already running")
+	ErrNotRunning      = errors.New("strategy not running")
+	ErrNoFactory       = errors.New("strategy has no factory")
+)
+
+// TradeSource is a live trade feed with LiveModel-style pub/sub.
+type TradeSource interface {
+	Subscribe(bufSize int) (id int, ch <-chan live.TradeEvent)
+	Unsubscribe(id int)
+}
+
+// OrderSubmitter receives orders generated from strategy signals; it is
+// satisfied by *engine.Engine.
+type OrderSubmitter interface {
+	SubmitOrder(ctx context.Context, order *domain.Order) (*domain.Order, error)
+}
+
+// RunnerStatus describes a strategy known to the runner.
+type RunnerStatus struct {
+	ID        string
+	Status    string
+	Symbols   []string // empty = all symbols
+	Params    map[string]string
+	StartedAt time.Time
+	Signals   int
+	Dropped   int // events dropped because the strategy fell behind
+	LastError string
+}
+
+// runnerEvent is a trade or a completed bar delivered to a strategy.
+type runnerEvent struct {
+	trade *domain.Trade
+	bar   *domain.Bar
+}
+
+// running is the per-strategy state of the runner.
+type running struct {
+	strat    Strategy
+	symbols  map[string]bool
+	orderQty float64
+	events   chan runnerEvent
+	cancel   context.CancelFunc
+	done     chan struct{}
+
+	mu     sync.Mutex
+	status RunnerStatus
+}
+
+// Runner feeds live trades, and one-minute bars aggregated from them, into
+// started strategies. Each strategy runs in its own goroutine behind a
+// buffered queue so one slow strategy cannot stall the others. Emitted
+// signals are persisted to the SignalStore, published to signal subscribers
+// and, when an OrderSubmitter is set, forwarded as market orders.
+type Runner struct {
+	registry *Registry
+	signals  store.SignalStore // optional
+	log      *slog.Logger
+
+	orders       OrderSubmitter // optional
+	orderQty     float64
+	maxSignalAge time.Duration
+	barInterval  time.Duration
+	barDelay     time.Duration
+
+	mu       sync.Mutex
+	running  map[string]*running
+	starting map[string]bool // reserved while Start initialises outside mu
+	stopped  map[string]RunnerStatus
+	bars     *live.BarStream
+
+	subsMu    sync.Mutex
+	nextSubID int
+	subs      map[int]chan domain.Signal
+}
+
+// NewRunner creates a Runner for the strategies in registry. signals may be
+// nil to skip persistence.
+func NewRunner(registry *Registry, signals store.SignalStore, log *slog.Logger) *Runner {
+	return &Runner{
+		registry:     registry,
+		signals:      signals,
+		log:          log.With("component", "strategy-runner"),
+		maxSignalAge: 2 * time.Minute,
+		barInterval:  time.Minute,
+		barDelay:     live.DefaultBarFlushDelay,
+		running:      make(map[string]*running),
+		starting:     make(map[string]bool),
+		stopped:      make(map[string]RunnerStatus),
+		bars:         live.NewBarStream(time.Minute, live.DefaultBarFlushDelay),
+		subs:         make(map[int]chan domain.Signal),
+	}
+}
+
+// SetOrderSubmitter enables forwarding of buy and sell signals as market
+// orders of orderQty shares. Signals older than the max signal age (e.g.
+// produced while replaying the day's backlog at startup) are not forwarded.
+func (r *Runner) SetOrderSubmitter(s OrderSubmitter, orderQty float64) {
+	r.orders = s
+	r.orderQty = orderQty
+}
+
+// SetMaxSignalAge sets how stale a signal may be and still be forwarded.
+func (r *Runner) SetMaxSignalAge(d time.Duration) {
+	r.maxSignalAge = d
+}
+
+// SetBarInterval sets the aggregation interval for bars (default 1m).
+func (r *Runner) SetBarInterval(d time.Duration) {
+	r.barInterval = d
+	r.bars = live.NewBarStream(d, r.barDelay)
+}
+
+// SetBarFlushDelay sets how long past the end of a bar window the runner
+// waits for a later trade before closing the window itself (default 2s), so
+// the last bar of a quiet symbol is still delivered.
+func (r *Runner) SetBarFlushDelay(d time.Duration) {
+	r.barDelay = d
+	r.bars = live.NewBarStream(r.barInterval, d)
+}
+
+// Run subscribes to src and dispatches events to running strategies until
+// ctx is cancelled, then stops all strategies.
+func (r *Runner) Run(ctx context.Context, src TradeSource) error {
+	subID, ch := src.Subscribe(8192)
+	defer src.Unsubscribe(subID)
+	defer r.stopAll()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	r.log.Info("strategy runner started")
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			r.flushBars(now)
+		case evt, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			r.dispatchTrade(live.TradeFromRecord(&evt.Record))
+		}
+	}
+}
+
+// Start initialises and starts the named strategy. symbols restricts the
+// events it receives (empty = all); params are passed to Configurable
+// strategies, except ParamOrderQty which the runner consumes itself.
+//
+// Strategies registered with a factory run as a fresh instance, so each start
+// is configured from scratch and the registry's shared instance is left
+// untouched; others run their shared instance.
+func (r *Runner) Start(ctx context.Context, id string, symbols []string, params map[string]string) error {
+	strat, err := r.registry.New(id)
+	if errors.Is(err, ErrNoFactory) {
+		strat, _ = r.registry.Get(id)
+	} else if err != nil {
+		return err
+	}
+
+	// Reserve the ID so Init, which may be slow, runs without holding mu.
+	r.mu.Lock()
+	if _, ok := r.running[id]; ok || r.starting[id] {
+		r.mu.Unlock()
+		return fmt.Errorf("%q: %w", id, ErrAlreadyRunning)
+	}
+	r.starting[id] = true
+	r.mu.Unlock()
+
+	orderQty, err := r.initStrategy(ctx, id, strat, params)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.starting, id)
+	if err != nil {
+		return err
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	rs := &running{
+		strat:    strat,
+		orderQty: orderQty,
+		events:   make(chan runnerEvent, 4096),
+		cancel:   cancel,
+		done:     make(chan struct{}),
+		status: RunnerStatus{
+			ID:        id,
+			Status:    StatusRunning,
+			Symbols:   append([]string(nil), symbols...),
+			Params:    maps.Clone(params),
+			StartedAt: time.Now(),
+		},
+	}
+	if len(symbols) > 0 {
+		rs.symbols = make(map[string]bool, len(symbols))
+		for _, s := range symbols {
+			rs.symbols[s] = true
+		}
+	}
+	r.running[id] = rs
+	delete(r.stopped, id)
+	go r.loop(runCtx, rs)
+
+	r.log.Info("strategy started", "id", id, "symbols", len(symbols))
+	return nil
+}
+
+// initStrategy configures strat from params and initialises it. It returns
+// the order quantity to use for its signals.
+func (r *Runner) initStrategy(ctx context.Context, id string, strat Strategy, params map[string]string) (float64, error) {
+	orderQty := r.orderQty
+	stratParams := maps.Clone(params)
+	if v, ok := stratParams[ParamOrderQty]; ok {
+		q, err := strconv.ParseFloat(v, 64)
+		if err != nil || q <= 0 {
+			return 0, fmt.Errorf("invalid %s %q", ParamOrderQty, v)
+		}
+		orderQty = q
+		delete(stratParams, ParamOrderQty)
+	}
+	if c, ok := strat.(Configurable); ok && len(stratParams) > 0 {
+		if err := c.Configure(stratParams); err != nil {
+			return 0, err
+		}
+	} else if len(stratParams) > 0 {
+		return 0, fmt.Errorf("strategy %q does not accept params", id)
+	}
+	if err := strat.Init(ctx); err != nil {
+		return 0, fmt.Errorf("initialising %s: %w", id, err)
+	}
+	return orderQty, nil
+}
+
+// Stop stops the named strategy and waits for its goroutine to exit.
+func (r *Runner) Stop(id string) error {
+	r.mu.Lock()
+	rs, ok := r.running[id]
+	if ok {
+		delete(r.running, id)
+	}
+	r.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%q: %w", id, ErrNotRunning)
+	}
+
+	rs.cancel()
+	<-rs.done
+
+	st := rs.snapshot()
+	if st.Status == StatusRunning {
+		st.Status = StatusStopped
+	}
+	r.mu.Lock()
+	r.stopped[id] = st
+	r.mu.Unlock()
+	r.log.Info("strategy stopped", "id", id, "signals", st.Signals)
+	return nil
+}
+
+// Status returns the runner's view of a strategy. Registered strategies that
+// were never started report StatusStopped.
+func (r *Runner) Status(id string) (RunnerStatus, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if rs, ok := r.running[id]; ok {
+		return rs.snapshot(), true
+	}
+	if st, ok := r.stopped[id]; ok {
+		return st, true
+	}
+	if _, ok := r.registry.Get(id); ok {
+		return RunnerStatus{ID: id, Status: StatusStopped}, true
+	}
+	return RunnerStatus{}, false
+}
+
+// List returns the status of every registered strategy, sorted by ID.
+func (r *Runner) List() []RunnerStatus {
+	names := r.registry.List()
+	out := make([]RunnerStatus, 0, len(names))
+	for _, name := range names {
+		if st, ok := r.Status(name); ok {
+			out = append(out, st)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// SubscribeSignals creates a subscription channel for emitted signals.
+// Slow subscribers drop signals rather than blocking strategies.
+func (r *Runner) SubscribeSignals(bufSize int) (id int, ch <-chan domain.Signal) {
+	r.subsMu.Lock()
+	defer r.subsMu.Unlock()
+	id = r.nextSubID
+	r.nextSubID++
+	c := make(chan domain.Signal, bufSize)
+	r.subs[id] = c
+	return id, c
+}
+
+// UnsubscribeSignals removes a subscription and closes its channel.
+func (r *Runner) UnsubscribeSignals(id int) {
+	r.subsMu.Lock()
+	defer r.subsMu.Unlock()
+	if ch, ok := r.subs[id]; ok {
+		close(ch)
+		delete(r.subs, id)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Dispatch
+// ---------------------------------------------------------------------------
+
+// dispatchTrade aggregates the trade into bars and fans both out to the
+// running strategies.
+func (r *Runner) dispatchTrade(t domain.Trade) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Close every open bar once a trade arrives past the current window.
+	r.sendBarsLocked(r.bars.Add(t))
+
+	trade := t
+	r.sendLocked(t.Symbol, runnerEvent{trade: &trade})
+}
+
+// flushBars closes the current bar window if no later trade has closed it
+// within the flush delay.
+func (r *Runner) flushBars(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sendBarsLocked(r.bars.Tick(now))
+}
+
+func (r *Runner) sendBarsLocked(bars []domain.Bar) {
+	for i := range bars {
+		r.sendLocked(bars[i].Symbol, runnerEvent{bar: &bars[i]})
+	}
+}
+
+// sendLocked queues evt for every running strategy interested in symbol.
+func (r *Runner) sendLocked(symbol string, evt runnerEvent) {
+	for _, rs := range r.running {
+		if rs.symbols != nil && !rs.symbols[symbol] {
+			continue
+		}
+		select {
+		case rs.events <- evt:
+		default:
+			rs.mu.Lock()
+			rs.status.Dropped++
+			rs.mu.Unlock()
+		}
+	}
+}
+
+// loop runs one strategy until ctx is cancelled.
+func (r *Runner) loop(ctx context.Context, rs *running) {
+	defer close(rs.done)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt := <-rs.events:
+			var sigs []domain.Signal
+			var err error
+			var ts time.Time
+			if evt.trade != nil {
+				ts = evt.trade.Timestamp
+				sigs, err = rs.strat.OnTrade(ctx, *evt.trade)
+			} else {
+				ts = evt.bar.Timestamp.Add(r.barInterval)
+				sigs, err = rs.strat.OnBar(ctx, *evt.bar)
+			}
+			if err != nil {
+				rs.mu.Lock()
+				rs.status.Status = StatusError
+				rs.status.LastError = err.Error()
+				rs.mu.Unlock()
+				r.log.Error("strategy error; stopping", "id", rs.status.ID, "error", err)
+				r.retire(rs)
+				return
+			}
+			for _, sig := range sigs {
+				r.emit(ctx, rs, sig, ts)
+			}
+		}
+	}
+}
+
+// retire removes a strategy that failed from the running set, so no more
+// events are queued (and counted as dropped) for it, and keeps its status.
+func (r *Runner) retire(rs *running) {
+	st := rs.snapshot()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.running[st.ID] != rs {
+		return // already stopped
+	}
+	rs.cancel()
+	delete(r.running, st.ID)
+	r.stopped[st.ID] = st
+}
+
+// emit persists, publishes and optionally forwards a signal.
+func (r *Runner) emit(ctx context.Context, rs *running, sig domain.Signal, ts time.Time) {
+	rs.mu.Lock()
+	id := rs.status.ID
+	rs.status.Signals++
+	rs.mu.Unlock()
+
+	if sig.StrategyID == "" {
+		sig.StrategyID = id
+	}
+	if sig.CreatedAt.IsZero() {
+		sig.CreatedAt = ts
+	}
+	if r.signals != nil {
+		if err := r.signals.SaveSignal(ctx, &sig); err != nil {
+			r.log.Error("saving signal", "strategy", id, "symbol", sig.Symbol, "error", err)
+		}
+	}
+
+	r.subsMu.Lock()
+	for _, ch := range r.subs {
+		select {
+		case ch <- sig:
+		default:
+		}
+	}
+	r.subsMu.Unlock()
+
+	if r.orders == nil || rs.orderQty <= 0 || sig.Type == domain.SignalTypeHold {
+		return
+	}
+	if age := time.Since(sig.CreatedAt); age > r.maxSignalAge {
+		r.log.Debug("not forwarding stale signal", "strategy", id, "symbol", sig.Symbol, "age", age)
+		return
+	}
+	side := domain.OrderSideBuy
+	if sig.Type == domain.SignalTypeSell {
+		side = domain.OrderSideSell
+	}
+	order := &domain.Order{
+		Symbol:      sig.Symbol,
+		Side:        side,
+		Type:        domain.OrderTypeMarket,
+		TimeInForce: domain.TimeInForceDay,
+		Qty:         rs.orderQty,
+		StrategyID:  id,
+	}
+	if _, err := r.orders.SubmitOrder(ctx, order); err != nil {
+		r.log.Warn("forwarding signal to engine", "strategy", id, "symbol", sig.Symbol, "error", err)
+	}
+}
+
+// stopAll stops every running strategy.
+func (r *Runner) stopAll() {
+	r.mu.Lock()
+	ids := make([]string, 0, len(r.running))
+	for id := range r.running {
+		ids = append(ids, id)
+	}
+	r.mu.Unlock()
+	for _, id := range ids {
+		r.Stop(id)
+	}
+}
+
+func (rs *running) snapshot() RunnerStatus {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	st := rs.status
+	st.Symbols = append([]string(nil), st.Symbols...)
+	st.Params = maps.Clone(st.Params)
+	return st
+}
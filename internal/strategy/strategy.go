@@ -4,6 +4,7 @@ package strategy
 
 import (
 	"context"
+	"fmt"
 	"sort"
 
 	"jupitor/internal/domain"
@@ -27,21 +28,51 @@ type Strategy interface {
 	OnTrade(ctx context.Context, trade domain.Trade) ([]domain.Signal, error)
 }
 
+// Factory creates a new, unconfigured instance of a strategy.
+type Factory func() Strategy
+
 // Registry holds a named collection of strategies for lookup and enumeration.
+// Each name maps to one shared instance and optionally a Factory for
+// independent instances, such as those the live runner and backtests run.
 type Registry struct {
 	strategies map[string]Strategy
+	factories  map[string]Factory
 }
 
 // NewRegistry creates an empty strategy Registry.
 func NewRegistry() *Registry {
 	return &Registry{
 		strategies: make(map[string]Strategy),
+		factories:  make(map[string]Factory),
 	}
 }
 
-// Register adds a strategy to the registry, keyed by its Name().
+// Register adds a strategy to the registry, keyed by its Name(). Strategies
+// registered this way have no factory; see RegisterFactory.
 func (r *Registry) Register(s Strategy) {
 	r.strategies[s.Name()] = s
+	delete(r.factories, s.Name())
+}
+
+// RegisterFactory registers a strategy by factory. One instance is created
+// now as the shared instance returned by Get; New creates further ones.
+func (r *Registry) RegisterFactory(f Factory) {
+	s := f()
+	r.strategies[s.Name()] = s
+	r.factories[s.Name()] = f
+}
+
+// New returns a fresh instance of the named strategy from its factory, so it
+// can be configured and run without touching the shared instance.
+func (r *Registry) New(name string) (Strategy, error) {
+	if _, ok := r.strategies[name]; !ok {
+		return nil, fmt.Errorf("%q: %w", name, ErrUnknownStrategy)
+	}
+	f, ok := r.factories[name]
+	if !ok {
+		return nil, fmt.Errorf("%q: %w", name, ErrNoFactory)
+	}
+	return f(), nil
 }
 
 // Get retrieves a strategy by name. The second return value indicates whether
@@ -60,3 +91,16 @@ func (r *Registry) List() []string {
 	sort.Strings(names)
 	return names
 }
+
+// Describer is implemented by strategies that provide a human-readable
+// description for listings.
+type Describer interface {
+	Description() string
+}
+
+// Configurable is implemented by strategies that accept string parameters,
+// e.g. from StartStrategyRequest.params or a backtest request. Configure is
+// called before Init; unknown keys should be rejected.
+type Configurable interface {
+	Configure(params map[string]string) error
+}
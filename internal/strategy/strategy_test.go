@@ -2,9 +2,20 @@ package strategy
 
 import (
 	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"jupitor/internal/domain"
+	"jupitor/internal/ettime"
+	"jupitor/internal/live"
+	"jupitor/internal/store"
 )
 
 // stubStrategy is a minimal Strategy implementation used in registry tests.
@@ -40,6 +51,27 @@ func TestRegistryGet_NotFound(t *testing.T) {
 	}
 }
 
+func TestRegistryNew(t *testing.T) {
+	r := NewRegistry()
+	r.RegisterFactory(func() Strategy { return &stubStrategy{name: "fresh"} })
+	r.Register(&stubStrategy{name: "shared"})
+
+	shared, _ := r.Get("fresh")
+	a, err := r.New("fresh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := r.New("fresh"); a == shared || a == b {
+		t.Error("New returned a shared instance")
+	}
+	if _, err := r.New("shared"); !errors.Is(err, ErrNoFactory) {
+		t.Errorf("New(shared) err = %v, want ErrNoFactory", err)
+	}
+	if _, err := r.New("missing"); !errors.Is(err, ErrUnknownStrategy) {
+		t.Errorf("New(missing) err = %v, want ErrUnknownStrategy", err)
+	}
+}
+
 func TestRegistryList(t *testing.T) {
 	r := NewRegistry()
 	r.Register(&stubStrategy{name: "alpha"})
@@ -54,3 +86,417 @@ func TestRegistryList(t *testing.T) {
 		t.Errorf("List returned %v, want [alpha beta]", names)
 	}
 }
+
+// scriptedStrategy emits pre-defined signals keyed by bar index per symbol.
+type scriptedStrategy struct {
+	script map[int]domain.SignalType
+	seen   map[string]int
+}
+
+func (s *scriptedStrategy) Name() string { return "scripted" }
+func (s *scriptedStrategy) Init(_ context.Context) error {
+	s.seen = make(map[string]int)
+	return nil
+}
+func (s *scriptedStrategy) OnTrade(_ context.Context, _ domain.Trade) ([]domain.Signal, error) {
+	return nil, nil
+}
+func (s *scriptedStrategy) OnBar(_ context.Context, bar domain.Bar) ([]domain.Signal, error) {
+	i := s.seen[bar.Symbol]
+	s.seen[bar.Symbol]++
+	typ, ok := s.script[i]
+	if !ok {
+		return nil, nil
+	}
+	return []domain.Signal{{StrategyID: s.Name(), Symbol: bar.Symbol, Type: typ}}, nil
+}
+
+func writeTestBars(t *testing.T, ps *store.ParquetStore, symbol string, closes []float64) {
+	t.Helper()
+	bars := make([]domain.Bar, len(closes))
+	for i, c := range closes {
+		bars[i] = domain.Bar{
+			Symbol:    symbol,
+			Timestamp: time.Date(2024, 1, 2+i, 0, 0, 0, 0, time.UTC),
+			Open:      c, High: c, Low: c, Close: c,
+			Volume: 1000,
+		}
+	}
+	if err := ps.WriteBars(context.Background(), bars); err != nil {
+		t.Fatalf("WriteBars: %v", err)
+	}
+}
+
+func TestBacktesterRun(t *testing.T) {
+	ps := store.NewParquetStore(t.TempDir())
+	// Signals fire on bars 1 and 3 and fill at the open of bars 2 and 4.
+	// AAA: buy 500 (5000/10) at 11, sell at 12 → +500.
+	// BBB: buy 250 (5000/20) at 18, sell at 15 → -750.
+	writeTestBars(t, ps, "AAA", []float64{9, 10, 11, 12, 12})
+	writeTestBars(t, ps, "BBB", []float64{21, 20, 18, 15, 15})
+
+	r := NewRegistry()
+	r.Register(&scriptedStrategy{script: map[int]domain.SignalType{
+		1: domain.SignalTypeBuy,
+		3: domain.SignalTypeSell,
+	}})
+	bt := NewBacktester(ps, r)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	res, err := bt.Run(context.Background(), "scripted", []string{"AAA", "BBB"}, start, end, 10000)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if res.TotalTrades != 2 {
+		t.Errorf("TotalTrades = %d, want 2", res.TotalTrades)
+	}
+	if res.WinRate != 0.5 {
+		t.Errorf("WinRate = %v, want 0.5", res.WinRate)
+	}
+	if math.Abs(res.ProfitFactor-500.0/750.0) > 1e-9 {
+		t.Errorf("ProfitFactor = %v, want %v", res.ProfitFactor, 500.0/750.0)
+	}
+	if math.Abs(res.FinalEquity-9750) > 1e-9 {
+		t.Errorf("FinalEquity = %v, want 9750", res.FinalEquity)
+	}
+	if math.Abs(res.TotalReturn-(-0.025)) > 1e-9 {
+		t.Errorf("TotalReturn = %v, want -0.025", res.TotalReturn)
+	}
+	if len(res.EquityCurve) != 5 {
+		t.Errorf("EquityCurve has %d points, want 5", len(res.EquityCurve))
+	}
+	if res.MaxDrawdown <= 0 {
+		t.Errorf("MaxDrawdown = %v, want > 0", res.MaxDrawdown)
+	}
+	if len(res.SignalLog) != 4 || res.Signals != 4 {
+		t.Errorf("SignalLog has %d signals (Signals=%d), want 4", len(res.SignalLog), res.Signals)
+	} else if s := res.SignalLog[0]; s.StrategyID != "scripted" || !s.CreatedAt.Equal(time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC)) {
+		// Bar 1 (2024-01-03) is delivered at the end of its day.
+		t.Errorf("SignalLog[0] = %+v", s)
+	}
+}
+
+// orderStrategy records the order in which it receives events.
+type orderStrategy struct {
+	events []string
+}
+
+func (s *orderStrategy) Name() string                 { return "order" }
+func (s *orderStrategy) Init(_ context.Context) error { return nil }
+func (s *orderStrategy) OnTrade(_ context.Context, tr domain.Trade) ([]domain.Signal, error) {
+	s.events = append(s.events, "trade "+tr.ID)
+	return nil, nil
+}
+func (s *orderStrategy) OnBar(_ context.Context, bar domain.Bar) ([]domain.Signal, error) {
+	s.events = append(s.events, "bar "+bar.Timestamp.Format("2006-01-02"))
+	return nil, nil
+}
+
+func TestBacktesterBarAfterItsTrades(t *testing.T) {
+	ps := store.NewParquetStore(t.TempDir())
+	ctx := context.Background()
+	et := ettime.Location()
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, et)
+	if err := ps.WriteBars(ctx, []domain.Bar{{
+		Symbol: "AAA", Timestamp: day, Open: 10, High: 12, Low: 9, Close: 11, Volume: 1000,
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	trade := func(id string, ts time.Time) domain.Trade {
+		return domain.Trade{Symbol: "AAA", Timestamp: ts, Price: 10, Size: 200, Exchange: "V", ID: id}
+	}
+	if err := ps.WriteTrades(ctx, []domain.Trade{
+		trade("1", day.Add(10*time.Hour)),
+		trade("2", day.Add(15*time.Hour)),
+		trade("3", day.AddDate(0, 0, 1).Add(10*time.Hour)),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	bt := NewBacktester(ps, NewRegistry())
+	bt.SetTradeStore(ps)
+	strat := &orderStrategy{}
+	if _, err := bt.RunStrategy(ctx, strat, []string{"AAA"}, day, day.AddDate(0, 0, 2), 10000); err != nil {
+		t.Fatal(err)
+	}
+	want := "trade 1,trade 2,bar 2024-01-02,trade 3"
+	if got := strings.Join(strat.events, ","); got != want {
+		t.Errorf("events = %s, want %s", got, want)
+	}
+}
+
+func TestBacktesterRun_UnknownStrategy(t *testing.T) {
+	bt := NewBacktester(store.NewParquetStore(t.TempDir()), NewRegistry())
+	_, err := bt.Run(context.Background(), "missing", []string{"AAA"}, time.Time{}, time.Now(), 1000)
+	if err == nil {
+		t.Fatal("expected error for unknown strategy")
+	}
+}
+
+// recordingStrategy records bars it receives and emits a buy on the first.
+type recordingStrategy struct {
+	mu     sync.Mutex
+	bars   []domain.Bar
+	trades int
+}
+
+func (s *recordingStrategy) Name() string                 { return "recording" }
+func (s *recordingStrategy) Init(_ context.Context) error { return nil }
+func (s *recordingStrategy) OnTrade(_ context.Context, _ domain.Trade) ([]domain.Signal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.trades++
+	return nil, nil
+}
+func (s *recordingStrategy) OnBar(_ context.Context, bar domain.Bar) ([]domain.Signal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bars = append(s.bars, bar)
+	if len(s.bars) > 1 {
+		return nil, nil
+	}
+	return []domain.Signal{{Symbol: bar.Symbol, Type: domain.SignalTypeBuy, Strength: 1}}, nil
+}
+
+type memSignalStore struct {
+	mu      sync.Mutex
+	signals []domain.Signal
+}
+
+func (m *memSignalStore) SaveSignal(_ context.Context, s *domain.Signal) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s.ID = int64(len(m.signals) + 1)
+	m.signals = append(m.signals, *s)
+	return nil
+}
+
+func (m *memSignalStore) ListSignals(_ context.Context, _ string, _ int) ([]domain.Signal, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]domain.Signal(nil), m.signals...), nil
+}
+
+type chanSubmitter chan domain.Order
+
+func (c chanSubmitter) SubmitOrder(_ context.Context, o *domain.Order) (*domain.Order, error) {
+	c <- *o
+	return o, nil
+}
+
+// subscribedSource signals when Run has subscribed to the model, so a test
+// adds trades only once the runner receives them.
+type subscribedSource struct {
+	*live.LiveModel
+	subscribed chan struct{}
+}
+
+func newSubscribedSource() *subscribedSource {
+	return &subscribedSource{LiveModel: live.NewLiveModel(math.MaxInt64), subscribed: make(chan struct{})}
+}
+
+func (s *subscribedSource) Subscribe(bufSize int) (int, <-chan live.TradeEvent) {
+	defer close(s.subscribed)
+	return s.LiveModel.Subscribe(bufSize)
+}
+
+func TestRunnerLiveTrades(t *testing.T) {
+	reg := NewRegistry()
+	rec := &recordingStrategy{}
+	reg.Register(rec)
+	reg.Register(&stubStrategy{name: "idle"})
+
+	signals := &memSignalStore{}
+	orders := make(chanSubmitter, 1)
+	r := NewRunner(reg, signals, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	r.SetOrderSubmitter(orders, 0)
+	r.SetMaxSignalAge(time.Hour)
+	r.SetBarFlushDelay(time.Hour) // bars close on later trades only
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := r.Start(ctx, "missing", nil, nil); !errors.Is(err, ErrUnknownStrategy) {
+		t.Errorf("Start(missing) err = %v", err)
+	}
+	if err := r.Start(ctx, "recording", []string{"AAPL"}, map[string]string{ParamOrderQty: "5"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Start(ctx, "recording", nil, nil); !errors.Is(err, ErrAlreadyRunning) {
+		t.Errorf("second Start err = %v, want ErrAlreadyRunning", err)
+	}
+	if err := r.Start(ctx, "idle", nil, map[string]string{"x": "1"}); err == nil {
+		t.Error("expected error passing params to non-configurable strategy")
+	}
+
+	model := newSubscribedSource()
+	subID, sigCh := r.SubscribeSignals(8)
+	defer r.UnsubscribeSignals(subID)
+	done := make(chan error, 1)
+	go func() { done <- r.Run(ctx, model) }()
+	<-model.subscribed
+
+	start := time.Now().Truncate(time.Minute).Add(-5 * time.Minute)
+	etMs := func(d time.Duration) int64 { return int64(ettime.FromTime(start.Add(d))) }
+
+	add := func(id int64, sym string, d time.Duration, price float64, size int64) {
+		model.Add(store.TradeRecord{Symbol: sym, Timestamp: etMs(d), Price: price, Size: size,
+			Exchange: "V", ID: strconv.FormatInt(id, 10)}, id, true)
+	}
+	add(1, "AAPL", 10*time.Second, 100, 10)
+	add(2, "MSFT", 20*time.Second, 300, 10)
+	add(3, "AAPL", 30*time.Second, 102, 30)
+	add(4, "AAPL", 50*time.Second, 99, 10)
+	add(5, "AAPL", 65*time.Second, 101, 10) // closes the first bar
+
+	var sig domain.Signal
+	select {
+	case sig = <-sigCh:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for signal")
+	}
+	if sig.StrategyID != "recording" || sig.Symbol != "AAPL" || !sig.CreatedAt.Equal(start.Add(time.Minute)) {
+		t.Errorf("signal = %+v", sig)
+	}
+
+	select {
+	case o := <-orders:
+		if o.Symbol != "AAPL" || o.Side != domain.OrderSideBuy || o.Qty != 5 || o.StrategyID != "recording" {
+			t.Errorf("forwarded order = %+v", o)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for forwarded order")
+	}
+
+	if err := r.Stop("recording"); err != nil {
+		t.Fatal(err)
+	}
+	st, _ := r.Status("recording")
+	if st.Status != StatusStopped || st.Signals != 1 {
+		t.Errorf("status after stop = %+v", st)
+	}
+	if err := r.Stop("recording"); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("second Stop err = %v, want ErrNotRunning", err)
+	}
+
+	rec.mu.Lock()
+	if len(rec.bars) != 1 {
+		t.Fatalf("bars = %+v", rec.bars)
+	}
+	b := rec.bars[0]
+	if b.Open != 100 || b.High != 102 || b.Low != 99 || b.Close != 99 || b.Volume != 50 || b.TradeCount != 3 ||
+		!b.Timestamp.Equal(start) {
+		t.Errorf("bar = %+v", b)
+	}
+	if wantVWAP := (100*10 + 102*30 + 99*10) / 50.0; math.Abs(b.VWAP-wantVWAP) > 1e-9 {
+		t.Errorf("VWAP = %v, want %v", b.VWAP, wantVWAP)
+	}
+	if rec.trades != 4 {
+		t.Errorf("trades = %d, want 4 (MSFT filtered out)", rec.trades)
+	}
+	rec.mu.Unlock()
+
+	if saved, _ := signals.ListSignals(ctx, "", 0); len(saved) != 1 {
+		t.Errorf("saved signals = %d, want 1", len(saved))
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Run: %v", err)
+	}
+}
+
+func TestRunnerFlushesQuietBar(t *testing.T) {
+	reg := NewRegistry()
+	rec := &recordingStrategy{}
+	reg.Register(rec)
+	r := NewRunner(reg, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := r.Start(ctx, "recording", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	model := newSubscribedSource()
+	subID, sigCh := r.SubscribeSignals(8)
+	defer r.UnsubscribeSignals(subID)
+	go r.Run(ctx, model)
+	<-model.subscribed
+
+	// A single trade with no later trade to close its bar.
+	start := time.Now().Truncate(time.Minute).Add(-5 * time.Minute)
+	model.Add(store.TradeRecord{Symbol: "AAPL", Timestamp: int64(ettime.FromTime(start.Add(10 * time.Second))),
+		Price: 100, Size: 10, Exchange: "V", ID: "1"}, 1, false)
+
+	select {
+	case sig := <-sigCh:
+		if sig.Symbol != "AAPL" || !sig.CreatedAt.Equal(start.Add(time.Minute)) {
+			t.Errorf("signal = %+v", sig)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the quiet bar to flush")
+	}
+}
+
+// failingStrategy records its params and fails on every trade.
+type failingStrategy struct {
+	params map[string]string
+}
+
+func (s *failingStrategy) Name() string                 { return "failing" }
+func (s *failingStrategy) Init(_ context.Context) error { return nil }
+func (s *failingStrategy) Configure(p map[string]string) error {
+	s.params = p
+	return nil
+}
+func (s *failingStrategy) OnBar(_ context.Context, _ domain.Bar) ([]domain.Signal, error) {
+	return nil, nil
+}
+func (s *failingStrategy) OnTrade(_ context.Context, _ domain.Trade) ([]domain.Signal, error) {
+	return nil, errors.New("boom")
+}
+
+func TestRunnerRetiresFailedStrategy(t *testing.T) {
+	reg := NewRegistry()
+	reg.RegisterFactory(func() Strategy { return &failingStrategy{} })
+	r := NewRunner(reg, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+
+	if err := r.Start(ctx, "failing", nil, map[string]string{"mode": "fast"}); err != nil {
+		t.Fatal(err)
+	}
+	if shared, _ := reg.Get("failing"); shared.(*failingStrategy).params != nil {
+		t.Errorf("shared instance configured with %v, want a fresh instance", shared.(*failingStrategy).params)
+	}
+
+	trade := domain.Trade{Symbol: "AAPL", Timestamp: time.Now(), Price: 100, Size: 10}
+	r.dispatchTrade(trade)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if st, _ := r.Status("failing"); st.Status == StatusError {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the strategy to fail")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Later events are no longer queued for it.
+	for range 5000 {
+		r.dispatchTrade(trade)
+	}
+	st, _ := r.Status("failing")
+	if st.Status != StatusError || st.LastError != "boom" || st.Dropped != 0 {
+		t.Errorf("status = %+v, want error boom with nothing dropped", st)
+	}
+	if err := r.Stop("failing"); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Stop err = %v, want ErrNotRunning", err)
+	}
+	if err := r.Start(ctx, "failing", nil, nil); err != nil {
+		t.Errorf("restart after failure: %v", err)
+	}
+	r.stopAll()
+}
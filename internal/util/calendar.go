@@ -1,38 +1,505 @@
 package util
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
 	"time"
 
 	"jupitor/internal/domain"
 )
 
+// SessionHours are a market's daily session boundaries, as clock offsets from
+// local midnight. Boundaries are applied as wall-clock times, so sessions on
+// DST transition days keep their local hours.
+type SessionHours struct {
+	PreOpen    time.Duration // start of pre-market; equal to Open if none
+	Open       time.Duration
+	BreakStart time.Duration // lunch break; zero if none
+	BreakEnd   time.Duration
+	Close      time.Duration
+	PostClose  time.Duration // end of post-market; equal to Close if none
+
+	// EarlyClose and EarlyPostClose replace Close and PostClose on half
+	// days; zero if the market has none.
+	EarlyClose     time.Duration
+	EarlyPostClose time.Duration
+}
+
+// Session is one trading day's session boundaries.
+type Session struct {
+	Date       time.Time // local midnight
+	PreOpen    time.Time
+	Open       time.Time
+	BreakStart time.Time // zero if the market has no break
+	BreakEnd   time.Time
+	Close      time.Time
+	PostClose  time.Time
+	HalfDay    bool
+}
+
+// CalendarDay is one trading day of an externally sourced calendar, in the
+// shape of Alpaca's calendar API. Open and Close are local "HH:MM".
+type CalendarDay struct {
+	Date  string `json:"date"`
+	Open  string `json:"open"`
+	Close string `json:"close"`
+}
+
 // TradingCalendar provides market-hours awareness for a specific market.
+// Holidays and half days are computed offline from exchange rules; Override
+// replaces them with an authoritative calendar for the days it covers.
 type TradingCalendar struct {
-	market domain.Market
+	market   domain.Market
+	loc      *time.Location
+	hours    SessionHours
+	holidays string // holiday rule set; see HolidaysNYSE
+
+	mu        sync.RWMutex
+	overrides map[string]CalendarDay
+	coverFrom string // first date covered by overrides
+	coverTo   string // last date covered by overrides
 }
 
-// NewTradingCalendar creates a TradingCalendar for the given market.
+// NewTradingCalendar creates a TradingCalendar for the given market with its
+// built-in timezone, session hours and holiday rules (those in
+// config/markets.yaml). Use MarketInfo.Calendar for a configured market.
 func NewTradingCalendar(market domain.Market) *TradingCalendar {
-	return &TradingCalendar{
-		market: market,
+	mi, err := DefaultMarketRegistry().Get(market)
+	if err != nil {
+		return &TradingCalendar{market: market, loc: time.UTC, hours: DefaultSessionHours(market)}
 	}
+	return mi.Calendar()
 }
 
-// IsMarketOpen returns whether the market is open at time t.
-func (tc *TradingCalendar) IsMarketOpen(_ time.Time) bool {
-	// TODO: implement market-hours check for US (NYSE 9:30-16:00 ET) and
-	// CN (SSE 9:30-11:30, 13:00-15:00 CST), accounting for holidays.
-	return false
+func hm(h, m int) time.Duration { return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute }
+
+// DefaultSessionHours returns the built-in session hours for market: NYSE
+// 04:00/09:30-16:00/20:00 ET with 13:00 half days, and SSE 09:30-11:30,
+// 13:00-15:00 CST.
+func DefaultSessionHours(market domain.Market) SessionHours {
+	switch market {
+	case domain.MarketCN:
+		return SessionHours{
+			PreOpen: hm(9, 30), Open: hm(9, 30),
+			BreakStart: hm(11, 30), BreakEnd: hm(13, 0),
+			Close: hm(15, 0), PostClose: hm(15, 0),
+		}
+	default:
+		return SessionHours{
+			PreOpen: hm(4, 0), Open: hm(9, 30),
+			Close: hm(16, 0), PostClose: hm(20, 0),
+			EarlyClose: hm(13, 0), EarlyPostClose: hm(17, 0),
+		}
+	}
+}
+
+// Location returns the market's timezone.
+func (tc *TradingCalendar) Location() *time.Location { return tc.loc }
+
+// Override makes days authoritative for the date range they span: listed
+// dates trade with the given hours and unlisted dates in the range are
+// closed. Dates outside the range keep the rule-based calendar.
+func (tc *TradingCalendar) Override(days []CalendarDay) error {
+	if len(days) == 0 {
+		return nil
+	}
+	m := make(map[string]CalendarDay, len(days))
+	from, to := days[0].Date, days[0].Date
+	for _, d := range days {
+		if _, err := time.Parse(time.DateOnly, d.Date); err != nil {
+			return fmt.Errorf("calendar day %q: %w", d.Date, err)
+		}
+		if _, err := parseClock(d.Open); err != nil {
+			return fmt.Errorf("calendar day %s open: %w", d.Date, err)
+		}
+		if _, err := parseClock(d.Close); err != nil {
+			return fmt.Errorf("calendar day %s close: %w", d.Date, err)
+		}
+		m[d.Date] = d
+		from, to = min(from, d.Date), max(to, d.Date)
+	}
+
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.overrides, tc.coverFrom, tc.coverTo = m, from, to
+	return nil
+}
+
+// IsTradingDay reports whether the market trades on t's local date.
+func (tc *TradingCalendar) IsTradingDay(t time.Time) bool {
+	_, ok := tc.Session(t)
+	return ok
+}
+
+// IsHalfDay reports whether t's local date is an early-close trading day.
+func (tc *TradingCalendar) IsHalfDay(t time.Time) bool {
+	s, ok := tc.Session(t)
+	return ok && s.HalfDay
+}
+
+// Session returns the session of t's local date, or false if the market is
+// closed that day.
+func (tc *TradingCalendar) Session(t time.Time) (Session, bool) {
+	lt := t.In(tc.loc)
+	y, mo, d := lt.Date()
+	date := time.Date(y, mo, d, 0, 0, 0, 0, tc.loc)
+	at := func(off time.Duration) time.Time {
+		return time.Date(y, mo, d, int(off/time.Hour), int(off%time.Hour/time.Minute), 0, 0, tc.loc)
+	}
+
+	h := tc.hours
+	halfDay := false
+	if day, covered := tc.override(date.Format(time.DateOnly)); covered {
+		if day == nil {
+			return Session{}, false
+		}
+		h.Open, _ = parseClock(day.Open)
+		h.Close, _ = parseClock(day.Close)
+		if h.Close < tc.hours.Close {
+			halfDay = true
+			h.PostClose = max(h.Close, tc.hours.EarlyPostClose)
+		}
+	} else {
+		if wd := date.Weekday(); wd == time.Saturday || wd == time.Sunday {
+			return Session{}, false
+		}
+		closed, early := tc.ruleDay(date)
+		if closed {
+			return Session{}, false
+		}
+		if early && h.EarlyClose > 0 {
+			halfDay = true
+			h.Close, h.PostClose = h.EarlyClose, max(h.EarlyClose, h.EarlyPostClose)
+		}
+	}
+
+	s := Session{
+		Date:      date,
+		PreOpen:   at(min(h.PreOpen, h.Open)),
+		Open:      at(h.Open),
+		Close:     at(h.Close),
+		PostClose: at(max(h.PostClose, h.Close)),
+		HalfDay:   halfDay,
+	}
+	if h.BreakStart > 0 && h.BreakEnd > h.BreakStart && h.BreakEnd < h.Close {
+		s.BreakStart, s.BreakEnd = at(h.BreakStart), at(h.BreakEnd)
+	}
+	return s, true
+}
+
+// IsMarketOpen returns whether the regular session is in progress at t,
+// excluding any lunch break.
+func (tc *TradingCalendar) IsMarketOpen(t time.Time) bool {
+	s, ok := tc.Session(t)
+	if !ok || t.Before(s.Open) || !t.Before(s.Close) {
+		return false
+	}
+	return s.BreakStart.IsZero() || t.Before(s.BreakStart) || !t.Before(s.BreakEnd)
 }
 
-// NextOpen returns the next market open time at or after t.
-func (tc *TradingCalendar) NextOpen(_ time.Time) time.Time {
-	// TODO: compute next trading session open based on market calendar
+// IsExtendedHours returns whether t falls in the pre- or post-market session.
+func (tc *TradingCalendar) IsExtendedHours(t time.Time) bool {
+	s, ok := tc.Session(t)
+	if !ok {
+		return false
+	}
+	return (!t.Before(s.PreOpen) && t.Before(s.Open)) || (!t.Before(s.Close) && t.Before(s.PostClose))
+}
+
+// NextOpen returns the next regular-session open at or after t. The end of
+// a lunch break counts as an open.
+func (tc *TradingCalendar) NextOpen(t time.Time) time.Time {
+	return tc.next(t, func(s Session) []time.Time { return []time.Time{s.Open, s.BreakEnd} })
+}
+
+// NextClose returns the next regular-session close at or after t. The start
+// of a lunch break counts as a close.
+func (tc *TradingCalendar) NextClose(t time.Time) time.Time {
+	return tc.next(t, func(s Session) []time.Time { return []time.Time{s.BreakStart, s.Close} })
+}
+
+// maxCalendarScan bounds searches for the next or previous trading day; the
+// longest closures (Spring Festival) last under two weeks.
+const maxCalendarScan = 30
+
+func (tc *TradingCalendar) next(t time.Time, boundaries func(Session) []time.Time) time.Time {
+	day := t.In(tc.loc)
+	for range maxCalendarScan {
+		if s, ok := tc.Session(day); ok {
+			for _, b := range boundaries(s) {
+				if !b.IsZero() && !b.Before(t) {
+					return b
+				}
+			}
+		}
+		y, mo, d := day.Date()
+		day = time.Date(y, mo, d+1, 12, 0, 0, 0, tc.loc)
+	}
 	return time.Time{}
 }
 
-// NextClose returns the next market close time at or after t.
-func (tc *TradingCalendar) NextClose(_ time.Time) time.Time {
-	// TODO: compute next trading session close based on market calendar
+// NextTradingDay returns local midnight of the first trading day after t's
+// local date.
+func (tc *TradingCalendar) NextTradingDay(t time.Time) time.Time {
+	return tc.step(t, 1)
+}
+
+// PrevTradingDay returns local midnight of the last trading day before t's
+// local date.
+func (tc *TradingCalendar) PrevTradingDay(t time.Time) time.Time {
+	return tc.step(t, -1)
+}
+
+func (tc *TradingCalendar) step(t time.Time, dir int) time.Time {
+	y, mo, d := t.In(tc.loc).Date()
+	for i := 1; i <= maxCalendarScan; i++ {
+		if s, ok := tc.Session(time.Date(y, mo, d+dir*i, 12, 0, 0, 0, tc.loc)); ok {
+			return s.Date
+		}
+	}
 	return time.Time{}
 }
+
+// override returns the override for date and whether date is in the
+// overridden range; a nil day in range means closed.
+func (tc *TradingCalendar) override(date string) (*CalendarDay, bool) {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	if tc.overrides == nil || date < tc.coverFrom || date > tc.coverTo {
+		return nil, false
+	}
+	if day, ok := tc.overrides[date]; ok {
+		return &day, true
+	}
+	return nil, true
+}
+
+// ruleDay applies the market's holiday rules to a weekday.
+func (tc *TradingCalendar) ruleDay(date time.Time) (closed, early bool) {
+	switch tc.holidays {
+	case HolidaysNYSE:
+		return nyseHoliday(date), nyseEarlyClose(date)
+	case HolidaysSSE:
+		return sseHoliday(date), false
+	default:
+		return false, false
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Calendar cache
+// ---------------------------------------------------------------------------
+
+// LoadCalendarCache reads calendar days saved by SaveCalendarCache.
+func LoadCalendarCache(path string) ([]CalendarDay, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var days []CalendarDay
+	if err := json.Unmarshal(data, &days); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return days, nil
+}
+
+// SaveCalendarCache writes days to path as JSON, sorted by date.
+func SaveCalendarCache(path string, days []CalendarDay) error {
+	sorted := append([]CalendarDay(nil), days...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Date < sorted[j].Date })
+	data, err := json.MarshalIndent(sorted, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// ---------------------------------------------------------------------------
+// NYSE rules
+// ---------------------------------------------------------------------------
+
+// nyseClosures are unscheduled full-day closures.
+var nyseClosures = map[string]bool{
+	"2001-09-11": true, "2001-09-12": true, "2001-09-13": true, "2001-09-14": true,
+	"2004-06-11": true,                     // President Reagan's funeral
+	"2007-01-02": true,                     // President Ford's funeral
+	"2012-10-29": true, "2012-10-30": true, // Hurricane Sandy
+	"2018-12-05": true, // President G.H.W. Bush's funeral
+	"2025-01-09": true, // President Carter's funeral
+}
+
+// nyseHoliday reports whether a weekday is an NYSE holiday.
+func nyseHoliday(date time.Time) bool {
+	if nyseClosures[date.Format(time.DateOnly)] {
+		return true
+	}
+	y, m, d := date.Date()
+	switch m {
+	case time.January:
+		// New Year's Day moves to Monday when on Sunday; a Saturday New
+		// Year's Day is not observed on the Friday before.
+		if d == 1 || (d == 2 && date.Weekday() == time.Monday) {
+			return true
+		}
+		return y >= 1998 && isNthWeekday(date, time.Monday, 3) // Martin Luther King Jr. Day
+	case time.February:
+		return isNthWeekday(date, time.Monday, 3) // Washington's Birthday
+	case time.March, time.April:
+		return date.Equal(easter(y, date.Location()).AddDate(0, 0, -2)) // Good Friday
+	case time.May:
+		return date.Weekday() == time.Monday && d+7 > 31 // Memorial Day
+	case time.June:
+		return y >= 2022 && observed(date, 19) // Juneteenth
+	case time.July:
+		return observed(date, 4) // Independence Day
+	case time.September:
+		return isNthWeekday(date, time.Monday, 1) // Labor Day
+	case time.November:
+		return isNthWeekday(date, time.Thursday, 4) // Thanksgiving
+	case time.December:
+		return observed(date, 25) // Christmas
+	}
+	return false
+}
+
+// nyseEarlyClose reports whether a trading day closes at 13:00: the day
+// before Independence Day, the day after Thanksgiving and Christmas Eve.
+func nyseEarlyClose(date time.Time) bool {
+	_, m, d := date.Date()
+	wd := date.Weekday()
+	switch {
+	case m == time.July && d == 3:
+		return wd >= time.Monday && wd <= time.Thursday
+	case m == time.November && wd == time.Friday:
+		return isNthWeekday(date.AddDate(0, 0, -1), time.Thursday, 4)
+	case m == time.December && d == 24:
+		return wd >= time.Monday && wd <= time.Thursday
+	}
+	return false
+}
+
+// observed reports whether a weekday is the observance of a fixed-date
+// holiday on day of its month, moved to Friday from Saturday and to Monday
+// from Sunday.
+func observed(date time.Time, day int) bool {
+	switch d := date.Day(); {
+	case d == day:
+		return true
+	case d == day-1 && date.Weekday() == time.Friday:
+		return true
+	case d == day+1 && date.Weekday() == time.Monday:
+		return true
+	}
+	return false
+}
+
+func isNthWeekday(date time.Time, wd time.Weekday, n int) bool {
+	return date.Weekday() == wd && (date.Day()-1)/7 == n-1
+}
+
+// easter returns Western Easter Sunday (anonymous Gregorian algorithm).
+func easter(y int, loc *time.Location) time.Time {
+	a := y % 19
+	b, c := y/100, y%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(y, time.Month(month), day, 0, 0, 0, 0, loc)
+}
+
+// ---------------------------------------------------------------------------
+// SSE rules
+// ---------------------------------------------------------------------------
+
+// sseSchedules are the published SSE closures (State Council holiday
+// schedules); weekend days inside a range are closed anyway.
+var sseSchedules = map[int][][2]string{
+	2020: {{"2020-01-01", "2020-01-01"}, {"2020-01-24", "2020-02-02"}, {"2020-04-04", "2020-04-06"},
+		{"2020-05-01", "2020-05-05"}, {"2020-06-25", "2020-06-27"}, {"2020-10-01", "2020-10-08"}},
+	2021: {{"2021-01-01", "2021-01-03"}, {"2021-02-11", "2021-02-17"}, {"2021-04-03", "2021-04-05"},
+		{"2021-05-01", "2021-05-05"}, {"2021-06-12", "2021-06-14"}, {"2021-09-19", "2021-09-21"},
+		{"2021-10-01", "2021-10-07"}},
+	2022: {{"2022-01-01", "2022-01-03"}, {"2022-01-31", "2022-02-06"}, {"2022-04-03", "2022-04-05"},
+		{"2022-04-30", "2022-05-04"}, {"2022-06-03", "2022-06-05"}, {"2022-09-10", "2022-09-12"},
+		{"2022-10-01", "2022-10-07"}},
+	2023: {{"2022-12-31", "2023-01-02"}, {"2023-01-21", "2023-01-27"}, {"2023-04-05", "2023-04-05"},
+		{"2023-04-29", "2023-05-03"}, {"2023-06-22", "2023-06-24"}, {"2023-09-29", "2023-10-06"}},
+	2024: {{"2024-01-01", "2024-01-01"}, {"2024-02-09", "2024-02-17"}, {"2024-04-04", "2024-04-06"},
+		{"2024-05-01", "2024-05-05"}, {"2024-06-10", "2024-06-10"}, {"2024-09-15", "2024-09-17"},
+		{"2024-10-01", "2024-10-07"}},
+	2025: {{"2025-01-01", "2025-01-01"}, {"2025-01-28", "2025-02-04"}, {"2025-04-04", "2025-04-06"},
+		{"2025-05-01", "2025-05-05"}, {"2025-05-31", "2025-06-02"}, {"2025-10-01", "2025-10-08"}},
+	2026: {{"2026-01-01", "2026-01-03"}, {"2026-02-15", "2026-02-23"}, {"2026-04-04", "2026-04-06"},
+		{"2026-05-01", "2026-05-05"}, {"2026-06-19", "2026-06-21"}, {"2026-09-25", "2026-09-27"},
+		{"2026-10-01", "2026-10-07"}},
+}
+
+// lunarNewYear is the first day of Spring Festival, for years without a
+// published schedule.
+var lunarNewYear = map[int]string{
+	2015: "2015-02-19", 2016: "2016-02-08", 2017: "2017-01-28", 2018: "2018-02-16",
+	2019: "2019-02-05", 2027: "2027-02-06", 2028: "2028-01-26", 2029: "2029-02-13",
+	2030: "2030-02-03",
+}
+
+// sseHoliday reports whether a weekday is an SSE holiday. Years without a
+// published schedule use an approximation (New Year's Day, Spring Festival
+// eve through its sixth day, May 1-3 and October 1-7) that misses the
+// Qingming, Dragon Boat and Mid-Autumn closures; seed those with Override.
+func sseHoliday(date time.Time) bool {
+	key := date.Format(time.DateOnly)
+	y := date.Year()
+	// A schedule may start in the previous December (2023 New Year).
+	for _, year := range []int{y, y + 1} {
+		for _, r := range sseSchedules[year] {
+			if key >= r[0] && key <= r[1] {
+				return true
+			}
+		}
+	}
+	if _, ok := sseSchedules[y]; ok {
+		return false
+	}
+
+	_, m, d := date.Date()
+	switch {
+	case m == time.January && d == 1:
+		return true
+	case m == time.May && d <= 3:
+		return true
+	case m == time.October && d <= 7:
+		return true
+	}
+	if lny, ok := lunarNewYear[y]; ok {
+		start, _ := time.ParseInLocation(time.DateOnly, lny, date.Location())
+		start = start.AddDate(0, 0, -1)
+		return !date.Before(start) && date.Before(start.AddDate(0, 0, 7))
+	}
+	return false
+}
+
+// parseClock parses a local "HH:MM" clock time into an offset from midnight.
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid clock time %q (want HH:MM)", s)
+	}
+	return hm(t.Hour(), t.Minute()), nil
+}
@@ -0,0 +1,230 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"jupitor/internal/domain"
+)
+
+// Holiday rule sets understood by TradingCalendar. A market with no rule set
+// trades every weekday unless an authoritative calendar is applied with
+// Override.
+const (
+	HolidaysNYSE = "NYSE"
+	HolidaysSSE  = "SSE"
+)
+
+// DataSource is a provider and the data types (daily, trade, stream, ...) it
+// supplies for a market.
+type DataSource struct {
+	Provider string   `yaml:"provider"`
+	Types    []string `yaml:"types"`
+}
+
+// MarketInfo is one market's definition from config/markets.yaml.
+type MarketInfo struct {
+	Market      domain.Market
+	Name        string
+	Currency    string
+	Location    *time.Location
+	Hours       SessionHours
+	Holidays    string // holiday rule set, e.g. HolidaysNYSE; empty for weekends only
+	DataSources []DataSource
+}
+
+// Calendar returns a new trading calendar for the market.
+func (mi *MarketInfo) Calendar() *TradingCalendar {
+	return &TradingCalendar{
+		market:   mi.Market,
+		loc:      mi.Location,
+		hours:    mi.Hours,
+		holidays: mi.Holidays,
+	}
+}
+
+// Provider returns the provider configured for dataType, or "" if none is.
+func (mi *MarketInfo) Provider(dataType string) string {
+	for _, ds := range mi.DataSources {
+		for _, t := range ds.Types {
+			if t == dataType {
+				return ds.Provider
+			}
+		}
+	}
+	return ""
+}
+
+// MarketRegistry holds the definitions of every configured market.
+type MarketRegistry struct {
+	markets map[domain.Market]*MarketInfo
+}
+
+// builtinMarket is the compiled-in definition of a market, used when no
+// markets.yaml is available and as the base for the file's entries.
+type builtinMarket struct {
+	name, timezone, currency, holidays string
+	sources                            []DataSource
+}
+
+var builtinMarkets = map[domain.Market]builtinMarket{
+	domain.MarketUS: {
+		name: "US Equities", timezone: "America/New_York", currency: "USD", holidays: HolidaysNYSE,
+		sources: []DataSource{{Provider: "alpaca", Types: []string{"daily", "trade", "stream"}}},
+	},
+	domain.MarketCN: {
+		name: "China A-Shares", timezone: "Asia/Shanghai", currency: "CNY", holidays: HolidaysSSE,
+		sources: []DataSource{{Provider: "baostock", Types: []string{"daily"}}},
+	},
+}
+
+// DefaultMarketRegistry returns the built-in US and CN definitions, which
+// match config/markets.yaml.
+func DefaultMarketRegistry() *MarketRegistry {
+	r := &MarketRegistry{markets: make(map[domain.Market]*MarketInfo, len(builtinMarkets))}
+	for m, b := range builtinMarkets {
+		loc, err := time.LoadLocation(b.timezone)
+		if err != nil {
+			loc = time.UTC
+		}
+		r.markets[m] = &MarketInfo{
+			Market:      m,
+			Name:        b.name,
+			Currency:    b.currency,
+			Location:    loc,
+			Hours:       DefaultSessionHours(m),
+			Holidays:    b.holidays,
+			DataSources: b.sources,
+		}
+	}
+	return r
+}
+
+// LoadMarketRegistry reads market definitions from a markets.yaml file.
+// Markets with built-in defaults start from them, so that half-day hours,
+// which the file does not describe, are kept; other markets must define
+// regular or morning/afternoon hours.
+func LoadMarketRegistry(path string) (*MarketRegistry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Markets map[string]struct {
+			Name             string                       `yaml:"name"`
+			Timezone         string                       `yaml:"timezone"`
+			Currency         string                       `yaml:"currency"`
+			TradingHours     map[string]map[string]string `yaml:"trading_hours"`
+			HolidaysCalendar string                       `yaml:"holidays_calendar"`
+			DataSources      []DataSource                 `yaml:"data_sources"`
+		} `yaml:"markets"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if len(file.Markets) == 0 {
+		return nil, fmt.Errorf("%s: no markets defined", path)
+	}
+
+	r := &MarketRegistry{markets: make(map[domain.Market]*MarketInfo, len(file.Markets))}
+	for key, m := range file.Markets {
+		market := domain.Market(key)
+		loc, err := time.LoadLocation(m.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("%s: market %q: %w", path, market, err)
+		}
+		switch m.HolidaysCalendar {
+		case "", HolidaysNYSE, HolidaysSSE:
+		default:
+			return nil, fmt.Errorf("%s: markets.%s.holidays_calendar: unknown rule set %q (want %s, %s or empty)",
+				path, market, m.HolidaysCalendar, HolidaysNYSE, HolidaysSSE)
+		}
+
+		var h SessionHours
+		if _, ok := builtinMarkets[market]; ok {
+			h = DefaultSessionHours(market)
+		}
+		set := func(section, key string, dst *time.Duration) error {
+			v, ok := m.TradingHours[section][key]
+			if !ok {
+				return nil
+			}
+			d, err := parseClock(v)
+			if err != nil {
+				return fmt.Errorf("%s: markets.%s.trading_hours.%s.%s: %w", path, market, section, key, err)
+			}
+			*dst = d
+			return nil
+		}
+		for _, f := range []struct {
+			section, key string
+			dst          *time.Duration
+		}{
+			{"regular", "open", &h.Open},
+			{"regular", "close", &h.Close},
+			{"extended", "pre_open", &h.PreOpen},
+			{"extended", "post_close", &h.PostClose},
+			{"morning", "open", &h.Open},
+			{"morning", "close", &h.BreakStart},
+			{"afternoon", "open", &h.BreakEnd},
+			{"afternoon", "close", &h.Close},
+		} {
+			if err := set(f.section, f.key, f.dst); err != nil {
+				return nil, err
+			}
+		}
+		if _, ok := m.TradingHours["extended"]; !ok {
+			h.PreOpen, h.PostClose = h.Open, h.Close
+		}
+		if h.Close <= h.Open {
+			return nil, fmt.Errorf("%s: markets.%s.trading_hours: close must be after open", path, market)
+		}
+
+		name := m.Name
+		if name == "" {
+			name = strings.ToUpper(key)
+		}
+		r.markets[market] = &MarketInfo{
+			Market:      market,
+			Name:        name,
+			Currency:    m.Currency,
+			Location:    loc,
+			Hours:       h,
+			Holidays:    m.HolidaysCalendar,
+			DataSources: m.DataSources,
+		}
+	}
+	return r, nil
+}
+
+// Get returns market's definition.
+func (r *MarketRegistry) Get(market domain.Market) (*MarketInfo, error) {
+	mi, ok := r.markets[market]
+	if !ok {
+		return nil, fmt.Errorf("market %q not defined", market)
+	}
+	return mi, nil
+}
+
+// Location returns market's timezone, or UTC if the market is not defined.
+func (r *MarketRegistry) Location(market domain.Market) *time.Location {
+	if mi, ok := r.markets[market]; ok {
+		return mi.Location
+	}
+	return time.UTC
+}
+
+// Markets returns the defined markets in sorted order.
+func (r *MarketRegistry) Markets() []domain.Market {
+	out := make([]domain.Market, 0, len(r.markets))
+	for m := range r.markets {
+		out = append(out, m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
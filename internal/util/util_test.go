@@ -3,7 +3,10 @@ package util
 import (
 	"context"
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"jupitor/internal/domain"
 )
@@ -58,3 +61,185 @@ func TestTradingCalendarNew(t *testing.T) {
 		t.Fatal("NewTradingCalendar returned nil")
 	}
 }
+
+func TestTradingCalendarNYSE(t *testing.T) {
+	cal := NewTradingCalendar(domain.MarketUS)
+	loc := cal.Location()
+	day := func(s string) time.Time {
+		d, _ := time.ParseInLocation(time.DateOnly, s, loc)
+		return d.Add(12 * time.Hour)
+	}
+
+	for _, d := range []string{
+		"2024-01-01", "2024-01-15", "2024-02-19", "2024-03-29", "2024-05-27", "2024-06-19",
+		"2024-07-04", "2024-09-02", "2024-11-28", "2024-12-25",
+		"2021-12-24",               // Christmas on Saturday, observed Friday
+		"2022-01-17", "2022-06-20", // Juneteenth on Sunday, observed Monday
+		"2023-01-02", // New Year's Day on Sunday
+		"2025-01-09", // national day of mourning
+		"2025-04-18", "2026-04-03", "2026-07-03",
+	} {
+		if cal.IsTradingDay(day(d)) {
+			t.Errorf("%s should be a holiday", d)
+		}
+	}
+	for _, d := range []string{"2021-12-31", "2024-03-28", "2024-07-05", "2021-06-18", "2024-10-14"} {
+		if !cal.IsTradingDay(day(d)) {
+			t.Errorf("%s should be a trading day", d)
+		}
+	}
+	for _, d := range []string{"2024-07-03", "2024-11-29", "2024-12-24", "2025-07-03"} {
+		if !cal.IsHalfDay(day(d)) {
+			t.Errorf("%s should be a half day", d)
+		}
+	}
+	if cal.IsHalfDay(day("2022-07-01")) || cal.IsHalfDay(day("2024-07-05")) {
+		t.Error("unexpected half day")
+	}
+
+	s, _ := cal.Session(day("2024-11-29"))
+	if got := s.Close.Format("15:04"); got != "13:00" {
+		t.Errorf("half-day close = %s, want 13:00", got)
+	}
+	if got := s.PostClose.Format("15:04"); got != "17:00" {
+		t.Errorf("half-day post close = %s, want 17:00", got)
+	}
+
+	// DST starts 2024-03-10: sessions keep their wall-clock hours.
+	s, _ = cal.Session(day("2024-03-11"))
+	if got := s.Open.Format("15:04 MST"); got != "09:30 EDT" {
+		t.Errorf("open after DST = %s", got)
+	}
+
+	fri := time.Date(2024, 3, 28, 15, 0, 0, 0, loc) // Thursday before Good Friday
+	if !cal.IsMarketOpen(fri) || cal.IsExtendedHours(fri) {
+		t.Error("market should be open at 15:00")
+	}
+	if !cal.IsExtendedHours(fri.Add(2 * time.Hour)) {
+		t.Error("17:00 should be post-market")
+	}
+	if got, want := cal.NextOpen(fri), time.Date(2024, 4, 1, 9, 30, 0, 0, loc); !got.Equal(want) {
+		t.Errorf("NextOpen = %v, want %v", got, want)
+	}
+	if got, want := cal.NextClose(fri), time.Date(2024, 3, 28, 16, 0, 0, 0, loc); !got.Equal(want) {
+		t.Errorf("NextClose = %v, want %v", got, want)
+	}
+	if got := cal.PrevTradingDay(day("2024-04-01")).Format(time.DateOnly); got != "2024-03-28" {
+		t.Errorf("PrevTradingDay = %s", got)
+	}
+	if got := cal.NextTradingDay(day("2024-03-28")).Format(time.DateOnly); got != "2024-04-01" {
+		t.Errorf("NextTradingDay = %s", got)
+	}
+}
+
+func TestTradingCalendarSSE(t *testing.T) {
+	cal := NewTradingCalendar(domain.MarketCN)
+	loc := cal.Location()
+
+	for _, d := range []string{"2024-02-09", "2024-02-16", "2024-04-04", "2024-10-07", "2025-01-28", "2026-02-23"} {
+		dt, _ := time.ParseInLocation(time.DateOnly, d, loc)
+		if cal.IsTradingDay(dt) {
+			t.Errorf("%s should be an SSE holiday", d)
+		}
+	}
+	if got := cal.NextTradingDay(time.Date(2024, 2, 8, 12, 0, 0, 0, loc)).Format(time.DateOnly); got != "2024-02-19" {
+		t.Errorf("trading resumes %s after Spring Festival, want 2024-02-19", got)
+	}
+
+	lunch := time.Date(2024, 3, 12, 12, 0, 0, 0, loc)
+	if cal.IsMarketOpen(lunch) {
+		t.Error("market should be closed over lunch")
+	}
+	if !cal.IsMarketOpen(lunch.Add(time.Hour)) {
+		t.Error("market should be open at 13:00")
+	}
+	if got, want := cal.NextOpen(lunch), time.Date(2024, 3, 12, 13, 0, 0, 0, loc); !got.Equal(want) {
+		t.Errorf("NextOpen = %v, want %v", got, want)
+	}
+}
+
+func TestTradingCalendarOverride(t *testing.T) {
+	cal := NewTradingCalendar(domain.MarketUS)
+	loc := cal.Location()
+	err := cal.Override([]CalendarDay{
+		{Date: "2030-01-02", Open: "09:30", Close: "16:00"},
+		{Date: "2030-01-04", Open: "09:30", Close: "13:00"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(d int) time.Time { return time.Date(2030, 1, d, 12, 0, 0, 0, loc) }
+	if cal.IsTradingDay(at(3)) {
+		t.Error("unlisted day inside the override range should be closed")
+	}
+	if !cal.IsHalfDay(at(4)) {
+		t.Error("early close from override should be a half day")
+	}
+	if !cal.IsTradingDay(at(7)) {
+		t.Error("days outside the override range should use the rules")
+	}
+	if err := cal.Override([]CalendarDay{{Date: "2030-01-02", Open: "9am", Close: "16:00"}}); err == nil {
+		t.Error("expected error for bad clock time")
+	}
+}
+
+func TestLoadMarketRegistry(t *testing.T) {
+	reg, err := LoadMarketRegistry(filepath.Join("..", "..", "config", "markets.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	us, err := reg.Get(domain.MarketUS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	def, _ := DefaultMarketRegistry().Get(domain.MarketUS)
+	if us.Currency != "USD" || us.Location.String() != "America/New_York" ||
+		us.Hours != def.Hours || us.Provider("stream") != "alpaca" {
+		t.Errorf("us = %+v", us)
+	}
+	cn, err := reg.Get(domain.MarketCN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := cn.Hours; cn.Location.String() != "Asia/Shanghai" || h.BreakStart != 11*time.Hour+30*time.Minute ||
+		h.BreakEnd != 13*time.Hour || h.Close != 15*time.Hour || h.PreOpen != h.Open {
+		t.Errorf("cn hours = %+v %v", h, cn.Location)
+	}
+	if got := reg.Markets(); len(got) != 2 || got[0] != domain.MarketCN || got[1] != domain.MarketUS {
+		t.Errorf("Markets() = %v", got)
+	}
+
+	// A market without built-in support needs only a config entry.
+	path := filepath.Join(t.TempDir(), "markets.yaml")
+	os.WriteFile(path, []byte(`markets:
+  hk:
+    name: "Hong Kong"
+    timezone: "Asia/Hong_Kong"
+    currency: "HKD"
+    trading_hours:
+      morning: {open: "09:30", close: "12:00"}
+      afternoon: {open: "13:00", close: "16:00"}
+`), 0o644)
+	reg, err = LoadMarketRegistry(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hk, err := reg.Get("hk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cal := hk.Calendar()
+	s, ok := cal.Session(time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC)) // weekday; no holiday rules
+	if !ok || s.Open.Format("15:04 MST") != "09:30 HKT" || s.BreakStart.Format("15:04") != "12:00" ||
+		s.Close.Format("15:04") != "16:00" || !s.PreOpen.Equal(s.Open) {
+		t.Errorf("hk session = %+v, %v", s, ok)
+	}
+	if _, err := reg.Get(domain.MarketUS); err == nil {
+		t.Error("us defined in hk-only registry")
+	}
+
+	os.WriteFile(path, []byte("markets:\n  hk:\n    timezone: \"Asia/Hong_Kong\"\n    holidays_calendar: \"HKEX\"\n"), 0o644)
+	if _, err := LoadMarketRegistry(path); err == nil {
+		t.Error("unknown holiday rule set accepted")
+	}
+}
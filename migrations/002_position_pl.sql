@@ -0,0 +1,4 @@
+-- Track unrealized P&L alongside market value so positions round-trip fully.
+
+ALTER TABLE positions ADD COLUMN unrealized_pl REAL DEFAULT 0;
+ALTER TABLE positions ADD COLUMN unrealized_pl_pct REAL DEFAULT 0;
@@ -0,0 +1,11 @@
+// Package migrations embeds the versioned SQL schema migrations applied by
+// store.NewSQLiteStore. Files are named NNN_description.sql and are applied
+// in ascending NNN order; once released, a migration must never be edited.
+package migrations
+
+import "embed"
+
+// FS holds all *.sql migration files.
+//
+//go:embed *.sql
+var FS embed.FS
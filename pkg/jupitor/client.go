@@ -1,46 +1,373 @@
+// Package jupitor is a Go SDK for the jupitor-server REST and Strategy gRPC
+// APIs and the us-stream dashboard endpoints (targets and watchlist).
 package jupitor
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
+
+	"jupitor/internal/domain"
+	"jupitor/internal/util"
+)
+
+const (
+	defaultTimeout     = 30 * time.Second
+	defaultMaxAttempts = 3
+	defaultRetryDelay  = 200 * time.Millisecond
 )
 
 // Client provides a Go SDK for interacting with the jupitor-server API.
+// Idempotent requests are retried with exponential backoff on network errors,
+// 429 and 5xx responses; order submission is never retried.
 type Client struct {
 	baseURL    string
 	httpClient *http.Client
+
+	timeout     time.Duration
+	maxAttempts int
+	retryDelay  time.Duration
 }
 
 // NewClient creates a new jupitor API client.
 func NewClient(baseURL string) *Client {
 	return &Client{
-		baseURL:    baseURL,
-		httpClient: &http.Client{Timeout: 30 * time.Second},
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		httpClient:  &http.Client{},
+		timeout:     defaultTimeout,
+		maxAttempts: defaultMaxAttempts,
+		retryDelay:  defaultRetryDelay,
+	}
+}
+
+// SetTimeout sets the per-attempt timeout applied to non-streaming requests
+// (default 30s). The caller's context deadline still applies on top.
+func (c *Client) SetTimeout(d time.Duration) {
+	c.timeout = d
+}
+
+// SetRetry sets the attempt count and initial backoff for retried requests
+// (default 3 attempts from 200ms). maxAttempts of 1 disables retries.
+func (c *Client) SetRetry(maxAttempts int, baseDelay time.Duration) {
+	c.maxAttempts = max(maxAttempts, 1)
+	c.retryDelay = baseDelay
+}
+
+// SetHTTPClient replaces the underlying HTTP client.
+func (c *Client) SetHTTPClient(hc *http.Client) {
+	c.httpClient = hc
+}
+
+// ---------------------------------------------------------------------------
+// Market data
+// ---------------------------------------------------------------------------
+
+// GetBars retrieves daily bars for a symbol. market is "us" or "cn"; zero
+// start or end use the server defaults.
+func (c *Client) GetBars(ctx context.Context, symbol, market string, start, end time.Time) ([]domain.Bar, error) {
+	q := url.Values{"symbol": {symbol}}
+	if market != "" {
+		q.Set("market", market)
+	}
+	setRange(q, start, end)
+
+	var resp listResponse[barJSON]
+	if err := c.get(ctx, "/api/v1/bars", q, &resp); err != nil {
+		return nil, err
+	}
+	bars := make([]domain.Bar, len(resp.Data))
+	for i, b := range resp.Data {
+		bars[i] = domain.Bar(b)
+	}
+	return bars, nil
+}
+
+// GetTrades retrieves all trades for a symbol within [start, end], following
+// pagination. Zero start or end use the server defaults.
+func (c *Client) GetTrades(ctx context.Context, symbol string, start, end time.Time) ([]domain.Trade, error) {
+	q := url.Values{"symbol": {symbol}}
+	setRange(q, start, end)
+
+	var trades []domain.Trade
+	for {
+		var resp listResponse[tradeJSON]
+		if err := c.get(ctx, "/api/v1/trades", q, &resp); err != nil {
+			return nil, err
+		}
+		for _, t := range resp.Data {
+			trades = append(trades, t.toDomain())
+		}
+		if resp.NextPageToken == "" {
+			return trades, nil
+		}
+		q.Set("page_token", resp.NextPageToken)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Trading
+// ---------------------------------------------------------------------------
+
+// GetOrders retrieves orders with the given status ("" for all).
+func (c *Client) GetOrders(ctx context.Context, status domain.OrderStatus) ([]domain.Order, error) {
+	q := url.Values{}
+	if status != "" {
+		q.Set("status", string(status))
+	}
+	var resp listResponse[orderJSON]
+	if err := c.get(ctx, "/api/v1/orders", q, &resp); err != nil {
+		return nil, err
+	}
+	orders := make([]domain.Order, len(resp.Data))
+	for i := range resp.Data {
+		orders[i] = resp.Data[i].toDomain()
+	}
+	return orders, nil
+}
+
+// GetOrder retrieves a single order by ID.
+func (c *Client) GetOrder(ctx context.Context, id string) (*domain.Order, error) {
+	var resp itemResponse[orderJSON]
+	if err := c.get(ctx, "/api/v1/orders/"+url.PathEscape(id), nil, &resp); err != nil {
+		return nil, err
+	}
+	o := resp.Data.toDomain()
+	return &o, nil
+}
+
+// SubmitOrder submits a new order and returns it as accepted by the server.
+// Risk rejections return an *APIError whose Rule names the rule.
+func (c *Client) SubmitOrder(ctx context.Context, order *domain.Order) (*domain.Order, error) {
+	body := submitOrderJSON{
+		Symbol:      order.Symbol,
+		Side:        string(order.Side),
+		Type:        string(order.Type),
+		TimeInForce: string(order.TimeInForce),
+		Qty:         order.Qty,
+		LimitPrice:  order.LimitPrice,
+		StopPrice:   order.StopPrice,
+		StrategyID:  order.StrategyID,
 	}
+	var resp itemResponse[orderJSON]
+	if err := c.doOnce(ctx, http.MethodPost, "/api/v1/orders", nil, body, &resp); err != nil {
+		return nil, err
+	}
+	o := resp.Data.toDomain()
+	return &o, nil
 }
 
-// GetBars retrieves daily bars for a symbol.
-func (c *Client) GetBars(ctx context.Context, symbol, market string, start, end time.Time) ([]byte, error) {
-	// TODO: implement GET /api/v1/bars?symbol=...&market=...&start=...&end=...
-	return nil, fmt.Errorf("GetBars: not implemented")
+// CancelOrder cancels an open order and returns its final state.
+func (c *Client) CancelOrder(ctx context.Context, id string) (*domain.Order, error) {
+	var resp itemResponse[orderJSON]
+	if err := c.do(ctx, http.MethodDelete, "/api/v1/orders/"+url.PathEscape(id), nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	o := resp.Data.toDomain()
+	return &o, nil
 }
 
 // GetPositions retrieves current positions.
-func (c *Client) GetPositions(ctx context.Context) ([]byte, error) {
-	// TODO: implement GET /api/v1/positions
-	return nil, fmt.Errorf("GetPositions: not implemented")
+func (c *Client) GetPositions(ctx context.Context) ([]domain.Position, error) {
+	var resp listResponse[positionJSON]
+	if err := c.get(ctx, "/api/v1/positions", nil, &resp); err != nil {
+		return nil, err
+	}
+	positions := make([]domain.Position, len(resp.Data))
+	for i, p := range resp.Data {
+		positions[i] = domain.Position{
+			Symbol:          p.Symbol,
+			Qty:             p.Qty,
+			AvgEntryPrice:   p.AvgEntryPrice,
+			MarketValue:     p.MarketValue,
+			UnrealizedPL:    p.UnrealizedPL,
+			UnrealizedPLPct: p.UnrealizedPLPct,
+			Side:            domain.PositionSide(p.Side),
+		}
+	}
+	return positions, nil
 }
 
 // GetAccount retrieves account information.
-func (c *Client) GetAccount(ctx context.Context) ([]byte, error) {
-	// TODO: implement GET /api/v1/account
-	return nil, fmt.Errorf("GetAccount: not implemented")
+func (c *Client) GetAccount(ctx context.Context) (*domain.AccountInfo, error) {
+	var resp itemResponse[accountJSON]
+	if err := c.get(ctx, "/api/v1/account", nil, &resp); err != nil {
+		return nil, err
+	}
+	acct := domain.AccountInfo(resp.Data)
+	return &acct, nil
+}
+
+// ---------------------------------------------------------------------------
+// Dashboard (us-stream)
+// ---------------------------------------------------------------------------
+
+// GetTargets retrieves the trade parameters set for date (YYYY-MM-DD, ""
+// for today).
+func (c *Client) GetTargets(ctx context.Context, date string) (map[string]float64, error) {
+	q := url.Values{}
+	if date != "" {
+		q.Set("date", date)
+	}
+	var resp struct {
+		Targets map[string]float64 `json:"targets"`
+	}
+	if err := c.get(ctx, "/api/targets", q, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Targets, nil
 }
 
-// SubmitOrder submits a new order.
-func (c *Client) SubmitOrder(ctx context.Context, order []byte) ([]byte, error) {
-	// TODO: implement POST /api/v1/orders
-	return nil, fmt.Errorf("SubmitOrder: not implemented")
+// SetTarget sets the trade parameter key to value for date.
+func (c *Client) SetTarget(ctx context.Context, date, key string, value float64) error {
+	body := struct {
+		Date  string  `json:"date"`
+		Key   string  `json:"key"`
+		Value float64 `json:"value"`
+	}{date, key, value}
+	return c.do(ctx, http.MethodPut, "/api/targets", nil, body, nil)
+}
+
+// DeleteTarget removes the trade parameter key for date.
+func (c *Client) DeleteTarget(ctx context.Context, date, key string) error {
+	return c.do(ctx, http.MethodDelete, "/api/targets", url.Values{"date": {date}, "key": {key}}, nil, nil)
+}
+
+// GetWatchlist retrieves the watchlist symbols for date ("" for today).
+func (c *Client) GetWatchlist(ctx context.Context, date string) ([]string, error) {
+	q := url.Values{}
+	if date != "" {
+		q.Set("date", date)
+	}
+	var resp struct {
+		Symbols []string `json:"symbols"`
+	}
+	if err := c.get(ctx, "/api/watchlist", q, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Symbols, nil
+}
+
+// AddToWatchlist adds symbol to the watchlist for date ("" for today).
+func (c *Client) AddToWatchlist(ctx context.Context, date, symbol string) error {
+	return c.do(ctx, http.MethodPut, "/api/watchlist/"+url.PathEscape(symbol), dateQuery(date), nil, nil)
+}
+
+// RemoveFromWatchlist removes symbol from the watchlist for date ("" for
+// today).
+func (c *Client) RemoveFromWatchlist(ctx context.Context, date, symbol string) error {
+	return c.do(ctx, http.MethodDelete, "/api/watchlist/"+url.PathEscape(symbol), dateQuery(date), nil, nil)
+}
+
+// ---------------------------------------------------------------------------
+// Request plumbing
+// ---------------------------------------------------------------------------
+
+func (c *Client) get(ctx context.Context, path string, q url.Values, out any) error {
+	return c.do(ctx, http.MethodGet, path, q, nil, out)
+}
+
+// do performs an idempotent request, retrying transient failures.
+func (c *Client) do(ctx context.Context, method, path string, q url.Values, body, out any) error {
+	var permanent error
+	err := util.Retry(ctx, c.maxAttempts, c.retryDelay, func() error {
+		err := c.doOnce(ctx, method, path, q, body, out)
+		if err != nil && !retryable(err) {
+			permanent = err
+			return nil
+		}
+		return err
+	})
+	if permanent != nil {
+		return permanent
+	}
+	return err
+}
+
+// doOnce performs a single request, decoding a 2xx JSON body into out (if
+// non-nil) and any other response into an *APIError.
+func (c *Client) doOnce(ctx context.Context, method, path string, q url.Values, body, out any) error {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	var rd io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encoding request: %w", err)
+		}
+		rd = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.url(path, q), rd)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return newAPIError(resp)
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding %s %s response: %w", method, path, err)
+	}
+	return nil
+}
+
+func (c *Client) url(path string, q url.Values) string {
+	u := c.baseURL + path
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	return u
+}
+
+// retryable reports whether err is a transient failure worth retrying.
+func retryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Temporary()
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, context.DeadlineExceeded)
+}
+
+func setRange(q url.Values, start, end time.Time) {
+	if !start.IsZero() {
+		q.Set("start", start.Format(time.RFC3339Nano))
+	}
+	if !end.IsZero() {
+		q.Set("end", end.Format(time.RFC3339Nano))
+	}
+}
+
+func dateQuery(date string) url.Values {
+	if date == "" {
+		return nil
+	}
+	return url.Values{"date": {date}}
 }
@@ -1,7 +1,21 @@
 package jupitor
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+
+	"jupitor/internal/domain"
+	"jupitor/internal/tradeparams"
 )
 
 func TestNewClient(t *testing.T) {
@@ -20,3 +34,143 @@ func TestNewClient(t *testing.T) {
 		t.Fatal("expected non-nil httpClient")
 	}
 }
+
+func TestGetBarsRetriesTransientErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path != "/api/v1/bars" || r.URL.Query().Get("symbol") != "AAPL" || r.URL.Query().Get("market") != "us" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		fmt.Fprint(w, `{"data":[{"symbol":"AAPL","timestamp":"2024-01-02T00:00:00Z","close":185.5,"volume":100,"vwap":185}]}`)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	c.SetRetry(3, time.Millisecond)
+	bars, err := c.GetBars(context.Background(), "AAPL", "us", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("calls = %d, want 2", calls.Load())
+	}
+	want := domain.Bar{Symbol: "AAPL", Timestamp: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Close: 185.5, Volume: 100, VWAP: 185}
+	if len(bars) != 1 || bars[0] != want {
+		t.Errorf("bars = %+v", bars)
+	}
+}
+
+func TestGetTradesFollowsPages(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page_token") == "" {
+			fmt.Fprint(w, `{"data":[{"symbol":"AAPL","id":"1"}],"next_page_token":"p2"}`)
+			return
+		}
+		fmt.Fprint(w, `{"data":[{"symbol":"AAPL","id":"2"}]}`)
+	}))
+	defer srv.Close()
+
+	trades, err := NewClient(srv.URL).GetTrades(context.Background(), "AAPL", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 2 || trades[0].ID != "1" || trades[1].ID != "2" {
+		t.Errorf("trades = %+v", trades)
+	}
+}
+
+func TestSubmitOrderErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		var body submitOrderJSON
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Symbol != "AAPL" || body.Qty != 200 {
+			t.Errorf("body = %+v (%v)", body, err)
+		}
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprint(w, `{"error":"position too large","rule":"position_limit"}`)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	c.SetRetry(3, time.Millisecond)
+	_, err := c.SubmitOrder(context.Background(), &domain.Order{Symbol: "AAPL", Side: domain.OrderSideBuy, Qty: 200})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !apiErr.Rejected() || apiErr.Rule != "position_limit" || apiErr.Message != "position too large" {
+		t.Fatalf("err = %#v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("calls = %d, want 1", calls.Load())
+	}
+}
+
+func TestStreamTargets(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"type\":\"snapshot\",\"data\":{\"2024-01-02\":{\"AAPL\":190}}}\n\n")
+		fmt.Fprint(w, ": keepalive\n\n")
+		fmt.Fprint(w, "data: {\"type\":\"set\",\"date\":\"2024-01-02\",\"key\":\"MSFT\",\"value\":400}\n\n")
+	}))
+	defer srv.Close()
+
+	var events []tradeparams.Event
+	err := NewClient(srv.URL).StreamTargets(context.Background(), func(evt tradeparams.Event) error {
+		events = append(events, evt)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].Data["2024-01-02"]["AAPL"] != 190 || events[1].Key != "MSFT" || events[1].Value != 400 {
+		t.Errorf("events = %+v", events)
+	}
+}
+
+func TestFeed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.CloseNow()
+		var req feedRequest
+		if err := wsjson.Read(r.Context(), conn, &req); err != nil {
+			t.Error(err)
+			return
+		}
+		if req.Action != "subscribe" || len(req.Channels) != 1 || req.Channels[0] != ChannelTrades {
+			t.Errorf("request = %+v", req)
+		}
+		wsjson.Write(r.Context(), conn, map[string]any{
+			"channel": "trades", "symbol": "AAPL", "data": map[string]any{"symbol": "AAPL", "price": 190.5, "size": 10},
+		})
+		conn.Read(r.Context()) // wait for the client to close
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	feed, err := NewClient(srv.URL).DialFeed(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer feed.Close()
+	if err := feed.Subscribe(ctx, []string{ChannelTrades}, []string{"AAPL"}); err != nil {
+		t.Fatal(err)
+	}
+	msg, err := feed.Next(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trade, err := msg.Trade()
+	if err != nil || trade.Symbol != "AAPL" || trade.Price != 190.5 || trade.Size != 10 {
+		t.Errorf("trade = %+v (%v)", trade, err)
+	}
+	if _, err := msg.Bar(); err == nil {
+		t.Error("Bar() on a trades message succeeded")
+	}
+}
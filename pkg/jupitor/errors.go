@@ -0,0 +1,55 @@
+package jupitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// maxErrorBody caps how much of an error response body is read.
+const maxErrorBody = 64 << 10
+
+// APIError is returned for any non-2xx response. Message is the server's
+// "error" field, or the raw body if it was not JSON.
+type APIError struct {
+	StatusCode int
+	Message    string
+	Rule       string // risk rule that rejected an order, if any
+}
+
+func (e *APIError) Error() string {
+	if e.Rule != "" {
+		return fmt.Sprintf("jupitor: %d %s: %s (rule %s)", e.StatusCode, http.StatusText(e.StatusCode), e.Message, e.Rule)
+	}
+	return fmt.Sprintf("jupitor: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Temporary reports whether the request may succeed if retried (429 or
+// 5xx other than 501).
+func (e *APIError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests ||
+		(e.StatusCode >= 500 && e.StatusCode != http.StatusNotImplemented)
+}
+
+// NotFound reports whether the server returned 404.
+func (e *APIError) NotFound() bool { return e.StatusCode == http.StatusNotFound }
+
+// Rejected reports whether an order was rejected by risk rules or the broker
+// (422).
+func (e *APIError) Rejected() bool { return e.StatusCode == http.StatusUnprocessableEntity }
+
+func newAPIError(resp *http.Response) *APIError {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	e := &APIError{StatusCode: resp.StatusCode}
+	var body struct {
+		Error string `json:"error"`
+		Rule  string `json:"rule"`
+	}
+	if json.Unmarshal(data, &body) == nil && body.Error != "" {
+		e.Message, e.Rule = body.Error, body.Rule
+	} else {
+		e.Message = string(data)
+	}
+	return e
+}
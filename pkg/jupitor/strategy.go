@@ -0,0 +1,145 @@
+package jupitor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "jupitor/internal/api/pb"
+	"jupitor/internal/domain"
+)
+
+// StrategyClient manages strategies over the jupitor-server Strategy gRPC
+// service. Errors are gRPC status errors; use status.Code to inspect them.
+type StrategyClient struct {
+	conn   *grpc.ClientConn
+	client pb.StrategyClient
+}
+
+// StrategyInfo describes a registered strategy and its live status.
+type StrategyInfo struct {
+	ID          string
+	Description string
+	Status      string // "running", "stopped" or "error"
+	Symbols     []string
+	Params      map[string]string
+}
+
+// BacktestRequest parameterises a backtest run.
+type BacktestRequest struct {
+	StrategyID     string
+	Symbols        []string
+	Start, End     time.Time
+	InitialCapital float64
+	Params         map[string]string
+}
+
+// BacktestResult holds the metrics and signals of a backtest run.
+type BacktestResult struct {
+	StrategyID   string
+	TotalReturn  float64
+	SharpeRatio  float64
+	MaxDrawdown  float64
+	TotalTrades  int
+	WinRate      float64
+	ProfitFactor float64
+	Signals      []domain.Signal
+}
+
+// DialStrategies creates a StrategyClient for the gRPC server at addr
+// (host:port). The connection is established lazily.
+func DialStrategies(addr string) (*StrategyClient, error) {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("connecting to %s: %w", addr, err)
+	}
+	return &StrategyClient{conn: conn, client: pb.NewStrategyClient(conn)}, nil
+}
+
+// Close closes the underlying connection.
+func (c *StrategyClient) Close() error {
+	return c.conn.Close()
+}
+
+// ListStrategies returns every registered strategy.
+func (c *StrategyClient) ListStrategies(ctx context.Context) ([]StrategyInfo, error) {
+	resp, err := c.client.ListStrategies(ctx, &pb.ListStrategiesRequest{})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]StrategyInfo, len(resp.GetStrategies()))
+	for i, s := range resp.GetStrategies() {
+		out[i] = StrategyInfo{
+			ID:          s.GetId(),
+			Description: s.GetDescription(),
+			Status:      s.GetStatus(),
+			Symbols:     s.GetSymbols(),
+			Params:      s.GetParams(),
+		}
+	}
+	return out, nil
+}
+
+// StartStrategy starts a strategy live on symbols (all if empty) with the
+// given params.
+func (c *StrategyClient) StartStrategy(ctx context.Context, id string, symbols []string, params map[string]string) error {
+	_, err := c.client.StartStrategy(ctx, &pb.StartStrategyRequest{StrategyId: id, Symbols: symbols, Params: params})
+	return err
+}
+
+// StopStrategy stops a running strategy.
+func (c *StrategyClient) StopStrategy(ctx context.Context, id string) error {
+	_, err := c.client.StopStrategy(ctx, &pb.StopStrategyRequest{StrategyId: id})
+	return err
+}
+
+// RunBacktest runs a backtest and waits for its result.
+func (c *StrategyClient) RunBacktest(ctx context.Context, req BacktestRequest) (*BacktestResult, error) {
+	resp, err := c.client.RunBacktest(ctx, &pb.RunBacktestRequest{
+		StrategyId:     req.StrategyID,
+		Symbols:        req.Symbols,
+		Start:          timestamppb.New(req.Start),
+		End:            timestamppb.New(req.End),
+		InitialCapital: req.InitialCapital,
+		Params:         req.Params,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := &BacktestResult{
+		StrategyID:   resp.GetStrategyId(),
+		TotalReturn:  resp.GetTotalReturn(),
+		SharpeRatio:  resp.GetSharpeRatio(),
+		MaxDrawdown:  resp.GetMaxDrawdown(),
+		TotalTrades:  int(resp.GetTotalTrades()),
+		WinRate:      resp.GetWinRate(),
+		ProfitFactor: resp.GetProfitFactor(),
+	}
+	for _, s := range resp.GetSignals() {
+		out.Signals = append(out.Signals, domain.Signal{
+			StrategyID: s.GetStrategyId(),
+			Symbol:     s.GetSymbol(),
+			Type:       signalTypeFromProto(s.GetType()),
+			Strength:   s.GetStrength(),
+			Metadata:   s.GetMetadata(),
+			CreatedAt:  s.GetTimestamp().AsTime(),
+		})
+	}
+	return out, nil
+}
+
+func signalTypeFromProto(t pb.SignalType) domain.SignalType {
+	switch t {
+	case pb.SignalType_SIGNAL_TYPE_BUY:
+		return domain.SignalTypeBuy
+	case pb.SignalType_SIGNAL_TYPE_SELL:
+		return domain.SignalTypeSell
+	case pb.SignalType_SIGNAL_TYPE_HOLD:
+		return domain.SignalTypeHold
+	}
+	return ""
+}
@@ -0,0 +1,238 @@
+package jupitor
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/coder/websocket"
+
+	"jupitor/internal/domain"
+	"jupitor/internal/tradeparams"
+)
+
+// feedReadLimit is the largest WebSocket message the feed accepts.
+const feedReadLimit = 1 << 20
+
+// Feed channels a WebSocket client can subscribe to.
+const (
+	ChannelTrades  = "trades"
+	ChannelBars    = "bars"
+	ChannelSignals = "signals"
+	ChannelOrders  = "orders"
+	ChannelTargets = "targets"
+)
+
+// ---------------------------------------------------------------------------
+// Server-sent events
+// ---------------------------------------------------------------------------
+
+// StreamTargets consumes the us-stream /api/targets/stream SSE feed, calling
+// fn with the initial snapshot and then every set/delete event. It blocks
+// until ctx is cancelled (returning nil), the stream ends, or fn returns an
+// error.
+func (c *Client) StreamTargets(ctx context.Context, fn func(tradeparams.Event) error) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url("/api/targets/stream", nil), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError(resp)
+	}
+
+	err = readSSE(bufio.NewScanner(resp.Body), func(data string) error {
+		var evt tradeparams.Event
+		if err := json.Unmarshal([]byte(data), &evt); err != nil {
+			return fmt.Errorf("decoding target event: %w", err)
+		}
+		return fn(evt)
+	})
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// readSSE calls fn with the data of each event in an SSE stream, joining
+// multi-line data fields and skipping comments and other fields.
+func readSSE(sc *bufio.Scanner, fn func(data string) error) error {
+	sc.Buffer(make([]byte, 0, 64<<10), feedReadLimit)
+	var data []string
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case line == "":
+			if len(data) > 0 {
+				if err := fn(strings.Join(data, "\n")); err != nil {
+					return err
+				}
+				data = data[:0]
+			}
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	return sc.Err()
+}
+
+// ---------------------------------------------------------------------------
+// WebSocket feed
+// ---------------------------------------------------------------------------
+
+// Feed is a WebSocket connection to the jupitor-server /ws endpoint. A Feed
+// receives nothing until it subscribes to at least one channel.
+type Feed struct {
+	conn *websocket.Conn
+}
+
+// FeedMessage is one message pushed by the server. Data holds the payload
+// for Channel; use the typed accessors to decode it.
+type FeedMessage struct {
+	Channel string          `json:"channel"`
+	Symbol  string          `json:"symbol,omitempty"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// feedRequest is a subscribe/unsubscribe request sent to the server.
+type feedRequest struct {
+	Action   string   `json:"action"`
+	Channels []string `json:"channels"`
+	Symbols  []string `json:"symbols,omitempty"`
+}
+
+// DialFeed opens a WebSocket feed on the server's /ws endpoint.
+func (c *Client) DialFeed(ctx context.Context) (*Feed, error) {
+	u := c.url("/ws", nil)
+	switch {
+	case strings.HasPrefix(u, "https://"):
+		u = "wss://" + strings.TrimPrefix(u, "https://")
+	case strings.HasPrefix(u, "http://"):
+		u = "ws://" + strings.TrimPrefix(u, "http://")
+	}
+	conn, resp, err := websocket.Dial(ctx, u, &websocket.DialOptions{HTTPClient: c.httpClient})
+	if err != nil {
+		if resp != nil && resp.StatusCode != http.StatusSwitchingProtocols {
+			return nil, newAPIError(resp)
+		}
+		return nil, fmt.Errorf("dialing %s: %w", u, err)
+	}
+	conn.SetReadLimit(feedReadLimit)
+	return &Feed{conn: conn}, nil
+}
+
+// Subscribe adds channels for symbols (all symbols if none are given) to
+// the feed.
+func (f *Feed) Subscribe(ctx context.Context, channels, symbols []string) error {
+	return f.send(ctx, feedRequest{Action: "subscribe", Channels: channels, Symbols: symbols})
+}
+
+// Unsubscribe removes channels for symbols (every symbol if none are given)
+// from the feed.
+func (f *Feed) Unsubscribe(ctx context.Context, channels, symbols []string) error {
+	return f.send(ctx, feedRequest{Action: "unsubscribe", Channels: channels, Symbols: symbols})
+}
+
+func (f *Feed) send(ctx context.Context, req feedRequest) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	return f.conn.Write(ctx, websocket.MessageText, data)
+}
+
+// Next blocks until the next message arrives. Server errors are returned as
+// *APIError with a zero StatusCode.
+func (f *Feed) Next(ctx context.Context) (*FeedMessage, error) {
+	_, data, err := f.conn.Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var msg FeedMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, fmt.Errorf("decoding feed message: %w", err)
+	}
+	if msg.Channel == "error" {
+		var e struct {
+			Error string `json:"error"`
+		}
+		_ = json.Unmarshal(msg.Data, &e)
+		return nil, &APIError{Message: e.Error}
+	}
+	return &msg, nil
+}
+
+// Close closes the feed.
+func (f *Feed) Close() error {
+	return f.conn.Close(websocket.StatusNormalClosure, "")
+}
+
+// Trade decodes a trades-channel message.
+func (m *FeedMessage) Trade() (domain.Trade, error) {
+	var t tradeJSON
+	if err := m.decode(ChannelTrades, &t); err != nil {
+		return domain.Trade{}, err
+	}
+	return t.toDomain(), nil
+}
+
+// Bar decodes a bars-channel message.
+func (m *FeedMessage) Bar() (domain.Bar, error) {
+	var b barJSON
+	if err := m.decode(ChannelBars, &b); err != nil {
+		return domain.Bar{}, err
+	}
+	return domain.Bar(b), nil
+}
+
+// Order decodes an orders-channel message.
+func (m *FeedMessage) Order() (domain.Order, error) {
+	var o orderJSON
+	if err := m.decode(ChannelOrders, &o); err != nil {
+		return domain.Order{}, err
+	}
+	return o.toDomain(), nil
+}
+
+// Signal decodes a signals-channel message.
+func (m *FeedMessage) Signal() (domain.Signal, error) {
+	var sig signalJSON
+	if err := m.decode(ChannelSignals, &sig); err != nil {
+		return domain.Signal{}, err
+	}
+	return domain.Signal{
+		ID:         sig.ID,
+		StrategyID: sig.StrategyID,
+		Symbol:     sig.Symbol,
+		Type:       domain.SignalType(sig.Type),
+		Strength:   sig.Strength,
+		Metadata:   sig.Metadata,
+		CreatedAt:  sig.CreatedAt,
+	}, nil
+}
+
+// TargetEvent decodes a targets-channel message.
+func (m *FeedMessage) TargetEvent() (tradeparams.Event, error) {
+	var evt tradeparams.Event
+	err := m.decode(ChannelTargets, &evt)
+	return evt, err
+}
+
+func (m *FeedMessage) decode(channel string, v any) error {
+	if m.Channel != channel {
+		return fmt.Errorf("message is on channel %q, not %q", m.Channel, channel)
+	}
+	return json.Unmarshal(m.Data, v)
+}
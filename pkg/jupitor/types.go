@@ -0,0 +1,130 @@
+package jupitor
+
+import (
+	"time"
+
+	"jupitor/internal/domain"
+)
+
+// Wire types of the jupitor-server REST API.
+
+type listResponse[T any] struct {
+	Data          []T    `json:"data"`
+	NextPageToken string `json:"next_page_token,omitempty"`
+}
+
+type itemResponse[T any] struct {
+	Data T `json:"data"`
+}
+
+type barJSON struct {
+	Symbol     string    `json:"symbol"`
+	Timestamp  time.Time `json:"timestamp"`
+	Open       float64   `json:"open"`
+	High       float64   `json:"high"`
+	Low        float64   `json:"low"`
+	Close      float64   `json:"close"`
+	Volume     int64     `json:"volume"`
+	TradeCount int64     `json:"trade_count"`
+	VWAP       float64   `json:"vwap"`
+}
+
+type tradeJSON struct {
+	Symbol     string    `json:"symbol"`
+	Timestamp  time.Time `json:"timestamp"`
+	Price      float64   `json:"price"`
+	Size       int64     `json:"size"`
+	Exchange   string    `json:"exchange"`
+	ID         string    `json:"id"`
+	Conditions string    `json:"conditions,omitempty"`
+}
+
+func (t *tradeJSON) toDomain() domain.Trade {
+	return domain.Trade{
+		Symbol:     t.Symbol,
+		Timestamp:  t.Timestamp,
+		Price:      t.Price,
+		Size:       t.Size,
+		Exchange:   t.Exchange,
+		ID:         t.ID,
+		Conditions: t.Conditions,
+	}
+}
+
+type orderJSON struct {
+	ID             string    `json:"id"`
+	Symbol         string    `json:"symbol"`
+	Side           string    `json:"side"`
+	Type           string    `json:"type"`
+	TimeInForce    string    `json:"time_in_force"`
+	Qty            float64   `json:"qty"`
+	LimitPrice     float64   `json:"limit_price,omitempty"`
+	StopPrice      float64   `json:"stop_price,omitempty"`
+	Status         string    `json:"status"`
+	FilledQty      float64   `json:"filled_qty"`
+	FilledAvgPrice float64   `json:"filled_avg_price"`
+	StrategyID     string    `json:"strategy_id,omitempty"`
+	BrokerOrderID  string    `json:"broker_order_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func (o *orderJSON) toDomain() domain.Order {
+	return domain.Order{
+		ID:             o.ID,
+		Symbol:         o.Symbol,
+		Side:           domain.OrderSide(o.Side),
+		Type:           domain.OrderType(o.Type),
+		TimeInForce:    domain.TimeInForce(o.TimeInForce),
+		Qty:            o.Qty,
+		LimitPrice:     o.LimitPrice,
+		StopPrice:      o.StopPrice,
+		Status:         domain.OrderStatus(o.Status),
+		FilledQty:      o.FilledQty,
+		FilledAvgPrice: o.FilledAvgPrice,
+		StrategyID:     o.StrategyID,
+		BrokerOrderID:  o.BrokerOrderID,
+		CreatedAt:      o.CreatedAt,
+		UpdatedAt:      o.UpdatedAt,
+	}
+}
+
+type submitOrderJSON struct {
+	Symbol      string  `json:"symbol"`
+	Side        string  `json:"side"`
+	Type        string  `json:"type,omitempty"`
+	TimeInForce string  `json:"time_in_force,omitempty"`
+	Qty         float64 `json:"qty"`
+	LimitPrice  float64 `json:"limit_price,omitempty"`
+	StopPrice   float64 `json:"stop_price,omitempty"`
+	StrategyID  string  `json:"strategy_id,omitempty"`
+}
+
+type positionJSON struct {
+	Symbol          string  `json:"symbol"`
+	Qty             float64 `json:"qty"`
+	AvgEntryPrice   float64 `json:"avg_entry_price"`
+	MarketValue     float64 `json:"market_value"`
+	UnrealizedPL    float64 `json:"unrealized_pl"`
+	UnrealizedPLPct float64 `json:"unrealized_pl_pct"`
+	Side            string  `json:"side"`
+}
+
+type accountJSON struct {
+	Equity         float64 `json:"equity"`
+	Cash           float64 `json:"cash"`
+	BuyingPower    float64 `json:"buying_power"`
+	PortfolioValue float64 `json:"portfolio_value"`
+	DailyPL        float64 `json:"daily_pl"`
+	DailyPLPct     float64 `json:"daily_pl_pct"`
+}
+
+type signalJSON struct {
+	ID         int64             `json:"id"`
+	StrategyID string            `json:"strategy_id"`
+	Symbol     string            `json:"symbol"`
+	Type       string            `json:"type"`
+	Strength   float64           `json:"strength"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
@@ -8,8 +8,9 @@ Supports a --session mode that splits trades into PM (previous post-market +
 today's pre-market) and regular (9:30 AM - 4:00 PM ET) sessions, showing
 open→high gain% and trade counts for each.
 
-Note: timestamps in the parquet files are ET stored as UTC (Go code converts
-before writing). The window is (prev_date 4PM ET, date 4PM ET].
+Note: files are read with jupitor.data.read_trades, which returns Eastern
+timestamps whether the file holds real UTC (schema v2) or ET stored as UTC
+(older files). The window is (prev_date 4PM ET, date 4PM ET].
 
 Usage:
   python python/scripts/us_ex_index_trades.py                        # by dollar vol
@@ -27,6 +28,7 @@ from pathlib import Path
 import pandas as pd
 
 from jupitor.config import get_data_dir
+from jupitor.data import read_trades
 
 
 def find_dates(data_dir: Path) -> list[str]:
@@ -72,11 +74,10 @@ def _agg_session(df: pd.DataFrame) -> pd.DataFrame:
 
 def load_session_data(path: Path, date_str: str) -> pd.DataFrame:
     """Load parquet, split into PM and regular sessions, return merged per-symbol stats."""
-    df = pd.read_parquet(path, columns=["symbol", "timestamp", "price", "size"])
+    df = read_trades(path, columns=["symbol", "timestamp", "price", "size"])
     df["dollar"] = df["price"] * df["size"]
 
-    # Timestamps are ET stored as UTC (Go converts before writing)
-    regular_open = pd.Timestamp(f"{date_str} 09:30:00", tz="UTC")
+    regular_open = pd.Timestamp(f"{date_str} 09:30:00", tz="America/New_York")
 
     pm_agg = _agg_session(df[df["timestamp"] < regular_open])
     reg_agg = _agg_session(df[df["timestamp"] >= regular_open])
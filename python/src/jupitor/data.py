@@ -5,9 +5,18 @@ from __future__ import annotations
 from pathlib import Path
 
 import pandas as pd
+import pyarrow.parquet as pq
 
 from jupitor.config import get_data_dir
 
+# Trade files record their timestamp convention under this parquet key-value
+# metadata key (see internal/store/timestamps.go). Files tagged "utc-ms"
+# (schema v2) hold real UTC; untagged files from older versions hold Eastern
+# wall-clock time stored as if it were UTC.
+TIMESTAMP_KEY = b"jupitor.timestamps"
+TIMESTAMPS_UTC = b"utc-ms"
+ET = "America/New_York"
+
 
 def read_daily_bars(symbol: str, market: str = "us", year: int | None = None) -> pd.DataFrame:
     """Read daily bar data from Parquet files.
@@ -43,6 +52,35 @@ def read_daily_bars(symbol: str, market: str = "us", year: int | None = None) ->
     return pd.concat(frames, ignore_index=True)
 
 
+def read_trades(path: str | Path, columns: list[str] | None = None) -> pd.DataFrame:
+    """Read a trade parquet file (per-symbol trades or stock-trades-*).
+
+    Args:
+        path: Path to the parquet file.
+        columns: Columns to read. If None, reads all columns.
+
+    Returns:
+        DataFrame whose timestamp column, if read, is timezone-aware
+        America/New_York whichever timestamp convention the file uses.
+    """
+    df = pd.read_parquet(path, columns=columns)
+    if "timestamp" not in df.columns:
+        return df
+
+    ts = df["timestamp"]
+    if ts.dt.tz is None:
+        ts = ts.dt.tz_localize("UTC")
+    metadata = pq.read_metadata(path).metadata or {}
+    if metadata.get(TIMESTAMP_KEY) == TIMESTAMPS_UTC:
+        ts = ts.dt.tz_convert(ET)
+    else:
+        # Session time: the UTC reading is the Eastern wall clock. Repeated
+        # hours resolve to the earlier (EDT) instant, as in the Go code.
+        ts = ts.dt.tz_localize(None).dt.tz_localize(ET, ambiguous=True, nonexistent="shift_forward")
+    df["timestamp"] = ts
+    return df
+
+
 def list_symbols(market: str = "us") -> list[str]:
     """List all symbols with available data.
 
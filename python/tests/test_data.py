@@ -1,6 +1,9 @@
 """Tests for jupitor.data module."""
 
-from jupitor.data import list_symbols, read_daily_bars
+import pyarrow as pa
+import pyarrow.parquet as pq
+
+from jupitor.data import list_symbols, read_daily_bars, read_trades
 
 
 def test_read_daily_bars_missing_symbol(tmp_path, monkeypatch):
@@ -15,3 +18,27 @@ def test_list_symbols_empty(tmp_path, monkeypatch):
     monkeypatch.setenv("DATA_1", str(tmp_path))
     symbols = list_symbols("us")
     assert symbols == []
+
+
+def _write_trades(path, millis, utc):
+    table = pa.table({
+        "symbol": ["AAPL"] * len(millis),
+        "timestamp": pa.array(millis, type=pa.timestamp("ms", tz="UTC")),
+    })
+    if utc:
+        table = table.replace_schema_metadata({"jupitor.timestamps": "utc-ms"})
+    pq.write_table(table, path)
+
+
+def test_read_trades_timestamp_conventions(tmp_path):
+    """Session-time (v1) and UTC (v2) files read back as the same ET instant."""
+    # 2024-03-11 09:30 EDT is 13:30 UTC.
+    v1 = tmp_path / "v1.parquet"
+    v2 = tmp_path / "v2.parquet"
+    _write_trades(v1, [1710149400000], utc=False)  # 09:30 as if UTC
+    _write_trades(v2, [1710163800000], utc=True)   # 13:30 UTC
+
+    for path in (v1, v2):
+        ts = read_trades(path)["timestamp"].iloc[0]
+        assert str(ts.tz) == "America/New_York"
+        assert ts.strftime("%Y-%m-%d %H:%M") == "2024-03-11 09:30"